@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
 )
 
 // PutObjectAcl AclGrant, AclRules can not set both.
@@ -45,26 +47,53 @@ func (bkt *Bucket) PutObjectAcl(ctx context.Context, input *PutObjectAclInput, o
 	return &PutObjectAclOutput{RequestInfo: res.RequestInfo()}, nil
 }
 
-// PutObjectACL put object ACL
+// PutObjectACL set ACL of the object, either by canned ACL and grant headers or by explicit Grants with Owner
 func (cli *ClientV2) PutObjectACL(ctx context.Context, input *PutObjectACLInput) (*PutObjectACLOutput, error) {
-	if err := isValidKey(input.Key); err != nil {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
 		return nil, err
 	}
-	var content io.Reader
+	byHeader := len(input.ACL) != 0 || len(input.GrantFullControl) != 0 || len(input.GrantRead) != 0 ||
+		len(input.GrantReadAcp) != 0 || len(input.GrantWrite) != 0 || len(input.GrantWriteAcp) != 0
+	if byHeader && len(input.Grants) != 0 {
+		return nil, newTosClientError("tos: ACL or grant headers and Grants can not be set both", nil)
+	}
+	if !byHeader && len(input.Grants) == 0 {
+		return nil, newTosClientError("tos: one of ACL, grant headers and Grants is required", nil)
+	}
+	var (
+		content    io.Reader
+		contentMD5 string
+	)
+	if len(input.ACL) != 0 {
+		if err := isValidACL(input.ACL); err != nil {
+			return nil, err
+		}
+	}
 	if len(input.Grants) != 0 {
-		data, err := json.Marshal(&accessControlList{
+		if len(input.Owner.ID) == 0 {
+			return nil, newTosClientError("tos: Owner.ID is required when Grants is set", nil)
+		}
+		if err := isValidGrants(input.Grants); err != nil {
+			return nil, err
+		}
+		data, md5Sum, err := marshalInput("PutObjectACLInput", &accessControlList{
 			Owner:  input.Owner,
 			Grants: input.Grants,
 		})
 		if err != nil {
-			return nil, newTosClientError("tos: marshal BucketAcl Ruels failed", err)
+			return nil, err
 		}
-		content = bytes.NewReader(data)
+		content, contentMD5 = bytes.NewReader(data), md5Sum
 	}
-	builder := cli.newBuilder(input.Bucket, input.Key).
+	res, err := cli.newBuilder(input.Bucket, input.Key).
 		WithQuery("acl", "").
-		WithParams(*input)
-	res, err := builder.Request(ctx, http.MethodPut, content, cli.roundTripper(http.StatusOK))
+		WithParams(*input).
+		WithHeader(HeaderContentMD5, contentMD5).
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodPut, content, cli.roundTripper(http.StatusOK))
 	if err != nil {
 		return nil, err
 	}
@@ -101,17 +130,18 @@ func (bkt *Bucket) GetObjectAcl(ctx context.Context, objectKey string, options .
 	return &out, nil
 }
 
-// GetObjectACL get object ACL
+// GetObjectACL get owner and grants of the object ACL
 func (cli *ClientV2) GetObjectACL(ctx context.Context, input *GetObjectACLInput) (*GetObjectACLOutput, error) {
-	if err := IsValidBucketName(input.Bucket); err != nil {
-		return nil, err
+	if input == nil {
+		return nil, InputIsNilClientError
 	}
-	if err := isValidKey(input.Key); err != nil {
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
 		return nil, err
 	}
 	res, err := cli.newBuilder(input.Bucket, input.Key).
 		WithQuery("acl", "").
 		WithParams(*input).
+		WithRetry(nil, StatusCodeClassifier{}).
 		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
 	if err != nil {
 		return nil, err
@@ -125,3 +155,103 @@ func (cli *ClientV2) GetObjectACL(ctx context.Context, input *GetObjectACLInput)
 	out.VersionID = res.Header.Get(HeaderVersionID)
 	return &out, nil
 }
+
+// isValidGrants validate grants of object or bucket ACL
+func isValidGrants(grants []GrantV2) error {
+	for _, grant := range grants {
+		grantee := grant.GranteeV2
+		switch grantee.Type {
+		case enum.GranteeUser:
+			if len(grantee.ID) == 0 {
+				return newTosClientError("tos: grantee ID is required for CanonicalUser grantee", nil)
+			}
+		case enum.GranteeGroup:
+			if len(grantee.Canned) == 0 {
+				return newTosClientError("tos: grantee Canned is required for Group grantee", nil)
+			}
+		default:
+			return newTosClientError("tos: invalid grantee type", nil)
+		}
+		if len(grant.Permission) == 0 {
+			return newTosClientError("tos: grant permission is required", nil)
+		}
+	}
+	return nil
+}
+
+// PutBucketACL set ACL of the bucket, either by canned ACL or by explicit grants.
+// Output of GetBucketACL can be put back as is, grants with permissions unknown to sdk are kept.
+func (cli *ClientV2) PutBucketACL(ctx context.Context, input *PutBucketACLInput) (*PutBucketACLOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if len(input.ACL) != 0 && len(input.Grants) != 0 {
+		return nil, newTosClientError("tos: ACL and Grants can not be set both", nil)
+	}
+	if len(input.ACL) == 0 && len(input.Grants) == 0 {
+		return nil, newTosClientError("tos: one of ACL and Grants is required", nil)
+	}
+	var (
+		content    io.Reader
+		contentMD5 string
+	)
+	if len(input.ACL) != 0 {
+		if err := isValidACL(input.ACL); err != nil {
+			return nil, err
+		}
+	} else {
+		if len(input.Owner.ID) == 0 {
+			return nil, newTosClientError("tos: Owner.ID is required when Grants is set", nil)
+		}
+		if err := isValidGrants(input.Grants); err != nil {
+			return nil, err
+		}
+		data, md5Sum, err := marshalInput("PutBucketACLInput", &bucketAccessControlList{
+			Owner:              input.Owner,
+			Grants:             input.Grants,
+			BucketAclDelivered: input.BucketAclDelivered,
+		})
+		if err != nil {
+			return nil, err
+		}
+		content, contentMD5 = bytes.NewReader(data), md5Sum
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("acl", "").
+		WithParams(*input).
+		WithHeader(HeaderContentMD5, contentMD5).
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodPut, content, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketACLOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketACL get owner and grants of the bucket ACL
+func (cli *ClientV2) GetBucketACL(ctx context.Context, input *GetBucketACLInput) (*GetBucketACLOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("acl", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	out := GetBucketACLOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(out.RequestID, res.Body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
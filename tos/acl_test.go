@@ -0,0 +1,138 @@
+package tos
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+func TestObjectACL(t *testing.T) {
+	var body []byte
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodPut {
+			if req.Content != nil {
+				body, _ = ioutil.ReadAll(req.Content)
+			}
+			return newMockResponse(http.StatusOK, "")()
+		}
+		res := newMockResponse(http.StatusOK, `{"Owner":{"ID":"owner"},"Grants":[`+
+			`{"Grantee":{"ID":"user","Type":"CanonicalUser"},"Permission":"READ"},`+
+			`{"Grantee":{"Type":"Group","Canned":"AllUsers"},"Permission":"READ_ACP"}]}`)()
+		res.Header.Set(HeaderVersionID, "version")
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.PutObjectACL(ctx, &PutObjectACLInput{Bucket: "bucket", Key: "key", VersionID: "version", ACL: enum.ACLPublicRead})
+	require.Nil(t, err)
+	req := transport.recorded()[0]
+	require.Contains(t, req.Query, "acl")
+	require.Equal(t, "version", req.Query.Get("versionId"))
+	require.Equal(t, string(enum.ACLPublicRead), req.Header.Get(HeaderACL))
+	require.Nil(t, body)
+
+	grants := []GrantV2{{GranteeV2: GranteeV2{ID: "user", Type: enum.GranteeUser}, Permission: enum.PermissionRead}}
+	_, err = client.PutObjectACL(ctx, &PutObjectACLInput{Bucket: "bucket", Key: "key", Owner: Owner{ID: "owner"}, Grants: grants})
+	require.Nil(t, err)
+	req = transport.recorded()[1]
+	require.Equal(t, "", req.Header.Get(HeaderACL))
+	require.NotEqual(t, "", req.Header.Get(HeaderContentMD5))
+	var acl accessControlList
+	require.Nil(t, json.Unmarshal(body, &acl))
+	require.Equal(t, "owner", acl.Owner.ID)
+	require.Equal(t, grants, acl.Grants)
+
+	// grant headers without canned ACL
+	_, err = client.PutObjectACL(ctx, &PutObjectACLInput{Bucket: "bucket", Key: "key", GrantRead: "id=user"})
+	require.Nil(t, err)
+	require.Equal(t, "id=user", transport.recorded()[2].Header.Get(HeaderGrantRead))
+
+	for _, input := range []*PutObjectACLInput{
+		{Bucket: "bucket", Key: "key"},
+		{Bucket: "bucket", Key: "key", ACL: enum.ACLPrivate, Owner: Owner{ID: "owner"}, Grants: grants},
+		{Bucket: "bucket", Key: "key", GrantRead: "id=user", Owner: Owner{ID: "owner"}, Grants: grants},
+		{Bucket: "bucket", Key: "key", ACL: "invalid"},
+		{Bucket: "bucket", Key: "key", Grants: grants},
+		{Bucket: "bucket", Key: "key", Owner: Owner{ID: "owner"}, Grants: []GrantV2{{GranteeV2: GranteeV2{Type: enum.GranteeUser}, Permission: enum.PermissionRead}}},
+		{Bucket: "bucket", Key: "key", Owner: Owner{ID: "owner"}, Grants: []GrantV2{{GranteeV2: GranteeV2{Type: enum.GranteeGroup}, Permission: enum.PermissionRead}}},
+		{Bucket: "bucket", Key: "key", Owner: Owner{ID: "owner"}, Grants: []GrantV2{{GranteeV2: GranteeV2{ID: "user"}, Permission: enum.PermissionRead}}},
+		{Bucket: "bucket", Key: "key", Owner: Owner{ID: "owner"}, Grants: []GrantV2{{GranteeV2: GranteeV2{ID: "user", Type: enum.GranteeUser}}}},
+	} {
+		_, err = client.PutObjectACL(ctx, input)
+		require.NotNil(t, err)
+	}
+	require.Len(t, transport.recorded(), 3)
+
+	get, err := client.GetObjectACL(ctx, &GetObjectACLInput{Bucket: "bucket", Key: "key", VersionID: "version"})
+	require.Nil(t, err)
+	req = transport.recorded()[3]
+	require.Equal(t, http.MethodGet, req.Method)
+	require.Equal(t, "version", req.Query.Get("versionId"))
+	require.Equal(t, "version", get.VersionID)
+	require.Equal(t, "owner", get.Owner.ID)
+	require.Len(t, get.Grants, 2)
+	require.Equal(t, enum.GranteeUser, get.Grants[0].GranteeV2.Type)
+	require.Equal(t, enum.PermissionRead, get.Grants[0].Permission)
+	require.Equal(t, enum.CannedAllUsers, get.Grants[1].GranteeV2.Canned)
+}
+
+func TestBucketACL(t *testing.T) {
+	var body []byte
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodPut {
+			if req.Content != nil {
+				body, _ = ioutil.ReadAll(req.Content)
+			}
+			return newMockResponse(http.StatusOK, "")()
+		}
+		return newMockResponse(http.StatusOK, `{"Owner":{"ID":"owner"},"BucketAclDelivered":true,"Grants":[
+			{"Grantee":{"ID":"user","Type":"CanonicalUser"},"Permission":"FULL_CONTROL"},
+			{"Grantee":{"Type":"Group","Canned":"AllUsers"},"Permission":"READ"},
+			{"Grantee":{"Type":"Group","Canned":"AuthenticatedUsers"},"Permission":"READ_NON_LIST"}]}`)()
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	get, err := client.GetBucketACL(ctx, &GetBucketACLInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, "owner", get.Owner.ID)
+	require.True(t, get.BucketAclDelivered)
+	require.Equal(t, 3, len(get.Grants))
+	require.Equal(t, enum.CannedAllUsers, get.Grants[1].GranteeV2.Canned)
+	// permission unknown to sdk is kept
+	require.Equal(t, enum.PermissionType("READ_NON_LIST"), get.Grants[2].Permission)
+
+	_, err = client.PutBucketACL(ctx, &PutBucketACLInput{Bucket: "bucket", Owner: get.Owner, Grants: get.Grants, BucketAclDelivered: get.BucketAclDelivered})
+	require.Nil(t, err)
+	var put GetBucketACLOutput
+	require.Nil(t, json.Unmarshal(body, &put))
+	require.Equal(t, get.Owner, put.Owner)
+	require.Equal(t, get.Grants, put.Grants)
+	require.True(t, put.BucketAclDelivered)
+	require.NotEmpty(t, transport.recorded()[1].Header.Get(HeaderContentMD5))
+
+	body = nil
+	_, err = client.PutBucketACL(ctx, &PutBucketACLInput{Bucket: "bucket", ACL: enum.ACLPublicRead})
+	require.Nil(t, err)
+	require.Nil(t, body)
+	require.Equal(t, "public-read", transport.recorded()[2].Header.Get(HeaderACL))
+
+	requests := len(transport.recorded())
+	_, err = client.PutBucketACL(ctx, &PutBucketACLInput{Bucket: "bucket"})
+	require.NotNil(t, err)
+	_, err = client.PutBucketACL(ctx, &PutBucketACLInput{Bucket: "bucket", ACL: enum.ACLPublicRead, Grants: get.Grants})
+	require.NotNil(t, err)
+	_, err = client.PutBucketACL(ctx, &PutBucketACLInput{Bucket: "bucket", Owner: get.Owner, Grants: []GrantV2{{GranteeV2: GranteeV2{Type: enum.GranteeGroup}, Permission: enum.PermissionRead}}})
+	require.NotNil(t, err)
+	// Owner is required with Grants
+	_, err = client.PutBucketACL(ctx, &PutBucketACLInput{Bucket: "bucket", Grants: get.Grants})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "Owner.ID")
+	require.Equal(t, requests, len(transport.recorded()))
+}
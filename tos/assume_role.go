@@ -0,0 +1,209 @@
+package tos
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSTSEndpoint = "https://sts.volcengineapi.com"
+	DefaultSTSRegion   = "cn-north-1"
+
+	stsVersion        = "2018-01-01"
+	stsService        = "sts"
+	stsSignAlgorithm  = "HMAC-SHA256"
+	stsSignedHeaders  = "host;x-content-sha256;x-date"
+	stsDefaultRetries = 3
+)
+
+type stsError struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+type assumeRoleResponse struct {
+	ResponseMetadata struct {
+		RequestID string    `json:"RequestId"`
+		Error     *stsError `json:"Error,omitempty"`
+	} `json:"ResponseMetadata"`
+	Result struct {
+		Credentials struct {
+			AccessKeyID     string    `json:"AccessKeyId"`
+			SecretAccessKey string    `json:"SecretAccessKey"`
+			SessionToken    string    `json:"SessionToken"`
+			ExpiredTime     time.Time `json:"ExpiredTime"`
+		} `json:"Credentials"`
+	} `json:"Result"`
+}
+
+// AssumeRoleCredentialsProvider provides temporary Credential of the role returned by STS AssumeRole,
+// which is called with the Credential of the base provider. The temporary Credential is cached and
+// refreshed before it expires as FederationCredentialsProvider does, and transient STS failures are retried.
+//
+// use WithEndpoint set STS endpoint, WithRegion set STS region, WithMaxRetryCount set max retries of
+// each AssumeRole call, and Expiration get expiration of the current role session.
+type AssumeRoleCredentialsProvider struct {
+	*FederationCredentialsProvider
+	base        CredentialsProvider
+	roleTrn     string
+	sessionName string
+	duration    time.Duration
+	endpoint    string
+	region      string
+	maxRetry    int
+	client      *http.Client
+}
+
+// NewAssumeRoleCredentialsProvider create AssumeRoleCredentialsProvider assuming roleTrn, e.g. trn:iam::2100000000:role/name,
+// duration of role sessions is 1 hour if it's zero.
+func NewAssumeRoleCredentialsProvider(base CredentialsProvider, roleTrn, sessionName string, duration time.Duration) *AssumeRoleCredentialsProvider {
+	if duration == 0 {
+		duration = time.Hour
+	}
+	provider := &AssumeRoleCredentialsProvider{
+		base:        base,
+		roleTrn:     roleTrn,
+		sessionName: sessionName,
+		duration:    duration,
+		endpoint:    DefaultSTSEndpoint,
+		region:      DefaultSTSRegion,
+		maxRetry:    stsDefaultRetries,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+	provider.FederationCredentialsProvider = NewFederationCredentialsProvider(provider.assumeRole)
+	return provider
+}
+
+// WithEndpoint set STS endpoint
+func (ap *AssumeRoleCredentialsProvider) WithEndpoint(endpoint string) {
+	ap.endpoint = strings.TrimSuffix(endpoint, "/")
+}
+
+// WithRegion set STS region used for signing
+func (ap *AssumeRoleCredentialsProvider) WithRegion(region string) {
+	ap.region = region
+}
+
+// WithMaxRetryCount set max retries of network errors, 429 and 5xx of each AssumeRole call
+func (ap *AssumeRoleCredentialsProvider) WithMaxRetryCount(maxRetry int) {
+	ap.maxRetry = maxRetry
+}
+
+func (ap *AssumeRoleCredentialsProvider) assumeRole(ctx context.Context) (*FederationToken, error) {
+	if len(ap.roleTrn) == 0 || len(ap.sessionName) == 0 {
+		return nil, newTosClientError("tos: role trn and session name are required", nil)
+	}
+	base, err := ap.base.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	backoff := 100 * time.Millisecond
+	for retry := 0; ; retry++ {
+		token, retryable, err := ap.doAssumeRole(ctx, &base)
+		if err == nil {
+			return token, nil
+		}
+		if !retryable || retry >= ap.maxRetry {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// doAssumeRole calls AssumeRole once, and reports whether the error is transient
+func (ap *AssumeRoleCredentialsProvider) doAssumeRole(ctx context.Context, base *Credential) (*FederationToken, bool, error) {
+	u, err := url.Parse(ap.endpoint)
+	if err != nil || len(u.Host) == 0 {
+		return nil, false, newTosClientError("tos: invalid STS endpoint "+ap.endpoint, err)
+	}
+	query := url.Values{}
+	query.Set("Action", "AssumeRole")
+	query.Set("Version", stsVersion)
+	query.Set("RoleTrn", ap.roleTrn)
+	query.Set("RoleSessionName", ap.sessionName)
+	query.Set("DurationSeconds", strconv.Itoa(int(ap.duration/time.Second)))
+	u.Path = "/"
+	u.RawQuery = strings.Replace(query.Encode(), "+", "%20", -1)
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, false, newTosClientError("tos: invalid STS request", err)
+	}
+	signSTSRequest(req, base, ap.region, UTCNow())
+	res, err := ap.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, true, newTosClientError("tos: request STS failed", err)
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, true, newTosClientError("tos: read STS response failed", err)
+	}
+	retryable := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError
+	var output assumeRoleResponse
+	if err = json.Unmarshal(data, &output); err != nil {
+		return nil, retryable, newTosClientError("tos: invalid STS response with status "+res.Status, err)
+	}
+	if se := output.ResponseMetadata.Error; se != nil || res.StatusCode != http.StatusOK {
+		message := "tos: AssumeRole failed with status " + res.Status
+		if se != nil {
+			message += ", Code=" + se.Code + ", Message=" + se.Message
+		}
+		return nil, retryable, newTosClientError(message+", RequestID="+output.ResponseMetadata.RequestID, nil)
+	}
+	cred := output.Result.Credentials
+	return &FederationToken{
+		Credential: Credential{
+			AccessKeyID:     cred.AccessKeyID,
+			AccessKeySecret: cred.SecretAccessKey,
+			SecurityToken:   cred.SessionToken,
+		},
+		Expiration: cred.ExpiredTime,
+	}, false, nil
+}
+
+// signSTSRequest signs request of volcengine OpenAPI with HMAC-SHA256, the body is always empty
+func signSTSRequest(req *http.Request, cred *Credential, region string, now time.Time) {
+	date := now.Format(iso8601Layout)
+	scope := now.Format(yyMMdd) + "/" + region + "/" + stsService + "/request"
+	req.Header.Set("X-Date", date)
+	req.Header.Set("X-Content-Sha256", emptySHA256)
+	if len(cred.SecurityToken) > 0 {
+		req.Header.Set("X-Security-Token", cred.SecurityToken)
+	}
+
+	canonical := strings.Join([]string{
+		req.Method,
+		"/",
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-content-sha256:" + emptySHA256,
+		"x-date:" + date,
+		"",
+		stsSignedHeaders,
+		emptySHA256,
+	}, "\n")
+	sum := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{stsSignAlgorithm, date, scope, hex.EncodeToString(sum[:])}, "\n")
+
+	key := hmacSHA256([]byte(cred.AccessKeySecret), []byte(now.Format(yyMMdd)))
+	key = hmacSHA256(key, []byte(region))
+	key = hmacSHA256(key, []byte(stsService))
+	key = hmacSHA256(key, []byte("request"))
+	signature := hex.EncodeToString(hmacSHA256(key, []byte(stringToSign)))
+	req.Header.Set(authorization, stsSignAlgorithm+" Credential="+cred.AccessKeyID+"/"+scope+
+		", SignedHeaders="+stsSignedHeaders+", Signature="+signature)
+}
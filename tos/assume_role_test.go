@@ -0,0 +1,80 @@
+package tos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssumeRoleCredentialsProvider(t *testing.T) {
+	var requests int32
+	expiration := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("Action") != "AssumeRole" || query.Get("RoleTrn") != "trn:iam::2100000000:role/reader" ||
+			query.Get("RoleSessionName") != "session" || query.Get("DurationSeconds") != "900" ||
+			r.Header.Get("X-Security-Token") != "base-sts" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "HMAC-SHA256 Credential=base-ak/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/cn-north-1/sts/request") {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"ResponseMetadata":{"RequestId":"id","Error":{"Code":"InvalidParameter","Message":"bad request"}}}`)
+			return
+		}
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"ResponseMetadata":{"RequestId":"id","Error":{"Code":"ServiceUnavailable","Message":"busy"}}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"ResponseMetadata":{"RequestId":"id"},"Result":{"Credentials":{"AccessKeyId":"role-ak",
+			"SecretAccessKey":"role-sk","SessionToken":"role-sts","ExpiredTime":%q}}}`, expiration.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	base := NewStaticCredentials("base-ak", "base-sk")
+	base.WithSecurityToken("base-sts")
+	provider := NewAssumeRoleCredentialsProvider(base, "trn:iam::2100000000:role/reader", "session", 15*time.Minute)
+	provider.WithEndpoint(server.URL)
+	require.True(t, provider.Expiration().IsZero())
+
+	cred, err := provider.Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, Credential{AccessKeyID: "role-ak", AccessKeySecret: "role-sk", SecurityToken: "role-sts"}, cred)
+	require.True(t, expiration.Equal(provider.Expiration()))
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// cached
+	_, err = provider.Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// not retried
+	provider = NewAssumeRoleCredentialsProvider(base, "trn:iam::2100000000:role/writer", "session", 15*time.Minute)
+	provider.WithEndpoint(server.URL)
+	_, err = provider.Credentials(context.Background())
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "InvalidParameter")
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestSignSTSRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://sts.volcengineapi.com/?Action=AssumeRole&Version=2018-01-01", nil)
+	require.Nil(t, err)
+	now := time.Date(2023, 6, 1, 8, 0, 0, 0, time.UTC)
+	signSTSRequest(req, &Credential{AccessKeyID: "ak", AccessKeySecret: "sk"}, "cn-north-1", now)
+	require.Equal(t, "20230601T080000Z", req.Header.Get("X-Date"))
+	require.Equal(t, "", req.Header.Get("X-Security-Token"))
+	auth := req.Header.Get("Authorization")
+	require.True(t, strings.HasPrefix(auth, "HMAC-SHA256 Credential=ak/20230601/cn-north-1/sts/request, SignedHeaders=host;x-content-sha256;x-date, Signature="))
+
+	// deterministic
+	again, _ := http.NewRequest(http.MethodGet, req.URL.String(), nil)
+	signSTSRequest(again, &Credential{AccessKeyID: "ak", AccessKeySecret: "sk"}, "cn-north-1", now)
+	require.Equal(t, auth, again.Header.Get("Authorization"))
+}
@@ -3,6 +3,7 @@ package tos
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
 )
@@ -56,6 +57,9 @@ func (cli *ClientV2) CreateBucketV2(ctx context.Context, input *CreateBucketV2In
 			return nil, err
 		}
 	}
+	if err := isValidCreateBucket(input); err != nil {
+		return nil, err
+	}
 
 	res, err := cli.newBuilder(input.Bucket, "").
 		WithParams(*input).
@@ -89,12 +93,63 @@ func (cli *Client) HeadBucket(ctx context.Context, bucket string) (*HeadBucketOu
 		RequestInfo:  res.RequestInfo(),
 		Region:       res.Header.Get(HeaderBucketRegion),
 		StorageClass: enum.StorageClassType(res.Header.Get(HeaderStorageClass)),
+		AzRedundancy: enum.AzRedundancyType(res.Header.Get(HeaderAzRedundancy)),
+		ProjectName:  res.Header.Get(HeaderProjectName),
+		BucketType:   enum.BucketType(res.Header.Get(HeaderBucketType)),
 	}, nil
 }
 
-// HeadBucket get some info of a bucket
+// HeadBucket get region, storage class, AZ redundancy, project and type of a bucket.
+// *BucketNotFoundError is returned if the bucket does not exist,
+// and *BucketAccessDeniedError if it exists but can not be accessed with the credentials.
+// They are returned instead of *TosServerError for 404 and 403, so callers asserting *TosServerError
+// should assert them instead, or use StatusCode, Code and RequestID which work with all of them.
 func (cli *ClientV2) HeadBucket(ctx context.Context, input *HeadBucketInput) (*HeadBucketOutput, error) {
-	return cli.Client.HeadBucket(ctx, input.Bucket)
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	output, err := cli.Client.HeadBucket(ctx, input.Bucket)
+	if err != nil {
+		return nil, newHeadBucketError(err, input.Bucket)
+	}
+	return output, nil
+}
+
+// GetBucketLocation get region and endpoints of the bucket, use ClientForRegion to access the bucket
+// if it's not in the region of the client.
+func (cli *ClientV2) GetBucketLocation(ctx context.Context, input *GetBucketLocationInput) (*GetBucketLocationOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("location", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetBucketLocationOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// DoesBucketExist returns false only if the bucket is not found,
+// other errors such as 403 and network errors are returned as is
+func (cli *ClientV2) DoesBucketExist(ctx context.Context, bucket string) (bool, error) {
+	_, err := cli.Client.HeadBucket(ctx, bucket)
+	if err == nil {
+		return true, nil
+	}
+	if isNotFound(err) {
+		return false, nil
+	}
+	return false, err
 }
 
 // DeleteBucket delete a bucket
@@ -139,9 +194,14 @@ func (cli *Client) ListBuckets(ctx context.Context, _ *ListBucketsInput) (*ListB
 	return &output, nil
 }
 
-// ListBuckets list the buckets that the AK can access
-func (cli *ClientV2) ListBuckets(ctx context.Context, _ *ListBucketsInput) (*ListBucketsOutput, error) {
+// ListBuckets list the buckets that the AK can access, filtered by ProjectName if it is set, input can be nil.
+// CreationDate of buckets is parsed as CreationTime, and Buckets is empty but not nil if there is no bucket.
+func (cli *ClientV2) ListBuckets(ctx context.Context, input *ListBucketsInput) (*ListBucketsOutput, error) {
+	if input == nil {
+		input = &ListBucketsInput{}
+	}
 	res, err := cli.newBuilder("", "").
+		WithParams(*input).
 		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
 	if err != nil {
 		return nil, err
@@ -152,5 +212,38 @@ func (cli *ClientV2) ListBuckets(ctx context.Context, _ *ListBucketsInput) (*Lis
 	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
 		return nil, err
 	}
+	if output.Buckets == nil {
+		output.Buckets = []ListedBucket{}
+	}
+	for i := range output.Buckets {
+		output.Buckets[i].CreationTime, _ = time.Parse(time.RFC3339, output.Buckets[i].CreationDate)
+	}
 	return &output, nil
 }
+
+// PutBucketStorageClass change the default storage class of bucket, objects already in the bucket are not changed.
+// Invalid storage class is rejected by sdk with *TosClientError, and storage class not supported
+// in the region is rejected by server with *TosServerError.
+func (cli *ClientV2) PutBucketStorageClass(ctx context.Context, input *PutBucketStorageClassInput) (*PutBucketStorageClassOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if input.StorageClass == "" {
+		return nil, newTosClientError("tos: StorageClass is required", nil)
+	}
+	if err := isValidStorageClass(input.StorageClass); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("storageClass", "").
+		WithParams(*input).
+		Request(ctx, http.MethodPut, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketStorageClassOutput{RequestInfo: res.RequestInfo()}, nil
+}
@@ -0,0 +1,209 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+func TestListBuckets(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Header.Get(HeaderProjectName) == "empty" {
+			return newMockResponse(http.StatusOK, `{"Owner":{"ID":"owner"}}`)()
+		}
+		return newMockResponse(http.StatusOK, `{"Buckets":[{"Name":"bucket","Location":"cn-beijing",
+			"CreationDate":"2022-09-09T08:00:00.000Z","ExtranetEndpoint":"tos-cn-beijing.volces.com",
+			"IntranetEndpoint":"tos-cn-beijing.ivolces.com","ProjectName":"project","BucketType":"hns"}],"Owner":{"ID":"owner"}}`)()
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	output, err := client.ListBuckets(ctx, &ListBucketsInput{ProjectName: "project"})
+	require.Nil(t, err)
+	require.Equal(t, "project", transport.recorded()[0].Header.Get(HeaderProjectName))
+	require.Equal(t, 1, len(output.Buckets))
+	bucket := output.Buckets[0]
+	require.Equal(t, "bucket", bucket.Name)
+	require.Equal(t, "cn-beijing", bucket.Location)
+	require.Equal(t, time.Date(2022, 9, 9, 8, 0, 0, 0, time.UTC), bucket.CreationTime.UTC())
+	require.Equal(t, "2022-09-09T08:00:00.000Z", bucket.CreationDate)
+	require.Equal(t, "tos-cn-beijing.volces.com", bucket.ExtranetEndpoint)
+	require.Equal(t, "tos-cn-beijing.ivolces.com", bucket.IntranetEndpoint)
+	require.Equal(t, "project", bucket.ProjectName)
+	require.Equal(t, enum.BucketTypeHNS, bucket.BucketType)
+	require.Equal(t, "owner", output.Owner.ID)
+
+	output, err = client.ListBuckets(ctx, &ListBucketsInput{ProjectName: "empty"})
+	require.Nil(t, err)
+	require.NotNil(t, output.Buckets)
+	require.Equal(t, 0, len(output.Buckets))
+
+	// nil input lists all buckets
+	_, err = client.ListBuckets(ctx, nil)
+	require.Nil(t, err)
+	require.Equal(t, "", transport.recorded()[2].Header.Get(HeaderProjectName))
+}
+
+func TestPutBucketStorageClass(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodHead {
+			res := newMockResponse(http.StatusOK, "")()
+			res.Body = nil
+			res.Header.Set(HeaderStorageClass, "IA")
+			return res
+		}
+		if req.Header.Get(HeaderStorageClass) == string(enum.StorageClassDeepColdArchive) {
+			return newMockResponse(http.StatusBadRequest, `{"Code":"InvalidStorageClass","Message":"not supported in region"}`)()
+		}
+		return newMockResponse(http.StatusOK, "")()
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.PutBucketStorageClass(ctx, &PutBucketStorageClassInput{Bucket: "bucket", StorageClass: enum.StorageClassIa})
+	require.Nil(t, err)
+	req := transport.recorded()[0]
+	require.Equal(t, http.MethodPut, req.Method)
+	require.Contains(t, req.Query, "storageClass")
+	require.Equal(t, "IA", req.Header.Get(HeaderStorageClass))
+
+	head, err := client.HeadBucket(ctx, &HeadBucketInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, enum.StorageClassIa, head.StorageClass)
+
+	_, err = client.PutBucketStorageClass(ctx, &PutBucketStorageClassInput{Bucket: "bucket", StorageClass: enum.StorageClassDeepColdArchive})
+	serverErr, ok := err.(*TosServerError)
+	require.True(t, ok)
+	require.Equal(t, "InvalidStorageClass", serverErr.Code)
+
+	requests := len(transport.recorded())
+	for _, storageClass := range []enum.StorageClassType{"", "GLACIER"} {
+		_, err = client.PutBucketStorageClass(ctx, &PutBucketStorageClassInput{Bucket: "bucket", StorageClass: storageClass})
+		_, ok = err.(*TosClientError)
+		require.True(t, ok)
+	}
+	require.Equal(t, requests, len(transport.recorded()))
+}
+
+func TestGetBucketLocation(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if _, ok := req.Query["location"]; ok {
+			return newMockResponse(http.StatusOK, `{"Region":"cn-shanghai","ExtranetEndpoint":"tos-cn-shanghai.volces.com",
+				"IntranetEndpoint":"tos-cn-shanghai.ivolces.com"}`)()
+		}
+		res := newMockResponse(http.StatusOK, "")()
+		res.Body = nil
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	location, err := client.GetBucketLocation(ctx, &GetBucketLocationInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, "cn-shanghai", location.Region)
+	require.Equal(t, "tos-cn-shanghai.volces.com", location.ExtranetEndpoint)
+	require.Equal(t, "tos-cn-shanghai.ivolces.com", location.IntranetEndpoint)
+
+	regional, err := client.ClientForRegion(location.Region, location.ExtranetEndpoint)
+	require.Nil(t, err)
+	_, err = regional.HeadBucket(ctx, &HeadBucketInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	req := transport.recorded()[1]
+	require.Equal(t, "https", req.Scheme)
+	require.Equal(t, "bucket.tos-cn-shanghai.volces.com", req.Host)
+	require.Contains(t, req.Header.Get("Authorization"), "/cn-shanghai/tos/request")
+
+	// the original client is not changed
+	_, err = client.HeadBucket(ctx, &HeadBucketInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	req = transport.recorded()[2]
+	require.Equal(t, "bucket.tos-cn-beijing.volces.com", req.Host)
+	require.Contains(t, req.Header.Get("Authorization"), "/cn-beijing/tos/request")
+
+	regional, err = client.ClientForRegion("cn-shanghai", "http://tos-cn-shanghai.ivolces.com")
+	require.Nil(t, err)
+	_, err = regional.HeadBucket(ctx, &HeadBucketInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, "http", transport.recorded()[3].Scheme)
+
+	_, err = client.ClientForRegion("", "tos-cn-shanghai.volces.com")
+	require.NotNil(t, err)
+
+	// custom signer is kept
+	signer := &recordingSigner{}
+	custom, err := NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithCredentials(NewStaticCredentials("ak", "sk")), WithSigner(signer), WithTransport(transport))
+	require.Nil(t, err)
+	regional, err = custom.ClientForRegion("cn-shanghai", "tos-cn-shanghai.volces.com")
+	require.Nil(t, err)
+	_, err = regional.HeadBucket(ctx, &HeadBucketInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, 1, signer.signed)
+	require.Equal(t, "custom", transport.recorded()[4].Header.Get("Authorization"))
+}
+
+type recordingSigner struct {
+	signed int
+}
+
+func (s *recordingSigner) SignHeader(req *Request) http.Header {
+	s.signed++
+	return http.Header{"Authorization": []string{"custom"}}
+}
+
+func (s *recordingSigner) SignQuery(req *Request, ttl time.Duration) url.Values {
+	return url.Values{}
+}
+
+func TestHeadBucketMeta(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		var res *Response
+		switch req.Host {
+		case "missing.tos-cn-beijing.volces.com":
+			res = newMockResponse(http.StatusNotFound, "")()
+		case "private.tos-cn-beijing.volces.com":
+			res = newMockResponse(http.StatusForbidden, "")()
+		default:
+			res = newMockResponse(http.StatusOK, "")()
+			res.Header.Set(HeaderBucketRegion, "cn-beijing")
+			res.Header.Set(HeaderStorageClass, "STANDARD")
+			res.Header.Set(HeaderAzRedundancy, "multi-az")
+			res.Header.Set(HeaderProjectName, "project")
+			res.Header.Set(HeaderBucketType, "hns")
+		}
+		res.Body = nil
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	head, err := client.HeadBucket(ctx, &HeadBucketInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, "cn-beijing", head.Region)
+	require.Equal(t, enum.StorageClassStandard, head.StorageClass)
+	require.Equal(t, enum.AzRedundancyMultiAz, head.AzRedundancy)
+	require.Equal(t, "project", head.ProjectName)
+	require.Equal(t, enum.BucketTypeHNS, head.BucketType)
+
+	_, err = client.HeadBucket(ctx, &HeadBucketInput{Bucket: "missing"})
+	notFound, ok := err.(*BucketNotFoundError)
+	require.True(t, ok)
+	require.Equal(t, "missing", notFound.Bucket)
+	require.Equal(t, http.StatusNotFound, StatusCode(err))
+	require.Equal(t, "request-id", RequestID(err))
+
+	_, err = client.HeadBucket(ctx, &HeadBucketInput{Bucket: "private"})
+	denied, ok := err.(*BucketAccessDeniedError)
+	require.True(t, ok)
+	require.Equal(t, "private", denied.Bucket)
+	require.Equal(t, http.StatusForbidden, StatusCode(err))
+
+	exist, err := client.DoesBucketExist(ctx, "missing")
+	require.Nil(t, err)
+	require.False(t, exist)
+}
@@ -1,6 +1,7 @@
 package tos
 
 import (
+	"strings"
 	"unicode/utf8"
 
 	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
@@ -67,6 +68,131 @@ func isValidKey(key string, keys ...string) error {
 	return nil
 }
 
+// sseKMS is value of X-Tos-Server-Side-Encryption for SSE-KMS
+const sseKMS = "kms"
+
+// sseAES256 is value of X-Tos-Server-Side-Encryption for SSE-TOS
+const sseAES256 = "AES256"
+
+// isValidSSE validate server side encryption parameters, SSE-C and SSE-KMS can not be used together,
+// and KMS key ID is only allowed with SSE-KMS. Return TosClientError if failed.
+func isValidSSE(ssecAlgorithm, ssecKey, serverSideEncryption, keyID string) error {
+	if (ssecAlgorithm != "" || ssecKey != "") && (serverSideEncryption != "" || keyID != "") {
+		return newTosClientError("tos: SSE-C and server side encryption can not be used together", nil)
+	}
+	if keyID != "" && !strings.EqualFold(serverSideEncryption, sseKMS) {
+		return newTosClientError("tos: ServerSideEncryptionKeyID requires ServerSideEncryption kms", nil)
+	}
+	return nil
+}
+
+// isValidBucketEncryption validate default encryption rule of bucket, SSEAlgorithm is kms or AES256,
+// and KMSMasterKeyID is only allowed with kms. Return TosClientError if failed.
+func isValidBucketEncryption(rule *BucketEncryptionRule) error {
+	algorithm := rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm
+	if algorithm != sseKMS && algorithm != sseAES256 {
+		return newTosClientError("tos: invalid SSEAlgorithm "+algorithm+", allowed: kms, AES256", nil)
+	}
+	if rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID != "" && algorithm != sseKMS {
+		return newTosClientError("tos: KMSMasterKeyID requires SSEAlgorithm kms", nil)
+	}
+	return nil
+}
+
+// maxMetaSize max total size of keys and values of user metadata
+const maxMetaSize = 2 * 1024
+
+// isValidMeta validate user metadata, return TosClientError if failed.
+// Keys consist of ASCII token characters or Chinese characters which are percent-encoded,
+// and the total size of keys and values is at most 2KB.
+func isValidMeta(meta map[string]string) error {
+	size := 0
+	for key, value := range meta {
+		if len(key) == 0 {
+			return newTosClientError("tos: meta key is empty", nil)
+		}
+		for _, r := range urlEncodeChinese(key) {
+			if !isTokenChar(r) {
+				return newTosClientError("tos: invalid character in meta key "+key, nil)
+			}
+		}
+		size += len(key) + len(value)
+	}
+	if size > maxMetaSize {
+		return newTosClientError("tos: the total size of meta keys and values must be at most 2KB", nil)
+	}
+	return nil
+}
+
+// isTokenChar reports whether r can be in HTTP header name
+func isTokenChar(r rune) bool {
+	if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+		return true
+	}
+	switch r {
+	case '!', '#', '$', '%', '&', '\'', '*', '+', '-', '.', '^', '_', '`', '|', '~':
+		return true
+	}
+	return false
+}
+
+// storageClasses are allowed values of X-Tos-Storage-Class
+var storageClasses = []enum.StorageClassType{
+	enum.StorageClassStandard,
+	enum.StorageClassIa,
+	enum.StorageClassArchiveFr,
+	enum.StorageClassIntelligentTiering,
+	enum.StorageClassColdArchive,
+	enum.StorageClassArchive,
+	enum.StorageClassDeepColdArchive,
+}
+
+// isValidStorageClass validate storageClass, empty storageClass is valid and the default class is used.
+// Return TosClientError with allowed classes if failed.
+func isValidStorageClass(storageClass enum.StorageClassType) error {
+	if storageClass == "" {
+		return nil
+	}
+	allowed := make([]string, 0, len(storageClasses))
+	for _, class := range storageClasses {
+		if class == storageClass {
+			return nil
+		}
+		allowed = append(allowed, string(class))
+	}
+	return newTosClientError("tos: invalid storage class "+string(storageClass)+", allowed: "+strings.Join(allowed, ", "), nil)
+}
+
+// singleAzStorageClasses are storage classes only supported by single-az buckets
+var singleAzStorageClasses = map[enum.StorageClassType]bool{
+	enum.StorageClassArchiveFr:       true,
+	enum.StorageClassColdArchive:     true,
+	enum.StorageClassArchive:         true,
+	enum.StorageClassDeepColdArchive: true,
+}
+
+// isValidCreateBucket validate storage class, AZ redundancy and bucket type of CreateBucketV2Input,
+// return TosClientError if failed
+func isValidCreateBucket(input *CreateBucketV2Input) error {
+	if err := isValidStorageClass(input.StorageClass); err != nil {
+		return err
+	}
+	switch input.AzRedundancy {
+	case "", enum.AzRedundancySingleAz:
+	case enum.AzRedundancyMultiAz:
+		if singleAzStorageClasses[input.StorageClass] {
+			return newTosClientError("tos: storage class "+string(input.StorageClass)+" is not supported by multi-az bucket, "+
+				"use single-az or one of STANDARD, IA, INTELLIGENT_TIERING", nil)
+		}
+	default:
+		return newTosClientError("tos: invalid AZ redundancy "+string(input.AzRedundancy)+", allowed: single-az, multi-az", nil)
+	}
+	if input.BucketType != "" && input.BucketType != enum.BucketTypeFNS && input.BucketType != enum.BucketTypeHNS {
+		return newTosClientError("tos: invalid bucket type "+string(input.BucketType)+", allowed: fns, hns", nil)
+	}
+	return nil
+}
+
 // isValidACL validate aclType, return TosClientError if failed
 func isValidACL(aclType enum.ACLType) error {
 	if aclType == enum.ACLPrivate || aclType == enum.ACLPublicRead || aclType == enum.ACLPublicReadWrite ||
@@ -76,3 +202,27 @@ func isValidACL(aclType enum.ACLType) error {
 
 	return newTosClientError("tos: invalid ACL", nil)
 }
+
+// isValidDomain validate domain name, return TosClientError if failed.
+// A domain has at least two labels separated by '.', each label consists of letters, numbers and '-',
+// and can be neither starting with '-' nor ending with '-'.
+func isValidDomain(domain string) error {
+	if length := len(domain); length == 0 || length > 253 {
+		return newTosClientError("tos: invalid domain, the length must be [1, 253]", nil)
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return newTosClientError("tos: invalid domain "+domain+", at least two labels are required", nil)
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return newTosClientError("tos: invalid domain label "+label+" in "+domain, nil)
+		}
+		for i := range label {
+			if char := label[i]; !(('a' <= char && char <= 'z') || ('A' <= char && char <= 'Z') || ('0' <= char && char <= '9') || char == '-') {
+				return newTosClientError("tos: invalid character in domain "+domain, nil)
+			}
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"golang.org/x/text/transform"
 
 	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
 )
 
 func TestIsValidBucketName(t *testing.T) {
@@ -76,3 +77,33 @@ func TestIsValidObjectKey(t *testing.T) {
 	require.NotNil(t, err)
 
 }
+
+func TestIsValidCreateBucket(t *testing.T) {
+	for _, input := range []CreateBucketV2Input{
+		{},
+		{StorageClass: enum.StorageClassIa, AzRedundancy: enum.AzRedundancyMultiAz, BucketType: enum.BucketTypeHNS},
+		{StorageClass: enum.StorageClassArchiveFr, AzRedundancy: enum.AzRedundancySingleAz},
+	} {
+		require.Nil(t, isValidCreateBucket(&input))
+	}
+	err := isValidCreateBucket(&CreateBucketV2Input{StorageClass: enum.StorageClassArchiveFr, AzRedundancy: enum.AzRedundancyMultiAz})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "ARCHIVE_FR is not supported by multi-az bucket")
+	for _, input := range []CreateBucketV2Input{
+		{StorageClass: "GLACIER"},
+		{AzRedundancy: "three-az"},
+		{BucketType: "flat"},
+	} {
+		require.NotNil(t, isValidCreateBucket(&input))
+	}
+}
+
+func TestIsValidDomain(t *testing.T) {
+	for _, domain := range []string{"example.com", "static.Example.com", "a-b.c1.example.cn", strings.Repeat("a", 63) + ".com"} {
+		require.Nil(t, isValidDomain(domain))
+	}
+	for _, domain := range []string{"", "localhost", "example..com", ".example.com", "-a.example.com", "a-.example.com",
+		"*.example.com", "exa_mple.com", "http://example.com", strings.Repeat("a", 64) + ".com", strings.Repeat("a.", 127) + "com"} {
+		require.NotNil(t, isValidDomain(domain), domain)
+	}
+}
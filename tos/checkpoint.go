@@ -0,0 +1,139 @@
+package tos
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	checkpointUpload   = "upload"
+	checkpointDownload = "download"
+	checkpointCopy     = "copy"
+)
+
+// fileCheckpointStore is the default CheckpointStore, it saves checkpoint in local file key.CheckpointFile
+type fileCheckpointStore struct{}
+
+func (fileCheckpointStore) Load(key CheckpointKey) ([]byte, error) {
+	data, err := ioutil.ReadFile(key.CheckpointFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (fileCheckpointStore) Save(key CheckpointKey, data []byte) error {
+	return ioutil.WriteFile(key.CheckpointFile, data, 0666)
+}
+
+func (fileCheckpointStore) Delete(key CheckpointKey) error {
+	err := os.Remove(key.CheckpointFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func getCheckpointStore(store CheckpointStore) CheckpointStore {
+	if store == nil {
+		return fileCheckpointStore{}
+	}
+	return store
+}
+
+// setDefaultCheckpointFile set checkpoint file of the default file store, named fileName in dir by default.
+// Other stores identify checkpoint by bucket and key, so the file is left as is.
+func setDefaultCheckpointFile(file *string, store CheckpointStore, dir, fileName string) {
+	if store != nil {
+		return
+	}
+	if len(*file) == 0 {
+		*file = filepath.Join(dir, fileName)
+	} else {
+		mustFile(file, fileName)
+	}
+}
+
+// loadCheckpoint load UploadFile, DownloadFile or ResumableCopyObject checkpoint from store.
+// checkpoint must be a pointer
+func loadCheckpoint(store CheckpointStore, key CheckpointKey, checkpoint interface{}) error {
+	contents, err := store.Load(key)
+	if err != nil {
+		return err
+	}
+	if len(contents) == 0 {
+		return newTosClientError("tos: checkpoint is empty", nil)
+	}
+	return json.Unmarshal(contents, checkpoint)
+}
+
+func saveCheckpoint(store CheckpointStore, key CheckpointKey, checkpoint interface{}) error {
+	if store == nil {
+		return nil
+	}
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return newTosClientError(err.Error(), err)
+	}
+	if err = store.Save(key, data); err != nil {
+		return newTosClientError("tos: save checkpoint failed", err)
+	}
+	return nil
+}
+
+func deleteCheckpoint(store CheckpointStore, key CheckpointKey) {
+	if store != nil {
+		_ = store.Delete(key)
+	}
+}
+
+// checkpointSaver decides when to save checkpoint after parts are finished.
+// Checkpoint is saved after every part if neither interval nor partCount is set.
+type checkpointSaver struct {
+	enabled   bool
+	interval  time.Duration
+	partCount int
+	pending   int
+	lastSaved time.Time
+}
+
+func newCheckpointSaver(enabled bool, interval time.Duration, partCount int) *checkpointSaver {
+	return &checkpointSaver{
+		enabled:   enabled,
+		interval:  interval,
+		partCount: partCount,
+		lastSaved: time.Now(),
+	}
+}
+
+// partFinished records a finished part, and saves checkpoint if needed
+func (s *checkpointSaver) partFinished(checkpoint checkPoint) {
+	if !s.enabled {
+		return
+	}
+	s.pending++
+	due := s.interval <= 0 && s.partCount <= 0
+	if s.partCount > 0 && s.pending >= s.partCount {
+		due = true
+	}
+	if s.interval > 0 && time.Since(s.lastSaved) >= s.interval {
+		due = true
+	}
+	if due {
+		s.flush(checkpoint)
+	}
+}
+
+// flush saves checkpoint if there are finished parts not saved
+func (s *checkpointSaver) flush(checkpoint checkPoint) {
+	if !s.enabled || s.pending == 0 {
+		return
+	}
+	if checkpoint.Save() == nil {
+		s.pending = 0
+		s.lastSaved = time.Now()
+	}
+}
@@ -0,0 +1,102 @@
+package tos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type memoryCheckpointStore struct {
+	data    map[string][]byte
+	saves   int
+	lastKey CheckpointKey
+}
+
+func (m *memoryCheckpointStore) Load(key CheckpointKey) ([]byte, error) {
+	return m.data[key.String()], nil
+}
+
+func (m *memoryCheckpointStore) Save(key CheckpointKey, data []byte) error {
+	m.saves++
+	m.lastKey = key
+	m.data[key.String()] = data
+	return nil
+}
+
+func (m *memoryCheckpointStore) Delete(key CheckpointKey) error {
+	delete(m.data, key.String())
+	return nil
+}
+
+func TestCheckpointSaver(t *testing.T) {
+	store := &memoryCheckpointStore{data: make(map[string][]byte)}
+	checkpoint := &uploadCheckpoint{Bucket: "bucket", Key: "key", store: store}
+
+	saver := newCheckpointSaver(true, 0, 0)
+	saver.partFinished(checkpoint)
+	saver.partFinished(checkpoint)
+	require.Equal(t, 2, store.saves)
+
+	store.saves = 0
+	saver = newCheckpointSaver(true, 0, 3)
+	for i := 0; i < 4; i++ {
+		saver.partFinished(checkpoint)
+	}
+	require.Equal(t, 1, store.saves)
+	saver.flush(checkpoint)
+	require.Equal(t, 2, store.saves)
+	saver.flush(checkpoint)
+	require.Equal(t, 2, store.saves)
+
+	store.saves = 0
+	saver = newCheckpointSaver(true, time.Hour, 0)
+	saver.partFinished(checkpoint)
+	require.Equal(t, 0, store.saves)
+	saver.lastSaved = time.Now().Add(-time.Hour)
+	saver.partFinished(checkpoint)
+	require.Equal(t, 1, store.saves)
+
+	store.saves = 0
+	saver = newCheckpointSaver(false, 0, 0)
+	saver.partFinished(checkpoint)
+	saver.flush(checkpoint)
+	require.Equal(t, 0, store.saves)
+}
+
+func TestCustomCheckpointStore(t *testing.T) {
+	store := &memoryCheckpointStore{data: make(map[string][]byte)}
+	file := ""
+	setDefaultCheckpointFile(&file, store, "dir", "file.bucket.key.upload")
+	require.Equal(t, "", file)
+
+	// workers with different local checkpoint files share the checkpoint of the same object
+	key := CheckpointKey{Operation: checkpointUpload, Bucket: "bucket", Key: "key", CheckpointFile: "worker-1"}
+	init := func() (*uploadCheckpoint, error) {
+		return &uploadCheckpoint{checkpointPath: key.CheckpointFile, Bucket: key.Bucket, Key: key.Key}, nil
+	}
+	valid := func(checkpoint *uploadCheckpoint) bool {
+		return checkpoint.UploadID == "upload-id"
+	}
+	checkpoint, err := getUploadCheckpoint(store, true, key, init, valid)
+	require.Nil(t, err)
+	require.Contains(t, store.data, "upload/bucket/key")
+
+	checkpoint.UploadID = "upload-id"
+	require.Nil(t, checkpoint.Save())
+	require.Equal(t, "upload-id", store.lastKey.UploadID)
+	key.CheckpointFile = "worker-2"
+	loaded, err := getUploadCheckpoint(store, true, key, init, valid)
+	require.Nil(t, err)
+	require.Equal(t, "upload-id", loaded.UploadID)
+
+	// checkpoint of another object is not loaded
+	key.Key = "other"
+	other, err := getUploadCheckpoint(store, true, key, init, valid)
+	require.Nil(t, err)
+	require.Equal(t, "", other.UploadID)
+	require.Contains(t, store.data, "upload/bucket/other")
+
+	loaded.Delete()
+	require.NotContains(t, store.data, "upload/bucket/key")
+}
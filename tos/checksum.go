@@ -1,11 +1,16 @@
 package tos
 
 import (
+	"bytes"
 	"crypto/md5"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"hash"
 	"io"
+	"io/ioutil"
+	"strconv"
 	"strings"
 )
 
@@ -53,3 +58,213 @@ func (ec *ETagCheckReadCloser) Read(p []byte) (n int, err error) {
 func (ec *ETagCheckReadCloser) Close() error {
 	return ec.closer.Close()
 }
+
+// contentWithMD5 calculates MD5 of content, and returns the content to upload.
+// Seekable content is read once and seeked back, otherwise it is buffered in memory up to bufferLimit bytes.
+// contentLength < 0 means the length is unknown.
+func contentWithMD5(content io.Reader, contentLength int64, bufferLimit int64) (io.Reader, []byte, error) {
+	checksum := md5.New()
+	if content == nil {
+		return content, checksum.Sum(nil), nil
+	}
+	reader := content
+	if contentLength >= 0 {
+		reader = io.LimitReader(content, contentLength)
+	}
+	if seeker, ok := content.(io.Seeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, nil, newTosClientError("tos: seek content failed", err)
+		}
+		if _, err = io.Copy(checksum, reader); err != nil {
+			return nil, nil, newTosClientError("tos: read content failed", err)
+		}
+		if _, err = seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, nil, newTosClientError("tos: seek content failed", err)
+		}
+		return content, checksum.Sum(nil), nil
+	}
+	if bufferLimit <= 0 {
+		bufferLimit = DefaultContentMD5BufferLimit
+	}
+	if contentLength > bufferLimit {
+		return nil, nil, newTosClientError("tos: content is not seekable and too large to calculate Content-MD5", nil)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(reader, bufferLimit+1))
+	if err != nil {
+		return nil, nil, newTosClientError("tos: read content failed", err)
+	}
+	if int64(len(data)) > bufferLimit {
+		return nil, nil, newTosClientError("tos: content is not seekable and too large to calculate Content-MD5", nil)
+	}
+	checksum.Write(data)
+	return bytes.NewReader(data), checksum.Sum(nil), nil
+}
+
+// checkETagWithMD5 checks ETag of object uploaded in one request, which is hex encoded MD5 of the object
+func checkETagWithMD5(eTag string, sum []byte, requestID string) error {
+	expected := hex.EncodeToString(sum)
+	if actual := strings.Trim(eTag, `"`); actual != expected {
+		return &ChecksumError{
+			RequestID:        requestID,
+			ExpectedChecksum: expected,
+			ActualChecksum:   actual,
+		}
+	}
+	return nil
+}
+
+func base64MD5(sum []byte) string {
+	return base64.StdEncoding.EncodeToString(sum)
+}
+
+// checkSSECKeyMD5 checks MD5 of SSE-C key returned by server is the same as the one sent,
+// keyMD5 is calculated from key if it is empty.
+func checkSSECKeyMD5(res *Response, key, keyMD5 string) error {
+	if keyMD5 == "" && key != "" {
+		raw, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return newTosClientError("tos: SSE-C key must be base64 encoded", err)
+		}
+		sum := md5.Sum(raw)
+		keyMD5 = base64MD5(sum[:])
+	}
+	returned := res.Header.Get(HeaderSSECustomerKeyMD5)
+	if keyMD5 == "" || returned == "" || returned == keyMD5 {
+		return nil
+	}
+	return newTosClientError("tos: SSE-C key MD5 mismatch, sent: "+keyMD5+", returned: "+returned, nil)
+}
+
+const (
+	// trailingChunkSize is the size of data in each chunk encoded by chunkedReader
+	trailingChunkSize = 64 * 1024
+	// trailingChecksumName is the trailer sent after the last chunk
+	trailingChecksumName = "x-tos-hash-crc64ecma"
+	// trailingSignatureName is the signature of the trailer, sent after it if chunks are signed
+	trailingSignatureName = "x-tos-trailer-signature"
+	// tosChunkedEncoding is the Content-Encoding of body encoded by chunkedReader
+	tosChunkedEncoding = "tos-chunked"
+)
+
+// chunkedReader encodes the base reader in chunks, each chunk is "hex(size);chunk-signature=<signature>\r\n<data>\r\n".
+// After the last chunk, a zero size chunk and the CRC64 trailer of all data "x-tos-hash-crc64ecma:<crc64>\r\n"
+// are emitted, followed by the trailer signature "x-tos-trailer-signature:<signature>\r\n" and "\r\n",
+// so that body of unknown length can be uploaded without buffering and still be checked by server.
+// Signatures are omitted if signer is nil, i.e. the request is not signed by SignV4.
+type chunkedReader struct {
+	base     io.Reader
+	buf      []byte
+	pending  bytes.Buffer
+	checker  hash.Hash64
+	signer   *chunkSigner // nullable
+	previous string       // signature of the previous chunk, empty before the first chunk
+	done     bool
+}
+
+func newChunkedReader(base io.Reader, signer *chunkSigner) *chunkedReader {
+	return &chunkedReader{
+		base:    base,
+		buf:     make([]byte, trailingChunkSize),
+		checker: NewCRC(DefaultCrcTable(), 0),
+		signer:  signer,
+	}
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	for r.pending.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.encodeChunk(); err != nil {
+			return 0, err
+		}
+	}
+	return r.pending.Read(p)
+}
+
+func (r *chunkedReader) encodeChunk() error {
+	n, err := io.ReadFull(r.base, r.buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if n > 0 {
+		r.checker.Write(r.buf[:n])
+		r.writeChunk(r.buf[:n])
+	}
+	if err != nil {
+		r.writeChunk(nil)
+		trailer := trailingChecksumName + ":" + strconv.FormatUint(r.checker.Sum64(), 10)
+		r.pending.WriteString(trailer + "\r\n")
+		if r.signer != nil {
+			r.pending.WriteString(trailingSignatureName + ":" + r.signer.signTrailer(r.previous, trailer+"\n") + "\r\n")
+		}
+		r.pending.WriteString("\r\n")
+		r.done = true
+	}
+	return nil
+}
+
+// writeChunk encodes data as a chunk, the last chunk is empty
+func (r *chunkedReader) writeChunk(data []byte) {
+	if r.signer == nil {
+		fmt.Fprintf(&r.pending, "%x\r\n", len(data))
+	} else {
+		if r.previous == "" {
+			r.previous = r.signer.seedSignature()
+		}
+		r.previous = r.signer.signChunk(r.previous, data)
+		fmt.Fprintf(&r.pending, "%x;chunk-signature=%s\r\n", len(data), r.previous)
+	}
+	if len(data) > 0 {
+		r.pending.Write(data)
+		r.pending.WriteString("\r\n")
+	}
+}
+
+// Seek seeks the base reader and restarts encoding, it is used to rewind the body on retrying,
+// and signatures restart from the signature of the request signed again.
+func (r *chunkedReader) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := r.base.(io.Seeker)
+	if !ok {
+		return 0, newTosClientError("tos: the request body is not seekable", nil)
+	}
+	pos, err := seeker.Seek(offset, whence)
+	if err != nil || (offset == 0 && whence == io.SeekCurrent) {
+		return pos, err
+	}
+	r.pending.Reset()
+	r.checker.Reset()
+	r.previous = ""
+	r.done = false
+	return pos, nil
+}
+
+// withTrailingChecksum sets headers of streaming payload with trailing checksum,
+// and returns content encoded by chunkedReader. Content-Length of the encoded body is unknown,
+// so the body is sent with Transfer-Encoding: chunked.
+// If the request is signed by SignV4, each chunk and the trailer are signed with chained signatures starting
+// from the signature of the request, i.e. STREAMING-TOS4-HMAC-SHA256-PAYLOAD-TRAILER. Custom signers can't
+// sign chunks, so the payload is declared as STREAMING-UNSIGNED-PAYLOAD-TRAILER and only checked by the checksum.
+func withTrailingChecksum(rb *requestBuilder, content io.Reader, contentLength int64) io.Reader {
+	if content == nil {
+		content = bytes.NewReader(nil)
+		contentLength = 0
+	}
+	encoding := tosChunkedEncoding
+	if origin := rb.Header.Get(HeaderContentEncoding); origin != "" {
+		encoding += "," + origin
+	}
+	rb.Header.Set(HeaderContentEncoding, encoding)
+	rb.Header.Set(HeaderContentSha256, streamingUnsignedPayloadTrailer)
+	if _, ok := rb.Signer.(*SignV4); ok {
+		rb.chunkSigner = &chunkSigner{}
+		rb.Header.Set(HeaderContentSha256, streamingSignedPayloadTrailer)
+	}
+	rb.Header.Set(HeaderTrailer, trailingChecksumName)
+	if contentLength >= 0 {
+		rb.Header.Set(HeaderDecodedContentLength, strconv.FormatInt(contentLength, 10))
+	}
+	rb.WithContentLength(-1)
+	return newChunkedReader(content, rb.chunkSigner)
+}
@@ -2,11 +2,17 @@ package tos
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"io"
 	"io/ioutil"
 	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -43,3 +49,259 @@ func TestETagCheckReadCloser(t *testing.T) {
 	require.Equal(t, int(n), len(buf))
 	require.Equal(t, rc.eTag, "abc")
 }
+
+func TestContentWithMD5(t *testing.T) {
+	data := []byte("hello world")
+	sum := md5.Sum(data)
+
+	seekable := bytes.NewReader(data)
+	content, md5Sum, err := contentWithMD5(seekable, int64(len(data)), 0)
+	require.Nil(t, err)
+	require.Equal(t, sum[:], md5Sum)
+	read, err := ioutil.ReadAll(content)
+	require.Nil(t, err)
+	require.Equal(t, data, read)
+
+	// only ContentLength bytes are uploaded
+	content, md5Sum, err = contentWithMD5(strings.NewReader("hello world!!!"), int64(len(data)), 0)
+	require.Nil(t, err)
+	require.Equal(t, sum[:], md5Sum)
+
+	content, md5Sum, err = contentWithMD5(ioutil.NopCloser(bytes.NewReader(data)), -1, int64(len(data)))
+	require.Nil(t, err)
+	require.Equal(t, sum[:], md5Sum)
+	read, err = ioutil.ReadAll(content)
+	require.Nil(t, err)
+	require.Equal(t, data, read)
+
+	_, _, err = contentWithMD5(ioutil.NopCloser(bytes.NewReader(data)), -1, int64(len(data)-1))
+	require.NotNil(t, err)
+	_, _, err = contentWithMD5(ioutil.NopCloser(bytes.NewReader(data)), int64(len(data)), int64(len(data)-1))
+	require.NotNil(t, err)
+}
+
+func TestPutObjectV2ContentMD5(t *testing.T) {
+	data := "hello world"
+	sum := md5.Sum([]byte(data))
+	eTag := `"` + hex.EncodeToString(sum[:]) + `"`
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		require.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), req.Header.Get(HeaderContentMD5))
+		res := newMockResponse(http.StatusOK, "")()
+		res.Header.Set(HeaderETag, eTag)
+		return res
+	}}
+	client, err := NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithCredentials(NewStaticCredentials("ak", "sk")), WithTransport(transport), WithEnableContentMD5(true))
+	require.Nil(t, err)
+	input := &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key"},
+		Content:             strings.NewReader(data),
+	}
+	_, err = client.PutObjectV2(context.Background(), input)
+	require.Nil(t, err)
+
+	eTag = `"etag"`
+	input.Content = strings.NewReader(data)
+	_, err = client.PutObjectV2(context.Background(), input)
+	_, ok := err.(*ChecksumError)
+	require.True(t, ok)
+
+	// Content-MD5 is enabled by input
+	client = newMockClient(t, transport)
+	_, err = client.UploadPartV2(context.Background(), &UploadPartV2Input{
+		UploadPartBasicInput: UploadPartBasicInput{Bucket: "bucket", Key: "key", UploadID: "upload-id", PartNumber: 1, EnableContentMD5: true},
+		Content:              ioutil.NopCloser(strings.NewReader(data)),
+	})
+	require.Nil(t, err)
+}
+
+// decodeChunked decodes body encoded by chunkedReader, returns data, trailer lines and signatures of chunks,
+// the signature of the trailer is the last line of trailer if chunks are signed
+func decodeChunked(t *testing.T, body []byte) ([]byte, []string, []string) {
+	var (
+		data       []byte
+		signatures []string
+	)
+	for {
+		idx := bytes.Index(body, []byte("\r\n"))
+		require.True(t, idx > 0)
+		line := string(body[:idx])
+		if i := strings.Index(line, ";chunk-signature="); i >= 0 {
+			signatures = append(signatures, line[i+len(";chunk-signature="):])
+			line = line[:i]
+		}
+		size, err := strconv.ParseInt(line, 16, 64)
+		require.Nil(t, err)
+		body = body[idx+2:]
+		if size == 0 {
+			require.True(t, bytes.HasSuffix(body, []byte("\r\n\r\n")))
+			return data, strings.Split(string(bytes.TrimSuffix(body, []byte("\r\n\r\n"))), "\r\n"), signatures
+		}
+		data = append(data, body[:size]...)
+		require.Equal(t, "\r\n", string(body[size:size+2]))
+		body = body[size+2:]
+	}
+}
+
+// verifyChunkSignatures verifies signatures of chunks and the trailer are chained from seed
+func verifyChunkSignatures(t *testing.T, data []byte, trailer, signatures []string, key []byte, timestamp, scope, seed string) {
+	sign := func(stringToSign string) string {
+		return hex.EncodeToString(hmacSHA256(key, []byte(stringToSign)))
+	}
+	sha := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+	previous := seed
+	for i, signature := range signatures {
+		// the last chunk is empty
+		start, end := i*trailingChunkSize, (i+1)*trailingChunkSize
+		if start > len(data) {
+			start = len(data)
+		}
+		if end > len(data) {
+			end = len(data)
+		}
+		expected := sign("TOS4-HMAC-SHA256-PAYLOAD\n" + timestamp + "\n" + scope + "\n" + previous + "\n" +
+			emptySHA256 + "\n" + sha(data[start:end]))
+		require.Equal(t, expected, signature, "chunk %d", i)
+		previous = signature
+	}
+	require.Equal(t, len(data)/trailingChunkSize+1+btoi(len(data)%trailingChunkSize != 0), len(signatures))
+	require.Len(t, trailer, 2)
+	expected := sign("TOS4-HMAC-SHA256-TRAILER\n" + timestamp + "\n" + scope + "\n" + previous + "\n" + sha([]byte(trailer[0]+"\n")))
+	require.Equal(t, "x-tos-trailer-signature:"+expected, trailer[1])
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestChunkedReader(t *testing.T) {
+	for _, size := range []int{0, 1, trailingChunkSize, trailingChunkSize*2 + 100} {
+		data := make([]byte, size)
+		rand.Read(data)
+		crc := NewCRC(DefaultCrcTable(), 0)
+		crc.Write(data)
+		trailer := "x-tos-hash-crc64ecma:" + strconv.FormatUint(crc.Sum64(), 10)
+
+		reader := newChunkedReader(bytes.NewReader(data), nil)
+		body, err := ioutil.ReadAll(reader)
+		require.Nil(t, err)
+		decoded, decodedTrailer, signatures := decodeChunked(t, body)
+		require.Equal(t, len(data), len(decoded))
+		require.True(t, bytes.Equal(data, decoded))
+		require.Equal(t, []string{trailer}, decodedTrailer)
+		require.Empty(t, signatures)
+
+		// rewind and encode again
+		_, err = reader.Seek(0, io.SeekStart)
+		require.Nil(t, err)
+		again, err := ioutil.ReadAll(reader)
+		require.Nil(t, err)
+		require.Equal(t, body, again)
+	}
+
+	_, err := newChunkedReader(ioutil.NopCloser(strings.NewReader("data")), nil).Seek(0, io.SeekStart)
+	require.NotNil(t, err)
+}
+
+func TestPutObjectWithTrailingChecksum(t *testing.T) {
+	data := make([]byte, trailingChunkSize+10)
+	rand.Read(data)
+	var bodies [][]byte
+	// headers of the request are signed again on retrying, so the signature of each attempt is kept here
+	var auths, timestamps []string
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		body, _ := ioutil.ReadAll(req.Content)
+		bodies = append(bodies, body)
+		auths = append(auths, req.Header.Get(authorization))
+		timestamps = append(timestamps, req.Header.Get(v4Date))
+		if len(bodies) == 1 {
+			return newMockResponse(http.StatusInternalServerError, "")()
+		}
+		return newMockResponse(http.StatusOK, "")()
+	}}
+	client := newMockClient(t, transport)
+	_, err := client.PutObjectV2(context.Background(), &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{
+			Bucket:                 "bucket",
+			Key:                    "key",
+			ContentEncoding:        "gzip",
+			EnableTrailingChecksum: true,
+		},
+		Content: bytes.NewReader(data),
+	})
+	require.Nil(t, err)
+	require.Len(t, bodies, 2)
+	for i, body := range bodies {
+		decoded, trailer, signatures := decodeChunked(t, body)
+		require.True(t, bytes.Equal(data, decoded))
+		require.True(t, strings.HasPrefix(trailer[0], "x-tos-hash-crc64ecma:"))
+		// chunks of each attempt are chained from the signature of the attempt
+		auth, timestamp := auths[i], timestamps[i]
+		seed := auth[strings.Index(auth, "Signature=")+len("Signature="):]
+		key := SigningKey(&SigningKeyInfo{Date: timestamp[:8], Region: "cn-beijing", Credential: &Credential{AccessKeySecret: "sk"}})
+		verifyChunkSignatures(t, data, trailer, signatures, key, timestamp, timestamp[:8]+"/cn-beijing/tos/request", seed)
+	}
+
+	req := transport.recorded()[1]
+	require.Equal(t, int64(-1), *req.ContentLength)
+	require.Equal(t, "tos-chunked,gzip", req.Header.Get(HeaderContentEncoding))
+	require.Equal(t, streamingSignedPayloadTrailer, req.Header.Get(HeaderContentSha256))
+	require.Equal(t, strconv.Itoa(len(data)), req.Header.Get(HeaderDecodedContentLength))
+	require.Equal(t, "x-tos-hash-crc64ecma", req.Header.Get(HeaderTrailer))
+	require.Contains(t, req.Header.Get(authorization), "x-tos-decoded-content-length")
+}
+
+func TestUploadPartWithTrailingChecksum(t *testing.T) {
+	var body []byte
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		body, _ = ioutil.ReadAll(req.Content)
+		return newMockResponse(http.StatusOK, "")()
+	}}
+	client := newMockClient(t, transport)
+	// content of unknown length
+	_, err := client.UploadPartV2(context.Background(), &UploadPartV2Input{
+		UploadPartBasicInput: UploadPartBasicInput{
+			Bucket:                 "bucket",
+			Key:                    "key",
+			UploadID:               "upload-id",
+			PartNumber:             1,
+			EnableTrailingChecksum: true,
+		},
+		Content: ioutil.NopCloser(strings.NewReader("hello world")),
+	})
+	require.Nil(t, err)
+	decoded, _, signatures := decodeChunked(t, body)
+	require.Equal(t, "hello world", string(decoded))
+	require.Len(t, signatures, 2)
+	req := transport.recorded()[0]
+	require.Equal(t, "", req.Header.Get(HeaderDecodedContentLength))
+	require.Equal(t, tosChunkedEncoding, req.Header.Get(HeaderContentEncoding))
+}
+
+func TestTrailingChecksumCustomSigner(t *testing.T) {
+	var body []byte
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		body, _ = ioutil.ReadAll(req.Content)
+		return newMockResponse(http.StatusOK, "")()
+	}}
+	signer := &recordingSigner{}
+	client, err := NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"), WithSigner(signer), WithTransport(transport))
+	require.Nil(t, err)
+	// custom signers can't sign chunks, so the payload is unsigned and checked by the trailing checksum only
+	_, err = client.PutObjectV2(context.Background(), &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key", EnableTrailingChecksum: true},
+		Content:             strings.NewReader("hello world"),
+	})
+	require.Nil(t, err)
+	decoded, trailer, signatures := decodeChunked(t, body)
+	require.Equal(t, "hello world", string(decoded))
+	require.Len(t, trailer, 1)
+	require.Empty(t, signatures)
+	require.Equal(t, streamingUnsignedPayloadTrailer, transport.recorded()[0].Header.Get(HeaderContentSha256))
+}
@@ -3,14 +3,19 @@ package tos
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
 )
 
 // Client TOS Client
@@ -33,16 +38,21 @@ type Client struct {
 	host         string
 	urlMode      urlMode
 	userAgent    string
-	credentials  Credentials // nullable
-	signer       Signer      // nullable
+	credentials  Credentials         // nullable
+	provider     CredentialsProvider // nullable, set by WithCredentialsProvider
+	signer       Signer              // nullable
 	transport    Transport
 	recognizer   ContentTypeRecognizer
 	config       Config
 	retry        *retryer
-	dnsCacheTime time.Duration // milliseconds
 	enableCRC    bool
-	proxy        *Proxy
+	enableMD5    bool
+	md5Limit     int64       // max bytes buffered to calculate Content-MD5 of content not seekable
+	rateLimiter  RateLimiter // nullable, shared by request and response bodies of all operations
 	logger       logrus.FieldLogger
+	requestPayer string // X-Tos-Request-Payer of all requests to buckets, overridden by RequestPayer of input
+	anonymous    bool   // set by WithAnonymous
+	signRule     SignHeaderRule
 }
 
 // ClientV2 TOS ClientV2
@@ -71,6 +81,33 @@ func (cli *ClientV2) Close() {
 	}
 }
 
+// ClientForRegion returns a copy of the client to access buckets in region through endpoint,
+// e.g. Region and ExtranetEndpoint of GetBucketLocation output. Credentials, transport and other options
+// are shared with the client, and the scheme of the client is used if endpoint has no scheme.
+// Signer set by WithSigner is shared as well, it should sign requests for the region by itself.
+func (cli *ClientV2) ClientForRegion(region, endpoint string) (*ClientV2, error) {
+	if len(region) == 0 || len(endpoint) == 0 {
+		return nil, newTosClientError("tos: region and endpoint are required", nil)
+	}
+	if !strings.HasPrefix(endpoint, "https://") && !strings.HasPrefix(endpoint, "http://") {
+		endpoint = cli.scheme + "://" + endpoint
+	}
+	client := &ClientV2{Client: cli.Client}
+	client.config.Endpoint = endpoint
+	client.config.Region = region
+	client.scheme, client.host, client.urlMode = schemeHost(endpoint)
+	// SignV4 is copied to sign requests for the region with the same options, custom signers are kept as is
+	if sv, ok := client.signer.(*SignV4); ok {
+		signer := *sv
+		signer.region = region
+		if sv.keyCache != nil {
+			signer.keyCache = &signingKeyCache{}
+		}
+		client.signer = &signer
+	}
+	return client, nil
+}
+
 func (cli *ClientV2) SetHTTPTransport(transport http.RoundTripper) {
 	cli.transport = newDefaultTranposrtWithHTTPTransport(transport)
 }
@@ -86,6 +123,24 @@ func WithCredentials(credentials Credentials) ClientOption {
 	}
 }
 
+// WithCredentialsProvider set CredentialsProvider, credentials are retrieved from it before signing each request,
+// and an error is returned by the request if it fails. It overrides WithCredentials.
+//
+// see CredentialsChain and NewDefaultCredentialsChain
+func WithCredentialsProvider(provider CredentialsProvider) ClientOption {
+	return func(client *Client) {
+		client.provider = provider
+	}
+}
+
+// WithAnonymous access public buckets without credentials, requests are not signed even if WithCredentials is used,
+// PreSignedURL returns unsigned URLs, and operations other than GET and HEAD fail without sending requests.
+func WithAnonymous() ClientOption {
+	return func(client *Client) {
+		client.anonymous = true
+	}
+}
+
 // WithEnableVerifySSL set whether a client verifies the server's certificate chain and host name.
 func WithEnableVerifySSL(enable bool) ClientOption {
 	skip := !enable
@@ -128,6 +183,27 @@ func WithMaxConnections(max int) ClientOption {
 
 }
 
+// WithMaxIdleConns set maximum number of idle http connections to all hosts, default is 1024
+func WithMaxIdleConns(max int) ClientOption {
+	return func(client *Client) {
+		client.config.TransportConfig.MaxIdleConns = max
+	}
+}
+
+// WithMaxIdleConnsPerHost set maximum number of idle http connections to each host, default is 1024
+func WithMaxIdleConnsPerHost(max int) ClientOption {
+	return func(client *Client) {
+		client.config.TransportConfig.MaxIdleConnsPerHost = max
+	}
+}
+
+// WithMaxConnsPerHost set maximum number of http connections to each host, default is 1024, zero means no limit
+func WithMaxConnsPerHost(max int) ClientOption {
+	return func(client *Client) {
+		client.config.TransportConfig.MaxConnsPerHost = max
+	}
+}
+
 // WithIdleConnTimeout set max idle time of a http connection
 func WithIdleConnTimeout(timeout time.Duration) ClientOption {
 	return func(client *Client) {
@@ -135,6 +211,30 @@ func WithIdleConnTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithTLSHandshakeTimeout set timeout of TLS handshake, default is 10s
+func WithTLSHandshakeTimeout(timeout time.Duration) ClientOption {
+	return func(client *Client) {
+		client.config.TransportConfig.TLSHandshakeTimeout = timeout
+	}
+}
+
+// WithBufferSize set sizes of read and write buffers of http connections, default is 64KiB,
+// zero means 4KiB as http.Transport does
+func WithBufferSize(readBufferSize, writeBufferSize int) ClientOption {
+	return func(client *Client) {
+		client.config.TransportConfig.ReadBufferSize = readBufferSize
+		client.config.TransportConfig.WriteBufferSize = writeBufferSize
+	}
+}
+
+// WithDisableCompression set whether gzip compression of responses is disabled, default is true,
+// so that objects are downloaded as they are stored
+func WithDisableCompression(disable bool) ClientOption {
+	return func(client *Client) {
+		client.config.TransportConfig.EnableCompression = !disable
+	}
+}
+
 // WithUserAgentSuffix set suffix of user-agent
 func WithUserAgentSuffix(suffix string) ClientOption {
 	return func(client *Client) {
@@ -142,22 +242,34 @@ func WithUserAgentSuffix(suffix string) ClientOption {
 	}
 }
 
-// // WithProxy set Proxy
-// //
-// // see StaticProxy
-// func WithProxy(proxy *Proxy) ClientV2Option {
-//	return func(client *ClientV2) {
-//		client.proxy = proxy
-//	}
-// }
-//
-// // WithDNSCacheTime set dnsCacheTime in milliseconds
-// func WithDNSCacheTime(dnsCacheTime int) ClientV2Option {
-//	return func(client *ClientV2) {
-//		client.dnsCacheTime = dnsCacheTime * time.Milliseconds
-//	}
-// }
-//
+// WithProxy set Proxy through which requests are sent, see NewProxy and NewProxyWithURL
+func WithProxy(proxy *Proxy) ClientOption {
+	return func(client *Client) {
+		client.config.TransportConfig.Proxy = proxy
+	}
+}
+
+// WithProxyFromEnvironment set whether proxy is resolved from HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables, WithProxy takes precedence over it
+func WithProxyFromEnvironment(enable bool) ClientOption {
+	return func(client *Client) {
+		client.config.TransportConfig.ProxyFromEnvironment = enable
+	}
+}
+
+// WithEnableDNSCache set whether hosts are resolved once per DNS cache TTL instead of for each connection
+func WithEnableDNSCache(enable bool) ClientOption {
+	return func(client *Client) {
+		client.config.TransportConfig.EnableDNSCache = enable
+	}
+}
+
+// WithDNSCacheTTL set time-to-live of cached IPs if DNS cache is enabled, default is 15 minutes
+func WithDNSCacheTTL(ttl time.Duration) ClientOption {
+	return func(client *Client) {
+		client.config.TransportConfig.DNSCacheTTL = ttl
+	}
+}
 
 // WithEnableCRC set if check crc after uploading object.
 // Checking crc is enabled by default.
@@ -167,15 +279,83 @@ func WithEnableCRC(enableCRC bool) ClientOption {
 	}
 }
 
+// WithRequestPayer set X-Tos-Request-Payer of all requests to buckets, e.g. RequestPayerRequester
+// to access requester-pays buckets, it's overridden by RequestPayer of input.
+func WithRequestPayer(payer string) ClientOption {
+	return func(client *Client) {
+		client.requestPayer = payer
+	}
+}
+
+// WithEnableContentMD5 set if calculate Content-MD5 of content uploaded by PutObjectV2 and UploadPartV2 automatically.
+// The ETag returned by PutObjectV2 is checked with the Content-MD5 too.
+// Calculating Content-MD5 is disabled by default.
+func WithEnableContentMD5(enableMD5 bool) ClientOption {
+	return func(client *Client) {
+		client.enableMD5 = enableMD5
+	}
+}
+
+// WithContentMD5BufferLimit set max bytes buffered in memory to calculate Content-MD5 of content not seekable,
+// DefaultContentMD5BufferLimit by default. Content larger than it is rejected with client error.
+func WithContentMD5BufferLimit(limit int64) ClientOption {
+	return func(client *Client) {
+		client.md5Limit = limit
+	}
+}
+
+// WithRateLimiter set a RateLimiter shared by request and response bodies of all operations of the client,
+// e.g. to limit bandwidth of the whole process. It works with RateLimiter of each request,
+// and the limiter must be safe for concurrent use. Use BypassRateLimiter to skip it for a request.
+func WithRateLimiter(limiter RateLimiter) ClientOption {
+	return func(client *Client) {
+		client.rateLimiter = limiter
+	}
+}
+
+type bypassRateLimiterKey struct{}
+
+// BypassRateLimiter returns a context with which requests skip the RateLimiter set by WithRateLimiter,
+// e.g. for latency-critical small requests.
+func BypassRateLimiter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassRateLimiterKey{}, true)
+}
+
+type signHeaderRuleKey struct{}
+
+// WithSignHeaderRule set SignHeaderRule of all requests and pre-signed URLs of the client
+func WithSignHeaderRule(rule SignHeaderRule) ClientOption {
+	return func(client *Client) {
+		client.signRule = rule
+	}
+}
+
+// ContextWithSignHeaderRule returns a context with which requests merge rule with SignHeaderRule of the client
+func ContextWithSignHeaderRule(ctx context.Context, rule SignHeaderRule) context.Context {
+	return context.WithValue(ctx, signHeaderRuleKey{}, rule)
+}
+
 // // WithMaxRetryCount set MaxRetryCount
 func WithMaxRetryCount(retryCount int) ClientOption {
 	return func(client *Client) {
-		if client.retry != nil {
-			client.retry.SetBackoff(exponentialBackoff(retryCount, DefaultRetryBackoffBase))
-		}
+		client.config.RetryPolicy.MaxRetryCount = retryCount
+	}
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy set RetryPolicy of all requests of the client
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(client *Client) {
+		client.config.RetryPolicy = policy
 	}
 }
 
+// ContextWithRetryPolicy returns a context with which requests use policy instead of RetryPolicy of the client
+func ContextWithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
 // WithTransport set Transport
 //
 // Deprecated: this function is Deprecated.
@@ -186,7 +366,7 @@ func WithTransport(transport Transport) ClientOption {
 	}
 }
 
-// WithHTTPTransport set Transport of http.Client
+// WithHTTPTransport set Transport of http.Client, which overrides all options of TransportConfig
 func WithHTTPTransport(transport http.RoundTripper) ClientOption {
 	return func(client *Client) {
 		client.transport = newDefaultTranposrtWithHTTPTransport(transport)
@@ -222,7 +402,8 @@ func WithRegion(region string) ClientOption {
 	}
 }
 
-// WithSigner for self-defined Signer
+// WithSigner for self-defined Signer, which signs all requests and pre-signed URLs of the client
+// instead of SignV4 created with WithCredentials and WithRegion
 func WithSigner(signer Signer) ClientOption {
 	return func(client *Client) {
 		client.signer = signer
@@ -268,7 +449,11 @@ func schemeHost(endpoint string) (scheme string, host string, urlMode urlMode) {
 		host = endpoint
 	}
 	urlMode = urlModeDefault
-	if net.ParseIP(host) != nil {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if net.ParseIP(hostname) != nil {
 		urlMode = urlModePath
 	}
 	return scheme, host, urlMode
@@ -280,11 +465,23 @@ func initClient(client *Client, endpoint string, options ...ClientOption) error
 		option(client)
 	}
 	client.scheme, client.host, client.urlMode = schemeHost(client.config.Endpoint)
+	if client.retry != nil {
+		client.retry = newRetryer(client.config.RetryPolicy)
+		client.retry.logger = client.logger
+	}
 	if client.transport == nil {
 		transport := NewDefaultTransport(&client.config.TransportConfig)
 		transport.WithDefaultTransportLogger(client.logger)
 		client.transport = transport
 	}
+	if client.anonymous {
+		client.credentials = nil
+		client.provider = nil
+		client.signer = nil
+	}
+	if client.provider != nil {
+		client.credentials = &providerCredentials{provider: client.provider}
+	}
 	if cred := client.credentials; cred != nil && client.signer == nil {
 		if len(client.config.Region) == 0 {
 			return newTosClientError("tos: missing Region option", nil)
@@ -319,25 +516,35 @@ func NewClient(endpoint string, options ...ClientOption) (*Client, error) {
 // NewClientV2 create a new Tos ClientV2
 //   endpoint: access endpoint
 //   options: WithCredentials set Credentials
-//     WithRegion set region, this is required if WithCredentials is used.
+//     WithCredentialsProvider set CredentialsProvider, credentials are retrieved for each request
+//     WithAnonymous access public buckets without credentials
+//     WithSigner set self-defined Signer
+//     WithRegion set region, this is required if WithCredentials or WithCredentialsProvider is used.
 //     If Region is supported and the Endpoint parameter is not set, the Endpoint will be resolved automatically
 //     WithSocketTimeout set read-write timeout
 //     WithTransportConfig set TransportConfig
 //     WithTransport set self-defined Transport
 //     WithLogger set self-defined Logger
 //     WithEnableCRC set CRC switch.
+//     WithEnableContentMD5 set Content-MD5 switch.
+//     WithContentMD5BufferLimit set max bytes buffered to calculate Content-MD5.
+//     WithRateLimiter set RateLimiter shared by all requests.
+//     WithMaxIdleConns, WithMaxIdleConnsPerHost, WithMaxConnsPerHost, WithIdleConnTimeout, WithTLSHandshakeTimeout,
+//     WithBufferSize and WithDisableCompression tune the http.Transport built by the SDK
+//     WithProxy set Proxy, WithProxyFromEnvironment resolve proxy from environment variables
+//     WithEnableDNSCache set whether resolved IPs are cached, WithDNSCacheTTL set their time-to-live
 //     WithMaxRetryCount  set Max Retry Count
+//     WithRetryPolicy set RetryPolicy, including max retry count, backoff and jitter
 func NewClientV2(endpoint string, options ...ClientOption) (*ClientV2, error) {
 	client := ClientV2{
 		Client: Client{
 			recognizer: ExtensionBasedContentTypeRecognizer{},
 			config:     defaultConfig(),
-			retry:      newRetryer([]time.Duration{}),
+			retry:      newRetryer(RetryPolicy{}),
 			userAgent:  fmt.Sprintf("tos-go-sdk/%s (%s/%s;%s)", Version, runtime.GOOS, runtime.GOARCH, runtime.Version()),
 			enableCRC:  true,
 		},
 	}
-	client.retry.SetJitter(0.25)
 	err := initClient(&client.Client, endpoint, options...)
 	if err != nil {
 		return nil, err
@@ -355,25 +562,50 @@ func (cli *Client) newBuilder(bucket, object string, options ...Option) *request
 		URLMode:    cli.urlMode,
 		Query:      make(url.Values),
 		Header:     make(http.Header),
+		Retry:      cli.retry,
 		OnRetry:    func(req *Request) {},
 		Classifier: StatusCodeClassifier{},
+		Anonymous:  cli.anonymous,
+		SignRule:   cli.signRule,
 	}
 	rb.Header.Set(HeaderUserAgent, cli.userAgent)
+	if len(cli.requestPayer) > 0 && len(bucket) > 0 {
+		rb.Header.Set(HeaderRequestPayer, cli.requestPayer)
+	}
 	if typ := cli.recognizer.ContentType(object); len(typ) > 0 {
 		rb.Header.Set(HeaderContentType, typ)
 	}
 	for _, option := range options {
 		option(rb)
 	}
-	rb.Retry = cli.retry
+	if cli.provider != nil && rb.Signer == cli.signer {
+		rb.Provider = cli.provider
+	}
 	return rb
 }
 
 func (cli *Client) roundTrip(ctx context.Context, req *Request, expectedCode int, expectedCodes ...int) (*Response, error) {
+	limiter := cli.rateLimiter
+	if bypass, _ := ctx.Value(bypassRateLimiterKey{}).(bool); bypass {
+		limiter = nil
+	}
+	if limiter != nil && req.Content != nil {
+		// req.Content is kept as is, so that it can be rewound on retrying
+		base, ok := req.Content.(io.ReadCloser)
+		if !ok {
+			base = ioutil.NopCloser(req.Content)
+		}
+		limited := *req
+		limited.Content = &ReadCloserWithLimiter{limiter: limiter, base: base}
+		req = &limited
+	}
 	res, err := cli.transport.RoundTrip(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	if limiter != nil && res.Body != nil {
+		res.Body = &ReadCloserWithLimiter{limiter: limiter, base: res.Body}
+	}
 	readBody := req.Method != http.MethodHead
 	if err = checkError(res, readBody, expectedCode, expectedCodes...); err != nil {
 		return nil, err
@@ -381,10 +613,10 @@ func (cli *Client) roundTrip(ctx context.Context, req *Request, expectedCode int
 	return res, nil
 }
 
-func (cli *Client) roundTripper(expectedCode int) roundTripper {
+func (cli *Client) roundTripper(expectedCode int, expectedCodes ...int) roundTripper {
 	return func(ctx context.Context, req *Request) (*Response, error) {
 		start := time.Now()
-		resp, err := cli.roundTrip(ctx, req, expectedCode)
+		resp, err := cli.roundTrip(ctx, req, expectedCode, expectedCodes...)
 		if cli.logger != nil {
 			if err != nil {
 				cli.logger.Infof("[tos] http error:%s.", err.Error())
@@ -416,32 +648,114 @@ func (cli *Client) PreSignedURL(httpMethod string, bucket, objectKey string, ttl
 		PreSignedURL(httpMethod, ttl)
 }
 
-// PreSignedURL return pre-signed url
+// PreSignedURL return pre-signed url, no request is sent.
+// SignedHeader of output contains the headers the client must send with the URL.
 func (cli *ClientV2) PreSignedURL(input *PreSignedURLInput) (*PreSignedURLOutput, error) {
-	if err := IsValidBucketName(input.Bucket); err != nil {
-		return nil, err
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if !input.IsCustomDomain {
+		if err := IsValidBucketName(input.Bucket); err != nil {
+			return nil, err
+		}
+	}
+	if len(input.Key) > 0 {
+		if err := isValidKey(input.Key); err != nil {
+			return nil, err
+		}
+	}
+	expires := input.Expires
+	if expires == 0 {
+		expires = DefaultPreSignedURLExpires
 	}
+	if expires < 1 || expires > MaxPreSignedURLExpires {
+		return nil, newTosClientError("tos: invalid expires, the expires must be [1, 604800] seconds", nil)
+	}
+	method := string(input.HTTPMethod)
+	if len(method) == 0 {
+		method = http.MethodGet
+	}
+
 	rb := cli.newBuilder(input.Bucket, input.Key)
+	if len(input.AlternativeEndpoint) > 0 {
+		rb.Scheme, rb.Host, rb.URLMode = schemeHost(input.AlternativeEndpoint)
+	}
+	if input.IsCustomDomain {
+		rb.URLMode = urlModeCustomDomain
+	}
+	// only headers set by user are required to send
+	rb.Header = make(http.Header, len(input.Header))
 	for k, v := range input.Header {
 		rb.WithHeader(k, v)
 	}
 	for k, v := range input.Query {
 		rb.WithQuery(k, v)
 	}
-	if input.Expires == 0 {
-		input.Expires = 3600
+	if len(input.Process) > 0 {
+		rb.Query.Set("x-tos-process", input.Process)
 	}
-	signedURL, err := rb.PreSignedURL(string(input.HTTPMethod), time.Second*time.Duration(3600))
+	rb.SignRule = rb.SignRule.merge(input.SignHeaderRule)
+	signedURL, err := rb.PreSignedURL(method, time.Second*time.Duration(expires))
 	if err != nil {
 		return nil, err
 	}
-	signed := make(map[string]string)
+	signed := make(map[string]string, len(rb.Header)+1)
 	for k := range rb.Header {
 		signed[k] = rb.Header.Get(k)
 	}
+	signed["Host"], _ = rb.hostPath()
 	output := &PreSignedURLOutput{
 		SignedUrl:    signedURL,
 		SignedHeader: signed,
 	}
 	return output, nil
 }
+
+// PreSignedMultipartUploadURLs return pre-signed URLs to upload parts of UploadID, complete and abort the upload,
+// no request is sent. Browsers need not send headers other than Host with the URLs.
+// Use PreSignedURL with HTTPMethod POST and Query "uploads" to pre-sign CreateMultipartUpload.
+func (cli *ClientV2) PreSignedMultipartUploadURLs(input *PreSignedMultipartUploadURLsInput) (*PreSignedMultipartUploadURLsOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if len(input.UploadID) == 0 {
+		return nil, newTosClientError("tos: upload id is empty", nil)
+	}
+	if input.PartCount < 1 || input.PartCount > MaxPartCount {
+		return nil, newTosClientError("tos: invalid part count, the part count must be [1, 10000]", nil)
+	}
+	sign := func(method enum.HttpMethodType, query map[string]string) (string, error) {
+		output, err := cli.PreSignedURL(&PreSignedURLInput{
+			HTTPMethod:          method,
+			Bucket:              input.Bucket,
+			Key:                 input.Key,
+			Expires:             input.Expires,
+			Query:               query,
+			AlternativeEndpoint: input.AlternativeEndpoint,
+			IsCustomDomain:      input.IsCustomDomain,
+		})
+		if err != nil {
+			return "", err
+		}
+		return output.SignedUrl, nil
+	}
+	output := &PreSignedMultipartUploadURLsOutput{PartURLs: make([]string, 0, input.PartCount)}
+	for partNumber := 1; partNumber <= input.PartCount; partNumber++ {
+		partURL, err := sign(enum.HttpMethodPut, map[string]string{
+			"uploadId":   input.UploadID,
+			"partNumber": strconv.Itoa(partNumber),
+		})
+		if err != nil {
+			return nil, err
+		}
+		output.PartURLs = append(output.PartURLs, partURL)
+	}
+	var err error
+	if output.CompleteURL, err = sign(enum.HttpMethodPost, map[string]string{"uploadId": input.UploadID}); err != nil {
+		return nil, err
+	}
+	if output.AbortURL, err = sign(enum.HttpMethodDelete, map[string]string{"uploadId": input.UploadID}); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
@@ -5,6 +5,7 @@ const (
 	NoSuchBucket                      = "NoSuchBucket"
 	NoContentLength                   = "NoContentLength"
 	InternalError                     = "InternalError"
+	ServiceUnavailable                = "ServiceUnavailable"
 	AuthFailed                        = "AuthFailed"
 	KeyTooLong                        = "KeyTooLong"
 	NotFound                          = "NotFound"
@@ -75,15 +76,18 @@ const (
 	MirrorFailed                      = "MirrorFailed"
 	NotAppendable                     = "NotAppendable"
 	OffsetNotMatched                  = "OffsetNotMatched"
+	PositionNotEqualToLength          = "PositionNotEqualToLength"
 	NoSuchWebsiteConfiguration        = "NoSuchWebsiteConfiguration"
 	InvalidRedirectLocation           = "InvalidRedirectLocation"
 	NoSuchMirrorConfiguration         = "NoSuchMirrorConfiguration"
+	NoSuchEncryptionConfiguration     = "NoSuchEncryptionConfiguration"
 	TryAgain                          = "TryAgain"
 	InvalidCrossRegionCopy            = "InvalidCrossRegionCopy"
 	SourceObjectAccessDenied          = "SourceObjectAccessDenied"
 	SourceObjectNotFound              = "SourceObjectNotFound"
 	NoSuchBucketReplication           = "NoSuchBucketReplication"
 	TooManyRequests                   = "TooManyRequests"
+	SlowDown                          = "SlowDown"
 	ConcurrencyUpdateObjectLimit      = "ConcurrencyUpdateObjectLimit"
 	DuplicateUpload                   = "DuplicateUpload"
 	DuplicateObject                   = "DuplicateObject"
@@ -6,6 +6,7 @@ type Config struct {
 	Endpoint        string
 	Region          string
 	TransportConfig TransportConfig
+	RetryPolicy     RetryPolicy
 }
 
 func defaultConfig() Config {
@@ -27,5 +28,8 @@ func DefaultTransportConfig() TransportConfig {
 		ExpectContinueTimeout: 3 * time.Second,
 		ReadTimeout:           30 * time.Second,
 		WriteTimeout:          30 * time.Second,
+		ReadBufferSize:        64 * 1024,
+		WriteBufferSize:       64 * 1024,
+		DNSCacheTTL:           DefaultDNSCacheTTL,
 	}
 }
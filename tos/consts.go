@@ -10,7 +10,9 @@ const (
 	Version = "v2.2.1"
 )
 
-const TempFileSuffix = ".temp"
+// TempFileSuffix is the default suffix of temp file written by GetObjectToFile and DownloadFile,
+// the temp file is renamed to the target file after downloaded
+const TempFileSuffix = ".tos.tmp"
 const DefaultFilePerm = os.FileMode(0644)
 
 var DefaultCrcTable = func() *crc64.Table {
@@ -19,6 +21,22 @@ var DefaultCrcTable = func() *crc64.Table {
 
 const DefaultTaskBufferSize = 100
 
+// DefaultContentMD5BufferLimit max bytes buffered to calculate Content-MD5 of content not seekable
+const DefaultContentMD5BufferLimit = 64 * 1024 * 1024
+
+// DefaultAbortTaskNum max number of uploads aborted in parallel by AbortStaleMultipartUploads
+const DefaultAbortTaskNum = 8
+
+// DefaultRateLimiterGranularity min bytes acquired from token bucket at once by NewBandwidthRateLimiter
+const DefaultRateLimiterGranularity = 32 * 1024
+
+// DefaultDirectoryTaskNum max number of files transferred in parallel by UploadDirectory and DownloadDirectory
+const DefaultDirectoryTaskNum = 8
+
+// DefaultMultipartThreshold files larger than it are uploaded by multipart upload in UploadDirectory,
+// and downloaded by range in DownloadDirectory
+const DefaultMultipartThreshold = 20 * 1024 * 1024
+
 func SupportedRegion() map[string]string {
 	return map[string]string{
 		"cn-beijing":   "tos-cn-beijing.volces.com",
@@ -28,10 +46,26 @@ func SupportedRegion() map[string]string {
 }
 
 const (
-	MaxPartSize = 5 * 1024 * 1024 * 1024
-	MinPartSize = 5 * 1024 * 1024
+	MaxPartSize  = 5 * 1024 * 1024 * 1024
+	MinPartSize  = 5 * 1024 * 1024
+	MaxPartCount = 10000
 )
 
+// MaxDeleteObjectsCount max number of objects deleted by DeleteMultiObjects at once
+const MaxDeleteObjectsCount = 1000
+
+// DefaultDeleteTaskNum max number of DeleteMultiObjects requests in parallel sent by DeleteObjects
+const DefaultDeleteTaskNum = 4
+
+// DefaultDeleteMaxRetryCount max times DeleteObjects retries to delete an object failed with retriable code
+const DefaultDeleteMaxRetryCount = 3
+
+// DefaultPreSignedURLExpires default expiration seconds of pre-signed URL
+const DefaultPreSignedURLExpires = 3600
+
+// MaxPreSignedURLExpires max expiration seconds of pre-signed URL, 7 days
+const MaxPreSignedURLExpires = 7 * 24 * 3600
+
 const (
 	// Deprecated: use enum.ACLPrivate instead
 	ACLPrivate = "private"
@@ -91,15 +125,18 @@ const (
 	HeaderDeleteMarker                = "X-Tos-Delete-Marker"
 	HeaderStorageClass                = "X-Tos-Storage-Class"
 	HeaderAzRedundancy                = "X-Tos-Az-Redundancy"
+	HeaderProjectName                 = "X-Tos-Project-Name"
+	HeaderBucketType                  = "X-Tos-Bucket-Type"
 	HeaderRestore                     = "X-Tos-Restore"
 	HeaderTag                         = "X-Tos-Tag"
 	HeaderSSECustomerAlgorithm        = "X-Tos-Server-Side-Encryption-Customer-Algorithm"
 	HeaderSSECustomerKeyMD5           = "X-Tos-Server-Side-Encryption-Customer-Key-MD5"
 	HeaderSSECustomerKey              = "X-Tos-Server-Side-Encryption-Customer-Key"
 	HeaderServerSideEncryption        = "X-Tos-Server-Side-Encryption"
-	HeaderCopySourceSSECAlgorithm     = "X-Tos-Server-Side-Encryption-Customer-Algorithm"
-	HeaderCopySourceSSECKeyMD5        = "X-Tos-Server-Side-Encryption-Customer-Key-MD5"
-	HeaderCopySourceSSECKey           = "X-Tos-Server-Side-Encryption-Customer-Key"
+	HeaderSSEKMSKeyID                 = "X-Tos-Server-Side-Encryption-Kms-Key-Id"
+	HeaderCopySourceSSECAlgorithm     = "X-Tos-Copy-Source-Server-Side-Encryption-Customer-Algorithm"
+	HeaderCopySourceSSECKeyMD5        = "X-Tos-Copy-Source-Server-Side-Encryption-Customer-Key-MD5"
+	HeaderCopySourceSSECKey           = "X-Tos-Copy-Source-Server-Side-Encryption-Customer-Key"
 	HeaderIfModifiedSince             = "If-Modified-Since"
 	HeaderIfUnmodifiedSince           = "If-Unmodified-Since"
 	HeaderIfMatch                     = "If-Match"
@@ -110,6 +147,7 @@ const (
 	HeaderID2                         = "X-Tos-Id-2"
 	HeaderBucketRegion                = "X-Tos-Bucket-Region"
 	HeaderLocation                    = "Location"
+	HeaderRetryAfter                  = "Retry-After"
 	HeaderACL                         = "X-Tos-Acl"
 	HeaderGrantFullControl            = "X-Tos-Grant-Full-Control"
 	HeaderGrantRead                   = "X-Tos-Grant-Read"
@@ -117,8 +155,13 @@ const (
 	HeaderGrantWrite                  = "X-Tos-Grant-Write"
 	HeaderGrantWriteAcp               = "X-Tos-Grant-Write-Acp"
 	HeaderNextAppendOffset            = "X-Tos-Next-Append-Offset"
+	HeaderNextModifyOffset            = "X-Tos-Next-Modify-Offset"
+	HeaderTrafficLimit                = "X-Tos-Traffic-Limit"
 	HeaderObjectType                  = "X-Tos-Object-Type"
 	HeaderHashCrc64ecma               = "X-Tos-Hash-Crc64ecma"
+	HeaderCompleteAll                 = "X-Tos-Complete-All"
+	HeaderDecodedContentLength        = "X-Tos-Decoded-Content-Length"
+	HeaderTrailer                     = "X-Tos-Trailer"
 	HeaderMetadataDirective           = "X-Tos-Metadata-Directive"
 	HeaderCopySource                  = "X-Tos-Copy-Source"
 	HeaderCopySourceIfMatch           = "X-Tos-Copy-Source-If-Match"
@@ -129,5 +172,21 @@ const (
 	HeaderCopySourceVersionID         = "X-Tos-Copy-Source-Version-Id"
 	HeaderWebsiteRedirectLocation     = "X-Tos-Website-Redirect-Location"
 	HeaderCSType                      = "X-Tos-Cs-Type"
+	HeaderSymlinkTarget               = "X-Tos-Symlink-Target"
+	HeaderSymlinkBucket               = "X-Tos-Symlink-Bucket"
+	HeaderForbidOverwrite             = "X-Tos-Forbid-Overwrite"
+	HeaderTagging                     = "X-Tos-Tagging"
+	HeaderTaggingCount                = "X-Tos-Tagging-Count"
+	HeaderTaggingDirective            = "X-Tos-Tagging-Directive"
+	HeaderObjectLockMode              = "X-Tos-Object-Lock-Mode"
+	HeaderObjectLockRetainUntilDate   = "X-Tos-Object-Lock-Retain-Until-Date"
+	HeaderObjectLockLegalHold         = "X-Tos-Object-Lock-Legal-Hold"
+	HeaderBypassGovernanceRetention   = "X-Tos-Bypass-Governance-Retention"
+	HeaderRequestPayer                = "X-Tos-Request-Payer"
+	HeaderRequestCharged              = "X-Tos-Request-Charged"
 	HeaderMetaPrefix                  = "X-Tos-Meta-"
 )
+
+// RequestPayerRequester is value of RequestPayer to access requester-pays bucket,
+// the requester pays for the request and the data transfer.
+const RequestPayerRequester = "requester"
@@ -1,12 +1,18 @@
 package tos
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
 	"time"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/codes"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
 )
 
 // CopyObject copy an object
@@ -103,7 +109,8 @@ func (cli *Client) copyObject(ctx context.Context, dstBucket, dstObject string,
 	return &out, nil
 }
 
-// CopyObject copy an object
+// CopyObject copy an object.
+// Metadata and tags of the source object are copied unless MetadataDirective or TaggingDirective is REPLACE.
 func (cli *ClientV2) CopyObject(ctx context.Context, input *CopyObjectInput) (*CopyObjectOutput, error) {
 	if err := IsValidBucketName(input.SrcBucket); err != nil {
 		return nil, err
@@ -114,11 +121,36 @@ func (cli *ClientV2) CopyObject(ctx context.Context, input *CopyObjectInput) (*C
 	if err := isValidKey(input.Key, input.SrcKey); err != nil {
 		return nil, err
 	}
-	res, err := cli.newBuilder(input.Bucket, input.Key).
-		WithParams(*input).
+	if err := isValidSSE(input.SSECAlgorithm, input.SSECKey, input.ServerSideEncryption, input.ServerSideEncryptionKeyID); err != nil {
+		return nil, err
+	}
+	if err := isValidStorageClass(input.StorageClass); err != nil {
+		return nil, err
+	}
+	params := *input
+	if params.MetadataDirective != enum.MetadataDirectiveReplace {
+		// metadata of the source object is copied
+		params.CacheControl = ""
+		params.ContentDisposition = ""
+		params.ContentEncoding = ""
+		params.ContentLanguage = ""
+		params.ContentType = ""
+		params.Expires = time.Time{}
+		params.WebsiteRedirectLocation = ""
+		params.Meta = nil
+	}
+	if params.TaggingDirective != enum.TaggingDirectiveReplace {
+		params.Tagging = ""
+	}
+	rb := cli.newBuilder(input.Bucket, input.Key)
+	if params.MetadataDirective != enum.MetadataDirectiveReplace {
+		// Content-Type recognized from the key is not set either
+		rb.Header.Del(HeaderContentType)
+	}
+	res, err := rb.WithParams(params).
 		WithCopySource(input.SrcBucket, input.SrcKey).
-		WithRetry(nil, ServerErrorClassifier{}).
-		Request(ctx, http.MethodPut, nil, cli.roundTripper(http.StatusOK))
+		WithRetry(nil, copyErrorClassifier{}).
+		Request(ctx, http.MethodPut, nil, cli.copyRoundTripper(http.StatusOK))
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +161,8 @@ func (cli *ClientV2) CopyObject(ctx context.Context, input *CopyObjectInput) (*C
 	}
 	out.VersionID = res.Header.Get(HeaderVersionID)
 	out.SourceVersionID = res.Header.Get(HeaderCopySourceVersionID)
+	out.ServerSideEncryption = res.Header.Get(HeaderServerSideEncryption)
+	out.ServerSideEncryptionKeyID = res.Header.Get(HeaderSSEKMSKeyID)
 	return &out, nil
 }
 
@@ -137,6 +171,51 @@ type uploadPartCopyOutput struct {
 	LastModified string `json:"LastModified,omitempty"`
 }
 
+// copyErrorClassifier classify errors returned by copy APIs.
+// Copy APIs may return status code 200 with an error in body, in this case it returns Retry if the code is
+// retryable, e.g. InternalError, ServiceUnavailable and SlowDown; otherwise, it is the same as ServerErrorClassifier.
+type copyErrorClassifier struct{}
+
+// Classify implements the classifier interface.
+func (classifier copyErrorClassifier) Classify(err error) retryAction {
+	if e, ok := err.(*TosServerError); ok && e.StatusCode == http.StatusOK {
+		switch e.Code {
+		case codes.InternalError, codes.ServiceUnavailable, codes.SlowDown:
+			return Retry
+		}
+	}
+	return ServerErrorClassifier{}.Classify(err)
+}
+
+// copyRoundTripper is the same as roundTripper, but also returns TosServerError
+// if status code is 200 and the response body is an error.
+func (cli *Client) copyRoundTripper(expectedCode int) roundTripper {
+	rt := cli.roundTripper(expectedCode)
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		res, err := rt(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(res.Body)
+		_ = res.Close()
+		if err != nil {
+			return nil, newTosClientError("tos: read response body failed", err)
+		}
+		var se Error
+		if json.Unmarshal(data, &se) == nil && len(se.Code) > 0 {
+			return nil, &TosServerError{
+				TosError:    TosError{se.Message},
+				RequestInfo: res.RequestInfo(),
+				Code:        se.Code,
+				HostID:      se.HostID,
+				Resource:    se.Resource,
+			}
+		}
+		res.Body = ioutil.NopCloser(bytes.NewReader(data))
+		return res, nil
+	}
+}
+
 func copyRange(startOffset, partSize *int64) string {
 	cr := ""
 	if startOffset != nil {
@@ -223,28 +302,43 @@ func (cli *ClientV2) UploadPartCopyV2(
 	if err := isValidKey(input.SrcKey, input.Key); err != nil {
 		return nil, err
 	}
-
+	if input.PartNumber == 0 || input.UploadID == "" {
+		return nil, InputInvalidClientError
+	}
+	copyRange := input.CopySourceRange
+	if len(copyRange) == 0 {
+		copyRange = copyRangeV2(input.CopySourceRangeStart, input.CopySourceRangeEnd)
+	}
 	res, err := cli.newBuilder(input.Bucket, input.Key).
 		WithParams(*input).
-		WithHeader(HeaderCopySourceRange, copyRangeV2(input.CopySourceRangeStart, input.CopySourceRangeEnd)).
+		WithHeader(HeaderCopySourceRange, copyRange).
 		WithCopySource(input.SrcBucket, input.SrcKey).
-		WithRetry(nil, ServerErrorClassifier{}).
-		Request(ctx, http.MethodPut, nil, cli.roundTripper(http.StatusOK))
+		WithRetry(nil, copyErrorClassifier{}).
+		Request(ctx, http.MethodPut, nil, cli.copyRoundTripper(http.StatusOK))
 	if err != nil {
 		return nil, err
 	}
 	defer res.Close()
+	if err = checkSSECKeyMD5(res, input.SSECKey, input.SSECKeyMD5); err != nil {
+		return nil, err
+	}
 	var out uploadPartCopyOutput
 	if err = marshalOutput(res.RequestInfo().RequestID, res.Body, &out); err != nil {
 		return nil, err
 	}
 
-	lastModified, _ := time.ParseInLocation(http.TimeFormat, res.Header.Get(HeaderLastModified), time.UTC)
+	lastModified, err := time.ParseInLocation(http.TimeFormat, res.Header.Get(HeaderLastModified), time.UTC)
+	if err != nil {
+		// fall back to the LastModified in body
+		lastModified, _ = time.Parse(time.RFC3339, out.LastModified)
+	}
 	return &UploadPartCopyV2Output{
 		RequestInfo:         res.RequestInfo(),
 		CopySourceVersionID: res.Header.Get(HeaderCopySourceVersionID),
 		PartNumber:          input.PartNumber,
 		ETag:                out.ETag,
 		LastModified:        lastModified,
+		SSECAlgorithm:       res.Header.Get(HeaderSSECustomerAlgorithm),
+		SSECKeyMD5:          res.Header.Get(HeaderSSECustomerKeyMD5),
 	}, nil
 }
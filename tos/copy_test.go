@@ -0,0 +1,154 @@
+package tos
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+type mockTransport struct {
+	lock      sync.Mutex
+	requests  []*Request
+	responses []func() *Response
+	handler   func(req *Request) *Response // takes precedence over responses if set
+}
+
+func (m *mockTransport) RoundTrip(_ context.Context, req *Request) (*Response, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.requests = append(m.requests, req)
+	if m.handler != nil {
+		return m.handler(req), nil
+	}
+	res := m.responses[0]()
+	if len(m.responses) > 1 {
+		m.responses = m.responses[1:]
+	}
+	return res, nil
+}
+
+func (m *mockTransport) recorded() []*Request {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]*Request(nil), m.requests...)
+}
+
+func newMockResponse(statusCode int, body string) func() *Response {
+	return func() *Response {
+		return &Response{
+			StatusCode: statusCode,
+			Header:     http.Header{HeaderRequestID: []string{"request-id"}},
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+		}
+	}
+}
+
+// requestBody reads the body of a recorded request, which is left unread by responses of mockTransport
+func requestBody(t *testing.T, req *Request) string {
+	if req.Content == nil {
+		return ""
+	}
+	data, err := ioutil.ReadAll(req.Content)
+	require.Nil(t, err)
+	return string(data)
+}
+
+func newMockClient(t *testing.T, transport *mockTransport) *ClientV2 {
+	client, err := NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithCredentials(NewStaticCredentials("ak", "sk")), WithTransport(transport), WithMaxRetryCount(2))
+	require.Nil(t, err)
+	return client
+}
+
+func TestUploadPartCopyV2ErrorInBody(t *testing.T) {
+	transport := &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, `{"Code":"InternalError","Message":"internal error"}`),
+		newMockResponse(http.StatusOK, `{"ETag":"\"etag\"","LastModified":"2022-09-09T08:00:00Z"}`),
+	}}
+	client := newMockClient(t, transport)
+	input := &UploadPartCopyV2Input{
+		Bucket:          "bucket",
+		Key:             "key",
+		UploadID:        "upload-id",
+		PartNumber:      1,
+		SrcBucket:       "src-bucket",
+		SrcKey:          "src-key",
+		CopySourceRange: "bytes=0-0",
+	}
+	output, err := client.UploadPartCopyV2(context.Background(), input)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(transport.requests))
+	require.Equal(t, `"etag"`, output.ETag)
+	require.Equal(t, 2022, output.LastModified.Year())
+	require.Equal(t, "bytes=0-0", transport.requests[0].Header.Get(HeaderCopySourceRange))
+
+	transport = &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, `{"Code":"InternalError","Message":"internal error"}`),
+	}}
+	client = newMockClient(t, transport)
+	_, err = client.UploadPartCopyV2(context.Background(), input)
+	require.NotNil(t, err)
+	require.Equal(t, "InternalError", Code(err))
+	require.Equal(t, 3, len(transport.requests))
+
+	// errors with non-retryable codes fail fast
+	transport = &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, `{"Code":"AccessDenied","Message":"access denied"}`),
+	}}
+	client = newMockClient(t, transport)
+	_, err = client.UploadPartCopyV2(context.Background(), input)
+	require.Equal(t, "AccessDenied", Code(err))
+	require.Equal(t, 1, len(transport.requests))
+}
+
+func TestCopyObjectDirectives(t *testing.T) {
+	transport := &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, `{"ETag":"\"etag\"","LastModified":"2022-09-09T08:00:00Z"}`),
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+	input := &CopyObjectInput{
+		Bucket:       "bucket",
+		Key:          "key.txt",
+		SrcBucket:    "src-bucket",
+		SrcKey:       "src-key",
+		SrcVersionID: "version",
+		ContentType:  "application/json",
+		StorageClass: enum.StorageClassIa,
+		Tagging:      "k=v",
+		Meta:         map[string]string{"k": "v"},
+	}
+	output, err := client.CopyObject(ctx, input)
+	require.Nil(t, err)
+	require.Equal(t, "\"etag\"", output.ETag)
+	require.Equal(t, "2022-09-09T08:00:00Z", output.LastModified)
+	req := transport.recorded()[0]
+	require.Equal(t, "/src-bucket/src-key?versionId=version", req.Header.Get(HeaderCopySource))
+	require.Empty(t, req.Query.Get("versionId"))
+	require.Equal(t, string(enum.StorageClassIa), req.Header.Get(HeaderStorageClass))
+	// metadata and tags are copied from the source object
+	require.Empty(t, req.Header.Get(HeaderContentType))
+	require.Empty(t, req.Header.Get(HeaderMetaPrefix+"k"))
+	require.Empty(t, req.Header.Get(HeaderTagging))
+
+	input.MetadataDirective = enum.MetadataDirectiveReplace
+	input.TaggingDirective = enum.TaggingDirectiveReplace
+	_, err = client.CopyObject(ctx, input)
+	require.Nil(t, err)
+	req = transport.recorded()[1]
+	require.Equal(t, "application/json", req.Header.Get(HeaderContentType))
+	require.Equal(t, "v", req.Header.Get(HeaderMetaPrefix+"k"))
+	require.Equal(t, "k=v", req.Header.Get(HeaderTagging))
+	require.Equal(t, "REPLACE", req.Header.Get(HeaderTaggingDirective))
+
+	// error in body of 200 response
+	transport.responses = []func() *Response{newMockResponse(http.StatusOK, `{"Code":"InternalError","Message":"internal error"}`)}
+	_, err = client.CopyObject(ctx, input)
+	require.Equal(t, "InternalError", Code(err))
+}
@@ -1,6 +1,7 @@
 package tos
 
 import (
+	"context"
 	"sync/atomic"
 	"time"
 	"unsafe"
@@ -19,6 +20,27 @@ type Credentials interface {
 	Credential() Credential
 }
 
+// CredentialsProvider provides Credential for each request, so that rotated credentials are used without
+// rebuilding the client. Implementations should cache Credential if retrieving is expensive.
+//
+// see CredentialsChain, EnvCredentialsProvider, SharedCredentialsProvider, ECSCredentialsProvider
+// and FederationCredentialsProvider
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credential, error)
+}
+
+// providerCredentials adapts CredentialsProvider to Credentials of SignV4,
+// it's used by presigned urls and post signatures which have no context,
+// a zero Credential is returned if the provider fails.
+type providerCredentials struct {
+	provider CredentialsProvider
+}
+
+func (pc *providerCredentials) Credential() Credential {
+	cred, _ := pc.provider.Credentials(context.Background())
+	return cred
+}
+
 // StaticCredentials Credentials with static access-key and secret-key
 type StaticCredentials struct {
 	accessKey     string
@@ -55,6 +77,14 @@ func (sc *StaticCredentials) Credential() Credential {
 	}
 }
 
+// Credentials for CredentialsProvider interface, fails if access-key or secret-key is empty
+func (sc *StaticCredentials) Credentials(ctx context.Context) (Credential, error) {
+	if len(sc.accessKey) == 0 || len(sc.secretKey) == 0 {
+		return Credential{}, newTosClientError("tos: access-key and secret-key of StaticCredentials are required", nil)
+	}
+	return sc.Credential(), nil
+}
+
 // WithoutSecretKeyCredentials Credentials with static access-key and no secret-key
 //
 // If you don't want to use secret-key directly, but use signed-key, you can use it as:
@@ -0,0 +1,554 @@
+package tos
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	EnvAccessKey       = "TOS_ACCESS_KEY"
+	EnvSecretKey       = "TOS_SECRET_KEY"
+	EnvSecurityToken   = "TOS_SECURITY_TOKEN"
+	EnvCredentialsFile = "TOS_CREDENTIALS_FILE"
+	EnvProfile         = "TOS_PROFILE"
+
+	DefaultProfile     = "default"
+	DefaultECSEndpoint = "http://100.96.0.96"
+)
+
+// EnvCredentialsProvider provides Credential from environment variables
+// TOS_ACCESS_KEY, TOS_SECRET_KEY and TOS_SECURITY_TOKEN, which are read for each request.
+type EnvCredentialsProvider struct{}
+
+func (EnvCredentialsProvider) Credentials(ctx context.Context) (Credential, error) {
+	cred := Credential{
+		AccessKeyID:     os.Getenv(EnvAccessKey),
+		AccessKeySecret: os.Getenv(EnvSecretKey),
+		SecurityToken:   os.Getenv(EnvSecurityToken),
+	}
+	if len(cred.AccessKeyID) == 0 || len(cred.AccessKeySecret) == 0 {
+		return Credential{}, newTosClientError("tos: "+EnvAccessKey+" and "+EnvSecretKey+" are not set", nil)
+	}
+	return cred, nil
+}
+
+// SharedCredentialsProvider provides Credential of a profile in the shared credentials file, e.g.
+//
+//	[default]
+//	access_key = AK
+//	secret_key = SK
+//	security_token = STS
+//
+// The file is read again if it's modified, so that the credentials can be rotated by rewriting it.
+type SharedCredentialsProvider struct {
+	path    string
+	profile string
+	mu      sync.Mutex
+	modTime time.Time
+	cached  *Credential
+}
+
+// NewSharedCredentialsProvider create SharedCredentialsProvider,
+// path is TOS_CREDENTIALS_FILE or ~/.tos/credentials if empty, and profile is TOS_PROFILE or "default" if empty.
+func NewSharedCredentialsProvider(path, profile string) *SharedCredentialsProvider {
+	if len(path) == 0 {
+		path = os.Getenv(EnvCredentialsFile)
+	}
+	if len(path) == 0 {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, ".tos", "credentials")
+		}
+	}
+	if len(profile) == 0 {
+		profile = os.Getenv(EnvProfile)
+	}
+	if len(profile) == 0 {
+		profile = DefaultProfile
+	}
+	return &SharedCredentialsProvider{path: path, profile: profile}
+}
+
+func (sp *SharedCredentialsProvider) Credentials(ctx context.Context) (Credential, error) {
+	if len(sp.path) == 0 {
+		return Credential{}, newTosClientError("tos: shared credentials file is not found", nil)
+	}
+	info, err := os.Stat(sp.path)
+	if err != nil {
+		return Credential{}, newTosClientError("tos: shared credentials file is not found", err)
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	if sp.cached != nil && sp.modTime.Equal(info.ModTime()) {
+		return *sp.cached, nil
+	}
+	cred, err := sp.load()
+	if err != nil {
+		return Credential{}, err
+	}
+	sp.cached = &cred
+	sp.modTime = info.ModTime()
+	return cred, nil
+}
+
+func (sp *SharedCredentialsProvider) load() (Credential, error) {
+	file, err := os.Open(sp.path)
+	if err != nil {
+		return Credential{}, newTosClientError("tos: open shared credentials file failed", err)
+	}
+	defer file.Close()
+	var (
+		cred    Credential
+		section string
+	)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != sp.profile {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(kv[1])
+		switch strings.TrimSpace(kv[0]) {
+		case "access_key":
+			cred.AccessKeyID = value
+		case "secret_key":
+			cred.AccessKeySecret = value
+		case "security_token":
+			cred.SecurityToken = value
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return Credential{}, newTosClientError("tos: read shared credentials file failed", err)
+	}
+	if len(cred.AccessKeyID) == 0 || len(cred.AccessKeySecret) == 0 {
+		return Credential{}, newTosClientError("tos: access_key and secret_key of profile "+sp.profile+" are not set", nil)
+	}
+	return cred, nil
+}
+
+const (
+	ecsCredentialsPath = "/volcstack/latest/iam/security_credentials/"
+	ecsTokenPath       = "/volcstack/latest/api/token"
+
+	headerMetadataToken    = "X-Metadata-Token"
+	headerMetadataTokenTTL = "X-Metadata-Token-TTL-Seconds"
+
+	// ecsTokenTTL TTL of metadata token, it's fetched again a minute before it expires
+	ecsTokenTTL = 6 * time.Hour
+)
+
+// ecsCredential is the security credential of the role returned by ECS metadata service
+type ecsCredential struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	SessionToken    string    `json:"SessionToken"`
+	ExpiredTime     time.Time `json:"ExpiredTime"`
+}
+
+// ECSCredentialsProvider provides Credential of the role attached to the ECS instance from the metadata service,
+// the Credential is cached and retrieved again before it expires. Requests to the metadata service carry
+// a metadata token if the service supports it, and time out quickly so that CredentialsChain falls through
+// outside ECS.
+//
+// use WithEndpoint set metadata endpoint, WithTimeout set timeout and WithPreFetch set prefetch time
+type ECSCredentialsProvider struct {
+	roleName        string
+	endpoint        string
+	preFetch        time.Duration
+	client          *http.Client
+	mu              sync.Mutex
+	cached          *FederationToken
+	token           string
+	tokenExpiration time.Time
+}
+
+// NewECSCredentialsProvider create ECSCredentialsProvider, the role attached to the instance is used if roleName is empty
+func NewECSCredentialsProvider(roleName string) *ECSCredentialsProvider {
+	return &ECSCredentialsProvider{
+		roleName: roleName,
+		endpoint: DefaultECSEndpoint,
+		preFetch: 5 * time.Minute,
+		client: &http.Client{
+			Timeout: time.Second,
+			// the metadata service is link-local and never accessed through proxy
+			Transport: &http.Transport{
+				DialContext: (&net.Dialer{Timeout: 200 * time.Millisecond}).DialContext,
+			},
+		},
+	}
+}
+
+// WithEndpoint set metadata endpoint, e.g. http://100.96.0.96
+func (ep *ECSCredentialsProvider) WithEndpoint(endpoint string) {
+	ep.endpoint = strings.TrimSuffix(endpoint, "/")
+}
+
+// WithTimeout set timeout of each request to the metadata service, 1s by default
+func (ep *ECSCredentialsProvider) WithTimeout(timeout time.Duration) {
+	ep.client.Timeout = timeout
+}
+
+// WithPreFetch set prefetch time
+func (ep *ECSCredentialsProvider) WithPreFetch(preFetch time.Duration) {
+	ep.preFetch = preFetch
+}
+
+func (ep *ECSCredentialsProvider) Credentials(ctx context.Context) (Credential, error) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	now := time.Now()
+	if ep.cached != nil && now.Add(ep.preFetch).Before(ep.cached.Expiration) {
+		return ep.cached.Credential, nil
+	}
+	token, err := ep.fetch(ctx)
+	if err != nil {
+		// keep using the cached one until it expires
+		if ep.cached != nil && now.Before(ep.cached.Expiration) {
+			return ep.cached.Credential, nil
+		}
+		return Credential{}, err
+	}
+	ep.cached = token
+	return token.Credential, nil
+}
+
+func (ep *ECSCredentialsProvider) fetch(ctx context.Context) (*FederationToken, error) {
+	if err := ep.fetchMetadataToken(ctx); err != nil {
+		return nil, err
+	}
+	roleName := ep.roleName
+	if len(roleName) == 0 {
+		data, err := ep.get(ctx, ep.endpoint+ecsCredentialsPath)
+		if err != nil {
+			return nil, err
+		}
+		roleName = strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0])
+		if len(roleName) == 0 {
+			return nil, newTosClientError("tos: no role is attached to the ECS instance", nil)
+		}
+	}
+	data, err := ep.get(ctx, ep.endpoint+ecsCredentialsPath+roleName)
+	if err != nil {
+		return nil, err
+	}
+	var cred ecsCredential
+	if err = json.Unmarshal(data, &cred); err != nil {
+		return nil, newTosClientError("tos: invalid ECS security credentials", err)
+	}
+	if len(cred.AccessKeyID) == 0 || len(cred.SecretAccessKey) == 0 {
+		return nil, newTosClientError("tos: empty ECS security credentials of role "+roleName, nil)
+	}
+	return &FederationToken{
+		Credential: Credential{
+			AccessKeyID:     cred.AccessKeyID,
+			AccessKeySecret: cred.SecretAccessKey,
+			SecurityToken:   cred.SessionToken,
+		},
+		Expiration: cred.ExpiredTime,
+	}, nil
+}
+
+// fetchMetadataToken fetches metadata token if it's not fetched or will expire in a minute,
+// no token is used if the metadata service doesn't support it.
+func (ep *ECSCredentialsProvider) fetchMetadataToken(ctx context.Context) error {
+	if len(ep.token) > 0 && time.Now().Add(time.Minute).Before(ep.tokenExpiration) {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodPut, ep.endpoint+ecsTokenPath, nil)
+	if err != nil {
+		return newTosClientError("tos: invalid ECS metadata endpoint", err)
+	}
+	req.Header.Set(headerMetadataTokenTTL, strconv.Itoa(int(ecsTokenTTL/time.Second)))
+	expiration := time.Now().Add(ecsTokenTTL)
+	res, err := ep.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return newTosClientError("tos: request ECS metadata service failed", err)
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return newTosClientError("tos: read ECS metadata token failed", err)
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+		ep.token = strings.TrimSpace(string(data))
+		ep.tokenExpiration = expiration
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		ep.token = ""
+	default:
+		return newTosClientError("tos: unexpected status "+res.Status+" of ECS metadata token", nil)
+	}
+	return nil
+}
+
+func (ep *ECSCredentialsProvider) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, newTosClientError("tos: invalid ECS metadata endpoint", err)
+	}
+	if len(ep.token) > 0 {
+		req.Header.Set(headerMetadataToken, ep.token)
+	}
+	res, err := ep.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, newTosClientError("tos: request ECS metadata service failed", err)
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, newTosClientError("tos: read ECS metadata failed", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		if res.StatusCode == http.StatusUnauthorized {
+			// token is revoked or expired
+			ep.token = ""
+		}
+		return nil, newTosClientError("tos: unexpected status "+res.Status+" of ECS metadata service", nil)
+	}
+	return data, nil
+}
+
+// FederationCredentialsProvider implements CredentialsProvider with FederationToken returned by fetch.
+// The token is cached and refreshed in the background refresh window before it expires, the cached token
+// is used while refreshing, and failed refreshes are retried with backoff until the token expires.
+// Requests fail only if there is no token not expired and fetch fails.
+//
+// use WithRefreshWindow set refresh window, WithRetryBackoff set backoff of retries,
+// use WithErrorHook set hook of background refresh errors
+type FederationCredentialsProvider struct {
+	fetch         func(ctx context.Context) (*FederationToken, error)
+	refreshWindow time.Duration
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+	onError       func(err error) // nullable
+	flight        singleflight.Group
+	mu            sync.Mutex
+	cached        *FederationToken
+	refreshing    bool
+	// stale is true if the cached token was already in the refresh window when it was fetched,
+	// so that it's not refreshed in the background again and again until it expires
+	stale bool
+}
+
+// NewFederationCredentialsProvider create FederationCredentialsProvider, fetch is called when the first request
+// is sent, and refresh window is 5 minutes by default.
+func NewFederationCredentialsProvider(fetch func(ctx context.Context) (*FederationToken, error)) *FederationCredentialsProvider {
+	return &FederationCredentialsProvider{
+		fetch:         fetch,
+		refreshWindow: 5 * time.Minute,
+		minBackoff:    time.Second,
+		maxBackoff:    30 * time.Second,
+	}
+}
+
+// WithRefreshWindow set how long before expiration the token is refreshed
+func (fp *FederationCredentialsProvider) WithRefreshWindow(refreshWindow time.Duration) {
+	fp.refreshWindow = refreshWindow
+}
+
+// WithRetryBackoff set backoff of retries of failed refreshes, it's doubled after each retry up to max.
+// min is 1s if it's not positive, and max is min if it's less than min.
+func (fp *FederationCredentialsProvider) WithRetryBackoff(min, max time.Duration) {
+	if min <= 0 {
+		min = time.Second
+	}
+	if max < min {
+		max = min
+	}
+	fp.minBackoff = min
+	fp.maxBackoff = max
+}
+
+// WithErrorHook set hook called with errors of background refreshes, which are not returned by requests
+func (fp *FederationCredentialsProvider) WithErrorHook(onError func(err error)) {
+	fp.onError = onError
+}
+
+// Expiration returns expiration of the cached token, it's zero if no token is fetched
+func (fp *FederationCredentialsProvider) Expiration() time.Time {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	if fp.cached == nil {
+		return time.Time{}
+	}
+	return fp.cached.Expiration
+}
+
+func (fp *FederationCredentialsProvider) Credentials(ctx context.Context) (Credential, error) {
+	now := time.Now()
+	fp.mu.Lock()
+	token := fp.cached
+	if token != nil && now.Before(token.Expiration) {
+		if !fp.refreshing && !fp.stale && now.Add(fp.refreshWindow).After(token.Expiration) {
+			fp.refreshing = true
+			go fp.refresh(token.Expiration)
+		}
+		fp.mu.Unlock()
+		return token.Credential, nil
+	}
+	fp.mu.Unlock()
+
+	// no token or expired, concurrent requests wait for the same fetch
+	flushed, err, _ := fp.flight.Do("fetch", func() (interface{}, error) {
+		token, err := fp.fetchToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		fp.store(token)
+		return token, nil
+	})
+	if err != nil {
+		if _, ok := err.(*TosClientError); ok {
+			return Credential{}, err
+		}
+		return Credential{}, newTosClientError("tos: fetch FederationToken failed", err)
+	}
+	return flushed.(*FederationToken).Credential, nil
+}
+
+func (fp *FederationCredentialsProvider) fetchToken(ctx context.Context) (*FederationToken, error) {
+	token, err := fp.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, newTosClientError("tos: FederationToken is nil", nil)
+	}
+	return token, nil
+}
+
+func (fp *FederationCredentialsProvider) store(token *FederationToken) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	fp.cached = token
+	fp.stale = time.Now().Add(fp.refreshWindow).After(token.Expiration)
+}
+
+// refresh fetches token in the background, and retries with backoff until it succeeds or expiration passes
+func (fp *FederationCredentialsProvider) refresh(expiration time.Time) {
+	defer func() {
+		fp.mu.Lock()
+		fp.refreshing = false
+		fp.mu.Unlock()
+	}()
+	backoff := fp.minBackoff
+	for {
+		token, err := fp.fetchToken(context.Background())
+		if err == nil {
+			fp.store(token)
+			return
+		}
+		if fp.onError != nil {
+			fp.onError(err)
+		}
+		if !time.Now().Add(backoff).Before(expiration) {
+			return
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > fp.maxBackoff {
+			backoff = fp.maxBackoff
+		}
+	}
+}
+
+// credentialsChainRetryInterval is the interval during which the failure of all providers is returned
+// without trying them again, so that e.g. the ECS provider doesn't time out for each request outside ECS
+const credentialsChainRetryInterval = 5 * time.Second
+
+// CredentialsChain provides Credential from the first provider that succeeds,
+// and the provider is tried first for later requests until it fails.
+// If all providers fail, the failure is returned for a short interval before they are tried again.
+type CredentialsChain struct {
+	providers []CredentialsProvider
+	current   int32 // index of the provider succeeded last time
+	mu        sync.Mutex
+	failure   error     // failure of all providers, nil if any of them succeeded last time
+	retryAt   time.Time // providers are not tried before it if failure is not nil
+}
+
+// NewCredentialsChain create CredentialsChain trying providers in order
+func NewCredentialsChain(providers ...CredentialsProvider) *CredentialsChain {
+	return &CredentialsChain{providers: providers}
+}
+
+// NewDefaultCredentialsChain create CredentialsChain trying in order static credentials if it's not nil,
+// environment variables, the shared credentials file and the role of the ECS instance.
+func NewDefaultCredentialsChain(static *StaticCredentials) *CredentialsChain {
+	providers := make([]CredentialsProvider, 0, 4)
+	if static != nil {
+		providers = append(providers, static)
+	}
+	providers = append(providers, EnvCredentialsProvider{}, NewSharedCredentialsProvider("", ""), NewECSCredentialsProvider(""))
+	return NewCredentialsChain(providers...)
+}
+
+func (cc *CredentialsChain) Credentials(ctx context.Context) (Credential, error) {
+	cc.mu.Lock()
+	if cc.failure != nil && time.Now().Before(cc.retryAt) {
+		err := cc.failure
+		cc.mu.Unlock()
+		return Credential{}, err
+	}
+	cc.mu.Unlock()
+	cred, err := cc.tryProviders(ctx)
+	if err != nil && ctx.Err() != nil {
+		// failures caused by the canceled request are not cached
+		return Credential{}, err
+	}
+	cc.mu.Lock()
+	cc.failure = err
+	cc.retryAt = time.Now().Add(credentialsChainRetryInterval)
+	cc.mu.Unlock()
+	return cred, err
+}
+
+func (cc *CredentialsChain) tryProviders(ctx context.Context) (Credential, error) {
+	current := int(atomic.LoadInt32(&cc.current))
+	errs := make([]error, len(cc.providers))
+	if current < len(cc.providers) {
+		cred, err := cc.providers[current].Credentials(ctx)
+		if err == nil {
+			return cred, nil
+		}
+		errs[current] = err
+	}
+	for i, provider := range cc.providers {
+		if errs[i] != nil {
+			continue
+		}
+		cred, err := provider.Credentials(ctx)
+		if err == nil {
+			atomic.StoreInt32(&cc.current, int32(i))
+			return cred, nil
+		}
+		errs[i] = err
+	}
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return Credential{}, newTosClientError("tos: no valid credentials in chain: "+strings.Join(messages, "; "), nil)
+}
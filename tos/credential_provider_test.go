@@ -0,0 +1,313 @@
+package tos
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedCredentialsProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tos-credentials")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "credentials")
+	content := "[default]\naccess_key = ak\nsecret_key = sk\n\n# comment\n[dev]\naccess_key=dev-ak\nsecret_key=dev-sk\nsecurity_token=dev-sts\n"
+	require.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	cred, err := NewSharedCredentialsProvider(path, "").Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, Credential{AccessKeyID: "ak", AccessKeySecret: "sk"}, cred)
+
+	provider := NewSharedCredentialsProvider(path, "dev")
+	cred, err = provider.Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, Credential{AccessKeyID: "dev-ak", AccessKeySecret: "dev-sk", SecurityToken: "dev-sts"}, cred)
+
+	// rotated by rewriting the file
+	require.Nil(t, ioutil.WriteFile(path, []byte("[dev]\naccess_key=new-ak\nsecret_key=new-sk\n"), 0600))
+	require.Nil(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	cred, err = provider.Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "new-ak", cred.AccessKeyID)
+
+	_, err = NewSharedCredentialsProvider(path, "missing").Credentials(context.Background())
+	require.NotNil(t, err)
+	_, err = NewSharedCredentialsProvider(filepath.Join(dir, "missing"), "").Credentials(context.Background())
+	require.NotNil(t, err)
+}
+
+func TestECSCredentialsProvider(t *testing.T) {
+	var (
+		requests      int32
+		tokenRequests int32
+		noToken       int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ecsTokenPath {
+			if atomic.LoadInt32(&noToken) == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			atomic.AddInt32(&tokenRequests, 1)
+			if r.Method != http.MethodPut || r.Header.Get(headerMetadataTokenTTL) == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, "metadata-token")
+			return
+		}
+		if atomic.LoadInt32(&noToken) == 0 && r.Header.Get(headerMetadataToken) != "metadata-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		count := atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case ecsCredentialsPath:
+			fmt.Fprint(w, "role")
+		case ecsCredentialsPath + "role":
+			fmt.Fprintf(w, `{"AccessKeyId":"ak-%d","SecretAccessKey":"sk","SessionToken":"sts","ExpiredTime":%q}`,
+				count, time.Now().Add(time.Hour).Format(time.RFC3339))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewECSCredentialsProvider("")
+	provider.WithEndpoint(server.URL + "/")
+	cred, err := provider.Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, Credential{AccessKeyID: "ak-2", AccessKeySecret: "sk", SecurityToken: "sts"}, cred)
+
+	// cached until prefetch time before expiration
+	cred, err = provider.Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "ak-2", cred.AccessKeyID)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	provider.WithPreFetch(2 * time.Hour)
+	cred, err = provider.Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "ak-4", cred.AccessKeyID)
+	require.Equal(t, int32(1), atomic.LoadInt32(&tokenRequests))
+
+	// metadata service without token support
+	atomic.StoreInt32(&noToken, 1)
+	provider = NewECSCredentialsProvider("role")
+	provider.WithEndpoint(server.URL)
+	cred, err = provider.Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "ak-5", cred.AccessKeyID)
+}
+
+func TestECSCredentialsProviderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	provider := NewECSCredentialsProvider("")
+	provider.WithEndpoint(server.URL)
+	provider.WithTimeout(50 * time.Millisecond)
+	chain := NewCredentialsChain(provider, NewStaticCredentials("ak", "sk"))
+	start := time.Now()
+	cred, err := chain.Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "ak", cred.AccessKeyID)
+	require.True(t, time.Since(start) < 250*time.Millisecond)
+}
+
+func TestCredentialsChain(t *testing.T) {
+	os.Setenv(EnvAccessKey, "env-ak")
+	os.Setenv(EnvSecretKey, "env-sk")
+	defer os.Unsetenv(EnvAccessKey)
+	defer os.Unsetenv(EnvSecretKey)
+
+	chain := NewDefaultCredentialsChain(NewStaticCredentials("", ""))
+	cred, err := chain.Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, Credential{AccessKeyID: "env-ak", AccessKeySecret: "env-sk"}, cred)
+
+	chain = NewDefaultCredentialsChain(NewStaticCredentials("ak", "sk"))
+	cred, err = chain.Credentials(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "ak", cred.AccessKeyID)
+
+	chain = NewCredentialsChain(NewStaticCredentials("", ""), EnvCredentialsProvider{})
+	os.Unsetenv(EnvAccessKey)
+	_, err = chain.Credentials(context.Background())
+	require.NotNil(t, err)
+	require.True(t, strings.Contains(err.Error(), EnvAccessKey))
+}
+
+func TestFederationCredentialsProviderStaleToken(t *testing.T) {
+	var fetched int32
+	provider := NewFederationCredentialsProvider(func(ctx context.Context) (*FederationToken, error) {
+		count := atomic.AddInt32(&fetched, 1)
+		return &FederationToken{
+			Credential: Credential{AccessKeyID: fmt.Sprintf("ak-%d", count), AccessKeySecret: "sk"},
+			Expiration: time.Now().Add(200 * time.Millisecond),
+		}, nil
+	})
+	provider.WithRefreshWindow(time.Second)
+	provider.WithRetryBackoff(0, 0)
+	require.Equal(t, time.Second, provider.minBackoff)
+	require.Equal(t, time.Second, provider.maxBackoff)
+	ctx := context.Background()
+
+	// the token fetched inside the refresh window is not refreshed in the background
+	for i := 0; i < 10; i++ {
+		cred, err := provider.Credentials(ctx)
+		require.Nil(t, err)
+		require.Equal(t, "ak-1", cred.AccessKeyID)
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetched))
+
+	time.Sleep(200 * time.Millisecond)
+	cred, err := provider.Credentials(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "ak-2", cred.AccessKeyID)
+}
+
+type failingProvider struct {
+	calls int32
+}
+
+func (fp *failingProvider) Credentials(ctx context.Context) (Credential, error) {
+	atomic.AddInt32(&fp.calls, 1)
+	return Credential{}, newTosClientError("tos: unavailable", nil)
+}
+
+func TestCredentialsChainFailureCached(t *testing.T) {
+	provider := &failingProvider{}
+	chain := NewCredentialsChain(provider)
+	_, err := chain.Credentials(context.Background())
+	require.NotNil(t, err)
+	_, cachedErr := chain.Credentials(context.Background())
+	require.Equal(t, err, cachedErr)
+	require.Equal(t, int32(1), atomic.LoadInt32(&provider.calls))
+
+	// providers are tried again after the interval
+	chain.retryAt = time.Now().Add(-time.Second)
+	_, err = chain.Credentials(context.Background())
+	require.NotNil(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&provider.calls))
+
+	// failures of canceled requests are not cached
+	chain = NewCredentialsChain(provider)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = chain.Credentials(ctx)
+	require.NotNil(t, err)
+	_, err = chain.Credentials(context.Background())
+	require.NotNil(t, err)
+	require.Equal(t, int32(4), atomic.LoadInt32(&provider.calls))
+}
+
+type rotatingProvider struct {
+	count int
+	err   error
+}
+
+func (rp *rotatingProvider) Credentials(ctx context.Context) (Credential, error) {
+	if rp.err != nil {
+		return Credential{}, rp.err
+	}
+	rp.count++
+	return Credential{AccessKeyID: fmt.Sprintf("ak-%d", rp.count), AccessKeySecret: "sk"}, nil
+}
+
+func TestWithCredentialsProvider(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, "")()
+		res.Body = nil
+		return res
+	}}
+	provider := &rotatingProvider{}
+	client, err := NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithCredentialsProvider(provider), WithTransport(transport))
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	_, err = client.HeadBucket(ctx, &HeadBucketInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	_, err = client.HeadBucket(ctx, &HeadBucketInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Contains(t, transport.recorded()[0].Header.Get("Authorization"), "Credential=ak-1/")
+	require.Contains(t, transport.recorded()[1].Header.Get("Authorization"), "Credential=ak-2/")
+
+	provider.err = fmt.Errorf("expired")
+	_, err = client.HeadBucket(ctx, &HeadBucketInput{Bucket: "bucket"})
+	_, ok := err.(*TosClientError)
+	require.True(t, ok)
+	require.Equal(t, 2, len(transport.recorded()))
+}
+
+func TestFederationCredentialsProvider(t *testing.T) {
+	var (
+		fetched int32
+		failing int32
+		hookErr int32
+	)
+	provider := NewFederationCredentialsProvider(func(ctx context.Context) (*FederationToken, error) {
+		if atomic.LoadInt32(&failing) == 1 {
+			return nil, fmt.Errorf("sts unavailable")
+		}
+		count := atomic.AddInt32(&fetched, 1)
+		return &FederationToken{
+			Credential: Credential{AccessKeyID: fmt.Sprintf("ak-%d", count), AccessKeySecret: "sk", SecurityToken: "sts"},
+			Expiration: time.Now().Add(500 * time.Millisecond),
+		}, nil
+	})
+	provider.WithRefreshWindow(400 * time.Millisecond)
+	provider.WithRetryBackoff(10*time.Millisecond, 50*time.Millisecond)
+	provider.WithErrorHook(func(err error) {
+		atomic.AddInt32(&hookErr, 1)
+	})
+	ctx := context.Background()
+
+	cred, err := provider.Credentials(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "ak-1", cred.AccessKeyID)
+
+	// the cached token is used while refreshing in the background
+	time.Sleep(150 * time.Millisecond)
+	cred, err = provider.Credentials(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "ak-1", cred.AccessKeyID)
+	require.Eventually(t, func() bool {
+		cred, err = provider.Credentials(ctx)
+		return err == nil && cred.AccessKeyID == "ak-2"
+	}, time.Second, 10*time.Millisecond)
+
+	// refresh failures are reported by the hook, and the token is used until it expires
+	atomic.StoreInt32(&failing, 1)
+	time.Sleep(150 * time.Millisecond)
+	cred, err = provider.Credentials(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "ak-2", cred.AccessKeyID)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&hookErr) > 1
+	}, time.Second, 10*time.Millisecond)
+
+	time.Sleep(500 * time.Millisecond)
+	_, err = provider.Credentials(ctx)
+	_, ok := err.(*TosClientError)
+	require.True(t, ok)
+
+	atomic.StoreInt32(&failing, 0)
+	cred, err = provider.Credentials(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "ak-3", cred.AccessKeyID)
+}
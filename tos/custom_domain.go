@@ -0,0 +1,79 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+)
+
+// PutBucketCustomDomain bind custom domain to bucket, the domain must be CNAME-ed to the bucket domain
+// before it can serve requests.
+func (cli *ClientV2) PutBucketCustomDomain(ctx context.Context, input *PutBucketCustomDomainInput) (*PutBucketCustomDomainOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if err := isValidDomain(input.Rule.Domain); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("PutBucketCustomDomainInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("customdomain", "").
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketCustomDomainOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// ListBucketCustomDomain list custom domains bound to bucket
+func (cli *ClientV2) ListBucketCustomDomain(ctx context.Context, input *ListBucketCustomDomainInput) (*ListBucketCustomDomainOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("customdomain", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := ListBucketCustomDomainOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// DeleteBucketCustomDomain unbind custom domain from bucket,
+// *CustomDomainNotFoundError is returned if the domain is not bound to the bucket.
+func (cli *ClientV2) DeleteBucketCustomDomain(ctx context.Context, input *DeleteBucketCustomDomainInput) (*DeleteBucketCustomDomainOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if err := isValidDomain(input.Domain); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("customdomain", input.Domain).
+		Request(ctx, http.MethodDelete, nil, cli.roundTripper(http.StatusNoContent))
+	if err != nil {
+		return nil, newCustomDomainNotFoundError(err, input.Domain)
+	}
+	defer res.Close()
+	return &DeleteBucketCustomDomainOutput{RequestInfo: res.RequestInfo()}, nil
+}
@@ -0,0 +1,55 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketCustomDomain(t *testing.T) {
+	transport := &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, `{"CustomDomainRules":[{"Domain":"static.example.com","CertId":"cert-id",
+			"Cname":"bucket.tos-cn-beijing.volces.com","Forbidden":true,"ForbiddenReason":"not filed","CertStatus":"expired",
+			"CertExpiration":"2030-01-02T03:04:05Z"}]}`),
+		newMockResponse(http.StatusNoContent, ""),
+		newMockResponse(http.StatusNotFound, `{"Code":"NoSuchCustomDomain"}`),
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.PutBucketCustomDomain(ctx, &PutBucketCustomDomainInput{Bucket: "bucket",
+		Rule: CustomDomainRule{Domain: "static.example.com", CertID: "cert-id"}})
+	require.Nil(t, err)
+	require.JSONEq(t, `{"CustomDomainRule":{"Domain":"static.example.com","CertId":"cert-id"}}`, requestBody(t, transport.recorded()[0]))
+
+	list, err := client.ListBucketCustomDomain(ctx, &ListBucketCustomDomainInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(list.Rules))
+	rule := list.Rules[0]
+	require.Equal(t, CustomDomainRule{Domain: "static.example.com", CertID: "cert-id"}, rule.CustomDomainRule)
+	require.Equal(t, "bucket.tos-cn-beijing.volces.com", rule.Cname)
+	require.True(t, rule.Forbidden)
+	require.Equal(t, "not filed", rule.ForbiddenReason)
+	require.True(t, time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC).Equal(rule.CertExpiration))
+
+	_, err = client.DeleteBucketCustomDomain(ctx, &DeleteBucketCustomDomainInput{Bucket: "bucket", Domain: "static.example.com"})
+	require.Nil(t, err)
+	require.Equal(t, "static.example.com", transport.recorded()[2].Query.Get("customdomain"))
+	_, err = client.DeleteBucketCustomDomain(ctx, &DeleteBucketCustomDomainInput{Bucket: "bucket", Domain: "other.example.com"})
+	notFound, ok := err.(*CustomDomainNotFoundError)
+	require.True(t, ok)
+	require.Equal(t, "other.example.com", notFound.Domain)
+	require.Equal(t, http.StatusNotFound, StatusCode(err))
+	require.Equal(t, "NoSuchCustomDomain", Code(err))
+
+	requests := len(transport.recorded())
+	_, err = client.PutBucketCustomDomain(ctx, &PutBucketCustomDomainInput{Bucket: "bucket", Rule: CustomDomainRule{Domain: "http://example.com"}})
+	require.NotNil(t, err)
+	_, err = client.DeleteBucketCustomDomain(ctx, &DeleteBucketCustomDomainInput{Bucket: "bucket"})
+	require.NotNil(t, err)
+	require.Equal(t, requests, len(transport.recorded()))
+}
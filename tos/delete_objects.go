@@ -0,0 +1,215 @@
+package tos
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// codes of DeleteError that the object can be deleted by retrying
+var retriableDeleteCodes = map[string]bool{
+	"InternalError":      true,
+	"SlowDown":           true,
+	"ServiceUnavailable": true,
+	"ExceedQPSLimit":     true,
+}
+
+// DeleteObjects deletes any number of objects by DeleteMultiObjects, MaxDeleteObjectsCount objects per request,
+// and at most TaskNum requests are sent in parallel.
+// Objects failed with retriable codes are retried up to MaxRetryCount times, others failed are returned in Failed of output.
+// If ctx is canceled, no more request is sent, and ctx.Err() is returned along with objects handled before that,
+// objects taken from Objects or ObjectsChan but not sent are returned in Failed with the error of ctx.
+func (cli *ClientV2) DeleteObjects(ctx context.Context, input *DeleteObjectsInput) (*DeleteObjectsOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	taskNum := input.TaskNum
+	if taskNum <= 0 {
+		taskNum = DefaultDeleteTaskNum
+	}
+	maxRetryCount := input.MaxRetryCount
+	if maxRetryCount <= 0 {
+		maxRetryCount = DefaultDeleteMaxRetryCount
+	}
+	var (
+		output   DeleteObjectsOutput
+		lock     sync.Mutex
+		wg       sync.WaitGroup
+		inflight = make(chan struct{}, taskNum)
+		index    = 0
+	)
+	// next returns the next object to delete, it blocks for objects from ObjectsChan only if block is set
+	next := func(block bool) (ObjectTobeDeleted, bool) {
+		if index < len(input.Objects) {
+			index++
+			return input.Objects[index-1], true
+		}
+		if input.ObjectsChan == nil {
+			return ObjectTobeDeleted{}, false
+		}
+		if block {
+			select {
+			case object, ok := <-input.ObjectsChan:
+				return object, ok
+			case <-ctx.Done():
+				return ObjectTobeDeleted{}, false
+			}
+		}
+		select {
+		case object, ok := <-input.ObjectsChan:
+			return object, ok
+		default:
+			return ObjectTobeDeleted{}, false
+		}
+	}
+	for ctx.Err() == nil {
+		// don't wait for a full batch, so that objects from a slow channel are deleted in time
+		object, ok := next(true)
+		if !ok {
+			break
+		}
+		batch := []ObjectTobeDeleted{object}
+		for len(batch) < MaxDeleteObjectsCount {
+			if object, ok = next(false); !ok {
+				break
+			}
+			batch = append(batch, object)
+		}
+		select {
+		case inflight <- struct{}{}:
+		case <-ctx.Done():
+			// objects already taken are reported as failed, so that callers can tell them from those deleted
+			lock.Lock()
+			for _, object := range batch {
+				output.Failed = append(output.Failed, DeleteError{Key: object.Key, VersionID: object.VersionID, Message: ctx.Err().Error()})
+			}
+			lock.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(batch []ObjectTobeDeleted) {
+			defer func() {
+				<-inflight
+				wg.Done()
+			}()
+			deleted, failed := cli.deleteObjectsBatch(ctx, input.Bucket, batch, maxRetryCount)
+			lock.Lock()
+			defer lock.Unlock()
+			output.DeletedCount += deleted
+			output.Failed = append(output.Failed, failed...)
+		}(batch)
+	}
+	wg.Wait()
+	return &output, ctx.Err()
+}
+
+// deleteObjectsBatch deletes a batch of objects, and retries objects failed with retriable codes
+func (cli *ClientV2) deleteObjectsBatch(ctx context.Context, bucket string, objects []ObjectTobeDeleted, maxRetryCount int) (deleted int64, failed []DeleteError) {
+	for retry := 0; ; retry++ {
+		output, err := cli.DeleteMultiObjects(ctx, &DeleteMultiObjectsInput{Bucket: bucket, Objects: objects, Quiet: true})
+		if err != nil {
+			for _, object := range objects {
+				failed = append(failed, DeleteError{Key: object.Key, VersionID: object.VersionID, Message: err.Error()})
+			}
+			return
+		}
+		deleted += int64(len(objects) - len(output.Error))
+		objects = objects[:0]
+		for _, deleteErr := range output.Error {
+			if retry < maxRetryCount && retriableDeleteCodes[deleteErr.Code] {
+				objects = append(objects, ObjectTobeDeleted{Key: deleteErr.Key, VersionID: deleteErr.VersionID})
+				continue
+			}
+			failed = append(failed, deleteErr)
+		}
+		if len(objects) == 0 {
+			return
+		}
+		select {
+		case <-time.After(time.Duration(retry+1) * 100 * time.Millisecond):
+		case <-ctx.Done():
+			for _, object := range objects {
+				failed = append(failed, DeleteError{Key: object.Key, VersionID: object.VersionID, Message: ctx.Err().Error()})
+			}
+			return
+		}
+	}
+}
+
+// DeleteObjectAllVersions deletes all versions and delete markers of key in a versioned bucket,
+// versions are listed with key as prefix and only those of exactly the key are deleted,
+// so other keys starting with key are not touched.
+// Versions failed to delete are returned in Failed of output, and listing error is returned along with versions handled before that.
+func (cli *ClientV2) DeleteObjectAllVersions(ctx context.Context, bucket, key string) (*DeleteObjectAllVersionsOutput, error) {
+	if err := isValidNames(bucket, key); err != nil {
+		return nil, err
+	}
+	var (
+		output  DeleteObjectAllVersionsOutput
+		batch   []ObjectTobeDeleted
+		markers = make(map[string]bool)
+	)
+	flush := func() {
+		deleted, failed := cli.deleteObjectsBatch(ctx, bucket, batch, DefaultDeleteMaxRetryCount)
+		failedMarkers := 0
+		for _, deleteErr := range failed {
+			if markers[deleteErr.VersionID] {
+				failedMarkers++
+			}
+		}
+		deletedMarkers := int64(len(markers) - failedMarkers)
+		output.DeletedDeleteMarkerCount += deletedMarkers
+		output.DeletedVersionCount += deleted - deletedMarkers
+		output.Failed = append(output.Failed, failed...)
+		batch = batch[:0]
+		markers = make(map[string]bool)
+	}
+	add := func(versionID string, isMarker bool) {
+		batch = append(batch, ObjectTobeDeleted{Key: key, VersionID: versionID})
+		if isMarker {
+			markers[versionID] = true
+		}
+		if len(batch) == MaxDeleteObjectsCount {
+			flush()
+		}
+	}
+	paginator := cli.NewListObjectVersionsPaginator(&ListObjectVersionsV2Input{
+		Bucket:                  bucket,
+		ListObjectVersionsInput: ListObjectVersionsInput{Prefix: key},
+	})
+	for paginator.HasNext() {
+		page, err := paginator.Next(ctx)
+		if err != nil {
+			if len(batch) > 0 {
+				flush()
+			}
+			return &output, err
+		}
+		// keys are listed in order, and versions of key come before keys starting with key
+		done := false
+		for _, version := range page.Versions {
+			if version.Key != key {
+				done = true
+				break
+			}
+			add(version.VersionID, false)
+		}
+		for _, marker := range page.DeleteMarkers {
+			if marker.Key != key {
+				done = true
+				break
+			}
+			add(marker.VersionID, true)
+		}
+		if done {
+			break
+		}
+	}
+	if len(batch) > 0 {
+		flush()
+	}
+	return &output, nil
+}
@@ -0,0 +1,176 @@
+package tos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteObjects(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		requests int
+		retried  = make(map[string]bool)
+	)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		data, _ := ioutil.ReadAll(req.Content)
+		var input deleteMultiObjectsInput
+		require.Nil(t, json.Unmarshal(data, &input))
+		require.True(t, len(input.Objects) <= MaxDeleteObjectsCount)
+		require.True(t, input.Quiet)
+		lock.Lock()
+		defer lock.Unlock()
+		requests++
+		var errors []DeleteError
+		for _, object := range input.Objects {
+			switch {
+			case object.Key == "key-7":
+				errors = append(errors, DeleteError{Key: object.Key, Code: "AccessDenied"})
+			case object.Key == "key-8":
+				errors = append(errors, DeleteError{Key: object.Key, Code: "InternalError"})
+			case strings.HasSuffix(object.Key, "9") && !retried[object.Key]:
+				retried[object.Key] = true
+				errors = append(errors, DeleteError{Key: object.Key, Code: "SlowDown"})
+			}
+		}
+		body, _ := json.Marshal(DeleteMultiObjectsOutput{Error: errors})
+		return newMockResponse(http.StatusOK, string(body))()
+	}}
+	client := newMockClient(t, transport)
+
+	objects := make([]ObjectTobeDeleted, 2500)
+	for i := range objects {
+		objects[i].Key = fmt.Sprintf("key-%d", i)
+	}
+	output, err := client.DeleteObjects(context.Background(), &DeleteObjectsInput{
+		Bucket:        "bucket",
+		Objects:       objects,
+		TaskNum:       2,
+		MaxRetryCount: 1,
+	})
+	require.Nil(t, err)
+	require.Equal(t, int64(2498), output.DeletedCount)
+	require.Len(t, output.Failed, 2)
+	codes := map[string]string{}
+	for _, failed := range output.Failed {
+		codes[failed.Key] = failed.Code
+	}
+	require.Equal(t, map[string]string{"key-7": "AccessDenied", "key-8": "InternalError"}, codes)
+	// 3 batches, and each batch retried once
+	require.Equal(t, 6, requests)
+
+	// objects from channel
+	requests = 0
+	ch := make(chan ObjectTobeDeleted)
+	go func() {
+		for i := 0; i < 3; i++ {
+			ch <- ObjectTobeDeleted{Key: fmt.Sprintf("chan-%d", i)}
+		}
+		close(ch)
+	}()
+	output, err = client.DeleteObjects(context.Background(), &DeleteObjectsInput{
+		Bucket:      "bucket",
+		Objects:     objects[:1],
+		ObjectsChan: ch,
+	})
+	require.Nil(t, err)
+	require.Equal(t, int64(4), output.DeletedCount)
+	require.Empty(t, output.Failed)
+
+	// no more request after canceled
+	requests = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	output, err = client.DeleteObjects(ctx, &DeleteObjectsInput{Bucket: "bucket", Objects: objects})
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, int64(0), output.DeletedCount)
+	require.Equal(t, 0, requests)
+}
+
+func TestDeleteObjectsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		close(started)
+		<-release
+		return newMockResponse(http.StatusOK, `{}`)()
+	}}
+	client := newMockClient(t, transport)
+	objects := make([]ObjectTobeDeleted, 2*MaxDeleteObjectsCount+1)
+	for i := range objects {
+		objects[i].Key = fmt.Sprintf("key-%d", i)
+	}
+	go func() {
+		<-started
+		// the second batch is taken and waits for the first one
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		close(release)
+	}()
+	output, err := client.DeleteObjects(ctx, &DeleteObjectsInput{Bucket: "bucket", Objects: objects, TaskNum: 1})
+	require.Equal(t, context.Canceled, err)
+	// the second batch is reported as failed, and the rest is not taken
+	failed := make(map[string]string)
+	for _, deleteErr := range output.Failed {
+		failed[deleteErr.Key] = deleteErr.Message
+	}
+	for _, object := range objects[MaxDeleteObjectsCount : 2*MaxDeleteObjectsCount] {
+		require.Equal(t, context.Canceled.Error(), failed[object.Key])
+	}
+	require.NotContains(t, failed, objects[2*MaxDeleteObjectsCount].Key)
+}
+
+func TestDeleteObjectAllVersions(t *testing.T) {
+	pages := map[string]string{
+		"|": `{"IsTruncated":true,"NextKeyMarker":"key","NextVersionIdMarker":"v3",
+			"Versions":[{"Key":"key","VersionId":"v1"},{"Key":"key","VersionId":"v2"}],
+			"DeleteMarkers":[{"Key":"key","VersionId":"v3"}]}`,
+		"key|v3": `{"IsTruncated":true,"NextKeyMarker":"key/other","NextVersionIdMarker":"v6",
+			"Versions":[{"Key":"key","VersionId":"v4"},{"Key":"key/other","VersionId":"v6"}],
+			"DeleteMarkers":[{"Key":"key","VersionId":"v5"},{"Key":"key0","VersionId":"v7"}]}`,
+	}
+	var deleted []ObjectTobeDeleted
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodGet {
+			require.Equal(t, "key", req.Query.Get("prefix"))
+			return newMockResponse(http.StatusOK, pages[req.Query.Get("key-marker")+"|"+req.Query.Get("version-id-marker")])()
+		}
+		data, _ := ioutil.ReadAll(req.Content)
+		var input deleteMultiObjectsInput
+		require.Nil(t, json.Unmarshal(data, &input))
+		var errors []DeleteError
+		for _, object := range input.Objects {
+			if object.VersionID == "v2" {
+				errors = append(errors, DeleteError{Key: object.Key, VersionID: object.VersionID, Code: "AccessDenied"})
+				continue
+			}
+			deleted = append(deleted, object)
+		}
+		body, _ := json.Marshal(DeleteMultiObjectsOutput{Error: errors})
+		return newMockResponse(http.StatusOK, string(body))()
+	}}
+	client := newMockClient(t, transport)
+
+	output, err := client.DeleteObjectAllVersions(context.Background(), "bucket", "key")
+	require.Nil(t, err)
+	require.Equal(t, int64(2), output.DeletedVersionCount)
+	require.Equal(t, int64(2), output.DeletedDeleteMarkerCount)
+	require.Equal(t, 1, len(output.Failed))
+	require.Equal(t, "v2", output.Failed[0].VersionID)
+	// keys sharing the prefix are not deleted, and listing stops after them
+	for _, object := range deleted {
+		require.Equal(t, "key", object.Key)
+	}
+	require.Equal(t, 4, len(deleted))
+	require.Equal(t, 3, len(transport.recorded()))
+}
@@ -0,0 +1,165 @@
+package tos
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultDNSCacheTTL = 15 * time.Minute
+
+	// dnsRefreshTimeout limits each asynchronous refresh of an entry
+	dnsRefreshTimeout = 5 * time.Second
+	// dnsRefreshRetryInterval is the minimum interval between failed refreshes of an entry
+	dnsRefreshRetryInterval = 5 * time.Second
+	// dnsMaxDialFailures is the number of consecutive dial failures after which an IP is evicted
+	dnsMaxDialFailures = 3
+)
+
+type dnsEntry struct {
+	ips        []string
+	failures   map[string]int // consecutive dial failures of each IP
+	next       int            // index of the IP dialed first, so that IPs are used in turn
+	expiration time.Time
+	retryAt    time.Time // refreshes are not started before it if the last refresh failed
+	refreshing bool
+}
+
+// dnsCache resolves each host once per TTL for dialing. Entries are refreshed asynchronously before they expire,
+// and stale entries are used if refreshes fail. IPs are dialed in turn, and evicted if dialing them fails
+// dnsMaxDialFailures times in a row, so that the host is resolved again once all of its IPs are evicted.
+type dnsCache struct {
+	ttl     time.Duration
+	lookup  func(ctx context.Context, host string) ([]string, error)
+	lock    sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+func newDNSCache(ttl time.Duration) *dnsCache {
+	if ttl <= 0 {
+		ttl = DefaultDNSCacheTTL
+	}
+	return &dnsCache{
+		ttl:     ttl,
+		lookup:  net.DefaultResolver.LookupHost,
+		entries: make(map[string]*dnsEntry),
+	}
+}
+
+// resolve returns IPs of host in the order to dial
+func (dc *dnsCache) resolve(ctx context.Context, host string) ([]string, error) {
+	dc.lock.Lock()
+	entry, ok := dc.entries[host]
+	if ok {
+		now := time.Now()
+		// refresh in the last tenth of TTL
+		if !entry.refreshing && now.After(entry.expiration.Add(-dc.ttl/10)) && now.After(entry.retryAt) {
+			entry.refreshing = true
+			go dc.refresh(host, entry)
+		}
+		ips := make([]string, 0, len(entry.ips))
+		for i := range entry.ips {
+			ips = append(ips, entry.ips[(entry.next+i)%len(entry.ips)])
+		}
+		entry.next = (entry.next + 1) % len(entry.ips)
+		dc.lock.Unlock()
+		return ips, nil
+	}
+	dc.lock.Unlock()
+
+	ips, err := dc.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	dc.lock.Lock()
+	if _, ok = dc.entries[host]; !ok {
+		dc.entries[host] = &dnsEntry{
+			ips:        ips,
+			failures:   make(map[string]int),
+			next:       1 % len(ips),
+			expiration: time.Now().Add(dc.ttl),
+		}
+	}
+	dc.lock.Unlock()
+	return ips, nil
+}
+
+// refresh resolves host again, and entry is kept as is if it fails
+func (dc *dnsCache) refresh(host string, entry *dnsEntry) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsRefreshTimeout)
+	defer cancel()
+	ips, err := dc.lookup(ctx, host)
+
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	entry.refreshing = false
+	if err != nil || len(ips) == 0 {
+		entry.retryAt = time.Now().Add(dnsRefreshRetryInterval)
+		return
+	}
+	entry.ips = ips
+	entry.failures = make(map[string]int)
+	entry.next = 0
+	entry.expiration = time.Now().Add(dc.ttl)
+}
+
+// report records the result of dialing ip of host
+func (dc *dnsCache) report(host, ip string, err error) {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	entry, ok := dc.entries[host]
+	if !ok {
+		return
+	}
+	if err == nil {
+		delete(entry.failures, ip)
+		return
+	}
+	entry.failures[ip]++
+	if entry.failures[ip] < dnsMaxDialFailures {
+		return
+	}
+	for i, cached := range entry.ips {
+		if cached == ip {
+			entry.ips = append(entry.ips[:i:i], entry.ips[i+1:]...)
+			break
+		}
+	}
+	delete(entry.failures, ip)
+	if len(entry.ips) == 0 {
+		delete(dc.entries, host)
+		return
+	}
+	entry.next %= len(entry.ips)
+}
+
+// DialContext dials IPs of the host of address in turn until one of them succeeds
+func (dc *dnsCache) DialContext(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return dialer.DialContext(ctx, network, address)
+	}
+	ips, err := dc.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			dc.report(host, ip, nil)
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			// failures caused by the canceled request are not failures of the IP
+			return nil, err
+		}
+		dc.report(host, ip, err)
+	}
+	return nil, err
+}
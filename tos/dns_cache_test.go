@@ -0,0 +1,127 @@
+package tos
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSCache(t *testing.T) {
+	var (
+		lookups int32
+		failing int32
+	)
+	cache := newDNSCache(time.Second)
+	cache.lookup = func(ctx context.Context, host string) ([]string, error) {
+		count := atomic.AddInt32(&lookups, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			return nil, fmt.Errorf("dns unavailable")
+		}
+		return []string{fmt.Sprintf("10.0.0.%d", count), "10.0.1.1"}, nil
+	}
+	ctx := context.Background()
+
+	// resolved once, and IPs are used in turn
+	ips, err := cache.resolve(ctx, "bucket.tos-cn-beijing.volces.com")
+	require.Nil(t, err)
+	require.Equal(t, []string{"10.0.0.1", "10.0.1.1"}, ips)
+	ips, err = cache.resolve(ctx, "bucket.tos-cn-beijing.volces.com")
+	require.Nil(t, err)
+	require.Equal(t, []string{"10.0.1.1", "10.0.0.1"}, ips)
+	require.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+
+	// stale entry is used if refreshing fails
+	atomic.StoreInt32(&failing, 1)
+	time.Sleep(950 * time.Millisecond)
+	ips, err = cache.resolve(ctx, "bucket.tos-cn-beijing.volces.com")
+	require.Nil(t, err)
+	require.Equal(t, []string{"10.0.0.1", "10.0.1.1"}, ips)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&lookups) == 2
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	ips, err = cache.resolve(ctx, "bucket.tos-cn-beijing.volces.com")
+	require.Nil(t, err)
+	require.Len(t, ips, 2)
+	require.Equal(t, int32(2), atomic.LoadInt32(&lookups))
+
+	// refreshed asynchronously
+	atomic.StoreInt32(&failing, 0)
+	cache.lock.Lock()
+	cache.entries["bucket.tos-cn-beijing.volces.com"].retryAt = time.Time{}
+	cache.lock.Unlock()
+	_, err = cache.resolve(ctx, "bucket.tos-cn-beijing.volces.com")
+	require.Nil(t, err)
+	require.Eventually(t, func() bool {
+		ips, err = cache.resolve(ctx, "bucket.tos-cn-beijing.volces.com")
+		return err == nil && (ips[0] == "10.0.0.3" || ips[1] == "10.0.0.3")
+	}, time.Second, 10*time.Millisecond)
+
+	// not cached if resolving fails
+	atomic.StoreInt32(&failing, 1)
+	_, err = cache.resolve(ctx, "other.tos-cn-beijing.volces.com")
+	require.NotNil(t, err)
+	require.Len(t, cache.entries, 1)
+}
+
+func TestDNSCacheEviction(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	var lookups int32
+	cache := newDNSCache(time.Minute)
+	cache.lookup = func(ctx context.Context, host string) ([]string, error) {
+		atomic.AddInt32(&lookups, 1)
+		// nothing listens on 127.0.0.2
+		return []string{"127.0.0.2", "127.0.0.1"}, nil
+	}
+	dialer := &net.Dialer{Timeout: time.Second}
+	for i := 0; i < 2*dnsMaxDialFailures; i++ {
+		conn, err := cache.DialContext(context.Background(), dialer, "tcp", net.JoinHostPort("tos.example.com", port))
+		require.Nil(t, err)
+		require.Equal(t, "127.0.0.1", conn.RemoteAddr().(*net.TCPAddr).IP.String())
+		conn.Close()
+	}
+	// the dead IP is evicted after repeated failures
+	require.Equal(t, []string{"127.0.0.1"}, cache.entries["tos.example.com"].ips)
+	require.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+
+	// the host is resolved again once all IPs are evicted
+	cache.lookup = func(ctx context.Context, host string) ([]string, error) {
+		atomic.AddInt32(&lookups, 1)
+		return []string{"127.0.0.2"}, nil
+	}
+	cache.entries["tos.example.com"].ips = []string{"127.0.0.2"}
+	for i := 0; i < dnsMaxDialFailures; i++ {
+		_, err = cache.DialContext(context.Background(), dialer, "tcp", net.JoinHostPort("tos.example.com", port))
+		require.NotNil(t, err)
+	}
+	require.Len(t, cache.entries, 0)
+	_, err = cache.DialContext(context.Background(), dialer, "tcp", net.JoinHostPort("tos.example.com", port))
+	require.NotNil(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&lookups))
+}
+
+func TestDNSCacheOption(t *testing.T) {
+	client, err := NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithEnableDNSCache(true), WithDNSCacheTTL(time.Minute))
+	require.Nil(t, err)
+	require.True(t, client.config.TransportConfig.EnableDNSCache)
+	require.Equal(t, time.Minute, client.config.TransportConfig.DNSCacheTTL)
+}
@@ -0,0 +1,155 @@
+package tos
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DownloadDirectory downloads objects under Prefix to LocalDir, keeping the relative directory structure.
+// Keys ending with "/" are directory placeholders and skipped, and keys escaping LocalDir
+// such as "prefix/../../file" are recorded in Failed rather than downloaded.
+// Objects are downloaded in parallel, objects larger than MultipartThreshold are downloaded by range,
+// and an object failed to download is recorded in output rather than stopping others.
+// Error is returned only if listing objects failed or ctx is done, along with objects handled before that.
+func (cli *ClientV2) DownloadDirectory(ctx context.Context, input *DownloadDirectoryInput) (*DownloadDirectoryOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(input.LocalDir)
+	if err != nil {
+		return nil, newTosClientError("tos: invalid local directory to download", err)
+	}
+	taskNum := input.TaskNum
+	if taskNum < 1 {
+		taskNum = DefaultDirectoryTaskNum
+	}
+	var (
+		output   DownloadDirectoryOutput
+		lock     sync.Mutex
+		wg       sync.WaitGroup
+		listed   *ListObjectsV2Output
+		inflight = make(chan struct{}, taskNum)
+		list     = &ListObjectsV2Input{Bucket: input.Bucket, ListObjectsInput: ListObjectsInput{Prefix: input.Prefix}}
+	)
+	for {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		listed, err = cli.ListObjectsV2(ctx, list)
+		if err != nil {
+			break
+		}
+		for _, object := range listed.Contents {
+			rel := strings.TrimPrefix(object.Key, input.Prefix)
+			if rel == "" || strings.HasSuffix(rel, "/") {
+				continue
+			}
+			filePath, ok := localFilePath(root, rel)
+			if !ok {
+				lock.Lock()
+				output.Failed = append(output.Failed, DownloadDirectoryFileError{Key: object.Key,
+					Err: newTosClientError("tos: the object key escapes the local directory", nil)})
+				lock.Unlock()
+				continue
+			}
+			inflight <- struct{}{}
+			wg.Add(1)
+			go func(object ListedObjectV2) {
+				defer func() {
+					<-inflight
+					wg.Done()
+				}()
+				file := DownloadedDirectoryFile{Key: object.Key, FilePath: filePath, Size: object.Size}
+				if input.SkipUnchanged && localFileUnchanged(filePath, object) {
+					lock.Lock()
+					output.Skipped = append(output.Skipped, file)
+					lock.Unlock()
+					return
+				}
+				err := cli.downloadDirectoryObject(ctx, input, object, filePath)
+				lock.Lock()
+				defer lock.Unlock()
+				if err != nil {
+					output.Failed = append(output.Failed, DownloadDirectoryFileError{Key: object.Key, FilePath: filePath, Err: err})
+					return
+				}
+				output.Succeeded = append(output.Succeeded, file)
+			}(object)
+		}
+		if !listed.IsTruncated {
+			break
+		}
+		nextMarker := listed.NextMarker
+		if nextMarker == "" && len(listed.Contents) > 0 {
+			// NextMarker may be omitted without Delimiter, the last listed key is the marker of the next page
+			nextMarker = listed.Contents[len(listed.Contents)-1].Key
+		}
+		if nextMarker == "" || nextMarker == list.Marker {
+			break
+		}
+		list.Marker = nextMarker
+	}
+	wg.Wait()
+	return &output, err
+}
+
+// localFilePath returns path of rel in root, false if the path is out of root
+func localFilePath(root, rel string) (string, bool) {
+	filePath := filepath.Join(root, filepath.FromSlash(rel))
+	relPath, err := filepath.Rel(root, filePath)
+	if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filePath, true
+}
+
+// localFileUnchanged returns true if size and CRC64 of the local file are the same as the object
+func localFileUnchanged(filePath string, object ListedObjectV2) bool {
+	if object.HashCrc64ecma == 0 {
+		return false
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil || !stat.Mode().IsRegular() || stat.Size() != object.Size {
+		return false
+	}
+	checker := NewCRC(DefaultCrcTable(), 0)
+	if _, err = io.Copy(checker, file); err != nil {
+		return false
+	}
+	return checker.Sum64() == object.HashCrc64ecma
+}
+
+func (cli *ClientV2) downloadDirectoryObject(ctx context.Context, input *DownloadDirectoryInput, object ListedObjectV2, filePath string) error {
+	threshold := input.MultipartThreshold
+	if threshold <= 0 {
+		threshold = DefaultMultipartThreshold
+	}
+	if object.Size > threshold {
+		_, err := cli.DownloadFile(ctx, &DownloadFileInput{
+			HeadObjectV2Input: HeadObjectV2Input{Bucket: input.Bucket, Key: object.Key},
+			FilePath:          filePath,
+			PartSize:          input.PartSize,
+			TaskNum:           input.PartTaskNum,
+			TransferManager:   input.TransferManager,
+		})
+		return err
+	}
+	_, err := cli.GetObjectToFile(ctx, &GetObjectToFileInput{
+		GetObjectV2Input: GetObjectV2Input{Bucket: input.Bucket, Key: object.Key},
+		FilePath:         filePath,
+		CreateParentDir:  true,
+	})
+	return err
+}
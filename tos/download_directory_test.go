@@ -0,0 +1,102 @@
+package tos
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadDirectory(t *testing.T) {
+	root, err := ioutil.TempDir("", "download-directory")
+	require.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	objects := map[string]string{
+		"prefix/a.txt":        "a",
+		"prefix/sub/b.txt":    "b",
+		"prefix/sub/":         "",
+		"prefix/../../escape": "escape",
+		"prefix/same.txt":     "same",
+	}
+	require.Nil(t, ioutil.WriteFile(filepath.Join(root, "same.txt"), []byte("same"), 0644))
+	var (
+		lock sync.Mutex
+		gets []string
+	)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Path == "/" {
+			// list objects by two pages, NextMarker is omitted without Delimiter
+			keys := make([]string, 0, len(objects))
+			for key := range objects {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			marker := req.Query.Get("marker")
+			var contents []string
+			for _, key := range keys {
+				if key > marker && len(contents) < 3 {
+					contents = append(contents, fmt.Sprintf(`{"Key":%q,"Size":%d,"HashCrc64ecma":"%d"}`,
+						key, len(objects[key]), crc64Of([]byte(objects[key]))))
+				}
+			}
+			truncated := len(contents) == 3
+			body := fmt.Sprintf(`{"IsTruncated":%t,"Contents":[%s]}`, truncated, strings.Join(contents, ","))
+			return newMockResponse(http.StatusOK, body)()
+		}
+		key := strings.TrimPrefix(req.Path, "/")
+		lock.Lock()
+		gets = append(gets, key)
+		lock.Unlock()
+		res := newMockResponse(http.StatusOK, objects[key])()
+		res.Header.Set(HeaderContentLength, strconv.Itoa(len(objects[key])))
+		return res
+	}}
+	client := newMockClient(t, transport)
+
+	output, err := client.DownloadDirectory(context.Background(), &DownloadDirectoryInput{
+		Bucket:        "bucket",
+		Prefix:        "prefix/",
+		LocalDir:      root,
+		SkipUnchanged: true,
+	})
+	require.Nil(t, err)
+	sort.Strings(gets)
+	require.Equal(t, []string{"prefix/a.txt", "prefix/sub/b.txt"}, gets)
+	require.Len(t, output.Succeeded, 2)
+	require.Len(t, output.Skipped, 1)
+	require.Equal(t, "prefix/same.txt", output.Skipped[0].Key)
+	require.Len(t, output.Failed, 1)
+	require.Equal(t, "prefix/../../escape", output.Failed[0].Key)
+
+	data, err := ioutil.ReadFile(filepath.Join(root, "sub", "b.txt"))
+	require.Nil(t, err)
+	require.Equal(t, "b", string(data))
+	_, err = os.Stat(filepath.Join(filepath.Dir(root), "escape"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestLocalFilePath(t *testing.T) {
+	root := filepath.Join(os.TempDir(), "root")
+	path, ok := localFilePath(root, "a/b")
+	require.True(t, ok)
+	require.Equal(t, filepath.Join(root, "a", "b"), path)
+	path, ok = localFilePath(root, "a/../b")
+	require.True(t, ok)
+	require.Equal(t, filepath.Join(root, "b"), path)
+	_, ok = localFilePath(root, "../b")
+	require.False(t, ok)
+	_, ok = localFilePath(root, "a/../..")
+	require.False(t, ok)
+	_, ok = localFilePath(root, "..foo")
+	require.True(t, ok)
+}
@@ -2,8 +2,8 @@ package tos
 
 import (
 	"context"
-	"encoding/json"
-	"io/ioutil"
+	"crypto/rand"
+	"encoding/hex"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,20 +11,18 @@ import (
 	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
 )
 
-func getDownloadCheckpoint(enabled bool, checkpointPath string, init func(input *HeadObjectV2Output) (*downloadCheckpoint, error), output *HeadObjectV2Output) (checkpoint *downloadCheckpoint, err error) {
+// getDownloadCheckpoint get struct checkpoint saved with key from store,
+// or initialize from scratch with function init.
+// A loaded checkpoint is reused only if valid returns true, otherwise it is overwritten.
+func getDownloadCheckpoint(store CheckpointStore, enabled bool, key CheckpointKey, init func(input *HeadObjectV2Output) (*downloadCheckpoint, error),
+	output *HeadObjectV2Output, valid func(checkpoint *downloadCheckpoint) bool) (checkpoint *downloadCheckpoint, err error) {
 	if enabled {
-		_, err = os.Stat(checkpointPath)
-		// if err is not empty, assume checkpoint not exists
-		if err == nil {
-			checkpoint = &downloadCheckpoint{}
-			loadCheckPoint(checkpointPath, checkpoint)
-			if checkpoint != nil && checkpoint.ObjectInfo.Etag == output.ETag {
-				return
-			}
-		}
-		_, err = os.Create(checkpointPath)
-		if err != nil {
-			return nil, newTosClientError(err.Error(), err)
+		checkpoint = &downloadCheckpoint{}
+		// if failed to load, assume checkpoint not exists
+		if loadCheckpoint(store, key, checkpoint) == nil && valid(checkpoint) {
+			checkpoint.checkpointPath = key.CheckpointFile
+			checkpoint.store = store
+			return checkpoint, nil
 		}
 	}
 	checkpoint, err = init(output)
@@ -32,8 +30,8 @@ func getDownloadCheckpoint(enabled bool, checkpointPath string, init func(input
 		return nil, err
 	}
 	if enabled {
-		err = checkpoint.WriteToFile()
-		if err != nil {
+		checkpoint.store = store
+		if err = checkpoint.Save(); err != nil {
 			return nil, err
 		}
 	}
@@ -41,6 +39,10 @@ func getDownloadCheckpoint(enabled bool, checkpointPath string, init func(input
 }
 
 func (cli *ClientV2) DownloadFile(ctx context.Context, input *DownloadFileInput) (*DownloadFileOutput, error) {
+	// avoid modifying on origin pointer
+	copied := *input
+	input = &copied
+
 	err := validateDownloadInput(input)
 	if err != nil {
 		return nil, err
@@ -49,6 +51,9 @@ func (cli *ClientV2) DownloadFile(ctx context.Context, input *DownloadFileInput)
 	if err != nil {
 		return nil, err
 	}
+	if input.PartSize, _, err = CalcPartSize(headOutput.ContentLength, input.PartSize); err != nil {
+		return nil, err
+	}
 	event := downloadEvent{input: input}
 	init := func(output *HeadObjectV2Output) (*downloadCheckpoint, error) {
 		err := createTempFile(input, event)
@@ -57,28 +62,105 @@ func (cli *ClientV2) DownloadFile(ctx context.Context, input *DownloadFileInput)
 		}
 		return initDownloadCheckpoint(input, headOutput)
 	}
-	checkpoint, err := getDownloadCheckpoint(input.EnableCheckpoint, input.CheckpointFile, init, headOutput)
+	// downloaded parts can be reused only if the object is unchanged and the temp file still exists
+	valid := func(checkpoint *downloadCheckpoint) bool {
+		if checkpoint.FileInfo.TempFilePath != input.tempFile {
+			return false
+		}
+		if _, err := os.Stat(checkpoint.FileInfo.TempFilePath); err != nil {
+			return false
+		}
+		return checkpoint.Valid(input, headOutput)
+	}
+	store := getCheckpointStore(input.CheckpointStore)
+	key := CheckpointKey{Operation: checkpointDownload, Bucket: input.Bucket, Key: input.Key, CheckpointFile: input.CheckpointFile}
+	checkpoint, err := getDownloadCheckpoint(store, input.EnableCheckpoint, key, init, headOutput, valid)
 	if err != nil {
 		return nil, err
 	}
 	cleaner := func() {
-		_ = os.Remove(input.CheckpointFile)
+		checkpoint.Delete()
+		_ = os.Remove(input.tempFile)
 	}
 	bindCancelHookWithCleaner(input.CancelHook, cleaner)
-	return cli.downloadFile(ctx, headOutput, checkpoint, input, event)
+	output, err := cli.downloadFile(ctx, headOutput, checkpoint, input, event)
+	if canceled, isAbort := isCanceled(input.CancelHook); canceled && isAbort {
+		// parts finished after canceling may have rewritten the checkpoint
+		cleaner()
+	}
+	return output, err
+}
+
+// checkpointFileName returns the default checkpoint file name, e.g. "file.bucket.key.upload".
+// Path separators in key are replaced so that the name stays in one directory.
+func checkpointFileName(filePath, bucket, key, suffix string) string {
+	key = strings.Replace(key, "/", "_", -1)
+	key = strings.Replace(key, "\\", "_", -1)
+	return strings.Join([]string{filepath.Base(filePath), bucket, key, suffix}, ".")
+}
+
+// tempFilePath returns path of the temp file which is renamed to filePath after downloaded
+func tempFilePath(filePath, suffix string) string {
+	if suffix == "" {
+		suffix = TempFileSuffix
+	}
+	return filePath + suffix
+}
+
+// createExclusiveTempFile creates a temp file exclusively beside filePath, named with a random number and suffix,
+// so that concurrent downloads to the same file never write to the same temp file.
+// The random number is read from crypto/rand, math/rand is not seeded and yields the same names in every process.
+func createExclusiveTempFile(filePath, suffix string) (*os.File, string, error) {
+	random := make([]byte, 8)
+	for i := 0; i < 100; i++ {
+		if _, err := rand.Read(random); err != nil {
+			return nil, "", newTosClientError("tos: create temp file failed", err)
+		}
+		tempFile := tempFilePath(filePath+"."+hex.EncodeToString(random), suffix)
+		fd, err := os.OpenFile(tempFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, DefaultFilePerm)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", newTosClientError("tos: create temp file failed", err)
+		}
+		return fd, tempFile, nil
+	}
+	return nil, "", newTosClientError("tos: create temp file failed, too many temp files exist", nil)
+}
+
+// checkDownloadTarget returns TosClientError if filePath is an existing directory
+func checkDownloadTarget(filePath string) error {
+	if stat, err := os.Stat(filePath); err == nil && stat.IsDir() {
+		return newTosClientError("tos: the file path to download to is an existing directory: "+filePath, nil)
+	}
+	return nil
 }
 
-// loadCheckPoint load UploadFile checkpoint or DownloadFile checkpoint.
-// checkpoint must be a pointer
-func loadCheckPoint(path string, checkpoint interface{}) {
-	contents, err := ioutil.ReadFile(path)
-	if err != nil && !os.IsNotExist(err) {
-		return
+// commitTempFile flushes the temp file to disk and renames it to filePath atomically,
+// the file mode of an existing filePath is kept.
+func commitTempFile(tempFile, filePath string) error {
+	file, err := os.OpenFile(tempFile, os.O_RDWR, DefaultFilePerm)
+	if err != nil {
+		return newTosClientError("tos: open temp file failed", err)
+	}
+	err = file.Sync()
+	_ = file.Close()
+	if err != nil {
+		return newTosClientError("tos: sync temp file failed", err)
 	}
-	if len(contents) == 0 {
-		return
+	if stat, err := os.Stat(filePath); err == nil {
+		if stat.IsDir() {
+			return newTosClientError("tos: the file path to download to is an existing directory: "+filePath, nil)
+		}
+		if err = os.Chmod(tempFile, stat.Mode().Perm()); err != nil {
+			return newTosClientError("tos: keep file mode of the existing file failed", err)
+		}
+	}
+	if err = os.Rename(tempFile, filePath); err != nil {
+		return newTosClientError("tos: rename temp file failed", err)
 	}
-	json.Unmarshal(contents, &checkpoint)
+	return nil
 }
 
 // if file is a directory, append suffix to it to make a file name
@@ -100,17 +182,23 @@ func validateDownloadInput(input *DownloadFileInput) error {
 		return newTosClientError("The input part size is invalid, please set it range from 5MB to 5GB", nil)
 	}
 	// if directory, append object key at end
-	mustFile(&input.FilePath, input.Key)
-	input.tempFile = input.FilePath + TempFileSuffix
+	if strings.HasSuffix(input.FilePath, "/") || strings.HasSuffix(input.FilePath, string(filepath.Separator)) {
+		input.FilePath = filepath.Join(input.FilePath, input.Key)
+	} else {
+		mustFile(&input.FilePath, input.Key)
+	}
+	if err := os.MkdirAll(filepath.Dir(input.FilePath), os.ModePerm); err != nil {
+		return newTosClientError("tos: create directory to download failed", err)
+	}
+	if err := checkDownloadTarget(input.FilePath); err != nil {
+		return err
+	}
+	input.tempFile = tempFilePath(input.FilePath, input.TempFileSuffix)
 	if input.EnableCheckpoint {
 		// get correct checkpoint path
-		if len(input.CheckpointFile) == 0 {
-			dirName, _ := filepath.Split(input.FilePath)
-			fileName := strings.Join([]string{input.FilePath, input.Bucket, input.Key, "download"}, ".")
-			input.CheckpointFile = filepath.Join(dirName, fileName)
-		} else {
-			mustFile(&input.CheckpointFile, strings.Join([]string{input.FilePath, input.Bucket, input.Key, "download"}, "."))
-		}
+		fileName := checkpointFileName(input.FilePath, input.Bucket, input.Key, "download")
+		dirName, _ := filepath.Split(input.FilePath)
+		setDefaultCheckpointFile(&input.CheckpointFile, input.CheckpointStore, dirName, fileName)
 	}
 	if input.TaskNum < 1 {
 		input.TaskNum = 1
@@ -138,7 +226,7 @@ func initDownloadCheckpoint(input *DownloadFileInput, headOutput *HeadObjectV2Ou
 	if remainder != 0 {
 		parts[partsNum-1].RangeEnd = (partsNum-1)*input.PartSize + remainder - 1
 	}
-	if len(parts) > 10000 {
+	if len(parts) > MaxPartCount {
 		return nil, newTosClientError("tos: part count too many", nil)
 	}
 	return &downloadCheckpoint{
@@ -152,7 +240,7 @@ func initDownloadCheckpoint(input *DownloadFileInput, headOutput *HeadObjectV2Ou
 		IfNoneMatch:       input.IfNoneMatch,
 		IfUnmodifiedSince: input.IfUnmodifiedSince,
 		SSECAlgorithm:     input.SSECAlgorithm,
-		SSECKeyMD5:        input.SSECKey,
+		SSECKeyMD5:        input.SSECKeyMD5,
 		ObjectInfo: downloadObjectInfo{
 			Etag:          headOutput.ETag,
 			HashCrc64ecma: headOutput.HashCrc64ecma,
@@ -168,7 +256,7 @@ func initDownloadCheckpoint(input *DownloadFileInput, headOutput *HeadObjectV2Ou
 }
 
 func createTempFile(input *DownloadFileInput, event downloadEvent) error {
-	_, err := os.Create(input.tempFile)
+	file, err := os.Create(input.tempFile)
 	if err != nil {
 		event.postDownloadEvent(&DownloadEvent{
 			Type:      enum.DownloadEventCreateTempFileFailed,
@@ -179,22 +267,21 @@ func createTempFile(input *DownloadFileInput, event downloadEvent) error {
 		})
 		return newTosClientError("tos: create temp file failed.", err)
 	}
+	_ = file.Close()
 	event.postDownloadEvent(&DownloadEvent{
 		Type:         enum.DownloadEventCreateTempFileSucceed,
 		Bucket:       input.Bucket,
 		Key:          input.Key,
 		VersionID:    input.VersionID,
 		FilePath:     input.FilePath,
-		TempFilePath: &input.FilePath,
+		TempFilePath: &input.tempFile,
 	})
 	return nil
 }
 
 func getDownloadTasks(cli *ClientV2, ctx context.Context, headOutput *HeadObjectV2Output,
-	checkpoint *downloadCheckpoint, input *DownloadFileInput) []task {
+	checkpoint *downloadCheckpoint, input *DownloadFileInput, progress *transferProgress) []task {
 	tasks := make([]task, 0)
-	consumed := int64(0)
-	subtotal := int64(0)
 	for _, part := range checkpoint.PartsInfo {
 		if !part.IsCompleted {
 			tasks = append(tasks, &downloadTask{
@@ -204,9 +291,8 @@ func getDownloadTasks(cli *ClientV2, ctx context.Context, headOutput *HeadObject
 				partNumber:  part.PartNumber,
 				rangeStart:  part.RangeStart,
 				rangeEnd:    part.RangeEnd,
-				consumed:    &consumed,
-				subtotal:    &subtotal,
-				total:       headOutput.ContentLength,
+				progress:    progress,
+				etag:        headOutput.ETag,
 				enableCRC64: cli.enableCRC,
 			})
 		}
@@ -255,35 +341,60 @@ func (d downloadEvent) postDownloadEvent(event *DownloadEvent) {
 }
 
 func (cli *ClientV2) downloadFile(ctx context.Context,
-	headOutput *HeadObjectV2Output, checkpoint *downloadCheckpoint, input *DownloadFileInput, event downloadEvent) (*DownloadFileOutput, error) {
+	headOutput *HeadObjectV2Output, checkpoint *downloadCheckpoint, input *DownloadFileInput, event downloadEvent) (output *DownloadFileOutput, err error) {
 	// prepare tasks
-	tasks := getDownloadTasks(cli, ctx, headOutput, checkpoint, input)
+	cancelHandle := getCancelHandle(input.CancelHook)
+	taskCtx, cancel := contextWithCancelHandle(ctx, cancelHandle)
+	defer cancel()
+	progress := newTransferProgress(input.DataTransferListener, headOutput.ContentLength)
+	defer func() {
+		if err != nil {
+			progress.fail()
+		}
+	}()
+	tasks := getDownloadTasks(cli, taskCtx, headOutput, checkpoint, input, progress)
 	routinesNum := min(input.TaskNum, len(tasks))
-	tg := newTaskGroup(getCancelHandle(input.CancelHook), routinesNum, checkpoint, event, input.EnableCheckpoint, tasks)
+	saver := newCheckpointSaver(input.EnableCheckpoint, input.CheckpointSaveInterval, input.CheckpointSavePartCount)
+	tg := newTaskGroup(taskCtx, cancelHandle, routinesNum, checkpoint, event, saver, input.TransferManager, tasks)
 	tg.RunWorker()
 	// start adding tasks
-	postDataTransferStatus(input.DataTransferListener, &DataTransferStatus{
-		Type: enum.DataTransferStarted,
-	})
+	progress.start(checkpoint.completedBytes(), checkpoint.PartSize)
 	tg.Scheduler()
 	success, err := tg.Wait()
+	if canceled, _ := isCanceled(input.CancelHook); canceled {
+		// the temp file is removed by CancelHook if needed, otherwise it is kept to be resumed
+		return nil, CanceledClientError
+	}
 	if err != nil {
 		_ = os.Remove(input.tempFile)
+		return nil, err
 	}
-
 	if success < len(tasks) {
+		// without checkpoint the downloaded parts can never be reused
+		if !input.EnableCheckpoint {
+			_ = os.Remove(input.tempFile)
+		}
 		return nil, newTosClientError("tos: some download task failed.", nil)
 	}
 	// Check CRC64
-	if cli.enableCRC && headOutput.HashCrc64ecma != 0 && combineCRCInDownload(checkpoint.PartsInfo) != headOutput.HashCrc64ecma {
-		return nil, newTosClientError("tos: crc of entire file mismatch.", nil)
+	if cli.enableCRC && headOutput.HashCrc64ecma != 0 {
+		if combined := combineCRCInDownload(checkpoint.PartsInfo); combined != headOutput.HashCrc64ecma {
+			// the temp file is corrupted and can not be resumed
+			checkpoint.Delete()
+			_ = os.Remove(input.tempFile)
+			return nil, newChecksumMismatchError(headOutput.RequestID, headOutput.HashCrc64ecma, combined)
+		}
 	}
-	err = os.Rename(input.tempFile, input.FilePath)
+	err = commitTempFile(input.tempFile, input.FilePath)
 	if err != nil {
 		event.postDownloadEvent(event.newFailedEvent(err, enum.DownloadEventRenameTempFileFailed))
+		if !input.EnableCheckpoint {
+			_ = os.Remove(input.tempFile)
+		}
 		return nil, err
 	}
 	event.postDownloadEvent(event.newSucceedEvent(enum.DownloadEventRenameTempFileSucceed))
-	_ = os.Remove(checkpoint.checkpointPath)
+	checkpoint.Delete()
+	progress.succeed()
 	return &DownloadFileOutput{*headOutput}, nil
 }
@@ -0,0 +1,192 @@
+package tos
+
+import (
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitDownloadCheckpoint(t *testing.T) {
+	input := &DownloadFileInput{
+		HeadObjectV2Input: HeadObjectV2Input{Bucket: "bucket", Key: "key", VersionID: "version"},
+		FilePath:          "file",
+		PartSize:          MinPartSize,
+	}
+	head := &HeadObjectV2Output{ObjectMetaV2: ObjectMetaV2{ETag: "etag", ContentLength: 2*MinPartSize + 1}}
+	checkpoint, err := initDownloadCheckpoint(input, head)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(checkpoint.PartsInfo))
+	require.Equal(t, int64(2*MinPartSize), checkpoint.PartsInfo[2].RangeStart)
+	require.Equal(t, int64(2*MinPartSize), checkpoint.PartsInfo[2].RangeEnd)
+	require.True(t, checkpoint.Valid(input, head))
+
+	// object smaller than one part
+	head.ContentLength = 1024
+	checkpoint, err = initDownloadCheckpoint(input, head)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(checkpoint.PartsInfo))
+	require.Equal(t, int64(1023), checkpoint.PartsInfo[0].RangeEnd)
+
+	// zero-byte object
+	head.ContentLength = 0
+	checkpoint, err = initDownloadCheckpoint(input, head)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(checkpoint.PartsInfo))
+
+	// object changed between resume attempts
+	changed := *head
+	changed.ETag = "changed"
+	require.False(t, checkpoint.Valid(input, &changed))
+}
+
+func TestGetObjectToFileTempFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "get-object-to-file")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	target := filepath.Join(dir, "file")
+	require.Nil(t, ioutil.WriteFile(target, []byte("old"), 0600))
+
+	fail := true
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if fail {
+			return newMockResponse(http.StatusForbidden, `{"Code":"AccessDenied"}`)()
+		}
+		return newMockResponse(http.StatusOK, "new")()
+	}}
+	client := newMockClient(t, transport)
+	input := &GetObjectToFileInput{
+		GetObjectV2Input: GetObjectV2Input{Bucket: "bucket", Key: "key"},
+		FilePath:         target,
+		TempFileSuffix:   ".part",
+	}
+	// the target is kept and the temp file is removed on failure
+	_, err = client.GetObjectToFile(context.Background(), input)
+	require.NotNil(t, err)
+	data, err := ioutil.ReadFile(target)
+	require.Nil(t, err)
+	require.Equal(t, "old", string(data))
+	requireNoTempFile(t, dir)
+
+	// the file mode of the existing target is kept
+	fail = false
+	_, err = client.GetObjectToFile(context.Background(), input)
+	require.Nil(t, err)
+	data, err = ioutil.ReadFile(target)
+	require.Nil(t, err)
+	require.Equal(t, "new", string(data))
+	stat, err := os.Stat(target)
+	require.Nil(t, err)
+	require.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+	requireNoTempFile(t, dir)
+
+	// target is an existing directory
+	input.FilePath = dir
+	_, err = client.GetObjectToFile(context.Background(), input)
+	_, ok := err.(*TosClientError)
+	require.True(t, ok)
+	require.Equal(t, target+TempFileSuffix, tempFilePath(target, ""))
+}
+
+func requireNoTempFile(t *testing.T, dir string) {
+	infos, err := ioutil.ReadDir(dir)
+	require.Nil(t, err)
+	for _, info := range infos {
+		require.False(t, strings.HasSuffix(info.Name(), ".part") || strings.HasSuffix(info.Name(), TempFileSuffix), info.Name())
+	}
+}
+
+func TestGetObjectToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "get-object-to-file")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	crc := strconv.FormatUint(crc64Of([]byte("hello")), 10)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, "hello")()
+		res.Header.Set(HeaderHashCrc64ecma, crc)
+		return res
+	}}
+	client := newMockClient(t, transport)
+	target := filepath.Join(dir, "a", "b", "file")
+	input := &GetObjectToFileInput{
+		GetObjectV2Input: GetObjectV2Input{Bucket: "bucket", Key: "key"},
+		FilePath:         target,
+	}
+	_, err = client.GetObjectToFile(context.Background(), input)
+	require.NotNil(t, err)
+
+	input.CreateParentDir = true
+	_, err = client.GetObjectToFile(context.Background(), input)
+	require.Nil(t, err)
+	data, err := ioutil.ReadFile(target)
+	require.Nil(t, err)
+	require.Equal(t, "hello", string(data))
+
+	// temp files of concurrent downloads don't conflict
+	fd, tempFile, err := createExclusiveTempFile(target, "")
+	require.Nil(t, err)
+	defer fd.Close()
+	fd2, tempFile2, err := createExclusiveTempFile(target, "")
+	require.Nil(t, err)
+	defer fd2.Close()
+	require.NotEqual(t, tempFile, tempFile2)
+	require.True(t, strings.HasSuffix(tempFile, TempFileSuffix))
+	require.Nil(t, os.Remove(tempFile))
+	require.Nil(t, os.Remove(tempFile2))
+	// names don't depend on the state of math/rand, which is the same in every process unless seeded
+	rand.Seed(1)
+	fd3, tempFile3, err := createExclusiveTempFile(target, "")
+	require.Nil(t, err)
+	fd3.Close()
+	require.Nil(t, os.Remove(tempFile3))
+	rand.Seed(1)
+	fd4, tempFile4, err := createExclusiveTempFile(target, "")
+	require.Nil(t, err)
+	fd4.Close()
+	require.Nil(t, os.Remove(tempFile4))
+	require.NotEqual(t, tempFile3, tempFile4)
+
+	// the target is kept if crc64 mismatches
+	crc = "1"
+	_, err = client.GetObjectToFile(context.Background(), input)
+	require.NotNil(t, err)
+	data, err = ioutil.ReadFile(target)
+	require.Nil(t, err)
+	require.Equal(t, "hello", string(data))
+	requireNoTempFile(t, filepath.Dir(target))
+}
+
+func TestDownloadFilePreconditionFailed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "download-file")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodHead {
+			res := newMockResponse(http.StatusOK, "")()
+			res.Body = nil
+			res.Header.Set(HeaderContentLength, "1024")
+			res.Header.Set(HeaderETag, "\"etag\"")
+			return res
+		}
+		return newMockResponse(http.StatusPreconditionFailed, `{"Code":"PreconditionFailed"}`)()
+	}}
+	client := newMockClient(t, transport)
+	_, err = client.DownloadFile(context.Background(), &DownloadFileInput{
+		HeadObjectV2Input: HeadObjectV2Input{Bucket: "bucket", Key: "key"},
+		FilePath:          filepath.Join(dir, "file"),
+	})
+	_, ok := err.(*PreconditionFailedError)
+	require.True(t, ok)
+	// head + the part without retrying
+	require.Len(t, transport.recorded(), 2)
+	requireNoTempFile(t, dir)
+}
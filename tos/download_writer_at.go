@@ -0,0 +1,182 @@
+package tos
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+type writerAtRange struct {
+	offset int64
+	size   int64
+	crc    uint64
+}
+
+// offsetWriter writes to io.WriterAt sequentially from offset
+type offsetWriter struct {
+	base   io.WriterAt
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.base.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+// DownloadToWriterAt downloads an object into io.WriterAt, ranges of the object are downloaded in parallel
+// and written at their offsets. Each range is retried independently, and sent with If-Match of ETag got by HeadObjectV2,
+// so that a replaced object fails the download instead of mixing content.
+// CRC64 of the object is checked with ranges if CRC is enabled and the object has CRC64.
+func (cli *ClientV2) DownloadToWriterAt(ctx context.Context, input *DownloadToWriterAtInput) (*DownloadToWriterAtOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	if input.WriterAt == nil {
+		return nil, newTosClientError("tos: WriterAt is nil", nil)
+	}
+	head, err := cli.HeadObjectV2(ctx, &input.HeadObjectV2Input)
+	if err != nil {
+		return nil, err
+	}
+	partSize, partCount, err := CalcPartSize(head.ContentLength, input.PartSize)
+	if err != nil {
+		return nil, err
+	}
+	ranges := make([]writerAtRange, 0, partCount)
+	for i := 0; i < partCount; i++ {
+		offset := int64(i) * partSize
+		size := partSize
+		if offset+size > head.ContentLength {
+			size = head.ContentLength - offset
+		}
+		ranges = append(ranges, writerAtRange{offset: offset, size: size})
+	}
+	taskNum := input.TaskNum
+	if taskNum < 1 {
+		taskNum = 1
+	}
+	if taskNum > len(ranges) {
+		taskNum = len(ranges)
+	}
+	progress := newTransferProgress(input.DataTransferListener, head.ContentLength)
+	progress.start(0, partSize)
+
+	if err = cli.downloadWriterAtRanges(ctx, input, head.ETag, ranges, taskNum, progress); err != nil {
+		progress.fail()
+		return nil, err
+	}
+	if cli.enableCRC && head.Header.Get(HeaderHashCrc64ecma) != "" {
+		var actual uint64
+		for _, r := range ranges {
+			actual = CRC64Combine(actual, r.crc, uint64(r.size))
+		}
+		if actual != head.HashCrc64ecma {
+			progress.fail()
+			return nil, newChecksumMismatchError(head.RequestID, head.HashCrc64ecma, actual)
+		}
+	}
+	progress.succeed()
+	return &DownloadToWriterAtOutput{HeadObjectV2Output: *head}, nil
+}
+
+// downloadWriterAtRanges downloads ranges with taskNum goroutines, and stops on the first error
+func (cli *ClientV2) downloadWriterAtRanges(ctx context.Context, input *DownloadToWriterAtInput, etag string,
+	ranges []writerAtRange, taskNum int, progress *transferProgress) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		jobs     = make(chan int)
+		retry    = newPartRetryPolicy(input.MaxPartRetries, input.PartRetryBackoffBase, input.PartRetryBackoffCap)
+		queue    = &transferQueue{}
+	)
+	for i := 0; i < taskNum; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				r := &ranges[index]
+				err := retry.Run(ctx, func(ctx context.Context) (err error) {
+					if input.TransferManager == nil {
+						r.crc, err = cli.downloadWriterAtRange(ctx, input, etag, *r, progress)
+						return err
+					}
+					if runErr := input.TransferManager.run(ctx, queue, func() {
+						r.crc, err = cli.downloadWriterAtRange(ctx, input, etag, *r, progress)
+					}); runErr != nil {
+						return runErr
+					}
+					return err
+				})
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						// interrupt ranges in flight
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+schedule:
+	for index := range ranges {
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			break schedule
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return newTosClientError(err.Error(), err)
+	}
+	return nil
+}
+
+// downloadWriterAtRange downloads a range once and returns its CRC64, every attempt writes the range from its offset
+func (cli *ClientV2) downloadWriterAtRange(ctx context.Context, input *DownloadToWriterAtInput, etag string,
+	r writerAtRange, progress *transferProgress) (uint64, error) {
+	output, err := cli.GetObjectV2(ctx, &GetObjectV2Input{
+		Bucket:        input.Bucket,
+		Key:           input.Key,
+		VersionID:     input.VersionID,
+		IfMatch:       etag,
+		SSECAlgorithm: input.SSECAlgorithm,
+		SSECKey:       input.SSECKey,
+		SSECKeyMD5:    input.SSECKeyMD5,
+		Range:         &HTTPRange{Start: r.offset, End: r.offset + r.size - 1},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer output.Content.Close()
+	listened := &readCloserWithProgress{base: output.Content, progress: progress}
+	var wrapped io.ReadCloser = listened
+	if input.RateLimiter != nil {
+		wrapped = &ReadCloserWithLimiter{limiter: input.RateLimiter, base: wrapped}
+	}
+	checker := NewCRC(DefaultCrcTable(), 0)
+	wrapped = &readCloserWithCRC{checker: checker, base: wrapped}
+	written, err := io.Copy(&offsetWriter{base: input.WriterAt, offset: r.offset}, wrapped)
+	if err == nil && written != r.size {
+		err = newTosClientError(fmt.Sprintf("tos: range %d-%d of the object want length %d but get %d, request id: %s",
+			r.offset, r.offset+r.size-1, r.size, written, output.RequestID), nil)
+	}
+	if err != nil {
+		// the range will be downloaded again on retrying
+		progress.rollback(listened.read)
+		return 0, err
+	}
+	return checker.Sum64(), nil
+}
@@ -0,0 +1,141 @@
+package tos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bufferWriterAt is an io.WriterAt over a preallocated buffer
+type bufferWriterAt []byte
+
+func (b bufferWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	return copy(b[off:], p), nil
+}
+
+func newWriterAtTransport(t *testing.T, data []byte, crc *string, failures map[int64]int) *mockTransport {
+	var lock sync.Mutex
+	return &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodHead {
+			res := newMockResponse(http.StatusOK, "")()
+			res.Body = nil
+			res.Header.Set(HeaderContentLength, strconv.Itoa(len(data)))
+			res.Header.Set(HeaderETag, "\"etag\"")
+			res.Header.Set(HeaderHashCrc64ecma, *crc)
+			return res
+		}
+		assert.Equal(t, "\"etag\"", req.Header.Get("If-Match"))
+		var start, end int64
+		fmt.Sscanf(req.Header.Get(HeaderRange), "bytes=%d-%d", &start, &end)
+		lock.Lock()
+		fail := failures[start] > 0
+		failures[start]--
+		lock.Unlock()
+		if fail {
+			return newMockResponse(http.StatusInternalServerError, `{"Code":"InternalError"}`)()
+		}
+		res := newMockResponse(http.StatusPartialContent, string(data[start:end+1]))()
+		res.Header.Set(HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		return res
+	}}
+}
+
+func TestDownloadToWriterAt(t *testing.T) {
+	data := make([]byte, 2*MinPartSize+MinPartSize/2)
+	rand.Read(data)
+	crc := strconv.FormatUint(crc64Of(data), 10)
+	// the second range fails 3 times, more than retries of the client, and is retried as a range
+	failures := map[int64]int{MinPartSize: 3}
+	transport := newWriterAtTransport(t, data, &crc, failures)
+	client := newMockClient(t, transport)
+
+	buf := make(bufferWriterAt, len(data))
+	listener := &recordingListener{}
+	output, err := client.DownloadToWriterAt(context.Background(), &DownloadToWriterAtInput{
+		HeadObjectV2Input:    HeadObjectV2Input{Bucket: "bucket", Key: "key"},
+		WriterAt:             buf,
+		TaskNum:              3,
+		PartRetryBackoffBase: 1,
+		DataTransferListener: listener,
+	})
+	require.Nil(t, err)
+	require.Equal(t, int64(len(data)), output.ContentLength)
+	require.Equal(t, data, []byte(buf))
+	// head + 3 ranges + 3 failures
+	require.Len(t, transport.recorded(), 7)
+	last := listener.statuses[len(listener.statuses)-1]
+	require.Equal(t, int64(len(data)), last.ConsumedBytes)
+
+	crc = "1"
+	_, err = client.DownloadToWriterAt(context.Background(), &DownloadToWriterAtInput{
+		HeadObjectV2Input: HeadObjectV2Input{Bucket: "bucket", Key: "key"},
+		WriterAt:          make(bufferWriterAt, len(data)),
+	})
+	_, ok := err.(*TosClientError).Cause.(*ChecksumError)
+	require.True(t, ok)
+
+	// ranges fail without retrying
+	crc = strconv.FormatUint(crc64Of(data), 10)
+	failures[0] = 3
+	_, err = client.DownloadToWriterAt(context.Background(), &DownloadToWriterAtInput{
+		HeadObjectV2Input: HeadObjectV2Input{Bucket: "bucket", Key: "key"},
+		WriterAt:          make(bufferWriterAt, len(data)),
+		MaxPartRetries:    -1,
+	})
+	require.Equal(t, http.StatusInternalServerError, StatusCode(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.DownloadToWriterAt(ctx, &DownloadToWriterAtInput{
+		HeadObjectV2Input: HeadObjectV2Input{Bucket: "bucket", Key: "key"},
+		WriterAt:          make(bufferWriterAt, len(data)),
+	})
+	require.NotNil(t, err)
+
+	_, err = client.DownloadToWriterAt(context.Background(), &DownloadToWriterAtInput{
+		HeadObjectV2Input: HeadObjectV2Input{Bucket: "bucket", Key: "key"},
+	})
+	require.NotNil(t, err)
+}
+
+func TestDownloadToWriterAtEmptyObject(t *testing.T) {
+	crc := "0"
+	transport := newWriterAtTransport(t, nil, &crc, map[int64]int{})
+	client := newMockClient(t, transport)
+	_, err := client.DownloadToWriterAt(context.Background(), &DownloadToWriterAtInput{
+		HeadObjectV2Input: HeadObjectV2Input{Bucket: "bucket", Key: "key"},
+		WriterAt:          make(bufferWriterAt, 0),
+	})
+	require.Nil(t, err)
+	require.Len(t, transport.recorded(), 1)
+}
+
+func TestDownloadToWriterAtPreconditionFailed(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodHead {
+			res := newMockResponse(http.StatusOK, "")()
+			res.Body = nil
+			res.Header.Set(HeaderContentLength, "1024")
+			res.Header.Set(HeaderETag, "\"etag\"")
+			return res
+		}
+		return newMockResponse(http.StatusPreconditionFailed, `{"Code":"PreconditionFailed"}`)()
+	}}
+	client := newMockClient(t, transport)
+	_, err := client.DownloadToWriterAt(context.Background(), &DownloadToWriterAtInput{
+		HeadObjectV2Input:    HeadObjectV2Input{Bucket: "bucket", Key: "key"},
+		WriterAt:             make(bufferWriterAt, 1024),
+		PartRetryBackoffBase: 1,
+	})
+	_, ok := err.(*PreconditionFailedError)
+	require.True(t, ok)
+	// head + the range without retrying
+	require.Len(t, transport.recorded(), 2)
+}
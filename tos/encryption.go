@@ -0,0 +1,76 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+)
+
+// PutBucketEncryption set default encryption of bucket, objects put without encryption headers
+// are encrypted with the rule.
+func (cli *ClientV2) PutBucketEncryption(ctx context.Context, input *PutBucketEncryptionInput) (*PutBucketEncryptionOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if err := isValidBucketEncryption(&input.Rule); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("PutBucketEncryptionInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("encryption", "").
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketEncryptionOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketEncryption get default encryption of bucket,
+// *EncryptionNotConfiguredError is returned if the bucket has no default encryption.
+func (cli *ClientV2) GetBucketEncryption(ctx context.Context, input *GetBucketEncryptionInput) (*GetBucketEncryptionOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("encryption", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, newEncryptionNotConfiguredError(err)
+	}
+	defer res.Close()
+	output := GetBucketEncryptionOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// DeleteBucketEncryption delete default encryption of bucket
+func (cli *ClientV2) DeleteBucketEncryption(ctx context.Context, input *DeleteBucketEncryptionInput) (*DeleteBucketEncryptionOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("encryption", "").
+		Request(ctx, http.MethodDelete, nil, cli.roundTripper(http.StatusNoContent))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &DeleteBucketEncryptionOutput{RequestInfo: res.RequestInfo()}, nil
+}
@@ -0,0 +1,60 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/codes"
+)
+
+// kmsEncryption is the serialized form of the kms rule in TestBucketEncryption
+const kmsEncryption = `{"Rule":{"ApplyServerSideEncryptionByDefault":{"SSEAlgorithm":"kms","KMSMasterKeyID":"key-id"}}}`
+
+func TestBucketEncryption(t *testing.T) {
+	noSuchEncryption := newMockResponse(http.StatusNotFound, `{"Code":"NoSuchEncryptionConfiguration"}`)
+	transport := &mockTransport{responses: []func() *Response{
+		noSuchEncryption,
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, kmsEncryption),
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusNoContent, ""),
+		noSuchEncryption,
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.GetBucketEncryption(ctx, &GetBucketEncryptionInput{Bucket: "bucket"})
+	_, ok := err.(*EncryptionNotConfiguredError)
+	require.True(t, ok)
+	require.Equal(t, codes.NoSuchEncryptionConfiguration, Code(err))
+	require.Equal(t, http.StatusNotFound, StatusCode(err))
+
+	rule := BucketEncryptionRule{ApplyServerSideEncryptionByDefault: ApplyServerSideEncryptionByDefault{SSEAlgorithm: "kms", KMSMasterKeyID: "key-id"}}
+	_, err = client.PutBucketEncryption(ctx, &PutBucketEncryptionInput{Bucket: "bucket", Rule: rule})
+	require.Nil(t, err)
+	require.JSONEq(t, kmsEncryption, requestBody(t, transport.recorded()[1]))
+	get, err := client.GetBucketEncryption(ctx, &GetBucketEncryptionInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, rule, get.Rule)
+
+	_, err = client.PutBucketEncryption(ctx, &PutBucketEncryptionInput{Bucket: "bucket",
+		Rule: BucketEncryptionRule{ApplyServerSideEncryptionByDefault: ApplyServerSideEncryptionByDefault{SSEAlgorithm: "AES256"}}})
+	require.Nil(t, err)
+	require.JSONEq(t, `{"Rule":{"ApplyServerSideEncryptionByDefault":{"SSEAlgorithm":"AES256"}}}`, requestBody(t, transport.recorded()[3]))
+
+	_, err = client.DeleteBucketEncryption(ctx, &DeleteBucketEncryptionInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	_, err = client.GetBucketEncryption(ctx, &GetBucketEncryptionInput{Bucket: "bucket"})
+	_, ok = err.(*EncryptionNotConfiguredError)
+	require.True(t, ok)
+
+	requests := len(transport.recorded())
+	for _, invalid := range []ApplyServerSideEncryptionByDefault{{}, {SSEAlgorithm: "SM4"}, {SSEAlgorithm: "AES256", KMSMasterKeyID: "key-id"}} {
+		_, err = client.PutBucketEncryption(ctx, &PutBucketEncryptionInput{Bucket: "bucket",
+			Rule: BucketEncryptionRule{ApplyServerSideEncryptionByDefault: invalid}})
+		require.NotNil(t, err)
+	}
+	require.Equal(t, requests, len(transport.recorded()))
+}
@@ -15,8 +15,21 @@ const (
 type StorageClassType string
 
 const (
-	StorageClassStandard StorageClassType = "STANDARD"
-	StorageClassIa       StorageClassType = "IA"
+	StorageClassStandard           StorageClassType = "STANDARD"
+	StorageClassIa                 StorageClassType = "IA"
+	StorageClassArchiveFr          StorageClassType = "ARCHIVE_FR"
+	StorageClassIntelligentTiering StorageClassType = "INTELLIGENT_TIERING"
+	StorageClassColdArchive        StorageClassType = "COLD_ARCHIVE"
+	StorageClassArchive            StorageClassType = "ARCHIVE"
+	StorageClassDeepColdArchive    StorageClassType = "DEEP_COLD_ARCHIVE"
+)
+
+type TierType string
+
+const (
+	TierStandard  TierType = "Standard"
+	TierExpedited TierType = "Expedited"
+	TierBulk      TierType = "Bulk"
 )
 
 type MetadataDirectiveType string
@@ -29,6 +42,32 @@ const (
 	MetadataDirectiveCopy MetadataDirectiveType = "COPY"
 )
 
+type TaggingDirectiveType string
+
+const (
+	// TaggingDirectiveReplace replace source object tags with Tagging when calling CopyObject
+	TaggingDirectiveReplace TaggingDirectiveType = "REPLACE"
+
+	// TaggingDirectiveCopy copy source object tags when calling CopyObject
+	TaggingDirectiveCopy TaggingDirectiveType = "COPY"
+)
+
+type ObjectLockModeType string
+
+const (
+	// ObjectLockModeGovernance the object can be deleted or overwritten before RetainUntilDate with BypassGovernanceRetention
+	ObjectLockModeGovernance ObjectLockModeType = "GOVERNANCE"
+	// ObjectLockModeCompliance the object can not be deleted or overwritten by anyone before RetainUntilDate
+	ObjectLockModeCompliance ObjectLockModeType = "COMPLIANCE"
+)
+
+type LegalHoldStatusType string
+
+const (
+	LegalHoldStatusOn  LegalHoldStatusType = "ON"
+	LegalHoldStatusOff LegalHoldStatusType = "OFF"
+)
+
 type AzRedundancyType string
 
 const (
@@ -36,6 +75,119 @@ const (
 	AzRedundancyMultiAz  AzRedundancyType = "multi-az"
 )
 
+type BucketType string
+
+const (
+	// BucketTypeFNS flat namespace bucket
+	BucketTypeFNS BucketType = "fns"
+	// BucketTypeHNS hierarchical namespace bucket, directories are real objects and can be renamed atomically
+	BucketTypeHNS BucketType = "hns"
+)
+
+type RedirectType string
+
+const (
+	// RedirectTypeMirror fetch from source and return to client when object not found
+	RedirectTypeMirror RedirectType = "Mirror"
+	// RedirectTypeAsync return 302 to client and fetch from source asynchronously
+	RedirectTypeAsync RedirectType = "Async"
+)
+
+type VersioningStatusType string
+
+const (
+	// VersioningStatusNotSet versioning has never been enabled on the bucket
+	VersioningStatusNotSet    VersioningStatusType = ""
+	VersioningStatusEnabled   VersioningStatusType = "Enabled"
+	VersioningStatusSuspended VersioningStatusType = "Suspended"
+)
+
+type ProtocolType string
+
+const (
+	ProtocolHttp  ProtocolType = "http"
+	ProtocolHttps ProtocolType = "https"
+)
+
+type EventType string
+
+const (
+	EventObjectCreatedAll                     EventType = "tos:ObjectCreated:*"
+	EventObjectCreatedPut                     EventType = "tos:ObjectCreated:Put"
+	EventObjectCreatedPost                    EventType = "tos:ObjectCreated:Post"
+	EventObjectCreatedCopy                    EventType = "tos:ObjectCreated:Copy"
+	EventObjectCreatedAppend                  EventType = "tos:ObjectCreated:Append"
+	EventObjectCreatedCompleteMultipartUpload EventType = "tos:ObjectCreated:CompleteMultipartUpload"
+	EventObjectCreatedFetch                   EventType = "tos:ObjectCreated:Fetch"
+	EventObjectRemovedAll                     EventType = "tos:ObjectRemoved:*"
+	EventObjectRemovedDelete                  EventType = "tos:ObjectRemoved:Delete"
+	EventObjectRemovedDeleteMarkerCreated     EventType = "tos:ObjectRemoved:DeleteMarkerCreated"
+	EventObjectRestoreAll                     EventType = "tos:ObjectRestore:*"
+	EventObjectRestorePost                    EventType = "tos:ObjectRestore:Post"
+	EventObjectRestoreCompleted               EventType = "tos:ObjectRestore:Completed"
+)
+
+type FilterRuleNameType string
+
+const (
+	FilterRuleNamePrefix FilterRuleNameType = "prefix"
+	FilterRuleNameSuffix FilterRuleNameType = "suffix"
+)
+
+type InventoryFrequencyType string
+
+const (
+	InventoryFrequencyDaily  InventoryFrequencyType = "Daily"
+	InventoryFrequencyWeekly InventoryFrequencyType = "Weekly"
+)
+
+type InventoryIncludedObjectVersionsType string
+
+const (
+	InventoryIncludedObjectVersionsAll     InventoryIncludedObjectVersionsType = "All"
+	InventoryIncludedObjectVersionsCurrent InventoryIncludedObjectVersionsType = "Current"
+)
+
+type InventoryFormatType string
+
+const (
+	InventoryFormatCsv InventoryFormatType = "CSV"
+)
+
+type InventoryOptionalFieldType string
+
+const (
+	InventoryOptionalFieldSize                InventoryOptionalFieldType = "Size"
+	InventoryOptionalFieldLastModifiedDate    InventoryOptionalFieldType = "LastModifiedDate"
+	InventoryOptionalFieldETag                InventoryOptionalFieldType = "ETag"
+	InventoryOptionalFieldStorageClass        InventoryOptionalFieldType = "StorageClass"
+	InventoryOptionalFieldIsMultipartUploaded InventoryOptionalFieldType = "IsMultipartUploaded"
+	InventoryOptionalFieldEncryptionStatus    InventoryOptionalFieldType = "EncryptionStatus"
+	InventoryOptionalFieldCRC64               InventoryOptionalFieldType = "CRC64"
+	InventoryOptionalFieldReplicationStatus   InventoryOptionalFieldType = "ReplicationStatus"
+)
+
+type PayerType string
+
+const (
+	PayerBucketOwner PayerType = "BucketOwner"
+	PayerRequester   PayerType = "Requester"
+)
+
+type StatusType string
+
+const (
+	StatusEnabled  StatusType = "Enabled"
+	StatusDisabled StatusType = "Disabled"
+)
+
+type AccessTierType string
+
+const (
+	AccessTierInfrequent AccessTierType = "INFREQUENT"
+	AccessTierArchiveFr  AccessTierType = "ARCHIVEFR"
+)
+
 type PermissionType string
 
 const (
@@ -67,6 +219,7 @@ const (
 	DataTransferRW      DataTransferType = 2
 	DataTransferSucceed DataTransferType = 3
 	DataTransferFailed  DataTransferType = 4
+	DataTransferRetried DataTransferType = 5 // data is rewound to be R/W again
 )
 
 type HttpMethodType string
@@ -86,7 +239,7 @@ const (
 	UploadEventCreateMultipartUploadFailed    UploadEventType = 2
 	UploadEventUploadPartSucceed              UploadEventType = 3
 	UploadEventUploadPartFailed               UploadEventType = 4
-	UploadEventUploadPartAborted              UploadEventType = 5 // The task needs to be interrupted in case of 403, 404, 405 errors
+	UploadEventUploadPartAborted              UploadEventType = 5 // The task needs to be interrupted in case of 4xx errors other than 408 and 429
 	UploadEventCompleteMultipartUploadSucceed UploadEventType = 6
 	UploadEventCompleteMultipartUploadFailed  UploadEventType = 7
 )
@@ -98,7 +251,52 @@ const (
 	DownloadEventCreateTempFileFailed  DownloadEventType = 2
 	DownloadEventDownloadPartSucceed   DownloadEventType = 3
 	DownloadEventDownloadPartFailed    DownloadEventType = 4
-	DownloadEventDownloadPartAborted   DownloadEventType = 5 // The task needs to be interrupted in case of 403, 404, 405 errors
+	DownloadEventDownloadPartAborted   DownloadEventType = 5 // The task needs to be interrupted in case of 4xx errors other than 408 and 429
 	DownloadEventRenameTempFileSucceed DownloadEventType = 6
 	DownloadEventRenameTempFileFailed  DownloadEventType = 7
 )
+
+type CopyEventType int
+
+const (
+	CopyEventCreateMultipartUploadSucceed   CopyEventType = 1
+	CopyEventCreateMultipartUploadFailed    CopyEventType = 2
+	CopyEventUploadPartCopySucceed          CopyEventType = 3
+	CopyEventUploadPartCopyFailed           CopyEventType = 4
+	CopyEventUploadPartCopyAborted          CopyEventType = 5 // The task needs to be interrupted in case of 4xx errors other than 408 and 429
+	CopyEventCompleteMultipartUploadSucceed CopyEventType = 6
+	CopyEventCompleteMultipartUploadFailed  CopyEventType = 7
+)
+
+type CompressionType string
+
+const (
+	CompressionNone CompressionType = "NONE"
+	CompressionGzip CompressionType = "GZIP"
+)
+
+type FileHeaderInfoType string
+
+const (
+	// FileHeaderInfoUse the first line is header, columns can be referred by header names in expression
+	FileHeaderInfoUse FileHeaderInfoType = "USE"
+	// FileHeaderInfoIgnore the first line is header and ignored
+	FileHeaderInfoIgnore FileHeaderInfoType = "IGNORE"
+	// FileHeaderInfoNone the first line is data
+	FileHeaderInfoNone FileHeaderInfoType = "NONE"
+)
+
+type JSONType string
+
+const (
+	JSONTypeDocument JSONType = "DOCUMENT"
+	JSONTypeLines    JSONType = "LINES"
+)
+
+type SelectEventType int
+
+const (
+	SelectEventProgress SelectEventType = 1
+	SelectEventStats    SelectEventType = 2
+	SelectEventEnd      SelectEventType = 3
+)
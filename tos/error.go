@@ -6,10 +6,14 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/codes"
 )
 
 var InputIsNilClientError = newTosClientError("input is nil. ", nil)
 var InputInvalidClientError = newTosClientError("input data is invalid. ", nil)
+var CanceledClientError = newTosClientError("task is canceled by CancelHook. ", nil)
 
 type TosError struct {
 	Message string
@@ -31,7 +35,24 @@ func newTosClientError(message string, cause error) *TosClientError {
 
 type TosClientError struct {
 	TosError
-	Cause error
+	Cause    error
+	Attempts int // number of attempts made by the request, including retries
+}
+
+// Timeout reports whether the error is caused by timeout, e.g. network timeout and OperationTimeout
+func (e *TosClientError) Timeout() bool {
+	t, ok := e.Cause.(interface{ Timeout() bool })
+	return ok && t.Timeout()
+}
+
+// newChecksumMismatchError returns error of CRC64 of the whole object mismatching with CRC64 combined from parts
+// or computed from data downloaded, the cause is *ChecksumError
+func newChecksumMismatchError(requestID string, expected, actual uint64) *TosClientError {
+	return newTosClientError("tos: crc64 of the whole object mismatch", &ChecksumError{
+		RequestID:        requestID,
+		ExpectedChecksum: strconv.FormatUint(expected, 10),
+		ActualChecksum:   strconv.FormatUint(actual, 10),
+	})
 }
 
 // try to unmarshal server error from response
@@ -66,6 +87,237 @@ type TosServerError struct {
 	Code        string `json:"Code,omitempty"`
 	HostID      string `json:"HostID,omitempty"`
 	Resource    string `json:"Resource,omitempty"`
+	Attempts    int    `json:"Attempts,omitempty"` // number of attempts made by the request, including retries
+}
+
+func (e *TosServerError) serverError() *TosServerError {
+	return e
+}
+
+// asServerError returns *TosServerError of err, which is err itself or embedded by typed errors
+// such as *PreconditionFailedError, so that they are classified the same as *TosServerError.
+func asServerError(err error) (*TosServerError, bool) {
+	if e, ok := err.(interface{ serverError() *TosServerError }); ok {
+		return e.serverError(), true
+	}
+	return nil, false
+}
+
+// AppendPositionError is returned by AppendObjectV2 if Offset is not equal to the length of the object,
+// NextAppendOffset is the offset expected by server, so that the append can be retried without HeadObject.
+type AppendPositionError struct {
+	TosServerError
+	NextAppendOffset int64
+}
+
+// newAppendPositionError converts 409 PositionNotEqualToLength error to *AppendPositionError,
+// other errors are returned as is
+func newAppendPositionError(err error) error {
+	se, ok := err.(*TosServerError)
+	if !ok || se.StatusCode != http.StatusConflict || se.Code != codes.PositionNotEqualToLength || se.Header == nil {
+		return err
+	}
+	nextOffset, perr := strconv.ParseInt(se.Header.Get(HeaderNextAppendOffset), 10, 64)
+	if perr != nil {
+		return err
+	}
+	return &AppendPositionError{TosServerError: *se, NextAppendOffset: nextOffset}
+}
+
+// ModifyPositionError is returned by ModifyObjectV2 if Offset conflicts with the length of the object,
+// NextModifyOffset is the length of the object returned by server, -1 if it is not returned.
+type ModifyPositionError struct {
+	TosServerError
+	NextModifyOffset int64
+}
+
+// newModifyPositionError converts 409 OffsetNotMatched or PositionNotEqualToLength error to *ModifyPositionError,
+// other errors are returned as is
+func newModifyPositionError(err error) error {
+	se, ok := err.(*TosServerError)
+	if !ok || se.StatusCode != http.StatusConflict ||
+		(se.Code != codes.OffsetNotMatched && se.Code != codes.PositionNotEqualToLength) {
+		return err
+	}
+	positionErr := &ModifyPositionError{TosServerError: *se, NextModifyOffset: -1}
+	if se.Header != nil {
+		if nextOffset, perr := strconv.ParseInt(se.Header.Get(HeaderNextModifyOffset), 10, 64); perr == nil {
+			positionErr.NextModifyOffset = nextOffset
+		}
+	}
+	return positionErr
+}
+
+// InvalidRangeError is returned by GetObjectV2 if the requested range is not satisfiable,
+// ObjectSize is the size of the object parsed from Content-Range, -1 if it is not returned.
+type InvalidRangeError struct {
+	TosServerError
+	ObjectSize int64
+}
+
+// newInvalidRangeError converts 416 error to *InvalidRangeError, other errors are returned as is
+func newInvalidRangeError(err error) error {
+	se, ok := err.(*TosServerError)
+	if !ok || se.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		return err
+	}
+	rangeErr := &InvalidRangeError{TosServerError: *se, ObjectSize: -1}
+	if se.Header != nil {
+		if cr, perr := parseContentRange(se.Header.Get(HeaderContentRange)); perr == nil {
+			rangeErr.ObjectSize = cr.Total
+		}
+	}
+	return rangeErr
+}
+
+// FetchTaskError is returned by FetchObjectV2 if the fetch failed, e.g. the source object is not found
+// or its MD5 mismatches with ContentMD5, and by GetFetchTaskV2 if the task failed.
+type FetchTaskError struct {
+	TosServerError
+	TaskID string // empty for FetchObjectV2
+	State  string // FetchTaskStateFailed
+}
+
+// newFetchTaskError converts errors describing the failed fetch to *FetchTaskError, i.e. the source object is not found
+// or can not be accessed, or its MD5 mismatches. Other errors, e.g. AccessDenied of the bucket, throttling and 5xx,
+// are returned as is.
+func newFetchTaskError(err error) error {
+	se, ok := err.(*TosServerError)
+	if !ok {
+		return err
+	}
+	switch se.Code {
+	case codes.SourceObjectNotFound, codes.SourceObjectAccessDenied, codes.BadDigest:
+		return &FetchTaskError{TosServerError: *se, State: FetchTaskStateFailed}
+	}
+	return err
+}
+
+// RestoreInProgressError is returned by RestoreObjectV2 if the object is being restored,
+// poll loops can treat it as success and wait for RestoreInProgress of HeadObjectV2 output to be false.
+type RestoreInProgressError struct {
+	TosServerError
+}
+
+// newRestoreInProgressError converts 409 RestoreAlreadyInProgress error to *RestoreInProgressError,
+// other errors are returned as is
+func newRestoreInProgressError(err error) error {
+	se, ok := err.(*TosServerError)
+	if !ok || se.StatusCode != http.StatusConflict || se.Code != codes.RestoreAlreadyInProgress {
+		return err
+	}
+	return &RestoreInProgressError{TosServerError: *se}
+}
+
+// NotModifiedError is returned by HeadObjectV2 and GetObjectV2 with status code 304,
+// if the object is not modified according to IfNoneMatch or IfModifiedSince.
+type NotModifiedError struct {
+	TosServerError
+}
+
+// PreconditionFailedError is returned by HeadObjectV2 and GetObjectV2 with status code 412,
+// if the object doesn't match IfMatch or is modified after IfUnmodifiedSince.
+type PreconditionFailedError struct {
+	TosServerError
+}
+
+// ObjectChangedError is returned by Read of GetObjectV2 content if the object is replaced while resuming the download,
+// content read before is from the original object and must be discarded.
+type ObjectChangedError struct {
+	TosServerError
+	ETag string // ETag of the original object
+}
+
+// newConditionalError converts 304 and 412 errors to *NotModifiedError and *PreconditionFailedError,
+// other errors are returned as is
+func newConditionalError(err error) error {
+	se, ok := err.(*TosServerError)
+	if !ok {
+		return err
+	}
+	switch se.StatusCode {
+	case http.StatusNotModified:
+		return &NotModifiedError{TosServerError: *se}
+	case http.StatusPreconditionFailed:
+		return &PreconditionFailedError{TosServerError: *se}
+	}
+	return err
+}
+
+// WebsiteNotConfiguredError is returned by GetBucketWebsite if the bucket has no website configuration,
+// so that an absent configuration can be told from a failed request.
+type WebsiteNotConfiguredError struct {
+	TosServerError
+}
+
+// newWebsiteNotConfiguredError converts 404 NoSuchWebsiteConfiguration error to *WebsiteNotConfiguredError,
+// other errors are returned as is
+func newWebsiteNotConfiguredError(err error) error {
+	se, ok := err.(*TosServerError)
+	if !ok || se.StatusCode != http.StatusNotFound || se.Code != codes.NoSuchWebsiteConfiguration {
+		return err
+	}
+	return &WebsiteNotConfiguredError{TosServerError: *se}
+}
+
+// EncryptionNotConfiguredError is returned by GetBucketEncryption if the bucket has no default encryption
+type EncryptionNotConfiguredError struct {
+	TosServerError
+}
+
+// newEncryptionNotConfiguredError converts 404 NoSuchEncryptionConfiguration error to *EncryptionNotConfiguredError,
+// other errors are returned as is
+func newEncryptionNotConfiguredError(err error) error {
+	se, ok := err.(*TosServerError)
+	if !ok || se.StatusCode != http.StatusNotFound || se.Code != codes.NoSuchEncryptionConfiguration {
+		return err
+	}
+	return &EncryptionNotConfiguredError{TosServerError: *se}
+}
+
+// CustomDomainNotFoundError is returned by DeleteBucketCustomDomain if the domain is not bound to the bucket
+type CustomDomainNotFoundError struct {
+	TosServerError
+	Domain string
+}
+
+// newCustomDomainNotFoundError converts 404 error other than NoSuchBucket to *CustomDomainNotFoundError,
+// other errors are returned as is
+func newCustomDomainNotFoundError(err error, domain string) error {
+	se, ok := err.(*TosServerError)
+	if !ok || se.StatusCode != http.StatusNotFound || se.Code == codes.NoSuchBucket {
+		return err
+	}
+	return &CustomDomainNotFoundError{TosServerError: *se, Domain: domain}
+}
+
+// BucketNotFoundError is returned by HeadBucket of ClientV2 with status code 404
+type BucketNotFoundError struct {
+	TosServerError
+	Bucket string
+}
+
+// BucketAccessDeniedError is returned by HeadBucket of ClientV2 with status code 403,
+// the bucket exists but the credentials have no permission on it.
+type BucketAccessDeniedError struct {
+	TosServerError
+	Bucket string
+}
+
+// newHeadBucketError converts 404 and 403 errors to *BucketNotFoundError and *BucketAccessDeniedError,
+// other errors are returned as is
+func newHeadBucketError(err error, bucket string) error {
+	se, ok := err.(*TosServerError)
+	if !ok {
+		return err
+	}
+	switch se.StatusCode {
+	case http.StatusNotFound:
+		return &BucketNotFoundError{TosServerError: *se, Bucket: bucket}
+	case http.StatusForbidden:
+		return &BucketAccessDeniedError{TosServerError: *se, Bucket: bucket}
+	}
+	return err
 }
 
 type Error struct {
@@ -82,9 +334,19 @@ func (e *Error) Error() string {
 		e.StatusCode, e.Code, e.Message, e.RequestID, e.HostID)
 }
 
-// Code return error code saved in TosServerError
+// isNotFound reports whether err means the bucket or object does not exist,
+// HEAD requests return 404 without error code in body
+func isNotFound(err error) bool {
+	if StatusCode(err) == http.StatusNotFound {
+		return true
+	}
+	code := Code(err)
+	return code == "NoSuchKey" || code == "NoSuchBucket"
+}
+
+// Code return error code saved in TosServerError, including typed errors embedding it
 func Code(err error) string {
-	if er, ok := err.(*TosServerError); ok {
+	if er, ok := asServerError(err); ok {
 		return er.Code
 	}
 	return ""
@@ -99,7 +361,7 @@ func StatueCode(err error) int {
 
 // StatusCode return status code saved in TosServerError or UnexpectedStatusCodeError
 func StatusCode(err error) int {
-	if er, ok := err.(*TosServerError); ok {
+	if er, ok := asServerError(err); ok {
 		return er.StatusCode
 	}
 	if er, ok := err.(*UnexpectedStatusCodeError); ok {
@@ -109,9 +371,10 @@ func StatusCode(err error) int {
 }
 
 func RequestID(err error) string {
+	if er, ok := asServerError(err); ok {
+		return er.RequestID
+	}
 	switch ev := err.(type) {
-	case *TosServerError:
-		return ev.RequestID
 	case *UnexpectedStatusCodeError:
 		return ev.RequestID
 	case *ChecksumError:
@@ -199,6 +462,7 @@ func checkError(res *Response, readBody bool, okCode int, okCodes ...int) error
 // StatusCodeClassifier classifies Errors.
 // If the error is nil, it returns NoRetry;
 // if the error is TimeoutException or can be interpreted as TosServerError, and the StatusCode is 5xx or 529, it returns Retry;
+// if the error is caused by ProxyError, it returns Retry;
 // otherwise, it returns NoRetry.
 type StatusCodeClassifier struct{}
 
@@ -207,7 +471,7 @@ func (classifier StatusCodeClassifier) Classify(err error) retryAction {
 	if err == nil {
 		return NoRetry
 	}
-	e, ok := err.(*TosServerError)
+	e, ok := asServerError(err)
 	if ok {
 		if e.StatusCode >= 500 || e.StatusCode == 429 {
 			return Retry
@@ -217,6 +481,11 @@ func (classifier StatusCodeClassifier) Classify(err error) retryAction {
 	if ok && t.Timeout() {
 		return Retry
 	}
+	if ce, ok := err.(*TosClientError); ok {
+		if _, ok = ce.Cause.(*ProxyError); ok {
+			return Retry
+		}
+	}
 
 	return NoRetry
 }
@@ -232,7 +501,7 @@ func (classifier ServerErrorClassifier) Classify(err error) retryAction {
 	if err == nil {
 		return NoRetry
 	}
-	e, ok := err.(*TosServerError)
+	e, ok := asServerError(err)
 	if ok {
 		if e.StatusCode >= 500 {
 			return Retry
@@ -2,6 +2,7 @@ package tos
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
@@ -64,7 +65,7 @@ func TestStatusRetrierBase(t *testing.T) {
 			4,
 		},
 	}
-	r := newRetryer(exponentialBackoff(3, 10*time.Millisecond))
+	r := newRetryer(RetryPolicy{MaxRetryCount: 3, BackoffBase: 10 * time.Millisecond})
 
 	for _, tt := range tests {
 		work, count := genWork(tt.errs)
@@ -101,10 +102,105 @@ func TestServerErrorRetrierBase(t *testing.T) {
 			2,
 		},
 	}
-	r := newRetryer(exponentialBackoff(3, 10*time.Millisecond))
+	r := newRetryer(RetryPolicy{MaxRetryCount: 3, BackoffBase: 10 * time.Millisecond})
 	for _, tt := range tests {
 		work, count := genWork(tt.errs)
 		r.Run(context.Background(), work, ServerErrorClassifier{})
 		require.Equal(t, tt.expect, *count)
 	}
 }
+
+func TestRetryPolicy(t *testing.T) {
+	r := newRetryer(RetryPolicy{MaxRetryCount: 5, BackoffBase: 10 * time.Millisecond, BackoffCap: 40 * time.Millisecond})
+	require.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond},
+		[]time.Duration{r.calcSleep(0), r.calcSleep(1), r.calcSleep(2), r.calcSleep(10)})
+	for i := 0; i < 100; i++ {
+		r.policy.Jitter = JitterFull
+		require.True(t, r.calcSleep(2) <= 40*time.Millisecond)
+		r.policy.Jitter = JitterEqual
+		sleep := r.calcSleep(2)
+		require.True(t, sleep >= 20*time.Millisecond && sleep <= 40*time.Millisecond)
+	}
+
+	r = newRetryer(RetryPolicy{MaxRetryCount: 3, BackoffBase: 10 * time.Millisecond})
+	work, count := genWork([]error{TosStatus500, TosStatus500, TosStatus500, TosStatus500})
+	err := r.Run(context.Background(), work, StatusCodeClassifier{})
+	require.Equal(t, 4, *count)
+	require.Equal(t, 4, err.(*TosServerError).Attempts)
+	require.Equal(t, 0, TosStatus500.(*TosServerError).Attempts)
+
+	// typed server errors carry the attempts too
+	typed := &FetchTaskError{TosServerError: TosServerError{RequestInfo: RequestInfo{StatusCode: 500}}, TaskID: "task"}
+	r = newRetryer(RetryPolicy{MaxRetryCount: 2, BackoffBase: time.Millisecond})
+	work, count = genWork([]error{typed, typed, typed})
+	err = r.Run(context.Background(), work, StatusCodeClassifier{})
+	require.Equal(t, 3, *count)
+	fetchErr, ok := err.(*FetchTaskError)
+	require.True(t, ok)
+	require.Equal(t, 3, fetchErr.Attempts)
+	require.Equal(t, "task", fetchErr.TaskID)
+	require.Equal(t, 0, typed.Attempts)
+
+	// retries stop before sleeping past the deadline
+	r = newRetryer(RetryPolicy{MaxRetryCount: 3, BackoffBase: 100 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	work, count = genWork([]error{TosStatus500, TosStatus500, TosStatus500, TosStatus500})
+	start := time.Now()
+	err = r.Run(ctx, work, StatusCodeClassifier{})
+	require.Equal(t, 2, *count)
+	require.Equal(t, 2, err.(*TosServerError).Attempts)
+	require.True(t, time.Since(start) < 150*time.Millisecond)
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Now()
+	throttled := func(status int, code, retryAfter string) error {
+		header := make(http.Header)
+		if len(retryAfter) > 0 {
+			header.Set(HeaderRetryAfter, retryAfter)
+		}
+		return &TosServerError{RequestInfo: RequestInfo{StatusCode: status, Header: header}, Code: code}
+	}
+	tests := []struct {
+		err       error
+		wait      time.Duration
+		throttled bool
+	}{
+		{TosStatus500, 0, false},
+		{TosStatus429, 0, true},
+		{throttled(http.StatusTooManyRequests, "", "3"), 3 * time.Second, true},
+		{throttled(http.StatusServiceUnavailable, "", "-1"), 0, true},
+		{throttled(http.StatusServiceUnavailable, "", now.Add(5*time.Second).UTC().Format(http.TimeFormat)), 5 * time.Second, true},
+		{throttled(http.StatusServiceUnavailable, "", now.Add(-time.Hour).UTC().Format(http.TimeFormat)), 0, true},
+		{throttled(http.StatusServiceUnavailable, "", "invalid"), 0, true},
+		{throttled(http.StatusBadRequest, "SlowDown", "1"), time.Second, true},
+		{ClientTimeout, 0, false},
+	}
+	for _, tt := range tests {
+		wait, ok := throttleWait(tt.err, now)
+		require.Equal(t, tt.throttled, ok)
+		require.True(t, wait <= tt.wait && wait > tt.wait-time.Second, "expected %s, actual %s", tt.wait, wait)
+	}
+
+	// Retry-After is honored and capped by MaxRetryAfter
+	var waits []time.Duration
+	r := newRetryer(RetryPolicy{MaxRetryCount: 2, BackoffBase: time.Millisecond, MaxRetryAfter: 100 * time.Millisecond,
+		OnThrottle: func(wait time.Duration, err error) {
+			waits = append(waits, wait)
+		}})
+	work, count := genWork([]error{throttled(http.StatusTooManyRequests, "", "1"), TosStatus500})
+	start := time.Now()
+	require.Nil(t, r.Run(context.Background(), work, StatusCodeClassifier{}))
+	require.Equal(t, 3, *count)
+	require.Equal(t, []time.Duration{100 * time.Millisecond}, waits)
+	require.True(t, time.Since(start) >= 100*time.Millisecond)
+
+	// Retry-After beyond the deadline stops retries
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	work, count = genWork([]error{throttled(http.StatusServiceUnavailable, "", "1")})
+	err := r.Run(ctx, work, StatusCodeClassifier{})
+	require.Equal(t, 1, *count)
+	require.Equal(t, 1, err.(*TosServerError).Attempts)
+}
@@ -133,6 +133,10 @@ func (bkt *Bucket) GetFetchTask(ctx context.Context, input *GetFetchTaskInput, o
 		WithQuery("fetchTask", "").
 		WithQuery("taskId", input.TaskID).
 		Request(ctx, http.MethodGet, nil, bkt.client.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
 
 	out := GetFetchTaskOutput{RequestInfo: res.RequestInfo()}
 	if err = marshalOutput(out.RequestID, res.Body, &out); err != nil {
@@ -140,3 +144,119 @@ func (bkt *Bucket) GetFetchTask(ctx context.Context, input *GetFetchTaskInput, o
 	}
 	return &out, nil
 }
+
+// FetchObjectV2 fetch an object from URL, it is blocked until the object is fetched.
+// If the fetch failed, e.g. the source object is not found, *FetchTaskError is returned.
+func (cli *ClientV2) FetchObjectV2(ctx context.Context, input *FetchObjectV2Input) (*FetchObjectV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("FetchObjectV2Input", &fetchObjectInput{
+		URL:           input.URL,
+		IgnoreSameKey: input.IgnoreSameKey,
+		ContentMD5:    input.ContentMD5,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("fetch", "").
+		WithParams(*input).
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPost, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, newFetchTaskError(err)
+	}
+	defer res.Close()
+
+	output := FetchObjectV2Output{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	output.VersionID = res.Header.Get(HeaderVersionID)
+	output.SSECAlgorithm = res.Header.Get(HeaderSSECustomerAlgorithm)
+	output.SSECKeyMD5 = res.Header.Get(HeaderSSECustomerKeyMD5)
+	return &output, nil
+}
+
+// PutFetchTaskV2 create a task to fetch an object from URL asynchronously, use GetFetchTaskV2 to query its state
+func (cli *ClientV2) PutFetchTaskV2(ctx context.Context, input *PutFetchTaskV2Input) (*PutFetchTaskV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("PutFetchTaskV2Input", &PutFetchTaskInput{
+		URL:           input.URL,
+		Object:        input.Key,
+		IgnoreSameKey: input.IgnoreSameKey,
+		ContentMD5:    input.ContentMD5,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("fetchTask", "").
+		WithParams(*input).
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPost, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	output := PutFetchTaskV2Output{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+type getFetchTaskV2Output struct {
+	GetFetchTaskV2Output
+	Err string `json:"Err,omitempty"` // reason of failed task
+}
+
+// GetFetchTaskV2 query state of the task created by PutFetchTaskV2.
+// If the task failed, *FetchTaskError with the reason is returned.
+func (cli *ClientV2) GetFetchTaskV2(ctx context.Context, input *GetFetchTaskV2Input) (*GetFetchTaskV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if input.TaskID == "" {
+		return nil, newTosClientError("tos: TaskID is empty", nil)
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("fetchTask", "").
+		WithParams(*input).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	output := getFetchTaskV2Output{GetFetchTaskV2Output: GetFetchTaskV2Output{RequestInfo: res.RequestInfo()}}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	if output.State == FetchTaskStateFailed {
+		return nil, &FetchTaskError{
+			TosServerError: TosServerError{
+				TosError:    TosError{Message: "tos: fetch task failed: " + output.Err},
+				RequestInfo: output.RequestInfo,
+			},
+			TaskID: input.TaskID,
+			State:  output.State,
+		}
+	}
+	return &output.GetFetchTaskV2Output, nil
+}
@@ -0,0 +1,108 @@
+package tos
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+func TestFetchObjectV2(t *testing.T) {
+	var (
+		body   map[string]interface{}
+		header http.Header
+	)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		header = req.Header
+		data, _ := ioutil.ReadAll(req.Content)
+		body = nil
+		_ = json.Unmarshal(data, &body)
+		switch body["URL"] {
+		case "http://example.com/missing":
+			return newMockResponse(http.StatusNotFound, `{"Code":"SourceObjectNotFound","Message":"source not found"}`)()
+		case "http://example.com/denied":
+			return newMockResponse(http.StatusForbidden, `{"Code":"AccessDenied","Message":"access denied"}`)()
+		}
+		res := newMockResponse(http.StatusOK, `{"ETag":"\"etag\""}`)()
+		res.Header.Set(HeaderVersionID, "version")
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	output, err := client.FetchObjectV2(ctx, &FetchObjectV2Input{
+		Bucket:        "bucket",
+		Key:           "key",
+		URL:           "http://example.com/file",
+		IgnoreSameKey: true,
+		ContentMD5:    "md5",
+		ACL:           enum.ACLPublicRead,
+		StorageClass:  enum.StorageClassIa,
+		Meta:          map[string]string{"k": "v"},
+	})
+	require.Nil(t, err)
+	require.Equal(t, "\"etag\"", output.ETag)
+	require.Equal(t, "version", output.VersionID)
+	require.Equal(t, "http://example.com/file", body["URL"])
+	require.Equal(t, true, body["IgnoreSameKey"])
+	require.Equal(t, "md5", body["ContentMD5"])
+	require.Equal(t, string(enum.ACLPublicRead), header.Get("X-Tos-Acl"))
+	require.Equal(t, string(enum.StorageClassIa), header.Get("X-Tos-Storage-Class"))
+	require.Equal(t, "v", header.Get(HeaderMetaPrefix+"k"))
+
+	_, err = client.FetchObjectV2(ctx, &FetchObjectV2Input{Bucket: "bucket", Key: "key", URL: "http://example.com/missing"})
+	fetchErr, ok := err.(*FetchTaskError)
+	require.True(t, ok)
+	require.Equal(t, FetchTaskStateFailed, fetchErr.State)
+	require.Equal(t, "SourceObjectNotFound", Code(err))
+	require.Equal(t, http.StatusNotFound, StatusCode(err))
+
+	// errors not describing the fetch are returned as is
+	_, err = client.FetchObjectV2(ctx, &FetchObjectV2Input{Bucket: "bucket", Key: "key", URL: "http://example.com/denied"})
+	_, ok = err.(*TosServerError)
+	require.True(t, ok)
+	require.Equal(t, "AccessDenied", Code(err))
+}
+
+func TestFetchTaskV2(t *testing.T) {
+	state := FetchTaskStateRunning
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodPost {
+			data, _ := ioutil.ReadAll(req.Content)
+			var body map[string]interface{}
+			_ = json.Unmarshal(data, &body)
+			if body["Object"] != "key" {
+				return newMockResponse(http.StatusBadRequest, `{"Code":"InvalidArgument"}`)()
+			}
+			return newMockResponse(http.StatusOK, `{"TaskId":"task-id"}`)()
+		}
+		if req.Query.Get("taskId") != "task-id" {
+			return newMockResponse(http.StatusNotFound, `{"Code":"NoSuchTask"}`)()
+		}
+		return newMockResponse(http.StatusOK, `{"State":"`+state+`","Err":"md5 mismatch","Task":{"URL":"http://example.com/file","Object":"key"}}`)()
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	put, err := client.PutFetchTaskV2(ctx, &PutFetchTaskV2Input{Bucket: "bucket", Key: "key", URL: "http://example.com/file"})
+	require.Nil(t, err)
+	require.Equal(t, "task-id", put.TaskID)
+
+	get, err := client.GetFetchTaskV2(ctx, &GetFetchTaskV2Input{Bucket: "bucket", TaskID: put.TaskID})
+	require.Nil(t, err)
+	require.Equal(t, FetchTaskStateRunning, get.State)
+	require.Equal(t, "key", get.Task.Key)
+
+	state = FetchTaskStateFailed
+	_, err = client.GetFetchTaskV2(ctx, &GetFetchTaskV2Input{Bucket: "bucket", TaskID: put.TaskID})
+	fetchErr, ok := err.(*FetchTaskError)
+	require.True(t, ok)
+	require.Equal(t, "task-id", fetchErr.TaskID)
+	require.Equal(t, FetchTaskStateFailed, fetchErr.State)
+	require.Contains(t, fetchErr.Message, "md5 mismatch")
+	require.Equal(t, "request-id", RequestID(err))
+}
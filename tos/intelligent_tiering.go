@@ -0,0 +1,142 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+// isValidStatus validate status of bucket features, which is Enabled or Disabled
+func isValidStatus(status enum.StatusType) error {
+	if status != enum.StatusEnabled && status != enum.StatusDisabled {
+		return newTosClientError("tos: invalid status "+string(status)+", allowed: Enabled, Disabled", nil)
+	}
+	return nil
+}
+
+// isValidIntelligentTiering validate status and transitions of intelligent tiering,
+// Days of transitions must be positive and increasing, and each AccessTier is set once at most.
+func isValidIntelligentTiering(input *PutBucketIntelligentTieringInput) error {
+	if err := isValidStatus(input.Status); err != nil {
+		return err
+	}
+	days := 0
+	tiers := make(map[enum.AccessTierType]bool, len(input.Transitions))
+	for _, transition := range input.Transitions {
+		if transition.AccessTier != enum.AccessTierInfrequent && transition.AccessTier != enum.AccessTierArchiveFr {
+			return newTosClientError("tos: invalid access tier "+string(transition.AccessTier)+", allowed: INFREQUENT, ARCHIVEFR", nil)
+		}
+		if tiers[transition.AccessTier] {
+			return newTosClientError("tos: duplicate access tier "+string(transition.AccessTier), nil)
+		}
+		tiers[transition.AccessTier] = true
+		if transition.Days <= days {
+			return newTosClientError("tos: days of transitions must be positive and increasing, got "+strconv.Itoa(transition.Days), nil)
+		}
+		days = transition.Days
+	}
+	return nil
+}
+
+// PutBucketAccessMonitor enable or disable access tracking of objects in bucket,
+// which must be enabled before PutBucketIntelligentTiering
+func (cli *ClientV2) PutBucketAccessMonitor(ctx context.Context, input *PutBucketAccessMonitorInput) (*PutBucketAccessMonitorOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if err := isValidStatus(input.Status); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("PutBucketAccessMonitorInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("accessmonitor", "").
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketAccessMonitorOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketAccessMonitor get whether access tracking is enabled for bucket
+func (cli *ClientV2) GetBucketAccessMonitor(ctx context.Context, input *GetBucketAccessMonitorInput) (*GetBucketAccessMonitorOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("accessmonitor", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetBucketAccessMonitorOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// PutBucketIntelligentTiering enable or disable transition of objects in INTELLIGENT_TIERING storage class
+// according to their access patterns, PutBucketAccessMonitor must be enabled first.
+func (cli *ClientV2) PutBucketIntelligentTiering(ctx context.Context, input *PutBucketIntelligentTieringInput) (*PutBucketIntelligentTieringOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if err := isValidIntelligentTiering(input); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("PutBucketIntelligentTieringInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("intelligenttiering", "").
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketIntelligentTieringOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketIntelligentTiering get status, transitions and enabled time of intelligent tiering of bucket
+func (cli *ClientV2) GetBucketIntelligentTiering(ctx context.Context, input *GetBucketIntelligentTieringInput) (*GetBucketIntelligentTieringOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("intelligenttiering", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetBucketIntelligentTieringOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
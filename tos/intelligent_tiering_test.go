@@ -0,0 +1,68 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+func TestBucketIntelligentTiering(t *testing.T) {
+	transport := &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, `{"Status":"Enabled"}`),
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, `{"Status":"Enabled","Transitions":[{"Days":30,"AccessTier":"INFREQUENT"},
+			{"Days":90,"AccessTier":"ARCHIVEFR"}],"EnabledTime":"2023-05-01T08:00:00Z"}`),
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.PutBucketAccessMonitor(ctx, &PutBucketAccessMonitorInput{Bucket: "bucket", Status: enum.StatusEnabled})
+	require.Nil(t, err)
+	require.Contains(t, transport.recorded()[0].Query, "accessmonitor")
+	require.JSONEq(t, `{"Status":"Enabled"}`, requestBody(t, transport.recorded()[0]))
+	monitor, err := client.GetBucketAccessMonitor(ctx, &GetBucketAccessMonitorInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, enum.StatusEnabled, monitor.Status)
+
+	_, err = client.PutBucketIntelligentTiering(ctx, &PutBucketIntelligentTieringInput{
+		Bucket: "bucket",
+		Status: enum.StatusEnabled,
+		Transitions: []IntelligentTieringTransition{
+			{Days: 30, AccessTier: enum.AccessTierInfrequent},
+			{Days: 90, AccessTier: enum.AccessTierArchiveFr},
+		},
+	})
+	require.Nil(t, err)
+	require.Contains(t, transport.recorded()[2].Query, "intelligenttiering")
+	require.JSONEq(t, `{"Status":"Enabled","Transitions":[{"Days":30,"AccessTier":"INFREQUENT"},
+		{"Days":90,"AccessTier":"ARCHIVEFR"}]}`, requestBody(t, transport.recorded()[2]))
+
+	tiering, err := client.GetBucketIntelligentTiering(ctx, &GetBucketIntelligentTieringInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, enum.StatusEnabled, tiering.Status)
+	require.Equal(t, []IntelligentTieringTransition{
+		{Days: 30, AccessTier: enum.AccessTierInfrequent},
+		{Days: 90, AccessTier: enum.AccessTierArchiveFr},
+	}, tiering.Transitions)
+	require.True(t, tiering.EnabledTime.Equal(time.Date(2023, 5, 1, 8, 0, 0, 0, time.UTC)))
+
+	requests := len(transport.recorded())
+	_, err = client.PutBucketAccessMonitor(ctx, &PutBucketAccessMonitorInput{Bucket: "bucket", Status: "On"})
+	require.NotNil(t, err)
+	for _, transitions := range [][]IntelligentTieringTransition{
+		{{Days: 0, AccessTier: enum.AccessTierInfrequent}},
+		{{Days: 30, AccessTier: "STANDARD"}},
+		{{Days: 90, AccessTier: enum.AccessTierInfrequent}, {Days: 30, AccessTier: enum.AccessTierArchiveFr}},
+		{{Days: 30, AccessTier: enum.AccessTierInfrequent}, {Days: 90, AccessTier: enum.AccessTierInfrequent}},
+	} {
+		_, err = client.PutBucketIntelligentTiering(ctx, &PutBucketIntelligentTieringInput{Bucket: "bucket", Status: enum.StatusEnabled, Transitions: transitions})
+		_, ok := err.(*TosClientError)
+		require.True(t, ok)
+	}
+	require.Equal(t, requests, len(transport.recorded()))
+}
@@ -0,0 +1,132 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+// isValidInventory checks ID, destination, schedule and included object versions of inventory configuration
+func isValidInventory(conf *BucketInventoryConfiguration) error {
+	if conf.ID == "" {
+		return newTosClientError("tos: ID of inventory configuration is required", nil)
+	}
+	destination := conf.Destination.TOSBucketDestination
+	if err := IsValidBucketName(destination.Bucket); err != nil {
+		return err
+	}
+	if destination.Format != enum.InventoryFormatCsv {
+		return newTosClientError("tos: invalid inventory format, must be CSV", nil)
+	}
+	if frequency := conf.Schedule.Frequency; frequency != enum.InventoryFrequencyDaily && frequency != enum.InventoryFrequencyWeekly {
+		return newTosClientError("tos: invalid inventory frequency, must be Daily or Weekly", nil)
+	}
+	if versions := conf.IncludedObjectVersions; versions != enum.InventoryIncludedObjectVersionsAll &&
+		versions != enum.InventoryIncludedObjectVersionsCurrent {
+		return newTosClientError("tos: invalid included object versions, must be All or Current", nil)
+	}
+	return nil
+}
+
+// PutBucketInventory create or replace the inventory configuration of ID
+func (cli *ClientV2) PutBucketInventory(ctx context.Context, input *PutBucketInventoryInput) (*PutBucketInventoryOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if err := isValidInventory(&input.BucketInventoryConfiguration); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("PutBucketInventoryInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("inventory", "").
+		WithQuery("id", input.ID).
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketInventoryOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketInventory get the inventory configuration of ID
+func (cli *ClientV2) GetBucketInventory(ctx context.Context, input *GetBucketInventoryInput) (*GetBucketInventoryOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if input.ID == "" {
+		return nil, newTosClientError("tos: ID of inventory configuration is required", nil)
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("inventory", "").
+		WithQuery("id", input.ID).
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetBucketInventoryOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// ListBucketInventory list inventory configurations of bucket, call again with NextContinuationToken
+// as ContinuationToken if IsTruncated is true.
+func (cli *ClientV2) ListBucketInventory(ctx context.Context, input *ListBucketInventoryInput) (*ListBucketInventoryOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("inventory", "").
+		WithParams(*input).
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := ListBucketInventoryOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// DeleteBucketInventory delete the inventory configuration of ID
+func (cli *ClientV2) DeleteBucketInventory(ctx context.Context, input *DeleteBucketInventoryInput) (*DeleteBucketInventoryOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if input.ID == "" {
+		return nil, newTosClientError("tos: ID of inventory configuration is required", nil)
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("inventory", "").
+		WithQuery("id", input.ID).
+		Request(ctx, http.MethodDelete, nil, cli.roundTripper(http.StatusNoContent))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &DeleteBucketInventoryOutput{RequestInfo: res.RequestInfo()}, nil
+}
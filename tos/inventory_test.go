@@ -0,0 +1,85 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+// recordedInventory is an inventory configuration returned by server with all fields set
+const recordedInventory = `{"Id":"daily","IsEnabled":true,"Filter":{"Prefix":"logs/"},
+	"Destination":{"TOSBucketDestination":{"Format":"CSV","AccountId":"2100000001","Role":"TosInventoryRole",
+	"Bucket":"inventory-bucket","Prefix":"reports/"}},"Schedule":{"Frequency":"Daily"},"IncludedObjectVersions":"All",
+	"OptionalFields":{"Field":["Size","LastModifiedDate","ETag","StorageClass","IsMultipartUploaded","EncryptionStatus","CRC64"]}}`
+
+func TestBucketInventory(t *testing.T) {
+	transport := &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, recordedInventory),
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, `{"InventoryConfigurations":[`+recordedInventory+`],
+			"IsTruncated":true,"NextContinuationToken":"next"}`),
+		newMockResponse(http.StatusOK, `{"InventoryConfigurations":[{"Id":"weekly","IsEnabled":false,
+			"Destination":{"TOSBucketDestination":{"Format":"CSV","AccountId":"2100000001","Role":"TosInventoryRole","Bucket":"inventory-bucket"}},
+			"Schedule":{"Frequency":"Weekly"},"IncludedObjectVersions":"Current"}],"IsTruncated":false}`),
+		newMockResponse(http.StatusNoContent, ""),
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	get, err := client.GetBucketInventory(ctx, &GetBucketInventoryInput{Bucket: "bucket", ID: "daily"})
+	require.Nil(t, err)
+	require.Equal(t, "daily", transport.recorded()[0].Query.Get("id"))
+	require.Equal(t, "daily", get.ID)
+	require.True(t, get.IsEnabled)
+	require.Equal(t, "logs/", get.Filter.Prefix)
+	require.Equal(t, TOSBucketDestination{Format: enum.InventoryFormatCsv, AccountID: "2100000001", Role: "TosInventoryRole",
+		Bucket: "inventory-bucket", Prefix: "reports/"}, get.Destination.TOSBucketDestination)
+	require.Equal(t, enum.InventoryFrequencyDaily, get.Schedule.Frequency)
+	require.Equal(t, enum.InventoryIncludedObjectVersionsAll, get.IncludedObjectVersions)
+	require.Equal(t, 7, len(get.OptionalFields.Field))
+
+	// put back the recorded configuration, every field must be kept
+	_, err = client.PutBucketInventory(ctx, &PutBucketInventoryInput{Bucket: "bucket", BucketInventoryConfiguration: get.BucketInventoryConfiguration})
+	require.Nil(t, err)
+	require.JSONEq(t, recordedInventory, requestBody(t, transport.recorded()[1]))
+	require.Equal(t, "daily", transport.recorded()[1].Query.Get("id"))
+
+	list, err := client.ListBucketInventory(ctx, &ListBucketInventoryInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.True(t, list.IsTruncated)
+	require.Equal(t, "next", list.NextContinuationToken)
+	require.Equal(t, get.BucketInventoryConfiguration, list.Configurations[0])
+	list, err = client.ListBucketInventory(ctx, &ListBucketInventoryInput{Bucket: "bucket", ContinuationToken: list.NextContinuationToken})
+	require.Nil(t, err)
+	require.Equal(t, "next", transport.recorded()[3].Query.Get("continuation-token"))
+	require.False(t, list.IsTruncated)
+	require.Equal(t, "weekly", list.Configurations[0].ID)
+	require.Nil(t, list.Configurations[0].Filter)
+
+	_, err = client.DeleteBucketInventory(ctx, &DeleteBucketInventoryInput{Bucket: "bucket", ID: "daily"})
+	require.Nil(t, err)
+	require.Equal(t, "daily", transport.recorded()[4].Query.Get("id"))
+
+	requests := len(transport.recorded())
+	_, err = client.GetBucketInventory(ctx, &GetBucketInventoryInput{Bucket: "bucket"})
+	require.NotNil(t, err)
+	_, err = client.DeleteBucketInventory(ctx, &DeleteBucketInventoryInput{Bucket: "bucket"})
+	require.NotNil(t, err)
+	valid := get.BucketInventoryConfiguration
+	for _, modify := range []func(conf *BucketInventoryConfiguration){
+		func(conf *BucketInventoryConfiguration) { conf.ID = "" },
+		func(conf *BucketInventoryConfiguration) { conf.Destination.TOSBucketDestination.Bucket = "" },
+		func(conf *BucketInventoryConfiguration) { conf.Destination.TOSBucketDestination.Format = "Parquet" },
+		func(conf *BucketInventoryConfiguration) { conf.Schedule.Frequency = "Hourly" },
+		func(conf *BucketInventoryConfiguration) { conf.IncludedObjectVersions = "" },
+	} {
+		conf := valid
+		modify(&conf)
+		_, err = client.PutBucketInventory(ctx, &PutBucketInventoryInput{Bucket: "bucket", BucketInventoryConfiguration: conf})
+		require.NotNil(t, err)
+	}
+	require.Equal(t, requests, len(transport.recorded()))
+}
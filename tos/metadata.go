@@ -35,24 +35,35 @@ type ObjectMeta struct {
 }
 
 type ObjectMetaV2 struct {
-	ETag                    string
-	LastModified            time.Time
-	DeleteMarker            bool
-	SSECAlgorithm           string
-	SSECKeyMD5              string
-	VersionID               string
-	WebsiteRedirectLocation string
-	ObjectType              string
-	HashCrc64ecma           uint64
-	StorageClass            enum.StorageClassType
-	Meta                    Metadata
-	ContentLength           int64
-	ContentType             string
-	CacheControl            string
-	ContentDisposition      string
-	ContentEncoding         string
-	ContentLanguage         string
-	Expires                 time.Time
+	ETag                 string
+	LastModified         time.Time
+	DeleteMarker         bool
+	SSECAlgorithm        string
+	SSECKeyMD5           string
+	ServerSideEncryption string // "AES256" or "kms" if the object is encrypted with server managed key
+	// KMS key ID of the object encrypted with ServerSideEncryption "kms"
+	ServerSideEncryptionKeyID string
+	VersionID                 string
+	WebsiteRedirectLocation   string
+	ObjectType                string
+	HashCrc64ecma             uint64
+	StorageClass              enum.StorageClassType
+	Meta                      Metadata
+	ContentLength             int64
+	ContentType               string
+	CacheControl              string
+	ContentDisposition        string
+	ContentEncoding           string
+	ContentLanguage           string
+	Expires                   time.Time
+	SymlinkTargetKey          string                   // target of the symlink, empty if the object is not a symlink
+	SymlinkTargetBucket       string                   // bucket of the symlink target, empty if it is the same bucket
+	RestoreInProgress         bool                     // an archived object is being restored
+	RestoreExpiryDate         time.Time                // expiry date of the restored copy, zero if it is not restored
+	TaggingCount              int                      // number of tags of the object
+	ObjectLockMode            enum.ObjectLockModeType  // retention mode, empty if the object has no retention
+	ObjectLockRetainUntilDate time.Time                // the object can not be deleted or overwritten before the date
+	ObjectLockLegalHold       enum.LegalHoldStatusType // legal hold status, empty if it is never set
 }
 
 func (om *ObjectMeta) fromResponse(res *Response) {
@@ -93,6 +104,8 @@ func (om *ObjectMetaV2) fromResponseV2(res *Response) {
 	om.DeleteMarker = deleteMarker
 	om.SSECAlgorithm = res.Header.Get(HeaderSSECustomerAlgorithm)
 	om.SSECKeyMD5 = res.Header.Get(HeaderContentMD5)
+	om.ServerSideEncryption = res.Header.Get(HeaderServerSideEncryption)
+	om.ServerSideEncryptionKeyID = res.Header.Get(HeaderSSEKMSKeyID)
 	om.VersionID = res.Header.Get(HeaderVersionID)
 	om.WebsiteRedirectLocation = res.Header.Get(HeaderWebsiteRedirectLocation)
 	om.ObjectType = res.Header.Get(HeaderObjectType)
@@ -106,6 +119,41 @@ func (om *ObjectMetaV2) fromResponseV2(res *Response) {
 	om.ContentEncoding = res.Header.Get(HeaderContentEncoding)
 	om.ContentLanguage = res.Header.Get(HeaderContentLanguage)
 	om.Expires = expires
+	om.SymlinkTargetKey = symlinkTarget(res.Header)
+	om.SymlinkTargetBucket = res.Header.Get(HeaderSymlinkBucket)
+	om.RestoreInProgress, om.RestoreExpiryDate = parseRestore(res.Header.Get(HeaderRestore))
+	om.TaggingCount, _ = strconv.Atoi(res.Header.Get(HeaderTaggingCount))
+	om.ObjectLockMode = enum.ObjectLockModeType(res.Header.Get(HeaderObjectLockMode))
+	om.ObjectLockRetainUntilDate, _ = time.Parse(time.RFC3339, res.Header.Get(HeaderObjectLockRetainUntilDate))
+	om.ObjectLockLegalHold = enum.LegalHoldStatusType(res.Header.Get(HeaderObjectLockLegalHold))
+}
+
+// parseRestore parses X-Tos-Restore header,
+// e.g. `ongoing-request="true"` or `ongoing-request="false", expiry-date="Fri, 23 Dec 2022 00:00:00 GMT"`
+func parseRestore(restore string) (inProgress bool, expiryDate time.Time) {
+	quoted := func(name string) string {
+		start := strings.Index(restore, name+`="`)
+		if start < 0 {
+			return ""
+		}
+		value := restore[start+len(name)+2:]
+		if end := strings.IndexByte(value, '"'); end >= 0 {
+			return value[:end]
+		}
+		return ""
+	}
+	inProgress = quoted("ongoing-request") == "true"
+	expiryDate, _ = time.ParseInLocation(http.TimeFormat, quoted("expiry-date"), time.UTC)
+	return
+}
+
+// symlinkTarget returns url decoded symlink target key
+func symlinkTarget(header http.Header) string {
+	target := header.Get(HeaderSymlinkTarget)
+	if decoded, err := url.PathUnescape(target); err == nil {
+		return decoded
+	}
+	return target
 }
 
 func userMetadata(header http.Header) map[string]string {
@@ -0,0 +1,102 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+// isValidMirrorBackRules checks there is at least one rule and every rule has a valid redirect type and source endpoint
+func isValidMirrorBackRules(rules []MirrorBackRule) error {
+	if len(rules) == 0 {
+		return newTosClientError("tos: rules of mirror back is empty", nil)
+	}
+	for i, rule := range rules {
+		redirect := rule.Redirect
+		if redirect.RedirectType != enum.RedirectTypeMirror && redirect.RedirectType != enum.RedirectTypeAsync {
+			return newTosClientError(fmt.Sprintf("tos: invalid redirect type of mirror back rule %d, must be Mirror or Async", i), nil)
+		}
+		endpoint := redirect.PublicSource.SourceEndpoint
+		if len(endpoint.Primary) == 0 {
+			return newTosClientError(fmt.Sprintf("tos: primary source endpoint of mirror back rule %d is empty", i), nil)
+		}
+		for _, sources := range [][]string{endpoint.Primary, endpoint.Follower} {
+			for _, source := range sources {
+				if source == "" {
+					return newTosClientError(fmt.Sprintf("tos: empty source endpoint in mirror back rule %d", i), nil)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// PutBucketMirrorBack set mirror back rules of bucket, all existing rules are replaced
+func (cli *ClientV2) PutBucketMirrorBack(ctx context.Context, input *PutBucketMirrorBackInput) (*PutBucketMirrorBackOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if err := isValidMirrorBackRules(input.Rules); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("PutBucketMirrorBackInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("mirror", "").
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketMirrorBackOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketMirrorBack get mirror back rules of bucket
+func (cli *ClientV2) GetBucketMirrorBack(ctx context.Context, input *GetBucketMirrorBackInput) (*GetBucketMirrorBackOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("mirror", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetBucketMirrorBackOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// DeleteBucketMirrorBack delete all mirror back rules of bucket
+func (cli *ClientV2) DeleteBucketMirrorBack(ctx context.Context, input *DeleteBucketMirrorBackInput) (*DeleteBucketMirrorBackOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("mirror", "").
+		Request(ctx, http.MethodDelete, nil, cli.roundTripper(http.StatusNoContent))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &DeleteBucketMirrorBackOutput{RequestInfo: res.RequestInfo()}, nil
+}
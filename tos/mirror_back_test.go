@@ -0,0 +1,79 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+// mirrorBackRules is the serialized form of the rule in TestBucketMirrorBack
+const mirrorBackRules = `{"Rules":[{"ID":"rule","Condition":{"HttpCode":404,"KeyPrefix":"img/","KeySuffix":".png"},
+	"Redirect":{"RedirectType":"Mirror","PassQuery":true,"FollowRedirect":true,
+	"MirrorHeader":{"Pass":["x-custom"],"Remove":["authorization"],"Set":[{"Key":"x-from","Value":"tos"}]},
+	"PublicSource":{"SourceEndpoint":{"Primary":["https://origin.example.com"],"Follower":["https://backup.example.com"]},"FixedEndpoint":true},
+	"Transform":{"ReplaceKeyPrefix":{"KeyPrefix":"img/","ReplaceWith":"images/"}}}}]}`
+
+func TestBucketMirrorBack(t *testing.T) {
+	transport := &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, mirrorBackRules),
+		newMockResponse(http.StatusNoContent, ""),
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	rule := MirrorBackRule{
+		ID:        "rule",
+		Condition: MirrorBackCondition{HttpCode: http.StatusNotFound, KeyPrefix: "img/", KeySuffix: ".png"},
+		Redirect: MirrorRedirect{
+			RedirectType:   enum.RedirectTypeMirror,
+			PassQuery:      true,
+			FollowRedirect: true,
+			MirrorHeader: MirrorHeader{
+				Pass:   []string{"x-custom"},
+				Remove: []string{"authorization"},
+				Set:    []MirrorHeaderKeyValue{{Key: "x-from", Value: "tos"}},
+			},
+			PublicSource: MirrorPublicSource{
+				SourceEndpoint: MirrorSourceEndpoint{Primary: []string{"https://origin.example.com"}, Follower: []string{"https://backup.example.com"}},
+				FixedEndpoint:  true,
+			},
+			Transform: MirrorTransform{ReplaceKeyPrefix: MirrorReplaceKeyPrefix{KeyPrefix: "img/", ReplaceWith: "images/"}},
+		},
+	}
+	_, err := client.PutBucketMirrorBack(ctx, &PutBucketMirrorBackInput{Bucket: "bucket", Rules: []MirrorBackRule{rule}})
+	require.Nil(t, err)
+	req := transport.recorded()[0]
+	require.Equal(t, http.MethodPut, req.Method)
+	require.Contains(t, req.Query, "mirror")
+	require.NotEmpty(t, req.Header.Get(HeaderContentMD5))
+	require.JSONEq(t, mirrorBackRules, requestBody(t, req))
+
+	get, err := client.GetBucketMirrorBack(ctx, &GetBucketMirrorBackInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, []MirrorBackRule{rule}, get.Rules)
+
+	_, err = client.DeleteBucketMirrorBack(ctx, &DeleteBucketMirrorBackInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, http.MethodDelete, transport.recorded()[2].Method)
+
+	requests := len(transport.recorded())
+	_, err = client.PutBucketMirrorBack(ctx, &PutBucketMirrorBackInput{Bucket: "bucket"})
+	require.NotNil(t, err)
+	noSource := rule
+	noSource.Redirect.PublicSource = MirrorPublicSource{}
+	_, err = client.PutBucketMirrorBack(ctx, &PutBucketMirrorBackInput{Bucket: "bucket", Rules: []MirrorBackRule{noSource}})
+	require.NotNil(t, err)
+	emptySource := rule
+	emptySource.Redirect.PublicSource = MirrorPublicSource{SourceEndpoint: MirrorSourceEndpoint{Primary: []string{"https://origin.example.com"}, Follower: []string{""}}}
+	_, err = client.PutBucketMirrorBack(ctx, &PutBucketMirrorBackInput{Bucket: "bucket", Rules: []MirrorBackRule{emptySource}})
+	require.NotNil(t, err)
+	noType := rule
+	noType.Redirect.RedirectType = ""
+	_, err = client.PutBucketMirrorBack(ctx, &PutBucketMirrorBackInput{Bucket: "bucket", Rules: []MirrorBackRule{noType}})
+	require.NotNil(t, err)
+	require.Equal(t, requests, len(transport.recorded()))
+}
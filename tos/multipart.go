@@ -11,6 +11,8 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"sync"
+	"time"
 )
 
 // CreateMultipartUpload create a multipart upload operation
@@ -67,6 +69,12 @@ func (cli *ClientV2) CreateMultipartUploadV2(
 	if err := isValidKey(input.Key); err != nil {
 		return nil, err
 	}
+	if err := isValidSSE(input.SSECAlgorithm, input.SSECKey, input.ServerSideEncryption, input.ServerSideEncryptionKeyID); err != nil {
+		return nil, err
+	}
+	if err := isValidStorageClass(input.StorageClass); err != nil {
+		return nil, err
+	}
 
 	res, err := cli.newBuilder(input.Bucket, input.Key).
 		WithQuery("uploads", "").
@@ -77,6 +85,9 @@ func (cli *ClientV2) CreateMultipartUploadV2(
 		return nil, err
 	}
 	defer res.Close()
+	if err = checkSSECKeyMD5(res, input.SSECKey, input.SSECKeyMD5); err != nil {
+		return nil, err
+	}
 
 	var upload multipartUpload
 	if err = marshalOutput(res.RequestInfo().RequestID, res.Body, &upload); err != nil {
@@ -91,6 +102,9 @@ func (cli *ClientV2) CreateMultipartUploadV2(
 		SSECAlgorithm: res.Header.Get(HeaderSSECustomerAlgorithm),
 		SSECKeyMD5:    res.Header.Get(HeaderSSECustomerKeyMD5),
 		EncodingType:  res.Header.Get(HeaderContentEncoding),
+
+		ServerSideEncryption:      res.Header.Get(HeaderServerSideEncryption),
+		ServerSideEncryptionKeyID: res.Header.Get(HeaderSSEKMSKeyID),
 	}, nil
 }
 
@@ -144,6 +158,16 @@ func (cli *ClientV2) UploadPartV2(ctx context.Context, input *UploadPartV2Input)
 	if cli.enableCRC {
 		checker = NewCRC(DefaultCrcTable(), 0)
 	}
+	var md5Sum []byte
+	if input.ContentMD5 == "" && (cli.enableMD5 || input.EnableContentMD5) {
+		var err error
+		if content, md5Sum, err = contentWithMD5(content, contentLength, cli.md5Limit); err != nil {
+			return nil, err
+		}
+		if contentLength < 0 {
+			contentLength = tryResolveLength(content)
+		}
+	}
 	var (
 		onRetry    func(req *Request) = nil
 		classifier classifier
@@ -169,15 +193,24 @@ func (cli *ClientV2) UploadPartV2(ctx context.Context, input *UploadPartV2Input)
 	if onRetry == nil {
 		classifier = ServerErrorClassifier{}
 	}
-	res, err := cli.newBuilder(input.Bucket, input.Key).
+	rb := cli.newBuilder(input.Bucket, input.Key).
 		WithParams(*input).
 		WithContentLength(input.ContentLength).
-		WithRetry(onRetry, classifier).
-		Request(ctx, http.MethodPut, content, cli.roundTripper(http.StatusOK))
+		WithRetry(onRetry, classifier)
+	if md5Sum != nil {
+		rb.WithHeader(HeaderContentMD5, base64MD5(md5Sum))
+	}
+	if input.EnableTrailingChecksum {
+		content = withTrailingChecksum(rb, content, contentLength)
+	}
+	res, err := rb.Request(ctx, http.MethodPut, content, cli.roundTripper(http.StatusOK))
 	if err != nil {
 		return nil, err
 	}
 	defer res.Close()
+	if err = checkSSECKeyMD5(res, input.SSECKey, input.SSECKeyMD5); err != nil {
+		return nil, err
+	}
 	if err = checkCrc64(res, checker); err != nil {
 		return nil, err
 	}
@@ -198,15 +231,23 @@ func (cli *ClientV2) UploadPartFromFile(ctx context.Context, input *UploadPartFr
 	if err != nil {
 		return nil, err
 	}
-	_, err = file.Seek(int64(input.Offset), io.SeekStart)
-
+	defer file.Close()
+	stat, err := file.Stat()
 	if err != nil {
-		return nil, err
+		return nil, newTosClientError("tos: stat file to upload failed", err)
+	}
+	offset, partSize := int64(input.Offset), input.PartSize
+	if partSize == 0 {
+		// upload the rest of file
+		partSize = stat.Size() - offset
+	}
+	if offset > stat.Size() || partSize < 0 || offset+partSize > stat.Size() {
+		return nil, newTosClientError(fmt.Sprintf("tos: part range [%d, %d) exceeds file size %d", offset, offset+partSize, stat.Size()), nil)
 	}
 	output, err := cli.UploadPartV2(ctx, &UploadPartV2Input{
 		UploadPartBasicInput: input.UploadPartBasicInput,
-		Content:              file,
-		ContentLength:        input.PartSize,
+		Content:              io.NewSectionReader(file, offset, partSize),
+		ContentLength:        partSize,
 	})
 	if err != nil {
 		return nil, err
@@ -243,10 +284,12 @@ func (bkt *Bucket) CompleteMultipartUpload(ctx context.Context, input *CompleteM
 	}
 	defer res.Close()
 
-	return &CompleteMultipartUploadOutput{
-		RequestInfo: res.RequestInfo(),
-		VersionID:   res.Header.Get(HeaderVersionID),
-	}, nil
+	output := &CompleteMultipartUploadOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, output); err != nil {
+		return nil, err
+	}
+	output.VersionID = res.Header.Get(HeaderVersionID)
+	return output, nil
 }
 
 // CompleteMultipartUploadV2 complete a multipart upload operation
@@ -256,21 +299,30 @@ func (cli *ClientV2) CompleteMultipartUploadV2(
 	if err := isValidNames(input.Bucket, input.Key); err != nil {
 		return nil, err
 	}
-	multipart := partsToComplete{Parts: make(uploadedParts, 0, len(input.Parts))}
-	for _, p := range input.Parts {
-		multipart.Parts = append(multipart.Parts, p.uploadedPart())
-	}
+	rb := cli.newBuilder(input.Bucket, input.Key).
+		WithParams(*input).
+		WithRetry(nil, ServerErrorClassifier{})
+	var body io.Reader
+	if input.CompleteAll {
+		if len(input.Parts) > 0 {
+			return nil, newTosClientError("tos: Parts must be empty if CompleteAll is set", nil)
+		}
+		rb.WithHeader(HeaderCompleteAll, "yes")
+	} else {
+		multipart := partsToComplete{Parts: make(uploadedParts, 0, len(input.Parts))}
+		for _, p := range input.Parts {
+			multipart.Parts = append(multipart.Parts, p.uploadedPart())
+		}
 
-	sort.Sort(multipart.Parts)
-	data, err := json.Marshal(&multipart)
-	if err != nil {
-		return nil, newTosClientError("tos: marshal uploadParts", err)
+		sort.Sort(multipart.Parts)
+		data, err := json.Marshal(&multipart)
+		if err != nil {
+			return nil, newTosClientError("tos: marshal uploadParts", err)
+		}
+		body = bytes.NewReader(data)
 	}
 
-	res, err := cli.newBuilder(input.Bucket, input.Key).
-		WithParams(*input).
-		WithRetry(nil, ServerErrorClassifier{}).
-		Request(ctx, http.MethodPost, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	res, err := rb.Request(ctx, http.MethodPost, body, cli.roundTripper(http.StatusOK))
 	if err != nil {
 		return nil, err
 	}
@@ -282,12 +334,39 @@ func (cli *ClientV2) CompleteMultipartUploadV2(
 		VersionID:     res.Header.Get(HeaderVersionID),
 		HashCrc64ecma: crc64,
 	}
-	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+	if err = marshalOutput(output.RequestID, res.Body, output); err != nil {
 		return nil, err
 	}
+	if cli.enableCRC && !input.CompleteAll && crc64 != 0 {
+		if combined, ok := combineCRCInUploadedParts(input.Parts); ok && combined != crc64 {
+			return nil, newChecksumMismatchError(output.RequestID, crc64, combined)
+		}
+	}
 	return output, nil
 }
 
+// combineCRCInUploadedParts calculates CRC64 of the object from parts,
+// ok is false if CRC64 or size of any part is not set.
+func combineCRCInUploadedParts(parts []UploadedPartV2) (crc uint64, ok bool) {
+	if len(parts) == 0 {
+		return 0, false
+	}
+	sorted := make([]UploadedPartV2, len(parts))
+	copy(sorted, parts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PartNumber < sorted[j].PartNumber })
+	for i, part := range sorted {
+		if part.HashCrc64ecma == 0 || part.Size <= 0 {
+			return 0, false
+		}
+		if i == 0 {
+			crc = part.HashCrc64ecma
+			continue
+		}
+		crc = CRC64Combine(crc, part.HashCrc64ecma, uint64(part.Size))
+	}
+	return crc, true
+}
+
 // AbortMultipartUpload abort a multipart upload operation
 //
 // Deprecated: use AbortMultipartUpload of ClientV2 instead
@@ -415,3 +494,55 @@ func (cli *ClientV2) ListMultipartUploadsV2(
 	}
 	return &output, nil
 }
+
+// AbortStaleMultipartUploads aborts multipart uploads with key prefix initiated more than olderThan ago.
+// Uploads are aborted in parallel, and an upload failed to abort is recorded in output rather than stopping others.
+// Error is returned only if listing uploads failed, along with uploads handled before that.
+func (cli *ClientV2) AbortStaleMultipartUploads(ctx context.Context, bucket, prefix string, olderThan time.Duration) (*AbortStaleMultipartUploadsOutput, error) {
+	if err := IsValidBucketName(bucket); err != nil {
+		return nil, err
+	}
+	var (
+		output    AbortStaleMultipartUploadsOutput
+		lock      sync.Mutex
+		wg        sync.WaitGroup
+		err       error
+		listed    *ListMultipartUploadsV2Output
+		deadline  = time.Now().Add(-olderThan)
+		inflight  = make(chan struct{}, DefaultAbortTaskNum)
+		paginator = cli.NewListMultipartUploadsPaginator(&ListMultipartUploadsV2Input{Bucket: bucket, Prefix: prefix})
+	)
+	for paginator.HasNext() {
+		listed, err = paginator.Next(ctx)
+		if err != nil {
+			break
+		}
+		for _, upload := range listed.Uploads {
+			if !upload.Initiated.Before(deadline) {
+				continue
+			}
+			inflight <- struct{}{}
+			wg.Add(1)
+			go func(upload ListedUpload) {
+				defer func() {
+					<-inflight
+					wg.Done()
+				}()
+				_, err := cli.AbortMultipartUpload(ctx, &AbortMultipartUploadInput{
+					Bucket:   bucket,
+					Key:      upload.Key,
+					UploadID: upload.UploadID,
+				})
+				lock.Lock()
+				defer lock.Unlock()
+				if err != nil {
+					output.Failed = append(output.Failed, AbortUploadError{Key: upload.Key, UploadID: upload.UploadID, Err: err})
+					return
+				}
+				output.Aborted = append(output.Aborted, AbortedUpload{Key: upload.Key, UploadID: upload.UploadID, Initiated: upload.Initiated})
+			}(upload)
+		}
+	}
+	wg.Wait()
+	return &output, err
+}
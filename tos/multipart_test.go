@@ -0,0 +1,277 @@
+package tos
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbortStaleMultipartUploads(t *testing.T) {
+	stale := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	fresh := time.Now().UTC().Format(time.RFC3339)
+	pages := map[string]string{
+		"": `{"IsTruncated":true,"NextKeyMarker":"prefix/b","NextUploadIdMarker":"id-2","Uploads":[
+			{"Key":"prefix/a","UploadId":"id-1","Initiated":"` + stale + `"},
+			{"Key":"prefix/b","UploadId":"id-2","Initiated":"` + fresh + `"}]}`,
+		"prefix/b|id-2": `{"IsTruncated":false,"Uploads":[
+			{"Key":"prefix/b","UploadId":"id-3","Initiated":"` + stale + `"},
+			{"Key":"prefix/c","UploadId":"id-4","Initiated":"` + stale + `"}]}`,
+	}
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodGet {
+			require.Equal(t, "prefix/", req.Query.Get("prefix"))
+			marker := req.Query.Get("key-marker")
+			if marker != "" {
+				marker += "|" + req.Query.Get("upload-id-marker")
+			}
+			return newMockResponse(http.StatusOK, pages[marker])()
+		}
+		if req.Query.Get("uploadId") == "id-4" {
+			return newMockResponse(http.StatusNotFound, `{"Code":"NoSuchUpload","Message":"upload not found"}`)()
+		}
+		return newMockResponse(http.StatusNoContent, "")()
+	}}
+	client := newMockClient(t, transport)
+
+	output, err := client.AbortStaleMultipartUploads(context.Background(), "bucket", "prefix/", 24*time.Hour)
+	require.Nil(t, err)
+	ids := make([]string, 0, len(output.Aborted))
+	for _, upload := range output.Aborted {
+		ids = append(ids, upload.UploadID)
+	}
+	sort.Strings(ids)
+	require.Equal(t, []string{"id-1", "id-3"}, ids)
+	require.Equal(t, 1, len(output.Failed))
+	require.Equal(t, "id-4", output.Failed[0].UploadID)
+	require.Equal(t, http.StatusNotFound, StatusCode(output.Failed[0].Err))
+}
+
+func TestCompleteMultipartUploadV2CompleteAll(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		require.Equal(t, "yes", req.Header.Get(HeaderCompleteAll))
+		require.Nil(t, req.Content)
+		return newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"key","ETag":"\"etag\"",
+			"CompletedParts":[{"PartNumber":1,"ETag":"\"etag-1\""},{"PartNumber":2,"ETag":"\"etag-2\""}]}`)()
+	}}
+	client := newMockClient(t, transport)
+	input := &CompleteMultipartUploadV2Input{Bucket: "bucket", Key: "key", UploadID: "upload-id", CompleteAll: true}
+	output, err := client.CompleteMultipartUploadV2(context.Background(), input)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(output.CompletedParts))
+	require.Equal(t, `"etag-2"`, output.CompletedParts[1].ETag)
+
+	input.Parts = []UploadedPartV2{{PartNumber: 1, ETag: `"etag-1"`}}
+	_, err = client.CompleteMultipartUploadV2(context.Background(), input)
+	require.NotNil(t, err)
+	require.Equal(t, 1, len(transport.recorded()))
+}
+
+func TestCompleteMultipartUploadOutput(t *testing.T) {
+	body := `{"Location":"http://bucket.tos-cn-beijing.volces.com/key","Bucket":"bucket","Key":"key","ETag":"\"etag-2\""}`
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, body)()
+		res.Header.Set(HeaderVersionID, "version-id")
+		res.Header.Set(HeaderHashCrc64ecma, "123")
+		return res
+	}}
+	client := newMockClient(t, transport)
+	output, err := client.CompleteMultipartUploadV2(context.Background(), &CompleteMultipartUploadV2Input{
+		Bucket: "bucket", Key: "key", UploadID: "upload-id", Parts: []UploadedPartV2{{PartNumber: 1, ETag: `"etag-1"`}},
+	})
+	require.Nil(t, err)
+	require.Equal(t, "bucket", output.Bucket)
+	require.Equal(t, "key", output.Key)
+	require.Equal(t, `"etag-2"`, output.ETag)
+	require.Equal(t, "http://bucket.tos-cn-beijing.volces.com/key", output.Location)
+	require.Equal(t, "version-id", output.VersionID)
+	require.Equal(t, uint64(123), output.HashCrc64ecma)
+	require.Equal(t, "request-id", output.RequestID)
+
+	legacy, err := client.Bucket("bucket")
+	require.Nil(t, err)
+	legacyOutput, err := legacy.CompleteMultipartUpload(context.Background(), &CompleteMultipartUploadInput{Key: "key", UploadID: "upload-id"})
+	require.Nil(t, err)
+	require.Equal(t, `"etag-2"`, legacyOutput.ETag)
+	require.Equal(t, "http://bucket.tos-cn-beijing.volces.com/key", legacyOutput.Location)
+	require.Equal(t, "version-id", legacyOutput.VersionID)
+}
+
+func TestCompleteMultipartUploadV2CheckCRC(t *testing.T) {
+	crc := func(data string) uint64 {
+		checker := NewCRC(DefaultCrcTable(), 0)
+		checker.Write([]byte(data))
+		return checker.Sum64()
+	}
+	parts := []UploadedPartV2{
+		{PartNumber: 2, ETag: `"etag-2"`, Size: 5, HashCrc64ecma: crc("world")},
+		{PartNumber: 1, ETag: `"etag-1"`, Size: 6, HashCrc64ecma: crc("hello ")},
+	}
+	combined, ok := combineCRCInUploadedParts(parts)
+	require.True(t, ok)
+	require.Equal(t, crc("hello world"), combined)
+
+	objectCRC := crc("hello world")
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"key"}`)()
+		res.Header.Set(HeaderHashCrc64ecma, strconv.FormatUint(objectCRC, 10))
+		return res
+	}}
+	client := newMockClient(t, transport)
+	input := &CompleteMultipartUploadV2Input{Bucket: "bucket", Key: "key", UploadID: "upload-id", Parts: parts}
+	_, err := client.CompleteMultipartUploadV2(context.Background(), input)
+	require.Nil(t, err)
+
+	objectCRC = crc("hello word")
+	_, err = client.CompleteMultipartUploadV2(context.Background(), input)
+	require.NotNil(t, err)
+	clientErr, ok := err.(*TosClientError)
+	require.True(t, ok)
+	_, ok = clientErr.Cause.(*ChecksumError)
+	require.True(t, ok)
+
+	// part CRC64 unknown, skip checking
+	input.Parts = []UploadedPartV2{{PartNumber: 1, ETag: `"etag-1"`}}
+	_, err = client.CompleteMultipartUploadV2(context.Background(), input)
+	require.Nil(t, err)
+}
+
+func openFileCount(t *testing.T) int {
+	entries, err := ioutil.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip("can not count open files")
+	}
+	return len(entries)
+}
+
+func TestUploadPartFromFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "tos-upload-part")
+	require.Nil(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("aaaabbbbcc")
+	require.Nil(t, err)
+	require.Nil(t, file.Close())
+
+	var lock sync.Mutex
+	parts := make(map[string]string)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		data, err := ioutil.ReadAll(req.Content)
+		assert.Nil(t, err)
+		lock.Lock()
+		parts[req.Query.Get("partNumber")] = string(data)
+		lock.Unlock()
+		return newMockResponse(http.StatusOK, "")()
+	}}
+	client := newMockClient(t, transport)
+	upload := func(partNumber int, offset uint64, partSize int64) error {
+		_, err := client.UploadPartFromFile(context.Background(), &UploadPartFromFileInput{
+			UploadPartBasicInput: UploadPartBasicInput{Bucket: "bucket", Key: "key", UploadID: "upload-id", PartNumber: partNumber},
+			FilePath:             file.Name(),
+			Offset:               offset,
+			PartSize:             partSize,
+		})
+		return err
+	}
+	opened := openFileCount(t)
+	require.Nil(t, upload(1, 0, 4))
+	require.Nil(t, upload(2, 4, 4))
+	require.Nil(t, upload(3, 8, 0))
+	require.Equal(t, map[string]string{"1": "aaaa", "2": "bbbb", "3": "cc"}, parts)
+
+	require.NotNil(t, upload(4, 8, 4))
+	require.NotNil(t, upload(4, 11, 0))
+	require.Equal(t, 3, len(transport.recorded()))
+	require.Equal(t, opened, openFileCount(t))
+}
+
+func TestSSECMultipartUpload(t *testing.T) {
+	rawKey := []byte("0123456789abcdef0123456789abcdef")
+	key := base64.StdEncoding.EncodeToString(rawKey)
+	sum := md5.Sum(rawKey)
+	keyMD5 := base64.StdEncoding.EncodeToString(sum[:])
+
+	var lock sync.Mutex
+	parts := make(map[string][]byte)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		// CompleteMultipartUpload does not need SSE-C key
+		if req.Header.Get(HeaderSSECustomerKey) == "" && (req.Method != http.MethodPost || req.Query.Get("uploadId") == "") {
+			return newMockResponse(http.StatusBadRequest, `{"Code":"InvalidRequest"}`)()
+		}
+		var res *Response
+		switch {
+		case req.Method == http.MethodPost && req.Query.Get("uploadId") == "":
+			res = newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"key","UploadId":"upload-id"}`)()
+		case req.Method == http.MethodPut:
+			data, err := ioutil.ReadAll(req.Content)
+			assert.Nil(t, err)
+			lock.Lock()
+			parts[req.Query.Get("partNumber")] = data
+			lock.Unlock()
+			res = newMockResponse(http.StatusOK, "")()
+		case req.Method == http.MethodPost:
+			res = newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"key"}`)()
+		default:
+			res = newMockResponse(http.StatusOK, string(parts["1"])+string(parts["2"]))()
+		}
+		res.Header.Set(HeaderSSECustomerAlgorithm, "AES256")
+		res.Header.Set(HeaderSSECustomerKeyMD5, keyMD5)
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+	created, err := client.CreateMultipartUploadV2(ctx, &CreateMultipartUploadV2Input{
+		Bucket: "bucket", Key: "key", SSECAlgorithm: "AES256", SSECKey: key, SSECKeyMD5: keyMD5,
+	})
+	require.Nil(t, err)
+	require.Equal(t, keyMD5, created.SSECKeyMD5)
+
+	var uploaded []UploadedPartV2
+	for i, data := range []string{"hello ", "world"} {
+		output, err := client.UploadPartV2(ctx, &UploadPartV2Input{
+			UploadPartBasicInput: UploadPartBasicInput{
+				Bucket: "bucket", Key: "key", UploadID: created.UploadID, PartNumber: i + 1,
+				SSECAlgorithm: "AES256", SSECKey: key, SSECKeyMD5: keyMD5,
+			},
+			Content: strings.NewReader(data),
+		})
+		require.Nil(t, err)
+		require.Equal(t, keyMD5, output.SSECKeyMD5)
+		uploaded = append(uploaded, UploadedPartV2{PartNumber: output.PartNumber, ETag: output.ETag})
+	}
+	_, err = client.CompleteMultipartUploadV2(ctx, &CompleteMultipartUploadV2Input{
+		Bucket: "bucket", Key: "key", UploadID: created.UploadID, Parts: uploaded,
+	})
+	require.Nil(t, err)
+
+	_, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Equal(t, http.StatusBadRequest, StatusCode(err))
+	get, err := client.GetObjectV2(ctx, &GetObjectV2Input{
+		Bucket: "bucket", Key: "key", SSECAlgorithm: "AES256", SSECKey: key, SSECKeyMD5: keyMD5,
+	})
+	require.Nil(t, err)
+	data, err := ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, "hello world", string(data))
+
+	// MD5 is calculated from key if not set, and it must match the returned one
+	otherKey := base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+	_, err = client.UploadPartV2(ctx, &UploadPartV2Input{
+		UploadPartBasicInput: UploadPartBasicInput{
+			Bucket: "bucket", Key: "key", UploadID: created.UploadID, PartNumber: 3, SSECAlgorithm: "AES256", SSECKey: otherKey,
+		},
+		Content: strings.NewReader("!"),
+	})
+	_, ok := err.(*TosClientError)
+	require.True(t, ok)
+}
@@ -0,0 +1,182 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+var knownEvents = map[enum.EventType]bool{
+	enum.EventObjectCreatedAll:                     true,
+	enum.EventObjectCreatedPut:                     true,
+	enum.EventObjectCreatedPost:                    true,
+	enum.EventObjectCreatedCopy:                    true,
+	enum.EventObjectCreatedAppend:                  true,
+	enum.EventObjectCreatedCompleteMultipartUpload: true,
+	enum.EventObjectCreatedFetch:                   true,
+	enum.EventObjectRemovedAll:                     true,
+	enum.EventObjectRemovedDelete:                  true,
+	enum.EventObjectRemovedDeleteMarkerCreated:     true,
+	enum.EventObjectRestoreAll:                     true,
+	enum.EventObjectRestorePost:                    true,
+	enum.EventObjectRestoreCompleted:               true,
+}
+
+// isValidNotificationRule checks events are not empty and filter rules are prefix or suffix,
+// events unknown to sdk are allowed with a warning, so that new events of server can be used.
+func (cli *ClientV2) isValidNotificationRule(events []enum.EventType, filter NotificationFilter) error {
+	if len(events) == 0 {
+		return newTosClientError("tos: events of notification rule is empty", nil)
+	}
+	for _, event := range events {
+		if event == "" {
+			return newTosClientError("tos: empty event in notification rule", nil)
+		}
+		if !knownEvents[event] && cli.logger != nil {
+			cli.logger.Warnf("[tos] unknown notification event %s, it's sent to server as is", event)
+		}
+	}
+	for _, rule := range filter.TOSKey.FilterRules {
+		if rule.Name != enum.FilterRuleNamePrefix && rule.Name != enum.FilterRuleNameSuffix {
+			return newTosClientError("tos: invalid filter rule name, must be prefix or suffix", nil)
+		}
+	}
+	return nil
+}
+
+func isValidRocketMQ(role, instanceID, topic string) error {
+	if role == "" || instanceID == "" || topic == "" {
+		return newTosClientError("tos: Role, InstanceID and Topic of RocketMQ destination are required", nil)
+	}
+	return nil
+}
+
+// PutBucketNotification set event notification configuration of bucket, all existing configurations are replaced
+func (cli *ClientV2) PutBucketNotification(ctx context.Context, input *PutBucketNotificationInput) (*PutBucketNotificationOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	for _, conf := range input.CloudFunctionConfigurations {
+		if err := cli.isValidNotificationRule(conf.Events, conf.Filter); err != nil {
+			return nil, err
+		}
+		if conf.CloudFunction == "" {
+			return nil, newTosClientError("tos: CloudFunction of notification configuration is required", nil)
+		}
+	}
+	for _, conf := range input.RocketMQConfigurations {
+		if err := cli.isValidNotificationRule(conf.Events, conf.Filter); err != nil {
+			return nil, err
+		}
+		if err := isValidRocketMQ(conf.Role, conf.RocketMQ.InstanceID, conf.RocketMQ.Topic); err != nil {
+			return nil, err
+		}
+	}
+	data, contentMD5, err := marshalInput("PutBucketNotificationInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("notification", "").
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketNotificationOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketNotification get event notification configuration of bucket
+func (cli *ClientV2) GetBucketNotification(ctx context.Context, input *GetBucketNotificationInput) (*GetBucketNotificationOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("notification", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetBucketNotificationOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// PutBucketNotificationType2 set event notification rules of bucket in type2 format,
+// in which a rule can deliver events to multiple destinations. All existing rules are replaced.
+func (cli *ClientV2) PutBucketNotificationType2(ctx context.Context, input *PutBucketNotificationType2Input) (*PutBucketNotificationType2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	for _, rule := range input.Rules {
+		if err := cli.isValidNotificationRule(rule.Events, rule.Filter); err != nil {
+			return nil, err
+		}
+		destination := rule.Destination
+		if len(destination.VeFaaS) == 0 && len(destination.RocketMQ) == 0 {
+			return nil, newTosClientError("tos: destination of notification rule is empty", nil)
+		}
+		for _, faas := range destination.VeFaaS {
+			if faas.FunctionID == "" {
+				return nil, newTosClientError("tos: FunctionID of VeFaaS destination is required", nil)
+			}
+		}
+		for _, mq := range destination.RocketMQ {
+			if err := isValidRocketMQ(mq.Role, mq.InstanceID, mq.Topic); err != nil {
+				return nil, err
+			}
+		}
+	}
+	data, contentMD5, err := marshalInput("PutBucketNotificationType2Input", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("notification_v2", "").
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketNotificationType2Output{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketNotificationType2 get event notification rules of bucket in type2 format
+func (cli *ClientV2) GetBucketNotificationType2(ctx context.Context, input *GetBucketNotificationType2Input) (*GetBucketNotificationType2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("notification_v2", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetBucketNotificationType2Output{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
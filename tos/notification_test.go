@@ -0,0 +1,98 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+const (
+	// bucketNotification is the serialized form of the configurations in TestBucketNotification
+	bucketNotification = `{"CloudFunctionConfigurations":[{"RuleId":"function","CloudFunction":"function-id",
+		"Events":["tos:ObjectCreated:Put","tos:ObjectCreated:CompleteMultipartUpload"],
+		"Filter":{"TOSKey":{"FilterRules":[{"Name":"prefix","Value":"images/"},{"Name":"suffix","Value":".png"}]}}}],
+		"RocketMQConfigurations":[{"RuleId":"mq","Role":"trn:iam::2100000001:role/tos","Events":["tos:ObjectRemoved:*"],
+		"Filter":{"TOSKey":{"FilterRules":[{"Name":"prefix","Value":"images/"},{"Name":"suffix","Value":".png"}]}},
+		"RocketMQ":{"InstanceId":"instance","Topic":"topic"}}]}`
+	// bucketNotificationType2 is the serialized form of the rules in TestBucketNotification
+	bucketNotificationType2 = `{"Rules":[{"RuleId":"rule","Events":["tos:ObjectCreated:Put","tos:ObjectCreated:Rename"],
+		"Filter":{"TOSKey":{"FilterRules":[{"Name":"prefix","Value":"images/"},{"Name":"suffix","Value":".png"}]}},
+		"Destination":{"VeFaaS":[{"FunctionId":"function-id"}],
+		"RocketMQ":[{"Role":"trn:iam::2100000001:role/tos","InstanceId":"instance","Topic":"topic","Region":"cn-beijing"}]}}]}`
+)
+
+func TestBucketNotification(t *testing.T) {
+	transport := &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, bucketNotification),
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, bucketNotificationType2),
+	}}
+	client := newMockClient(t, transport)
+	var log bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&log)
+	logger.SetLevel(logrus.WarnLevel)
+	client.logger = logger
+	ctx := context.Background()
+
+	filter := NotificationFilter{TOSKey: NotificationFilterKey{FilterRules: []NotificationFilterRule{
+		{Name: enum.FilterRuleNamePrefix, Value: "images/"}, {Name: enum.FilterRuleNameSuffix, Value: ".png"}}}}
+	input := &PutBucketNotificationInput{
+		Bucket: "bucket",
+		CloudFunctionConfigurations: []CloudFunctionConfiguration{{ID: "function", CloudFunction: "function-id", Filter: filter,
+			Events: []enum.EventType{enum.EventObjectCreatedPut, enum.EventObjectCreatedCompleteMultipartUpload}}},
+		RocketMQConfigurations: []RocketMQConfiguration{{ID: "mq", Role: "trn:iam::2100000001:role/tos", Filter: filter,
+			Events: []enum.EventType{enum.EventObjectRemovedAll}, RocketMQ: RocketMQConf{InstanceID: "instance", Topic: "topic"}}},
+	}
+	_, err := client.PutBucketNotification(ctx, input)
+	require.Nil(t, err)
+	require.Contains(t, transport.recorded()[0].Query, "notification")
+	require.JSONEq(t, bucketNotification, requestBody(t, transport.recorded()[0]))
+	require.Empty(t, log.String())
+	get, err := client.GetBucketNotification(ctx, &GetBucketNotificationInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, input.CloudFunctionConfigurations, get.CloudFunctionConfigurations)
+	require.Equal(t, input.RocketMQConfigurations, get.RocketMQConfigurations)
+
+	// unknown events are sent with a warning
+	input2 := &PutBucketNotificationType2Input{
+		Bucket: "bucket",
+		Rules: []NotificationRule{{RuleID: "rule", Filter: filter,
+			Events: []enum.EventType{enum.EventObjectCreatedPut, "tos:ObjectCreated:Rename"},
+			Destination: NotificationDestination{
+				VeFaaS:   []DestinationVeFaaS{{FunctionID: "function-id"}},
+				RocketMQ: []DestinationRocketMQ{{Role: "trn:iam::2100000001:role/tos", InstanceID: "instance", Topic: "topic", Region: "cn-beijing"}},
+			}}},
+	}
+	_, err = client.PutBucketNotificationType2(ctx, input2)
+	require.Nil(t, err)
+	require.Contains(t, log.String(), "tos:ObjectCreated:Rename")
+	require.Contains(t, transport.recorded()[2].Query, "notification_v2")
+	require.JSONEq(t, bucketNotificationType2, requestBody(t, transport.recorded()[2]))
+	get2, err := client.GetBucketNotificationType2(ctx, &GetBucketNotificationType2Input{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, input2.Rules, get2.Rules)
+
+	requests := len(transport.recorded())
+	_, err = client.PutBucketNotification(ctx, &PutBucketNotificationInput{Bucket: "bucket",
+		CloudFunctionConfigurations: []CloudFunctionConfiguration{{CloudFunction: "function-id"}}})
+	require.NotNil(t, err)
+	_, err = client.PutBucketNotification(ctx, &PutBucketNotificationInput{Bucket: "bucket",
+		RocketMQConfigurations: []RocketMQConfiguration{{Events: []enum.EventType{enum.EventObjectCreatedPut}, Role: "role"}}})
+	require.NotNil(t, err)
+	_, err = client.PutBucketNotificationType2(ctx, &PutBucketNotificationType2Input{Bucket: "bucket",
+		Rules: []NotificationRule{{Events: []enum.EventType{enum.EventObjectCreatedPut}}}})
+	require.NotNil(t, err)
+	_, err = client.PutBucketNotificationType2(ctx, &PutBucketNotificationType2Input{Bucket: "bucket",
+		Rules: []NotificationRule{{Events: []enum.EventType{enum.EventObjectCreatedPut},
+			Filter:      NotificationFilter{TOSKey: NotificationFilterKey{FilterRules: []NotificationFilterRule{{Name: "regex"}}}},
+			Destination: NotificationDestination{VeFaaS: []DestinationVeFaaS{{FunctionID: "function-id"}}}}}})
+	require.NotNil(t, err)
+	require.Equal(t, requests, len(transport.recorded()))
+}
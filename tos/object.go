@@ -2,7 +2,9 @@ package tos
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"hash"
@@ -11,7 +13,9 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 type Bucket struct {
@@ -45,37 +49,86 @@ func (bkt *Bucket) GetObject(ctx context.Context, objectKey string, options ...O
 	return &output, nil
 }
 
-// GetObjectToFile get object and write it to file
-func (cli *ClientV2) GetObjectToFile(ctx context.Context, input *GetObjectToFileInput) (*GetObjectToFileOutput, error) {
-	tempFilePath := input.FilePath + TempFileSuffix
-	fd, err := os.OpenFile(tempFilePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, DefaultFilePerm)
+// GetObjectToFile get object and write it to file.
+// The object is written to a temp file created exclusively beside FilePath first, which is renamed to FilePath
+// after downloaded, so that FilePath is never left truncated and concurrent downloads to the same FilePath
+// don't corrupt each other. The temp file is removed if failed.
+// CRC64 of the object is checked if it is enabled by client and the whole object is downloaded.
+func (cli *ClientV2) GetObjectToFile(ctx context.Context, input *GetObjectToFileInput) (output *GetObjectToFileOutput, err error) {
+	if input.CreateParentDir {
+		if err = os.MkdirAll(filepath.Dir(input.FilePath), os.ModePerm); err != nil {
+			return nil, newTosClientError("tos: create directory to download failed", err)
+		}
+	}
+	if err = checkDownloadTarget(input.FilePath); err != nil {
+		return nil, err
+	}
+	fd, tempFile, err := createExclusiveTempFile(input.FilePath, input.TempFileSuffix)
 	if err != nil {
 		return nil, err
 	}
-	defer fd.Close()
+	defer func() {
+		_ = fd.Close()
+		if err != nil {
+			_ = os.Remove(tempFile)
+		}
+	}()
 	get, err := cli.GetObjectV2(ctx, &input.GetObjectV2Input)
 	if err != nil {
 		return nil, err
 	}
-	_, err = io.Copy(fd, get.Content)
-	if err != nil {
+	defer get.Content.Close()
+	if _, err = io.Copy(fd, get.Content); err != nil {
 		return nil, err
 	}
-	err = os.Rename(tempFilePath, input.FilePath)
-	if err != nil {
+	if err = fd.Close(); err != nil {
+		return nil, newTosClientError("tos: close temp file failed", err)
+	}
+	if err = commitTempFile(tempFile, input.FilePath); err != nil {
 		return nil, err
 	}
 	return &GetObjectToFileOutput{get.GetObjectBasicOutput}, nil
 }
 
-// GetObjectV2 get data and metadata of an object
+// GetObjectV2 get data and metadata of an object.
+// If the requested range is not satisfiable, *InvalidRangeError with the size of the object is returned,
+// and *NotModifiedError or *PreconditionFailedError is returned if the conditional headers are not satisfied.
 func (cli *ClientV2) GetObjectV2(ctx context.Context, input *GetObjectV2Input) (*GetObjectV2Output, error) {
 	if err := isValidNames(input.Bucket, input.Key); err != nil {
 		return nil, err
 	}
+	if len(input.SaveBucket) > 0 || len(input.SaveObject) > 0 {
+		if len(input.Process) == 0 {
+			return nil, newTosClientError("tos: Process is required to save processed data", nil)
+		}
+		if len(input.SaveObject) == 0 {
+			return nil, newTosClientError("tos: SaveObject is required if SaveBucket is set", nil)
+		}
+		if err := isValidKey(input.SaveObject); err != nil {
+			return nil, err
+		}
+		if len(input.SaveBucket) > 0 {
+			if err := IsValidBucketName(input.SaveBucket); err != nil {
+				return nil, err
+			}
+		}
+	}
 	rb := cli.newBuilder(input.Bucket, input.Key).
 		WithParams(*input)
-	if input.RangeEnd != 0 || input.RangeStart != 0 {
+	if len(input.SaveObject) > 0 {
+		rb.WithQuery("x-tos-save-object", base64.URLEncoding.EncodeToString([]byte(input.SaveObject)))
+		if len(input.SaveBucket) > 0 {
+			rb.WithQuery("x-tos-save-bucket", base64.URLEncoding.EncodeToString([]byte(input.SaveBucket)))
+		}
+	}
+	if input.Range != nil {
+		if err := input.Range.validate(); err != nil {
+			return nil, err
+		}
+		// set rb.Range will change expected code
+		rb.Range = &Range{Start: input.Range.Start, End: input.Range.End}
+		rb.WithHeader(HeaderRange, input.Range.FormatHTTPRange())
+	} else if input.RangeEnd != 0 || input.RangeStart != 0 {
 		if input.RangeEnd < input.RangeStart {
 			return nil, errors.New("tos: invalid range")
 		}
@@ -85,20 +138,99 @@ func (cli *ClientV2) GetObjectV2(ctx context.Context, input *GetObjectV2Input) (
 	}
 	res, err := rb.Request(ctx, http.MethodGet, nil, cli.roundTripper(expectedCode(rb)))
 	if err != nil {
-		return nil, err
+		return nil, newConditionalError(newInvalidRangeError(err))
 	}
 	basic := GetObjectBasicOutput{
 		RequestInfo:  res.RequestInfo(),
 		ContentRange: res.Header.Get(HeaderContentRange),
 	}
+	if basic.ContentRange != "" {
+		basic.Range, _ = parseContentRange(basic.ContentRange)
+	}
 	basic.ObjectMetaV2.fromResponseV2(res)
+	if len(input.SaveObject) > 0 {
+		defer res.Close()
+		var result ProcessSaveResult
+		if err = marshalOutput(basic.RequestID, res.Body, &result); err != nil {
+			return nil, err
+		}
+		return &GetObjectV2Output{
+			GetObjectBasicOutput: basic,
+			Content:              ioutil.NopCloser(bytes.NewReader(nil)),
+			SaveResult:           &result,
+		}, nil
+	}
+	var body io.ReadCloser = res.Body
+	if input.MaxResumeCount > 0 && input.PartNumber == 0 && len(input.Process) == 0 && len(basic.ETag) > 0 {
+		body = newReadCloserWithResume(ctx, cli, input, res.Body, &basic)
+	}
+	total := res.ContentLength
+	decompressed := false
+	// partial gzip content can not be decompressed alone
+	if input.AutoDecompress && res.StatusCode == http.StatusOK &&
+		strings.EqualFold(strings.TrimSpace(basic.ContentEncoding), "gzip") {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			res.Close()
+			return nil, newTosClientError("tos: invalid gzip content, request id: "+basic.RequestID, err)
+		}
+		body, total, decompressed = &gzipReadCloser{Reader: gz, base: body}, -1, true
+	}
 	output := GetObjectV2Output{
 		GetObjectBasicOutput: basic,
-		Content:              wrapReader(res.Body, res.ContentLength, input.DataTransferListener, input.RateLimiter, nil),
+		Content:              wrapReader(body, total, input.DataTransferListener, input.RateLimiter, nil),
+		Decompressed:         decompressed,
+	}
+	// neither partial content nor processed content can be checked with CRC64 of the whole object,
+	// and CRC64 of gzip object is calculated from compressed data
+	if cli.enableCRC && !input.DisableCRCCheck && !decompressed && rb.Range == nil && input.PartNumber == 0 && len(input.Process) == 0 &&
+		res.StatusCode != http.StatusPartialContent {
+		if expected, err := strconv.ParseUint(res.Header.Get(HeaderHashCrc64ecma), 10, 64); err == nil {
+			output.Content = &readCloserWithCRCCheck{
+				base:      output.Content,
+				checker:   NewCRC(DefaultCrcTable(), 0),
+				expected:  expected,
+				requestID: basic.RequestID,
+			}
+		}
 	}
+	output.Content = newReadCloserWithWriterTo(output.Content)
 	return &output, nil
 }
 
+// gzipReadCloser decompresses gzip content, Close closes both the gzip reader and the base body
+type gzipReadCloser struct {
+	*gzip.Reader
+	base io.Closer
+}
+
+func (r *gzipReadCloser) Close() error {
+	err := r.Reader.Close()
+	if baseErr := r.base.Close(); baseErr != nil {
+		return baseErr
+	}
+	return err
+}
+
+// DoesObjectExist returns false only if the object or the bucket is not found,
+// other errors such as 403 and network errors are returned as is.
+//   options: WithVersionID which version of this object
+func (cli *ClientV2) DoesObjectExist(ctx context.Context, bucket, key string, options ...Option) (bool, error) {
+	if err := isValidNames(bucket, key); err != nil {
+		return false, err
+	}
+	res, err := cli.newBuilder(bucket, key, options...).
+		Request(ctx, http.MethodHead, nil, cli.roundTripper(http.StatusOK))
+	if err == nil {
+		res.Close()
+		return true, nil
+	}
+	if isNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
 // HeadObject get metadata of an object
 //  objectKey: the name of object
 //  options: WithVersionID which version of this object
@@ -127,7 +259,8 @@ func (bkt *Bucket) HeadObject(ctx context.Context, objectKey string, options ...
 	return &output, nil
 }
 
-// HeadObjectV2 get metadata of an object
+// HeadObjectV2 get metadata of an object.
+// *NotModifiedError or *PreconditionFailedError is returned if the conditional headers are not satisfied.
 func (cli *ClientV2) HeadObjectV2(ctx context.Context, input *HeadObjectV2Input) (*HeadObjectV2Output, error) {
 	if err := isValidNames(input.Bucket, input.Key); err != nil {
 		return nil, err
@@ -138,7 +271,7 @@ func (cli *ClientV2) HeadObjectV2(ctx context.Context, input *HeadObjectV2Input)
 		WithRetry(nil, StatusCodeClassifier{})
 	res, err := rb.Request(ctx, http.MethodHead, nil, cli.roundTripper(expectedCode(rb)))
 	if err != nil {
-		return nil, err
+		return nil, newConditionalError(err)
 	}
 	defer res.Close()
 
@@ -188,10 +321,13 @@ func (cli *ClientV2) DeleteObjectV2(ctx context.Context, input *DeleteObjectV2In
 		return nil, err
 	}
 
-	res, err := cli.newBuilder(input.Bucket, input.Key).
+	rb := cli.newBuilder(input.Bucket, input.Key).
 		WithParams(*input).
-		WithRetry(nil, StatusCodeClassifier{}).
-		Request(ctx, http.MethodDelete, nil, cli.roundTripper(http.StatusNoContent))
+		WithRetry(nil, StatusCodeClassifier{})
+	if input.BypassGovernanceRetention {
+		rb.WithHeader(HeaderBypassGovernanceRetention, "true")
+	}
+	res, err := rb.Request(ctx, http.MethodDelete, nil, cli.roundTripper(http.StatusNoContent))
 	if err != nil {
 		return nil, err
 	}
@@ -238,11 +374,18 @@ func (bkt *Bucket) DeleteMultiObjects(ctx context.Context, input *DeleteMultiObj
 	return &output, nil
 }
 
-// DeleteMultiObjects delete multi-objects
+// DeleteMultiObjects delete at most MaxDeleteObjectsCount objects at once.
+// Objects failed to delete are returned in Error of output with their codes, and Deleted is empty if Quiet is set.
 func (cli *ClientV2) DeleteMultiObjects(ctx context.Context, input *DeleteMultiObjectsInput) (*DeleteMultiObjectsOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
 	if err := IsValidBucketName(input.Bucket); err != nil {
 		return nil, err
 	}
+	if len(input.Objects) == 0 || len(input.Objects) > MaxDeleteObjectsCount {
+		return nil, newTosClientError(fmt.Sprintf("tos: the number of objects to delete must be 1 to %d", MaxDeleteObjectsCount), nil)
+	}
 	for _, object := range input.Objects {
 		if err := isValidKey(object.Key); err != nil {
 			return nil, err
@@ -256,11 +399,14 @@ func (cli *ClientV2) DeleteMultiObjects(ctx context.Context, input *DeleteMultiO
 		return nil, err
 	}
 	// POST method, don't retry
-	res, err := cli.newBuilder(input.Bucket, "").
+	rb := cli.newBuilder(input.Bucket, "").
 		WithQuery("delete", "").
 		WithHeader(HeaderContentMD5, contentMD5).
-		WithRetry(nil, ServerErrorClassifier{}).
-		Request(ctx, http.MethodPost, bytes.NewReader(in), cli.roundTripper(http.StatusOK))
+		WithRetry(nil, ServerErrorClassifier{})
+	if input.BypassGovernanceRetention {
+		rb.WithHeader(HeaderBypassGovernanceRetention, "true")
+	}
+	res, err := rb.Request(ctx, http.MethodPost, bytes.NewReader(in), cli.roundTripper(http.StatusOK))
 	if err != nil {
 		return nil, err
 	}
@@ -357,16 +503,19 @@ func wrapReader(reader io.Reader, totalBytes int64, listener DataTransferListene
 	// get base ReadCloser
 	if rc, ok := reader.(io.ReadCloser); ok {
 		wrapped = rc
+	} else {
+		wrapped = ioutil.NopCloser(reader)
 	}
-	wrapped = ioutil.NopCloser(reader)
 	// wrap with listener
+	var listened *readCloserWithListener
 	if listener != nil {
-		wrapped = &readCloserWithListener{
+		listened = &readCloserWithListener{
 			listener: listener,
 			base:     wrapped,
 			consumed: 0,
 			total:    totalBytes,
 		}
+		wrapped = listened
 	}
 	// wrap with limiter
 	if limiter != nil {
@@ -382,6 +531,18 @@ func wrapReader(reader io.Reader, totalBytes int64, listener DataTransferListene
 			base:    wrapped,
 		}
 	}
+	// keep seekable, so that the request can be retried
+	if seeker, ok := reader.(io.Seeker); ok {
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			return &seekableReadCloser{
+				ReadCloser: wrapped,
+				seeker:     seeker,
+				start:      start,
+				checker:    checker,
+				listened:   listened,
+			}
+		}
+	}
 	return wrapped
 }
 
@@ -390,6 +551,12 @@ func (cli *ClientV2) PutObjectV2(ctx context.Context, input *PutObjectV2Input) (
 	if err := isValidNames(input.Bucket, input.Key); err != nil {
 		return nil, err
 	}
+	if err := isValidSSE(input.SSECAlgorithm, input.SSECKey, input.ServerSideEncryption, input.ServerSideEncryptionKeyID); err != nil {
+		return nil, err
+	}
+	if err := isValidStorageClass(input.StorageClass); err != nil {
+		return nil, err
+	}
 	var (
 		checker       hash.Hash64
 		content       = input.Content
@@ -401,6 +568,21 @@ func (cli *ClientV2) PutObjectV2(ctx context.Context, input *PutObjectV2Input) (
 	if contentLength <= 0 {
 		contentLength = tryResolveLength(content)
 	}
+	var md5Sum []byte
+	if input.ContentMD5 == "" && (cli.enableMD5 || input.EnableContentMD5) {
+		var err error
+		if content, md5Sum, err = contentWithMD5(content, contentLength, cli.md5Limit); err != nil {
+			return nil, err
+		}
+		if contentLength < 0 {
+			contentLength = tryResolveLength(content)
+		}
+	}
+	// content of unknown length is sent with Transfer-Encoding: chunked, and can't be rewound on retrying
+	chunked := content != nil && contentLength < 0 && !input.EnableTrailingChecksum
+	if chunked && !input.AllowChunked {
+		return nil, newTosClientError("tos: the length of content is unknown, please set ContentLength or AllowChunked", nil)
+	}
 	if content != nil {
 		content = wrapReader(content, contentLength, input.DataTransferListener, input.RateLimiter, checker)
 	}
@@ -409,7 +591,7 @@ func (cli *ClientV2) PutObjectV2(ctx context.Context, input *PutObjectV2Input) (
 		classifier classifier
 	)
 	classifier = StatusCodeClassifier{}
-	if seeker, ok := content.(io.Seeker); ok {
+	if seeker, ok := content.(io.Seeker); ok && !chunked {
 		start, err := seeker.Seek(0, io.SeekCurrent)
 		if err != nil {
 			return nil, err
@@ -426,39 +608,84 @@ func (cli *ClientV2) PutObjectV2(ctx context.Context, input *PutObjectV2Input) (
 	if onRetry == nil {
 		classifier = ServerErrorClassifier{}
 	}
+	if chunked {
+		classifier = NoRetryClassifier{}
+	}
 	rb := cli.newBuilder(input.Bucket, input.Key).
 		WithContentLength(contentLength).
 		WithParams(*input).
 		WithRetry(onRetry, classifier)
+	if md5Sum != nil {
+		rb.WithHeader(HeaderContentMD5, base64MD5(md5Sum))
+	}
+	if chunked && rb.Header.Get(HeaderContentSha256) == "" {
+		rb.WithHeader(HeaderContentSha256, unsignedPayload)
+	}
+	if input.EnableTrailingChecksum {
+		content = withTrailingChecksum(rb, content, contentLength)
+	}
 	res, err := rb.Request(ctx, http.MethodPut, content, cli.roundTripper(http.StatusOK))
 	if err != nil {
 		return nil, err
 	}
 	defer res.Close()
+	if err = checkSSECKeyMD5(res, input.SSECKey, input.SSECKeyMD5); err != nil {
+		return nil, err
+	}
 	if err = checkCrc64(res, checker); err != nil {
 		return nil, err
 	}
+	// ETag of object encrypted by server is not MD5 of the object
+	if md5Sum != nil && input.SSECAlgorithm == "" && input.ServerSideEncryption == "" {
+		if err = checkETagWithMD5(res.Header.Get(HeaderETag), md5Sum, res.RequestInfo().RequestID); err != nil {
+			return nil, err
+		}
+	}
 	crc64, _ := strconv.ParseUint(res.Header.Get(HeaderHashCrc64ecma), 10, 64)
 	return &PutObjectV2Output{
-		RequestInfo:   res.RequestInfo(),
-		ETag:          res.Header.Get(HeaderETag),
-		SSECAlgorithm: res.Header.Get(HeaderSSECustomerAlgorithm),
-		SSECKeyMD5:    res.Header.Get(HeaderSSECustomerKeyMD5),
-		VersionID:     res.Header.Get(HeaderVersionID),
-		HashCrc64ecma: crc64,
+		RequestInfo:               res.RequestInfo(),
+		ETag:                      res.Header.Get(HeaderETag),
+		SSECAlgorithm:             res.Header.Get(HeaderSSECustomerAlgorithm),
+		SSECKeyMD5:                res.Header.Get(HeaderSSECustomerKeyMD5),
+		ServerSideEncryption:      res.Header.Get(HeaderServerSideEncryption),
+		ServerSideEncryptionKeyID: res.Header.Get(HeaderSSEKMSKeyID),
+		VersionID:                 res.Header.Get(HeaderVersionID),
+		HashCrc64ecma:             crc64,
 	}, nil
 }
 
-// PutObjectFromFile put an object from file
+// PutObjectFromFile put an object from file.
+// ContentLength is resolved from the file, and Content-Type is recognized from the extension of the file
+// if it is not set and can't be recognized from the object key.
+// If Offset or Length is set, only the slice of the file is uploaded.
 func (cli *ClientV2) PutObjectFromFile(ctx context.Context, input *PutObjectFromFileInput) (*PutObjectFromFileOutput, error) {
+	stat, err := os.Stat(input.FilePath)
+	if err != nil {
+		return nil, newTosClientError("tos: stat file to upload failed", err)
+	}
+	if stat.IsDir() {
+		return nil, newTosClientError("tos: the file path to upload is a directory: "+input.FilePath, nil)
+	}
+	length := stat.Size() - input.Offset
+	if input.Length > 0 {
+		length = input.Length
+	}
+	if input.Offset < 0 || length < 0 || input.Offset+length > stat.Size() {
+		return nil, newTosClientError("tos: invalid offset or length of the file to upload", nil)
+	}
 	file, err := os.Open(input.FilePath)
 	if err != nil {
-		return nil, err
+		return nil, newTosClientError("tos: open file to upload failed", err)
 	}
 	defer file.Close()
+	basic := input.PutObjectBasicInput
+	basic.ContentLength = length
+	if basic.ContentType == "" && cli.recognizer.ContentType(basic.Key) == "" {
+		basic.ContentType = cli.recognizer.ContentType(input.FilePath)
+	}
 	putOutput, err := cli.PutObjectV2(ctx, &PutObjectV2Input{
-		PutObjectBasicInput: input.PutObjectBasicInput,
-		Content:             file,
+		PutObjectBasicInput: basic,
+		Content:             io.NewSectionReader(file, input.Offset, length),
 	})
 	if err != nil {
 		return nil, err
@@ -514,11 +741,18 @@ func (bkt *Bucket) AppendObject(ctx context.Context, objectKey string, content i
 	}, nil
 }
 
-// AppendObjectV2 append content at the tail of an appendable object
+// AppendObjectV2 append content at the tail of an appendable object, Offset must be equal to the length of the object.
+// If not, *AppendPositionError with the offset expected by server is returned.
 func (cli *ClientV2) AppendObjectV2(ctx context.Context, input *AppendObjectV2Input) (*AppendObjectV2Output, error) {
 	if err := isValidNames(input.Bucket, input.Key); err != nil {
 		return nil, err
 	}
+	if err := isValidSSE("", "", input.ServerSideEncryption, input.ServerSideEncryptionKeyID); err != nil {
+		return nil, err
+	}
+	if err := isValidStorageClass(input.StorageClass); err != nil {
+		return nil, err
+	}
 	var (
 		checker       hash.Hash64
 		content       = input.Content
@@ -527,7 +761,8 @@ func (cli *ClientV2) AppendObjectV2(ctx context.Context, input *AppendObjectV2In
 	if contentLength <= 0 {
 		contentLength = tryResolveLength(content)
 	}
-	if cli.enableCRC {
+	// CRC64 of the whole object can not be checked if CRC64 of the data before Offset is unknown
+	if cli.enableCRC && (input.Offset == 0 || input.PreHashCrc64ecma != 0) {
 		checker = NewCRC(DefaultCrcTable(), input.PreHashCrc64ecma)
 	}
 	if content != nil {
@@ -540,7 +775,7 @@ func (cli *ClientV2) AppendObjectV2(ctx context.Context, input *AppendObjectV2In
 		WithRetry(nil, NoRetryClassifier{}).
 		Request(ctx, http.MethodPost, content, cli.roundTripper(http.StatusOK))
 	if err != nil {
-		return nil, err
+		return nil, newAppendPositionError(err)
 	}
 	defer res.Close()
 
@@ -557,9 +792,78 @@ func (cli *ClientV2) AppendObjectV2(ctx context.Context, input *AppendObjectV2In
 	}
 	crc64, _ := strconv.ParseUint(res.Header.Get(HeaderHashCrc64ecma), 10, 64)
 	return &AppendObjectV2Output{
+		RequestInfo:               res.RequestInfo(),
+		VersionID:                 res.Header.Get(HeaderVersionID),
+		NextAppendOffset:          appendOffset,
+		HashCrc64ecma:             crc64,
+		ServerSideEncryption:      res.Header.Get(HeaderServerSideEncryption),
+		ServerSideEncryptionKeyID: res.Header.Get(HeaderSSEKMSKeyID),
+	}, nil
+}
+
+// ModifyObjectV2 writes content at Offset of an object in hierarchical namespace bucket,
+// Offset must not be larger than the length of the object.
+// If Offset conflicts with the length of the object, *ModifyPositionError is returned.
+func (cli *ClientV2) ModifyObjectV2(ctx context.Context, input *ModifyObjectV2Input) (*ModifyObjectV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	var (
+		content       = input.Content
+		contentLength = input.ContentLength
+	)
+	if contentLength <= 0 {
+		contentLength = tryResolveLength(content)
+	}
+	if content != nil {
+		content = wrapReader(content, contentLength, input.DataTransferListener, input.RateLimiter, nil)
+	}
+	var (
+		onRetry    func(req *Request) = nil
+		classifier classifier
+	)
+	classifier = StatusCodeClassifier{}
+	if seeker, ok := content.(io.Seeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		onRetry = func(req *Request) {
+			// writing the same content at the same offset is idempotent if the request body can be rewound
+			if seeker, ok := req.Content.(io.Seeker); ok {
+				seeker.Seek(start, io.SeekStart)
+			}
+		}
+	}
+	if onRetry == nil {
+		classifier = ServerErrorClassifier{}
+	}
+	res, err := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("modify", "").
+		WithParams(*input).
+		WithContentLength(contentLength).
+		WithRetry(onRetry, classifier).
+		Request(ctx, http.MethodPost, content, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, newModifyPositionError(err)
+	}
+	defer res.Close()
+
+	nextOffset := res.Header.Get(HeaderNextModifyOffset)
+	modifyOffset, err := strconv.ParseInt(nextOffset, 10, 64)
+	if err != nil {
+		return nil, &TosServerError{
+			TosError:    TosError{fmt.Sprintf("tos: server return unexpected Next-Modify-Offset header %q", nextOffset)},
+			RequestInfo: res.RequestInfo(),
+		}
+	}
+	crc64, _ := strconv.ParseUint(res.Header.Get(HeaderHashCrc64ecma), 10, 64)
+	return &ModifyObjectV2Output{
 		RequestInfo:      res.RequestInfo(),
-		VersionID:        res.Header.Get(HeaderVersionID),
-		NextAppendOffset: appendOffset,
+		NextModifyOffset: modifyOffset,
 		HashCrc64ecma:    crc64,
 	}, nil
 }
@@ -595,11 +899,39 @@ func (bkt *Bucket) SetObjectMeta(ctx context.Context, objectKey string, options
 }
 
 // SetObjectMeta overwrites metadata of the object
+//
+// Deprecated: use SetObjectMetaV2 instead
 func (cli *ClientV2) SetObjectMeta(ctx context.Context, input *SetObjectMetaInput) (*SetObjectMetaOutput, error) {
-	if err := isValidNames(input.Bucket, input.Key); err != nil {
+	output, err := cli.SetObjectMetaV2(ctx, &SetObjectMetaV2Input{
+		Bucket:             input.Bucket,
+		Key:                input.Key,
+		VersionID:          input.VersionID,
+		CacheControl:       input.CacheControl,
+		ContentDisposition: input.ContentDisposition,
+		ContentEncoding:    input.ContentEncoding,
+		ContentLanguage:    input.ContentLanguage,
+		ContentType:        input.ContentType,
+		Expires:            input.Expires,
+		Meta:               input.Meta,
+	})
+	if err != nil {
 		return nil, err
 	}
+	return &SetObjectMetaOutput{RequestInfo: output.RequestInfo}, nil
+}
 
+// SetObjectMetaV2 overwrites metadata of the object without rewriting its data.
+// Meta keys and values with Chinese characters are percent-encoded the same as PutObjectV2.
+func (cli *ClientV2) SetObjectMetaV2(ctx context.Context, input *SetObjectMetaV2Input) (*SetObjectMetaV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	if err := isValidMeta(input.Meta); err != nil {
+		return nil, err
+	}
 	res, err := cli.newBuilder(input.Bucket, input.Key).
 		WithQuery("metadata", "").
 		WithParams(*input).
@@ -610,7 +942,35 @@ func (cli *ClientV2) SetObjectMeta(ctx context.Context, input *SetObjectMetaInpu
 	}
 	defer res.Close()
 
-	return &SetObjectMetaOutput{RequestInfo: res.RequestInfo()}, nil
+	return &SetObjectMetaV2Output{RequestInfo: res.RequestInfo()}, nil
+}
+
+// RestoreObjectV2 restore an archived object for Days, the restored copy can be read after restored.
+// If the object is being restored, *RestoreInProgressError is returned.
+func (cli *ClientV2) RestoreObjectV2(ctx context.Context, input *RestoreObjectV2Input) (*RestoreObjectV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("RestoreObjectV2Input", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("restore", "").
+		WithParams(*input).
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPost, bytes.NewReader(data), cli.roundTripper(http.StatusAccepted, http.StatusOK))
+	if err != nil {
+		return nil, newRestoreInProgressError(err)
+	}
+	defer res.Close()
+	return &RestoreObjectV2Output{
+		RequestInfo: res.RequestInfo(),
+		Accepted:    res.StatusCode == http.StatusAccepted,
+	}, nil
 }
 
 // ListObjects list objects of a bucket
@@ -655,17 +1015,37 @@ func (cli *ClientV2) ListObjectsV2(ctx context.Context, input *ListObjectsV2Inpu
 	if err = marshalOutput(temp.RequestID, res.Body, &temp); err != nil {
 		return nil, err
 	}
-	contents := make([]ListedObjectV2, 0, len(temp.Contents))
-	for _, object := range temp.Contents {
+	contents, err := convertListedObjectsV2(temp.RequestID, temp.Contents)
+	if err != nil {
+		return nil, err
+	}
+	output := ListObjectsV2Output{
+		RequestInfo:    temp.RequestInfo,
+		Name:           temp.Name,
+		Prefix:         temp.Prefix,
+		Marker:         temp.Marker,
+		MaxKeys:        temp.MaxKeys,
+		NextMarker:     temp.NextMarker,
+		Delimiter:      temp.Delimiter,
+		IsTruncated:    temp.IsTruncated,
+		EncodingType:   temp.EncodingType,
+		CommonPrefixes: temp.CommonPrefixes,
+		Contents:       contents,
+	}
+	return &output, nil
+}
+
+func convertListedObjectsV2(requestID string, listed []listedObjectV2) ([]ListedObjectV2, error) {
+	contents := make([]ListedObjectV2, 0, len(listed))
+	for _, object := range listed {
 		var hashCrc uint64
-		if len(object.HashCrc64ecma) == 0 {
-			hashCrc = 0
-		} else {
+		if len(object.HashCrc64ecma) != 0 {
+			var err error
 			hashCrc, err = strconv.ParseUint(object.HashCrc64ecma, 10, 64)
 			if err != nil {
 				return nil, &TosServerError{
 					TosError:    TosError{Message: "tos: server returned invalid HashCrc64Ecma"},
-					RequestInfo: RequestInfo{RequestID: temp.RequestID},
+					RequestInfo: RequestInfo{RequestID: requestID},
 				}
 			}
 		}
@@ -676,25 +1056,84 @@ func (cli *ClientV2) ListObjectsV2(ctx context.Context, input *ListObjectsV2Inpu
 			Size:          object.Size,
 			Owner:         object.Owner,
 			StorageClass:  object.StorageClass,
-			HashCrc64ecma: uint64(hashCrc),
+			HashCrc64ecma: hashCrc,
 		})
 	}
-	output := ListObjectsV2Output{
-		RequestInfo:    temp.RequestInfo,
-		Name:           temp.Name,
-		Prefix:         temp.Prefix,
-		Marker:         temp.Marker,
-		MaxKeys:        temp.MaxKeys,
-		NextMarker:     temp.NextMarker,
-		Delimiter:      temp.Delimiter,
-		IsTruncated:    temp.IsTruncated,
-		EncodingType:   temp.EncodingType,
-		CommonPrefixes: temp.CommonPrefixes,
-		Contents:       contents,
+	return contents, nil
+}
+
+// ListObjectsType2 list objects of a bucket by continuation token.
+// If EncodingType is "url", keys and prefixes in output are decoded.
+func (cli *ClientV2) ListObjectsType2(ctx context.Context, input *ListObjectsType2Input) (*ListObjectsType2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("list-type", "2").
+		WithParams(*input).
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	temp := listObjectsType2Output{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(temp.RequestID, res.Body, &temp); err != nil {
+		return nil, err
+	}
+	contents, err := convertListedObjectsV2(temp.RequestID, temp.Contents)
+	if err != nil {
+		return nil, err
+	}
+	output := ListObjectsType2Output{
+		RequestInfo:           temp.RequestInfo,
+		Name:                  temp.Name,
+		Prefix:                temp.Prefix,
+		StartAfter:            temp.StartAfter,
+		ContinuationToken:     temp.ContinuationToken,
+		MaxKeys:               temp.MaxKeys,
+		Delimiter:             temp.Delimiter,
+		IsTruncated:           temp.IsTruncated,
+		EncodingType:          temp.EncodingType,
+		KeyCount:              temp.KeyCount,
+		NextContinuationToken: temp.NextContinuationToken,
+		CommonPrefixes:        temp.CommonPrefixes,
+		Contents:              contents,
+	}
+	if output.EncodingType == "url" {
+		if err = output.decode(); err != nil {
+			return nil, &TosServerError{
+				TosError:    TosError{Message: "tos: server returned invalid url encoded key"},
+				RequestInfo: RequestInfo{RequestID: temp.RequestID},
+			}
+		}
 	}
 	return &output, nil
 }
 
+// decode url encoded keys and prefixes
+func (out *ListObjectsType2Output) decode() (err error) {
+	for _, s := range []*string{&out.Prefix, &out.StartAfter, &out.Delimiter} {
+		if *s, err = url.QueryUnescape(*s); err != nil {
+			return err
+		}
+	}
+	for i := range out.CommonPrefixes {
+		if out.CommonPrefixes[i].Prefix, err = url.QueryUnescape(out.CommonPrefixes[i].Prefix); err != nil {
+			return err
+		}
+	}
+	for i := range out.Contents {
+		if out.Contents[i].Key, err = url.QueryUnescape(out.Contents[i].Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ListObjectVersions list multi-version objects of a bucket
 //
 // Deprecated: use ListObjectV2Versions of ClientV2 instead
@@ -723,11 +1162,16 @@ func (bkt *Bucket) ListObjectVersions(ctx context.Context, input *ListObjectVers
 func (cli *ClientV2) ListObjectVersionsV2(
 	ctx context.Context,
 	input *ListObjectVersionsV2Input) (*ListObjectVersionsV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
 	if err := IsValidBucketName(input.Bucket); err != nil {
 		return nil, err
 	}
 	res, err := cli.newBuilder(input.Bucket, "").
 		WithQuery("versions", "").
+		WithParams(*input).
+		WithRetry(nil, StatusCodeClassifier{}).
 		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
 	if err != nil {
 		return nil, err
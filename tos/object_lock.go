@@ -0,0 +1,135 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+// validate checks mode is GOVERNANCE or COMPLIANCE and RetainUntilDate is set
+func (r *ObjectRetention) validate() error {
+	if r.Mode != enum.ObjectLockModeGovernance && r.Mode != enum.ObjectLockModeCompliance {
+		return newTosClientError("tos: invalid retention mode, must be GOVERNANCE or COMPLIANCE", nil)
+	}
+	if r.RetainUntilDate.IsZero() {
+		return newTosClientError("tos: RetainUntilDate of retention is required", nil)
+	}
+	return nil
+}
+
+// PutObjectRetention set retention of an object version, the bucket must have object lock enabled.
+// Retention of COMPLIANCE mode can only be extended, and GOVERNANCE mode can be shortened or removed
+// with BypassGovernanceRetention.
+func (cli *ClientV2) PutObjectRetention(ctx context.Context, input *PutObjectRetentionInput) (*PutObjectRetentionOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	if err := input.Retention.validate(); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("PutObjectRetentionInput", input)
+	if err != nil {
+		return nil, err
+	}
+	rb := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("retention", "").
+		WithParams(*input).
+		WithHeader(HeaderContentMD5, contentMD5)
+	if input.BypassGovernanceRetention {
+		rb.WithHeader(HeaderBypassGovernanceRetention, "true")
+	}
+	res, err := rb.Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutObjectRetentionOutput{
+		RequestInfo: res.RequestInfo(),
+		VersionID:   res.Header.Get(HeaderVersionID),
+	}, nil
+}
+
+// GetObjectRetention get retention of an object version
+func (cli *ClientV2) GetObjectRetention(ctx context.Context, input *GetObjectRetentionInput) (*GetObjectRetentionOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("retention", "").
+		WithParams(*input).
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetObjectRetentionOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	output.VersionID = res.Header.Get(HeaderVersionID)
+	return &output, nil
+}
+
+// PutObjectLegalHold set legal hold of an object version, the bucket must have object lock enabled
+func (cli *ClientV2) PutObjectLegalHold(ctx context.Context, input *PutObjectLegalHoldInput) (*PutObjectLegalHoldOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	if status := input.LegalHold.Status; status != enum.LegalHoldStatusOn && status != enum.LegalHoldStatusOff {
+		return nil, newTosClientError("tos: invalid legal hold status, must be ON or OFF", nil)
+	}
+	data, contentMD5, err := marshalInput("PutObjectLegalHoldInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("legal-hold", "").
+		WithParams(*input).
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutObjectLegalHoldOutput{
+		RequestInfo: res.RequestInfo(),
+		VersionID:   res.Header.Get(HeaderVersionID),
+	}, nil
+}
+
+// GetObjectLegalHold get legal hold of an object version
+func (cli *ClientV2) GetObjectLegalHold(ctx context.Context, input *GetObjectLegalHoldInput) (*GetObjectLegalHoldOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("legal-hold", "").
+		WithParams(*input).
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetObjectLegalHoldOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	output.VersionID = res.Header.Get(HeaderVersionID)
+	return &output, nil
+}
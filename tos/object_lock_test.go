@@ -0,0 +1,111 @@
+package tos
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+func TestObjectRetention(t *testing.T) {
+	var retention, legalHold string
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, "")()
+		res.Header.Set(HeaderVersionID, req.Query.Get("versionId"))
+		_, isRetention := req.Query["retention"]
+		switch {
+		case req.Method == http.MethodPut && isRetention:
+			data, _ := ioutil.ReadAll(req.Content)
+			retention = string(data)
+		case req.Method == http.MethodPut:
+			data, _ := ioutil.ReadAll(req.Content)
+			legalHold = string(data)
+		case req.Method == http.MethodGet && isRetention:
+			res.Body = ioutil.NopCloser(strings.NewReader(retention))
+		case req.Method == http.MethodGet:
+			res.Body = ioutil.NopCloser(strings.NewReader(legalHold))
+		}
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	put, err := client.PutObjectRetention(ctx, &PutObjectRetentionInput{
+		Bucket:                    "bucket",
+		Key:                       "key",
+		VersionID:                 "version",
+		Retention:                 ObjectRetention{Mode: enum.ObjectLockModeGovernance, RetainUntilDate: until},
+		BypassGovernanceRetention: true,
+	})
+	require.Nil(t, err)
+	require.Equal(t, "version", put.VersionID)
+	require.Equal(t, `{"Retention":{"Mode":"GOVERNANCE","RetainUntilDate":"2030-01-02T03:04:05Z"}}`, retention)
+	req := transport.recorded()[0]
+	require.Equal(t, "true", req.Header.Get(HeaderBypassGovernanceRetention))
+	require.NotEmpty(t, req.Header.Get(HeaderContentMD5))
+
+	get, err := client.GetObjectRetention(ctx, &GetObjectRetentionInput{Bucket: "bucket", Key: "key", VersionID: "version"})
+	require.Nil(t, err)
+	require.Equal(t, enum.ObjectLockModeGovernance, get.Retention.Mode)
+	require.True(t, until.Equal(get.Retention.RetainUntilDate))
+	require.Equal(t, "version", get.VersionID)
+
+	_, err = client.PutObjectLegalHold(ctx, &PutObjectLegalHoldInput{Bucket: "bucket", Key: "key", LegalHold: ObjectLegalHold{Status: enum.LegalHoldStatusOn}})
+	require.Nil(t, err)
+	hold, err := client.GetObjectLegalHold(ctx, &GetObjectLegalHoldInput{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	require.Equal(t, enum.LegalHoldStatusOn, hold.LegalHold.Status)
+
+	requests := len(transport.recorded())
+	_, err = client.PutObjectRetention(ctx, &PutObjectRetentionInput{Bucket: "bucket", Key: "key", Retention: ObjectRetention{Mode: "LOCKED", RetainUntilDate: until}})
+	require.NotNil(t, err)
+	_, err = client.PutObjectRetention(ctx, &PutObjectRetentionInput{Bucket: "bucket", Key: "key", Retention: ObjectRetention{Mode: enum.ObjectLockModeCompliance}})
+	require.NotNil(t, err)
+	_, err = client.PutObjectLegalHold(ctx, &PutObjectLegalHoldInput{Bucket: "bucket", Key: "key"})
+	require.NotNil(t, err)
+	require.Equal(t, requests, len(transport.recorded()))
+}
+
+func TestObjectLockHeaders(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodDelete {
+			res := newMockResponse(http.StatusNoContent, "")()
+			res.Body = nil
+			return res
+		}
+		if req.Method == http.MethodPost {
+			return newMockResponse(http.StatusOK, `{"Deleted":[{"Key":"key"}]}`)()
+		}
+		res := newMockResponse(http.StatusOK, "")()
+		res.Body = nil
+		res.Header.Set(HeaderObjectLockMode, "COMPLIANCE")
+		res.Header.Set(HeaderObjectLockRetainUntilDate, "2030-01-02T03:04:05Z")
+		res.Header.Set(HeaderObjectLockLegalHold, "ON")
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	head, err := client.HeadObjectV2(ctx, &HeadObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	require.Equal(t, enum.ObjectLockModeCompliance, head.ObjectLockMode)
+	require.True(t, time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC).Equal(head.ObjectLockRetainUntilDate))
+	require.Equal(t, enum.LegalHoldStatusOn, head.ObjectLockLegalHold)
+
+	_, err = client.DeleteObjectV2(ctx, &DeleteObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	_, err = client.DeleteObjectV2(ctx, &DeleteObjectV2Input{Bucket: "bucket", Key: "key", BypassGovernanceRetention: true})
+	require.Nil(t, err)
+	_, err = client.DeleteMultiObjects(ctx, &DeleteMultiObjectsInput{Bucket: "bucket", Objects: []ObjectTobeDeleted{{Key: "key"}}, BypassGovernanceRetention: true})
+	require.Nil(t, err)
+	requests := transport.recorded()
+	require.Equal(t, "", requests[1].Header.Get(HeaderBypassGovernanceRetention))
+	require.Equal(t, "true", requests[2].Header.Get(HeaderBypassGovernanceRetention))
+	require.Equal(t, "true", requests[3].Header.Get(HeaderBypassGovernanceRetention))
+}
@@ -0,0 +1,169 @@
+package tos
+
+import (
+	"container/list"
+	"context"
+	"io"
+	"sync"
+)
+
+// ObjectReaderAt reads an object by ranged GetObjectV2 requests, it implements io.ReaderAt and is safe for concurrent use,
+// e.g. a remote zip archive can be read by
+//
+//	r, err := client.NewObjectReaderAt(bucket, key, "")
+//	archive, err := zip.NewReader(r, r.Size())
+//
+// Size and ETag of the object are fetched once by HeadObjectV2, and every ranged request is sent with If-Match,
+// so ReadAt returns error instead of mixed content if the object is replaced.
+// By default each ReadAt requests exactly the range read, WithObjectReaderAtCache enables caching of fetched blocks.
+type ObjectReaderAt struct {
+	cli        *ClientV2
+	ctx        context.Context
+	bucket     string
+	key        string
+	versionID  string
+	etag       string
+	size       int64
+	blockSize  int64
+	blockCount int
+	lock       sync.Mutex
+	blocks     map[int64]*list.Element
+	recent     *list.List
+}
+
+type objectBlock struct {
+	index int64
+	data  []byte
+}
+
+type ObjectReaderAtOption func(*ObjectReaderAt)
+
+// WithObjectReaderAtContext set context used by requests of ObjectReaderAt, context.Background() by default
+func WithObjectReaderAtContext(ctx context.Context) ObjectReaderAtOption {
+	return func(r *ObjectReaderAt) {
+		r.ctx = ctx
+	}
+}
+
+// WithObjectReaderAtCache reads the object by blocks aligned to blockSize, and caches at most blockCount
+// blocks recently read, so memory used by cache is bounded by blockSize * blockCount.
+func WithObjectReaderAtCache(blockSize int64, blockCount int) ObjectReaderAtOption {
+	return func(r *ObjectReaderAt) {
+		r.blockSize = blockSize
+		r.blockCount = blockCount
+	}
+}
+
+// NewObjectReaderAt create an ObjectReaderAt of the object, versionID can be empty for the latest version
+func (cli *ClientV2) NewObjectReaderAt(bucket, key, versionID string, options ...ObjectReaderAtOption) (*ObjectReaderAt, error) {
+	if err := isValidNames(bucket, key); err != nil {
+		return nil, err
+	}
+	r := &ObjectReaderAt{
+		cli:       cli,
+		ctx:       context.Background(),
+		bucket:    bucket,
+		key:       key,
+		versionID: versionID,
+	}
+	for _, option := range options {
+		option(r)
+	}
+	if r.blockSize <= 0 || r.blockCount <= 0 {
+		r.blockSize, r.blockCount = 0, 0
+	} else {
+		r.blocks = make(map[int64]*list.Element, r.blockCount)
+		r.recent = list.New()
+	}
+	head, err := cli.HeadObjectV2(r.ctx, &HeadObjectV2Input{Bucket: bucket, Key: key, VersionID: versionID})
+	if err != nil {
+		return nil, err
+	}
+	r.size = head.ContentLength
+	r.etag = head.ETag
+	return r, nil
+}
+
+// Size returns size of the object
+func (r *ObjectReaderAt) Size() int64 {
+	return r.size
+}
+
+// ETag returns ETag of the object
+func (r *ObjectReaderAt) ETag() string {
+	return r.etag
+}
+
+// ReadAt implements io.ReaderAt, it returns io.EOF if less than len(p) bytes are read because of the end of the object
+func (r *ObjectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, newTosClientError("tos: negative offset", nil)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	n := len(p)
+	if remaining := r.size - off; int64(n) > remaining {
+		n = int(remaining)
+	}
+	if err := r.read(p[:n], off); err != nil {
+		return 0, err
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *ObjectReaderAt) read(p []byte, off int64) error {
+	if r.blockSize == 0 {
+		return readObjectRange(r.ctx, r.cli, r.bucket, r.key, r.versionID, r.etag, off, p)
+	}
+	for len(p) > 0 {
+		index := off / r.blockSize
+		data, err := r.getBlock(index)
+		if err != nil {
+			return err
+		}
+		n := copy(p, data[off-index*r.blockSize:])
+		p = p[n:]
+		off += int64(n)
+	}
+	return nil
+}
+
+// getBlock returns the block from cache, or reads it from the object and adds it to cache.
+// Concurrent misses of the same block may read it more than once.
+func (r *ObjectReaderAt) getBlock(index int64) ([]byte, error) {
+	r.lock.Lock()
+	if e, ok := r.blocks[index]; ok {
+		r.recent.MoveToFront(e)
+		r.lock.Unlock()
+		return e.Value.(*objectBlock).data, nil
+	}
+	r.lock.Unlock()
+
+	start := index * r.blockSize
+	length := r.blockSize
+	if remaining := r.size - start; length > remaining {
+		length = remaining
+	}
+	data := make([]byte, length)
+	if err := readObjectRange(r.ctx, r.cli, r.bucket, r.key, r.versionID, r.etag, start, data); err != nil {
+		return nil, err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.blocks[index]; !ok {
+		r.blocks[index] = r.recent.PushFront(&objectBlock{index: index, data: data})
+		if r.recent.Len() > r.blockCount {
+			oldest := r.recent.Remove(r.recent.Back()).(*objectBlock)
+			delete(r.blocks, oldest.index)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,101 @@
+package tos
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectReaderAt(t *testing.T) {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		w, err := writer.Create(name)
+		require.Nil(t, err)
+		_, err = w.Write(bytes.Repeat([]byte(name), 100))
+		require.Nil(t, err)
+	}
+	require.Nil(t, writer.Close())
+
+	etag := "\"etag\""
+	transport := newRangeReaderTransport(archive.String(), &etag)
+	client := newMockClient(t, transport)
+	reader, err := client.NewObjectReaderAt("bucket", "archive.zip", "")
+	require.Nil(t, err)
+	require.Equal(t, int64(archive.Len()), reader.Size())
+	zr, err := zip.NewReader(reader, reader.Size())
+	require.Nil(t, err)
+	require.Len(t, zr.File, 2)
+	file, err := zr.File[1].Open()
+	require.Nil(t, err)
+	data, err := ioutil.ReadAll(file)
+	require.Nil(t, err)
+	require.Equal(t, bytes.Repeat([]byte("b.txt"), 100), data)
+
+	p := make([]byte, 10)
+	n, err := reader.ReadAt(p, reader.Size()-4)
+	require.Equal(t, 4, n)
+	require.Equal(t, io.EOF, err)
+	_, err = reader.ReadAt(p, reader.Size())
+	require.Equal(t, io.EOF, err)
+	_, err = reader.ReadAt(p, -1)
+	require.NotNil(t, err)
+	// empty read sends no request
+	requests := len(transport.recorded())
+	n, err = reader.ReadAt(p[:0], 1)
+	require.Equal(t, 0, n)
+	require.Nil(t, err)
+	require.Len(t, transport.recorded(), requests)
+
+	etag = "\"replaced\""
+	_, err = reader.ReadAt(p, 0)
+	require.Equal(t, http.StatusPreconditionFailed, StatusCode(err))
+}
+
+func TestObjectReaderAtCache(t *testing.T) {
+	etag := "\"etag\""
+	transport := newRangeReaderTransport("0123456789", &etag)
+	client := newMockClient(t, transport)
+	reader, err := client.NewObjectReaderAt("bucket", "key", "", WithObjectReaderAtCache(4, 2))
+	require.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := make([]byte, 5)
+			n, err := reader.ReadAt(p, 2)
+			require.Nil(t, err)
+			require.Equal(t, "23456", string(p[:n]))
+		}()
+	}
+	wg.Wait()
+
+	// blocks 0 and 1 are cached
+	requests := len(transport.recorded())
+	p := make([]byte, 8)
+	n, err := reader.ReadAt(p, 0)
+	require.Nil(t, err)
+	require.Equal(t, "01234567", string(p[:n]))
+	require.Equal(t, requests, len(transport.recorded()))
+
+	// reading block 2 evicts block 0, the least recently used
+	n, err = reader.ReadAt(p, 8)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, "89", string(p[:n]))
+	require.Equal(t, requests+1, len(transport.recorded()))
+	n, err = reader.ReadAt(p[:1], 4)
+	require.Nil(t, err)
+	require.Equal(t, requests+1, len(transport.recorded()))
+	n, err = reader.ReadAt(p[:1], 0)
+	require.Nil(t, err)
+	require.Equal(t, "0", string(p[:n]))
+	require.Equal(t, requests+2, len(transport.recorded()))
+}
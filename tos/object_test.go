@@ -0,0 +1,1297 @@
+package tos
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+func TestAppendObjectV2(t *testing.T) {
+	var (
+		length int64
+		crc    = NewCRC(DefaultCrcTable(), 0)
+	)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		offset, _ := strconv.ParseInt(req.Query.Get("offset"), 10, 64)
+		if offset != length {
+			res := newMockResponse(http.StatusConflict, `{"Code":"PositionNotEqualToLength","Message":"position not equal to length"}`)()
+			res.Header.Set(HeaderNextAppendOffset, strconv.FormatInt(length, 10))
+			return res
+		}
+		data, _ := ioutil.ReadAll(req.Content)
+		length += int64(len(data))
+		crc.Write(data)
+		res := newMockResponse(http.StatusOK, "")()
+		res.Header.Set(HeaderNextAppendOffset, strconv.FormatInt(length, 10))
+		res.Header.Set(HeaderHashCrc64ecma, strconv.FormatUint(crc.Sum64(), 10))
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	output, err := client.AppendObjectV2(ctx, &AppendObjectV2Input{
+		Bucket:  "bucket",
+		Key:     "key",
+		Content: strings.NewReader("hello "),
+	})
+	require.Nil(t, err)
+	require.Equal(t, int64(6), output.NextAppendOffset)
+	req := transport.recorded()[0]
+	require.Equal(t, http.MethodPost, req.Method)
+	require.Equal(t, "0", req.Query.Get("offset"))
+	_, ok := req.Query["append"]
+	require.True(t, ok)
+
+	_, err = client.AppendObjectV2(ctx, &AppendObjectV2Input{
+		Bucket:  "bucket",
+		Key:     "key",
+		Offset:  3,
+		Content: strings.NewReader("world"),
+	})
+	positionErr, ok := err.(*AppendPositionError)
+	require.True(t, ok)
+	require.Equal(t, int64(6), positionErr.NextAppendOffset)
+	require.Equal(t, http.StatusConflict, StatusCode(err))
+	require.Equal(t, "PositionNotEqualToLength", Code(err))
+	require.Equal(t, "request-id", RequestID(err))
+
+	// recover with the offset expected by server, CRC64 is not checked without PreHashCrc64ecma
+	output, err = client.AppendObjectV2(ctx, &AppendObjectV2Input{
+		Bucket:  "bucket",
+		Key:     "key",
+		Offset:  positionErr.NextAppendOffset,
+		Content: strings.NewReader("world"),
+	})
+	require.Nil(t, err)
+	require.Equal(t, int64(11), output.NextAppendOffset)
+}
+
+func TestModifyObjectV2(t *testing.T) {
+	var (
+		length int64 = 5
+		bodies []string
+	)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		offset, _ := strconv.ParseInt(req.Query.Get("offset"), 10, 64)
+		if offset > length {
+			res := newMockResponse(http.StatusConflict, `{"Code":"OffsetNotMatched","Message":"offset not matched"}`)()
+			res.Header.Set(HeaderNextModifyOffset, strconv.FormatInt(length, 10))
+			return res
+		}
+		data, _ := ioutil.ReadAll(req.Content)
+		bodies = append(bodies, string(data))
+		if len(bodies) == 1 {
+			return newMockResponse(http.StatusInternalServerError, "")()
+		}
+		if end := offset + int64(len(data)); end > length {
+			length = end
+		}
+		res := newMockResponse(http.StatusOK, "")()
+		res.Header.Set(HeaderNextModifyOffset, strconv.FormatInt(length, 10))
+		res.Header.Set(HeaderHashCrc64ecma, "123")
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.ModifyObjectV2(ctx, &ModifyObjectV2Input{
+		Bucket:  "bucket",
+		Key:     "key",
+		Offset:  10,
+		Content: strings.NewReader("world"),
+	})
+	positionErr, ok := err.(*ModifyPositionError)
+	require.True(t, ok)
+	require.Equal(t, int64(5), positionErr.NextModifyOffset)
+	require.Equal(t, http.StatusConflict, StatusCode(err))
+
+	// other conflicts are not position errors
+	conflict := &TosServerError{TosError: TosError{Message: "conflict"},
+		RequestInfo: RequestInfo{StatusCode: http.StatusConflict}, Code: "ConcurrencyUpdateObjectLimit"}
+	require.Equal(t, conflict, newModifyPositionError(conflict))
+
+	// seekable content is rewound on retrying
+	output, err := client.ModifyObjectV2(ctx, &ModifyObjectV2Input{
+		Bucket:       "bucket",
+		Key:          "key",
+		Offset:       positionErr.NextModifyOffset,
+		Content:      strings.NewReader("world"),
+		TrafficLimit: 8 * 1024 * 1024,
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"world", "world"}, bodies)
+	require.Equal(t, int64(10), output.NextModifyOffset)
+	require.Equal(t, uint64(123), output.HashCrc64ecma)
+	req := transport.recorded()[2]
+	require.Equal(t, "5", req.Query.Get("offset"))
+	require.Equal(t, "8388608", req.Header.Get(HeaderTrafficLimit))
+	_, ok = req.Query["modify"]
+	require.True(t, ok)
+}
+
+func TestPutObjectV2Chunked(t *testing.T) {
+	var body []byte
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		body, _ = ioutil.ReadAll(req.Content)
+		return newMockResponse(http.StatusInternalServerError, "")()
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	reader, writer, err := os.Pipe()
+	require.Nil(t, err)
+	defer reader.Close()
+	go func() {
+		writer.WriteString("hello world")
+		writer.Close()
+	}()
+	require.Equal(t, int64(-1), tryResolveLength(reader))
+
+	input := &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key"},
+		Content:             reader,
+	}
+	_, err = client.PutObjectV2(ctx, input)
+	_, ok := err.(*TosClientError)
+	require.True(t, ok)
+	require.Len(t, transport.recorded(), 0)
+
+	// body of unknown length is sent in chunks only if allowed, and not retried
+	input.AllowChunked = true
+	_, err = client.PutObjectV2(ctx, input)
+	require.Equal(t, http.StatusInternalServerError, StatusCode(err))
+	require.Len(t, transport.recorded(), 1)
+	require.Equal(t, "hello world", string(body))
+	req := transport.recorded()[0]
+	require.Equal(t, int64(-1), *req.ContentLength)
+	require.Equal(t, unsignedPayload, req.Header.Get(HeaderContentSha256))
+}
+
+type countingRateLimiter struct {
+	lock     sync.Mutex
+	acquired int64
+}
+
+func (c *countingRateLimiter) Acquire(want int64) (bool, time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.acquired += want
+	return true, 0
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	var bodies []string
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodGet {
+			return newMockResponse(http.StatusOK, "hello world")()
+		}
+		data, _ := ioutil.ReadAll(req.Content)
+		bodies = append(bodies, string(data))
+		if len(bodies) == 1 {
+			return newMockResponse(http.StatusInternalServerError, "")()
+		}
+		return newMockResponse(http.StatusOK, "")()
+	}}
+	limiter := &countingRateLimiter{}
+	client, err := NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithCredentials(NewStaticCredentials("ak", "sk")), WithTransport(transport), WithMaxRetryCount(2),
+		WithRateLimiter(limiter))
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	// the request body is still rewound on retrying
+	_, err = client.PutObjectV2(ctx, &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key"},
+		Content:             strings.NewReader("hello"),
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"hello", "hello"}, bodies)
+	require.True(t, limiter.acquired > 0)
+
+	limiter.acquired = 0
+	get, err := client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	data, err := ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, "hello world", string(data))
+	require.True(t, limiter.acquired > 0)
+
+	limiter.acquired = 0
+	get, err = client.GetObjectV2(BypassRateLimiter(ctx), &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	_, err = ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, int64(0), limiter.acquired)
+}
+
+func TestPutObjectV2RetriedProgress(t *testing.T) {
+	data := make([]byte, DefaultProgressCallbackSize+100)
+	attempts := 0
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		attempts++
+		if attempts == 1 {
+			// fail after reading part of the body
+			_, _ = io.ReadFull(req.Content, make([]byte, DefaultProgressCallbackSize+10))
+			return newMockResponse(http.StatusInternalServerError, "")()
+		}
+		_, _ = ioutil.ReadAll(req.Content)
+		return newMockResponse(http.StatusOK, "")()
+	}}
+	client := newMockClient(t, transport)
+	listener := &recordingListener{}
+	_, err := client.PutObjectV2(context.Background(), &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key", DataTransferListener: listener},
+		Content:             bytes.NewReader(data),
+	})
+	require.Nil(t, err)
+
+	var (
+		consumed int64
+		types    []enum.DataTransferType
+	)
+	for _, status := range listener.statuses {
+		types = append(types, status.Type)
+		switch status.Type {
+		case enum.DataTransferRW:
+			consumed += status.RWOnceBytes
+			require.Equal(t, consumed, status.ConsumedBytes)
+		case enum.DataTransferRetried:
+			require.Equal(t, 1, status.RetryCount)
+			consumed -= status.RollbackBytes
+			require.Equal(t, consumed, status.ConsumedBytes)
+		}
+		require.True(t, consumed <= int64(len(data)))
+	}
+	require.Equal(t, int64(len(data)), consumed)
+	require.Equal(t, enum.DataTransferStarted, types[0])
+	require.Equal(t, enum.DataTransferRetried, types[2])
+	require.Equal(t, enum.DataTransferSucceed, types[len(types)-1])
+}
+
+// closeRecorder records whether it's closed
+type closeRecorder struct {
+	io.ReadSeeker
+	closed int32
+}
+
+func (r *closeRecorder) Close() error {
+	atomic.AddInt32(&r.closed, 1)
+	return nil
+}
+
+func TestObjectContentClose(t *testing.T) {
+	// closing content of GetObjectV2 closes the response body
+	body := &closeRecorder{ReadSeeker: strings.NewReader("hello")}
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, "")()
+		res.Body = body
+		return res
+	}}
+	client := newMockClient(t, transport)
+	get, err := client.GetObjectV2(context.Background(), &GetObjectV2Input{Bucket: "bucket", Key: "key",
+		DataTransferListener: &recordingListener{}})
+	require.Nil(t, err)
+	require.Nil(t, get.Content.Close())
+	require.Equal(t, int32(1), atomic.LoadInt32(&body.closed))
+
+	// content to upload is owned by the caller, it's rewound but not closed on retrying
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		require.Equal(t, "hello", string(data))
+	}))
+	defer server.Close()
+	client, err = NewClientV2(server.URL, WithRegion("cn-beijing"),
+		WithCredentials(NewStaticCredentials("ak", "sk")), WithMaxRetryCount(1))
+	require.Nil(t, err)
+	content := &closeRecorder{ReadSeeker: strings.NewReader("hello")}
+	_, err = client.PutObjectV2(context.Background(), &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key", ContentLength: 5},
+		Content:             content,
+	})
+	require.Nil(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	require.Equal(t, int32(0), atomic.LoadInt32(&content.closed))
+}
+
+// bodyTracker counts response bodies returned by a mockTransport and closed
+type bodyTracker struct {
+	opened int32
+	closed int32
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	tracker *bodyTracker
+}
+
+func (b *trackedBody) Close() error {
+	atomic.AddInt32(&b.tracker.closed, 1)
+	return b.ReadCloser.Close()
+}
+
+func trackBodies(transport *mockTransport) *bodyTracker {
+	tracker := &bodyTracker{}
+	handler := transport.handler
+	transport.handler = func(req *Request) *Response {
+		res := handler(req)
+		if res.Body != nil {
+			atomic.AddInt32(&tracker.opened, 1)
+			res.Body = &trackedBody{ReadCloser: res.Body, tracker: tracker}
+		}
+		return res
+	}
+	return tracker
+}
+
+func (b *bodyTracker) requireAllClosed(t *testing.T) {
+	require.True(t, atomic.LoadInt32(&b.opened) > 0)
+	require.Equal(t, atomic.LoadInt32(&b.opened), atomic.LoadInt32(&b.closed))
+}
+
+func TestObjectContentBodiesClosed(t *testing.T) {
+	ctx := context.Background()
+	data := "hello world, hello tos"
+
+	// bodies broken and replaced by resuming are closed too
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		start := 0
+		fmt.Sscanf(req.Header.Get(HeaderRange), "bytes=%d-", &start)
+		res := newMockResponse(http.StatusOK, "")()
+		if start > 0 {
+			res.StatusCode = http.StatusPartialContent
+			res.Header.Set(HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+		}
+		res.Header.Set(HeaderETag, "\"etag\"")
+		res.Body = ioutil.NopCloser(&brokenReader{data: []byte(data[start:min(start+5, len(data))]), err: io.ErrUnexpectedEOF})
+		if start+5 >= len(data) {
+			res.Body = ioutil.NopCloser(strings.NewReader(data[start:]))
+		}
+		return res
+	}}
+	tracker := trackBodies(transport)
+	client := newMockClient(t, transport)
+	// each attempt of the request is limited by OperationTimeout until the body is closed
+	get, err := client.GetObjectV2(ContextWithOperationTimeout(ctx, OperationTimeout{Timeout: time.Minute}),
+		&GetObjectV2Input{Bucket: "bucket", Key: "key", MaxResumeCount: 5})
+	require.Nil(t, err)
+	content, err := ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, data, string(content))
+	require.Nil(t, get.Content.Close())
+	tracker.requireAllClosed(t)
+
+	// ranges read by ObjectReaderAt
+	etag := "\"etag\""
+	transport = newRangeReaderTransport(data, &etag)
+	tracker = trackBodies(transport)
+	client = newMockClient(t, transport)
+	reader, err := client.NewObjectReaderAt("bucket", "key", "")
+	require.Nil(t, err)
+	_, err = reader.ReadAt(make([]byte, 5), 3)
+	require.Nil(t, err)
+	tracker.requireAllClosed(t)
+
+	// ranges downloaded by DownloadToWriterAt
+	crc := strconv.FormatUint(crc64Of([]byte(data)), 10)
+	transport = newWriterAtTransport(t, []byte(data), &crc, map[int64]int{})
+	tracker = trackBodies(transport)
+	client = newMockClient(t, transport)
+	_, err = client.DownloadToWriterAt(ctx, &DownloadToWriterAtInput{
+		HeadObjectV2Input: HeadObjectV2Input{Bucket: "bucket", Key: "key"},
+		WriterAt:          make(bufferWriterAt, len(data)),
+	})
+	require.Nil(t, err)
+	tracker.requireAllClosed(t)
+}
+
+func TestGetObjectV2CRCCheck(t *testing.T) {
+	crc := "0"
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, "hello world")()
+		if req.Header.Get(HeaderRange) != "" {
+			res.StatusCode = http.StatusPartialContent
+		}
+		res.Header.Set(HeaderHashCrc64ecma, crc)
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	crc = strconv.FormatUint(crc64Of([]byte("hello world")), 10)
+	get, err := client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	data, err := ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, "hello world", string(data))
+	require.Nil(t, get.Content.Close())
+
+	crc = "1"
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	_, err = ioutil.ReadAll(get.Content)
+	require.NotNil(t, err)
+	checksumErr, ok := err.(*TosClientError).Cause.(*ChecksumError)
+	require.True(t, ok)
+	require.Equal(t, "1", checksumErr.ExpectedChecksum)
+	require.Equal(t, "request-id", checksumErr.RequestID)
+	require.NotNil(t, get.Content.Close())
+
+	// closed before reading entirely
+	crc = strconv.FormatUint(crc64Of([]byte("hello world")), 10)
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	_, err = get.Content.Read(make([]byte, 5))
+	require.Nil(t, err)
+	require.NotNil(t, get.Content.Close())
+
+	// disabled per request
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", DisableCRCCheck: true})
+	require.Nil(t, err)
+	_, err = get.Content.Read(make([]byte, 5))
+	require.Nil(t, err)
+	require.Nil(t, get.Content.Close())
+
+	// range request is not checked
+	crc = "1"
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", RangeStart: 0, RangeEnd: 4})
+	require.Nil(t, err)
+	_, err = ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Nil(t, get.Content.Close())
+
+	// processed content is not checked
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", Process: "image/resize,w_100"})
+	require.Nil(t, err)
+	_, err = ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Nil(t, get.Content.Close())
+
+	// disabled by client
+	client.enableCRC = false
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	_, err = ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+}
+
+func TestGetObjectV2WriteTo(t *testing.T) {
+	data := bytes.Repeat([]byte("hello world"), copyBufferSize/5)
+	crc := strconv.FormatUint(crc64Of(data), 10)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, string(data))()
+		res.ContentLength = int64(len(data))
+		res.Header.Set(HeaderHashCrc64ecma, crc)
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	listener := &recordingListener{}
+	get, err := client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", DataTransferListener: listener})
+	require.Nil(t, err)
+	_, ok := get.Content.(io.WriterTo)
+	require.True(t, ok)
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, get.Content)
+	require.Nil(t, err)
+	require.Equal(t, int64(len(data)), n)
+	require.Equal(t, data, buf.Bytes())
+	require.Nil(t, get.Content.Close())
+	last := listener.statuses[len(listener.statuses)-1]
+	require.Equal(t, enum.DataTransferSucceed, last.Type)
+	require.Equal(t, int64(len(data)), last.ConsumedBytes)
+
+	crc = "1"
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	_, err = io.Copy(ioutil.Discard, get.Content)
+	_, ok = err.(*TosClientError).Cause.(*ChecksumError)
+	require.True(t, ok)
+}
+
+func TestGetObjectV2WriteToFile(t *testing.T) {
+	data := bytes.Repeat([]byte("hello world"), copyBufferSize/5)
+	source, err := ioutil.TempFile("", "source")
+	require.Nil(t, err)
+	defer os.Remove(source.Name())
+	defer source.Close()
+	_, err = source.Write(data)
+	require.Nil(t, err)
+	crc := ""
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		body, err := os.Open(source.Name())
+		require.Nil(t, err)
+		res := newMockResponse(http.StatusOK, "")()
+		res.ContentLength = int64(len(data))
+		res.Body = body
+		res.Header.Set(HeaderHashCrc64ecma, crc)
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+	target, err := ioutil.TempFile("", "target")
+	require.Nil(t, err)
+	defer os.Remove(target.Name())
+	defer target.Close()
+
+	// the file body is copied by ReadFrom of the target, the listener and limiter are still fed
+	listener := &recordingListener{}
+	limiter := &countingRateLimiter{}
+	get, err := client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", DataTransferListener: listener, RateLimiter: limiter})
+	require.Nil(t, err)
+	require.NotNil(t, get.Content.(*readCloserWithWriterTo).raw)
+	n, err := io.Copy(target, get.Content)
+	require.Nil(t, err)
+	require.Equal(t, int64(len(data)), n)
+	require.Nil(t, get.Content.Close())
+	copied, err := ioutil.ReadFile(target.Name())
+	require.Nil(t, err)
+	require.Equal(t, data, copied)
+	require.Equal(t, enum.DataTransferStarted, listener.statuses[0].Type)
+	last := listener.statuses[len(listener.statuses)-1]
+	require.Equal(t, enum.DataTransferSucceed, last.Type)
+	require.Equal(t, int64(len(data)), last.ConsumedBytes)
+	require.True(t, limiter.acquired >= int64(len(data)))
+
+	// content checked with CRC64 is read with the buffer
+	crc = strconv.FormatUint(crc64Of(data), 10)
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	require.Nil(t, get.Content.(*readCloserWithWriterTo).raw)
+	_, err = target.Seek(0, io.SeekStart)
+	require.Nil(t, err)
+	n, err = io.Copy(target, get.Content)
+	require.Nil(t, err)
+	require.Equal(t, int64(len(data)), n)
+	require.Nil(t, get.Content.Close())
+}
+
+// zeroReader reads zeros endlessly
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// BenchmarkGetObjectV2Copy copies a 1GB object with and without WriteTo of Content, CRC64 is not checked
+// so that the cost of copying is measured. The body is read from a stream, or from a sparse file which is copied
+// by ReadFrom of the destination file.
+func BenchmarkGetObjectV2Copy(b *testing.B) {
+	const size = 1024 * 1024 * 1024
+	source, err := ioutil.TempFile("", "source")
+	require.Nil(b, err)
+	defer os.Remove(source.Name())
+	defer source.Close()
+	require.Nil(b, source.Truncate(size))
+	fileBody := false
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, "")()
+		res.ContentLength = size
+		res.Body = ioutil.NopCloser(io.LimitReader(zeroReader{}, size))
+		if fileBody {
+			body, err := os.Open(source.Name())
+			require.Nil(b, err)
+			res.Body = body
+		}
+		return res
+	}}
+	client, err := NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithCredentials(NewStaticCredentials("ak", "sk")), WithTransport(transport))
+	require.Nil(b, err)
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.Nil(b, err)
+	defer devNull.Close()
+	file, err := ioutil.TempFile("", "object")
+	require.Nil(b, err)
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	for _, body := range []bool{false, true} {
+		for _, dst := range []struct {
+			name string
+			file *os.File
+		}{{"DevNull", devNull}, {"File", file}} {
+			for _, writeTo := range []bool{true, false} {
+				name := dst.name + "/Read"
+				if writeTo {
+					name = dst.name + "/WriteTo"
+				}
+				if body {
+					name = "FileBody/" + name
+				}
+				b.Run(name, func(b *testing.B) {
+					fileBody = body
+					b.SetBytes(size)
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						_, err := dst.file.Seek(0, io.SeekStart)
+						require.Nil(b, err)
+						get, err := client.GetObjectV2(context.Background(), &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+						require.Nil(b, err)
+						var src io.Reader = get.Content
+						if !writeTo {
+							// hide WriteTo, io.Copy allocates a 32KB buffer or uses ReadFrom of dst
+							src = struct{ io.Reader }{get.Content}
+						}
+						_, err = io.Copy(dst.file, src)
+						require.Nil(b, err)
+						require.Nil(b, get.Content.Close())
+					}
+				})
+			}
+		}
+	}
+}
+
+// brokenReader returns err after data is read
+type brokenReader struct {
+	data []byte
+	err  error
+}
+
+func (r *brokenReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestGetObjectV2Resume(t *testing.T) {
+	data := []byte("hello world, hello tos")
+	etag := "\"etag\""
+	var ranges []string
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if match := req.Header.Get("If-Match"); match != "" && match != etag {
+			return newMockResponse(http.StatusPreconditionFailed, `{"Code":"PreconditionFailed"}`)()
+		}
+		start, end := 0, len(data)-1
+		rng := req.Header.Get(HeaderRange)
+		ranges = append(ranges, rng)
+		if rng != "" {
+			if _, err := fmt.Sscanf(rng, "bytes=%d-%d", &start, &end); err != nil {
+				end = len(data) - 1
+			}
+		}
+		content := data[start : end+1]
+		res := newMockResponse(http.StatusOK, "")()
+		if rng != "" {
+			res.StatusCode = http.StatusPartialContent
+			res.Header.Set(HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		}
+		// every response is broken after 5 bytes
+		if len(content) > 5 {
+			res.Body = ioutil.NopCloser(&brokenReader{data: content[:5], err: io.ErrUnexpectedEOF})
+		} else {
+			res.Body = ioutil.NopCloser(bytes.NewReader(content))
+		}
+		res.Header.Set(HeaderETag, etag)
+		res.Header.Set(HeaderHashCrc64ecma, strconv.FormatUint(crc64Of(data), 10))
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	get, err := client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", MaxResumeCount: 5})
+	require.Nil(t, err)
+	content, err := ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, data, content)
+	// CRC64 of the whole content is checked
+	require.Nil(t, get.Content.Close())
+	require.Equal(t, []string{"", "bytes=5-", "bytes=10-", "bytes=15-", "bytes=20-"}, ranges)
+
+	// range of the original request is kept
+	ranges = nil
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", RangeStart: 6, RangeEnd: 16, MaxResumeCount: 5})
+	require.Nil(t, err)
+	content, err = ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, data[6:17], content)
+	require.Equal(t, []string{"bytes=6-16", "bytes=11-16", "bytes=16-16"}, ranges)
+
+	// resume count exhausted
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", MaxResumeCount: 2})
+	require.Nil(t, err)
+	content, err = ioutil.ReadAll(get.Content)
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	require.Equal(t, data[:15], content)
+
+	// not resumed by default
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	_, err = ioutil.ReadAll(get.Content)
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+
+	// the object is replaced before resuming
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", MaxResumeCount: 5})
+	require.Nil(t, err)
+	etag = "\"replaced\""
+	_, err = ioutil.ReadAll(get.Content)
+	changed, ok := err.(*ObjectChangedError)
+	require.True(t, ok)
+	require.Equal(t, "\"etag\"", changed.ETag)
+	require.Equal(t, http.StatusPreconditionFailed, StatusCode(err))
+	_, err = get.Content.Read(make([]byte, 1))
+	require.Equal(t, changed, err)
+}
+
+func TestGetObjectV2AutoDecompress(t *testing.T) {
+	data := bytes.Repeat([]byte("hello tos "), 1000)
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	_, err := gw.Write(data)
+	require.Nil(t, err)
+	require.Nil(t, gw.Close())
+	encoding := "gzip"
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, compressed.String())()
+		res.ContentLength = int64(compressed.Len())
+		res.Header.Set(HeaderContentLength, strconv.Itoa(compressed.Len()))
+		res.Header.Set(HeaderContentEncoding, encoding)
+		// CRC64 of compressed data
+		res.Header.Set(HeaderHashCrc64ecma, strconv.FormatUint(crc64Of(compressed.Bytes()), 10))
+		return res
+	}}
+	tracker := trackBodies(transport)
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	listener := &recordingListener{}
+	get, err := client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", AutoDecompress: true, DataTransferListener: listener})
+	require.Nil(t, err)
+	require.True(t, get.Decompressed)
+	require.Equal(t, int64(compressed.Len()), get.ContentLength)
+	content, err := ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, data, content)
+	require.Nil(t, get.Content.Close())
+	// the response body is closed with the gzip reader
+	tracker.requireAllClosed(t)
+	last := listener.statuses[len(listener.statuses)-1]
+	require.Equal(t, enum.DataTransferSucceed, last.Type)
+	require.Equal(t, int64(len(data)), last.ConsumedBytes)
+	require.Equal(t, int64(-1), last.TotalBytes)
+
+	// compressed content is returned by default
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	require.False(t, get.Decompressed)
+	content, err = ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, compressed.Bytes(), content)
+
+	// unknown encoding passes through
+	encoding = "br"
+	get, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", AutoDecompress: true})
+	require.Nil(t, err)
+	require.False(t, get.Decompressed)
+	content, err = ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, compressed.Bytes(), content)
+}
+
+func TestGetObjectV2Range(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		switch req.Header.Get(HeaderRange) {
+		case "bytes=6-":
+			res := newMockResponse(http.StatusPartialContent, "world")()
+			res.Header.Set(HeaderContentRange, "bytes 6-10/11")
+			return res
+		case "bytes=20-":
+			res := newMockResponse(http.StatusRequestedRangeNotSatisfiable, `{"Code":"InvalidRange","Message":"invalid range"}`)()
+			res.Header.Set(HeaderContentRange, "bytes */11")
+			return res
+		}
+		return newMockResponse(http.StatusBadRequest, "")()
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	get, err := client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", Range: &HTTPRange{Start: 6, End: -1}})
+	require.Nil(t, err)
+	data, err := ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, "world", string(data))
+	require.Equal(t, ContentRange{Start: 6, End: 10, Total: 11}, *get.Range)
+
+	_, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", Range: &HTTPRange{Start: 20, End: -1}})
+	rangeErr, ok := err.(*InvalidRangeError)
+	require.True(t, ok)
+	require.Equal(t, int64(11), rangeErr.ObjectSize)
+	require.Equal(t, http.StatusRequestedRangeNotSatisfiable, StatusCode(err))
+	require.Equal(t, "InvalidRange", Code(err))
+
+	_, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", Range: &HTTPRange{Start: 2, End: 1}})
+	_, ok = err.(*TosClientError)
+	require.True(t, ok)
+}
+
+func TestPutObjectFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "put-object-from-file")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "file.json")
+	require.Nil(t, ioutil.WriteFile(filePath, []byte("hello world"), 0644))
+
+	var (
+		body        string
+		contentType string
+	)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		data, _ := ioutil.ReadAll(req.Content)
+		body = string(data)
+		contentType = req.Header.Get(HeaderContentType)
+		return newMockResponse(http.StatusOK, "")()
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+	input := &PutObjectFromFileInput{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key"},
+		FilePath:            filePath,
+	}
+	_, err = client.PutObjectFromFile(ctx, input)
+	require.Nil(t, err)
+	require.Equal(t, "hello world", body)
+	require.Equal(t, "application/json", contentType)
+
+	// upload a slice of the file, Content-Type recognized from the key takes precedence
+	input.Key = "key.txt"
+	input.Offset = 6
+	_, err = client.PutObjectFromFile(ctx, input)
+	require.Nil(t, err)
+	require.Equal(t, "world", body)
+	require.Equal(t, "text/plain", contentType)
+
+	input.Length = 3
+	input.ContentType = "application/octet-stream"
+	_, err = client.PutObjectFromFile(ctx, input)
+	require.Nil(t, err)
+	require.Equal(t, "wor", body)
+	require.Equal(t, "application/octet-stream", contentType)
+
+	input.Length = 10
+	_, err = client.PutObjectFromFile(ctx, input)
+	_, ok := err.(*TosClientError)
+	require.True(t, ok)
+
+	input.FilePath = dir
+	_, err = client.PutObjectFromFile(ctx, input)
+	_, ok = err.(*TosClientError)
+	require.True(t, ok)
+}
+
+func TestRestoreObjectV2(t *testing.T) {
+	restored := false
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodHead {
+			res := newMockResponse(http.StatusOK, "")()
+			res.Header.Set(HeaderRestore, `ongoing-request="false", expiry-date="Fri, 23 Dec 2022 00:00:00 GMT"`)
+			return res
+		}
+		data, _ := ioutil.ReadAll(req.Content)
+		require.Equal(t, `{"Days":3,"RestoreJobParameters":{"Tier":"Expedited"}}`, string(data))
+		if restored {
+			return newMockResponse(http.StatusConflict, `{"Code":"RestoreAlreadyInProgress","Message":"restore in progress"}`)()
+		}
+		restored = true
+		return newMockResponse(http.StatusAccepted, "")()
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+	input := &RestoreObjectV2Input{
+		Bucket:               "bucket",
+		Key:                  "key",
+		Days:                 3,
+		RestoreJobParameters: &RestoreJobParameters{Tier: enum.TierExpedited},
+	}
+	output, err := client.RestoreObjectV2(ctx, input)
+	require.Nil(t, err)
+	require.True(t, output.Accepted)
+
+	_, err = client.RestoreObjectV2(ctx, input)
+	_, ok := err.(*RestoreInProgressError)
+	require.True(t, ok)
+	require.Equal(t, "RestoreAlreadyInProgress", Code(err))
+
+	// other conflicts are returned as is
+	conflict := &TosServerError{TosError: TosError{Message: "conflict"},
+		RequestInfo: RequestInfo{StatusCode: http.StatusConflict}, Code: "InvalidObjectState"}
+	require.Equal(t, conflict, newRestoreInProgressError(conflict))
+
+	head, err := client.HeadObjectV2(ctx, &HeadObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	require.False(t, head.RestoreInProgress)
+	require.Equal(t, time.Date(2022, 12, 23, 0, 0, 0, 0, time.UTC), head.RestoreExpiryDate)
+
+	inProgress, expiry := parseRestore(`ongoing-request="true"`)
+	require.True(t, inProgress)
+	require.True(t, expiry.IsZero())
+}
+
+func TestDeleteMultiObjects(t *testing.T) {
+	var (
+		body       string
+		contentMD5 string
+	)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		data, _ := ioutil.ReadAll(req.Content)
+		body = string(data)
+		contentMD5 = req.Header.Get(HeaderContentMD5)
+		return newMockResponse(http.StatusOK, `{"Deleted":[{"Key":"a"}],"Error":[{"Key":"b","VersionId":"v","Code":"AccessDenied"}]}`)()
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	output, err := client.DeleteMultiObjects(ctx, &DeleteMultiObjectsInput{
+		Bucket:  "bucket",
+		Objects: []ObjectTobeDeleted{{Key: "a"}, {Key: "b", VersionID: "v"}},
+		Quiet:   true,
+	})
+	require.Nil(t, err)
+	require.Equal(t, `{"Objects":[{"Key":"a"},{"Key":"b","VersionId":"v"}],"Quiet":true}`, body)
+	sum := md5.Sum([]byte(body))
+	require.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), contentMD5)
+	require.Equal(t, "a", output.Deleted[0].Key)
+	require.Equal(t, "AccessDenied", output.Error[0].Code)
+	require.Equal(t, "v", output.Error[0].VersionID)
+
+	for _, count := range []int{0, MaxDeleteObjectsCount + 1} {
+		_, err = client.DeleteMultiObjects(ctx, &DeleteMultiObjectsInput{Bucket: "bucket", Objects: make([]ObjectTobeDeleted, count)})
+		_, ok := err.(*TosClientError)
+		require.True(t, ok)
+	}
+}
+
+func TestConditionalHeaders(t *testing.T) {
+	lastModified := time.Date(2022, 9, 9, 8, 0, 0, 0, time.UTC)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Header.Get(HeaderIfNoneMatch) == "\"etag\"" ||
+			req.Header.Get(HeaderIfModifiedSince) == lastModified.Format(http.TimeFormat) {
+			res := newMockResponse(http.StatusNotModified, "")()
+			res.Body = nil
+			return res
+		}
+		if req.Header.Get(HeaderIfMatch) != "" && req.Header.Get(HeaderIfMatch) != "\"etag\"" {
+			if req.Method == http.MethodHead {
+				return newMockResponse(http.StatusPreconditionFailed, "")()
+			}
+			return newMockResponse(http.StatusPreconditionFailed, `{"Code":"PreconditionFailed","Message":"precondition failed"}`)()
+		}
+		res := newMockResponse(http.StatusOK, "hello")()
+		res.Header.Set(HeaderETag, "\"etag\"")
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.HeadObjectV2(ctx, &HeadObjectV2Input{Bucket: "bucket", Key: "key", IfNoneMatch: "\"etag\""})
+	_, ok := err.(*NotModifiedError)
+	require.True(t, ok)
+	require.Equal(t, http.StatusNotModified, StatusCode(err))
+	_, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", IfModifiedSince: lastModified})
+	_, ok = err.(*NotModifiedError)
+	require.True(t, ok)
+
+	_, err = client.HeadObjectV2(ctx, &HeadObjectV2Input{Bucket: "bucket", Key: "key", IfMatch: "\"other\""})
+	_, ok = err.(*PreconditionFailedError)
+	require.True(t, ok)
+	_, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", IfMatch: "\"other\""})
+	_, ok = err.(*PreconditionFailedError)
+	require.True(t, ok)
+	require.Equal(t, "PreconditionFailed", Code(err))
+
+	get, err := client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", IfNoneMatch: "\"other\"", IfMatch: "\"etag\""})
+	require.Nil(t, err)
+	require.Equal(t, "\"etag\"", get.ETag)
+}
+
+func TestServerSideEncryptionKMS(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		body := ""
+		if _, ok := req.Query["uploads"]; ok {
+			body = `{"Bucket":"bucket","Key":"key","UploadId":"upload"}`
+		} else if req.Header.Get(HeaderCopySource) != "" {
+			body = `{"ETag":"\"etag\""}`
+		}
+		res := newMockResponse(http.StatusOK, body)()
+		if sse := req.Header.Get(HeaderServerSideEncryption); sse != "" {
+			res.Header.Set(HeaderServerSideEncryption, sse)
+			res.Header.Set(HeaderSSEKMSKeyID, req.Header.Get(HeaderSSEKMSKeyID))
+		}
+		res.Header.Set(HeaderNextAppendOffset, "5")
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	put, err := client.PutObjectV2(ctx, &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key", ServerSideEncryption: "kms", ServerSideEncryptionKeyID: "key-id"},
+		Content:             strings.NewReader("hello"),
+	})
+	require.Nil(t, err)
+	require.Equal(t, "kms", put.ServerSideEncryption)
+	require.Equal(t, "key-id", put.ServerSideEncryptionKeyID)
+	req := transport.recorded()[0]
+	require.Equal(t, "kms", req.Header.Get(HeaderServerSideEncryption))
+	require.Equal(t, "key-id", req.Header.Get(HeaderSSEKMSKeyID))
+
+	created, err := client.CreateMultipartUploadV2(ctx, &CreateMultipartUploadV2Input{Bucket: "bucket", Key: "key", ServerSideEncryption: "kms", ServerSideEncryptionKeyID: "key-id"})
+	require.Nil(t, err)
+	require.Equal(t, "key-id", created.ServerSideEncryptionKeyID)
+
+	copied, err := client.CopyObject(ctx, &CopyObjectInput{Bucket: "bucket", Key: "key", SrcBucket: "bucket", SrcKey: "src", ServerSideEncryption: "kms", ServerSideEncryptionKeyID: "key-id"})
+	require.Nil(t, err)
+	require.Equal(t, "kms", copied.ServerSideEncryption)
+	require.Equal(t, "key-id", copied.ServerSideEncryptionKeyID)
+
+	appended, err := client.AppendObjectV2(ctx, &AppendObjectV2Input{Bucket: "bucket", Key: "key", Content: strings.NewReader("hello"), ServerSideEncryption: "kms", ServerSideEncryptionKeyID: "key-id"})
+	require.Nil(t, err)
+	require.Equal(t, "key-id", appended.ServerSideEncryptionKeyID)
+
+	head, err := client.HeadObjectV2(ctx, &HeadObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	require.Equal(t, "", head.ServerSideEncryption)
+
+	// SSE-C and SSE-KMS can not be used together, and key ID requires SSE-KMS
+	requests := len(transport.recorded())
+	_, err = client.PutObjectV2(ctx, &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key", SSECAlgorithm: "AES256", SSECKey: "a2V5", ServerSideEncryption: "kms"},
+	})
+	require.NotNil(t, err)
+	_, err = client.CreateMultipartUploadV2(ctx, &CreateMultipartUploadV2Input{Bucket: "bucket", Key: "key", SSECAlgorithm: "AES256", ServerSideEncryptionKeyID: "key-id"})
+	require.NotNil(t, err)
+	_, err = client.CopyObject(ctx, &CopyObjectInput{Bucket: "bucket", Key: "key", SrcBucket: "bucket", SrcKey: "src", ServerSideEncryption: "AES256", ServerSideEncryptionKeyID: "key-id"})
+	require.NotNil(t, err)
+	require.Equal(t, requests, len(transport.recorded()))
+}
+
+func TestStorageClass(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, "")()
+		res.Header.Set(HeaderStorageClass, string(enum.StorageClassArchiveFr))
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.PutObjectV2(ctx, &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key", StorageClass: enum.StorageClassArchiveFr},
+		Content:             strings.NewReader("hello"),
+	})
+	require.Nil(t, err)
+	require.Equal(t, "ARCHIVE_FR", transport.recorded()[0].Header.Get(HeaderStorageClass))
+	head, err := client.HeadObjectV2(ctx, &HeadObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	require.Equal(t, enum.StorageClassArchiveFr, head.StorageClass)
+
+	_, err = client.PutObjectV2(ctx, &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key", StorageClass: "ia"},
+		Content:             strings.NewReader("hello"),
+	})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "STANDARD, IA, ARCHIVE_FR")
+	_, err = client.AppendObjectV2(ctx, &AppendObjectV2Input{Bucket: "bucket", Key: "key", StorageClass: "GLACIER"})
+	require.NotNil(t, err)
+	_, err = client.CreateMultipartUploadV2(ctx, &CreateMultipartUploadV2Input{Bucket: "bucket", Key: "key", StorageClass: "GLACIER"})
+	require.NotNil(t, err)
+	_, err = client.CopyObject(ctx, &CopyObjectInput{Bucket: "bucket", Key: "key", SrcBucket: "bucket", SrcKey: "src", StorageClass: "GLACIER"})
+	require.NotNil(t, err)
+	require.Equal(t, 2, len(transport.recorded()))
+}
+
+func TestSetObjectMetaV2(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		return newMockResponse(http.StatusOK, "")()
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	output, err := client.SetObjectMetaV2(ctx, &SetObjectMetaV2Input{
+		Bucket:             "bucket",
+		Key:                "key",
+		VersionID:          "version",
+		ContentType:        "text/plain",
+		ContentDisposition: "attachment; filename=中文.txt",
+		Meta:               map[string]string{"key": "值"},
+	})
+	require.Nil(t, err)
+	require.Equal(t, "request-id", output.RequestID)
+	req := transport.recorded()[0]
+	require.Equal(t, http.MethodPost, req.Method)
+	require.Contains(t, req.Query, "metadata")
+	require.Equal(t, "version", req.Query.Get("versionId"))
+	require.Equal(t, "text/plain", req.Header.Get(HeaderContentType))
+	require.Equal(t, urlEncodeChinese("attachment; filename=中文.txt"), req.Header.Get(HeaderContentDisposition))
+	require.Equal(t, urlEncodeChinese("值"), req.Header.Get(HeaderMetaPrefix+"key"))
+
+	_, err = client.SetObjectMetaV2(ctx, &SetObjectMetaV2Input{Bucket: "bucket", Key: "key", Meta: map[string]string{"": "value"}})
+	require.NotNil(t, err)
+	_, err = client.SetObjectMetaV2(ctx, &SetObjectMetaV2Input{Bucket: "bucket", Key: "key", Meta: map[string]string{"a b": "value"}})
+	require.NotNil(t, err)
+	_, err = client.SetObjectMetaV2(ctx, &SetObjectMetaV2Input{Bucket: "bucket", Key: "key", Meta: map[string]string{"key": strings.Repeat("a", 2048)}})
+	require.NotNil(t, err)
+	require.Len(t, transport.recorded(), 1)
+}
+
+func TestGetObjectV2Process(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, "png")()
+		res.Header.Set(HeaderContentType, "image/png")
+		return res
+	}}
+	client := newMockClient(t, transport)
+	get, err := client.GetObjectV2(context.Background(), &GetObjectV2Input{Bucket: "bucket", Key: "image.jpg", Process: "image/format,png"})
+	require.Nil(t, err)
+	require.Equal(t, "image/png", get.ContentType)
+	require.Equal(t, "image/format,png", transport.recorded()[0].Query.Get("x-tos-process"))
+
+	signed, err := client.PreSignedURL(&PreSignedURLInput{Bucket: "bucket", Key: "image.jpg", Process: "image/format,png"})
+	require.Nil(t, err)
+	u, err := url.Parse(signed.SignedUrl)
+	require.Nil(t, err)
+	require.Equal(t, "image/format,png", u.Query().Get("x-tos-process"))
+}
+
+func TestGetObjectV2ProcessSave(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		return newMockResponse(http.StatusOK, `{"bucket":"dest","object":"thumbs/图片.png","fileSize":1024,"status":"OK"}`)()
+	}}
+	client := newMockClient(t, transport)
+	get, err := client.GetObjectV2(context.Background(), &GetObjectV2Input{
+		Bucket:     "bucket",
+		Key:        "image.jpg",
+		Process:    "image/format,png",
+		SaveBucket: "dest",
+		SaveObject: "thumbs/图片.png",
+	})
+	require.Nil(t, err)
+	require.Equal(t, &ProcessSaveResult{Bucket: "dest", Key: "thumbs/图片.png", Size: 1024, Status: "OK"}, get.SaveResult)
+	require.Nil(t, get.Content.Close())
+	query := transport.recorded()[0].Query
+	require.Equal(t, base64.URLEncoding.EncodeToString([]byte("dest")), query.Get("x-tos-save-bucket"))
+	require.Equal(t, base64.URLEncoding.EncodeToString([]byte("thumbs/图片.png")), query.Get("x-tos-save-object"))
+
+	for _, input := range []*GetObjectV2Input{
+		{Bucket: "bucket", Key: "image.jpg", SaveObject: "thumb.png"},
+		{Bucket: "bucket", Key: "image.jpg", Process: "image/format,png", SaveBucket: "dest"},
+		{Bucket: "bucket", Key: "image.jpg", Process: "image/format,png", SaveObject: "/thumb.png"},
+		{Bucket: "bucket", Key: "image.jpg", Process: "image/format,png", SaveBucket: "-", SaveObject: "thumb.png"},
+	} {
+		_, err = client.GetObjectV2(context.Background(), input)
+		require.NotNil(t, err)
+	}
+	require.Len(t, transport.recorded(), 1)
+}
+
+func TestDoesObjectExist(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		var res *Response
+		switch {
+		case req.Path == "/exist" || strings.HasPrefix(req.Host, "exist."):
+			res = newMockResponse(http.StatusOK, "")()
+		case req.Path == "/forbidden":
+			res = newMockResponse(http.StatusForbidden, "")()
+		default:
+			res = newMockResponse(http.StatusNotFound, "")()
+		}
+		if req.Method == http.MethodHead {
+			res.Body = nil
+		}
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	exist, err := client.DoesObjectExist(ctx, "bucket", "exist", WithVersionID("version"))
+	require.Nil(t, err)
+	require.True(t, exist)
+	require.Equal(t, "version", transport.recorded()[0].Query.Get("versionId"))
+	exist, err = client.DoesObjectExist(ctx, "bucket", "missing")
+	require.Nil(t, err)
+	require.False(t, exist)
+	exist, err = client.DoesObjectExist(ctx, "bucket", "forbidden")
+	require.Equal(t, http.StatusForbidden, StatusCode(err))
+	require.False(t, exist)
+
+	exist, err = client.DoesBucketExist(ctx, "bucket")
+	require.Nil(t, err)
+	require.False(t, exist)
+
+	exist, err = client.DoesBucketExist(ctx, "exist")
+	require.Nil(t, err)
+	require.True(t, exist)
+
+	require.True(t, isNotFound(&TosServerError{Code: "NoSuchKey"}))
+	require.False(t, isNotFound(&TosServerError{Code: "AccessDenied", RequestInfo: RequestInfo{StatusCode: http.StatusForbidden}}))
+	require.False(t, isNotFound(newTosClientError("tos: timeout", nil)))
+}
+
+func TestListObjectsType2(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		return newMockResponse(http.StatusOK, `{"Name":"bucket","Prefix":"dir%2F","KeyCount":2,"MaxKeys":2,"IsTruncated":true,`+
+			`"EncodingType":"url","NextContinuationToken":"token-2","CommonPrefixes":[{"Prefix":"dir%2F%E5%AD%90%2F"}],`+
+			`"Contents":[{"Key":"dir%2Fa+b.txt","LastModified":"2022-09-09T08:00:00Z","Size":10,"StorageClass":"STANDARD",`+
+			`"Owner":{"ID":"owner"},"HashCrc64ecma":"123"}]}`)()
+	}}
+	client := newMockClient(t, transport)
+	output, err := client.ListObjectsType2(context.Background(), &ListObjectsType2Input{
+		Bucket:            "bucket",
+		Prefix:            "dir/",
+		ContinuationToken: "token-1",
+		StartAfter:        "dir/0",
+		MaxKeys:           2,
+		FetchOwner:        true,
+		EncodingType:      "url",
+	})
+	require.Nil(t, err)
+	query := transport.recorded()[0].Query
+	require.Equal(t, "2", query.Get("list-type"))
+	require.Equal(t, "token-1", query.Get("continuation-token"))
+	require.Equal(t, "dir/0", query.Get("start-after"))
+	require.Equal(t, "true", query.Get("fetch-owner"))
+	require.Equal(t, "url", query.Get("encoding-type"))
+
+	require.Equal(t, "dir/", output.Prefix)
+	require.Equal(t, int64(2), output.KeyCount)
+	require.True(t, output.IsTruncated)
+	require.Equal(t, "token-2", output.NextContinuationToken)
+	require.Equal(t, "dir/子/", output.CommonPrefixes[0].Prefix)
+	require.Len(t, output.Contents, 1)
+	object := output.Contents[0]
+	require.Equal(t, "dir/a b.txt", object.Key)
+	require.Equal(t, int64(10), object.Size)
+	require.Equal(t, time.Date(2022, 9, 9, 8, 0, 0, 0, time.UTC), object.LastModified.UTC())
+	require.Equal(t, enum.StorageClassStandard, object.StorageClass)
+	require.Equal(t, "owner", object.Owner.ID)
+	require.Equal(t, uint64(123), object.HashCrc64ecma)
+}
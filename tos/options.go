@@ -255,6 +255,27 @@ func WithPerRequestSigner(signer Signer) Option {
 	}
 }
 
+// WithPerRequestSignHeaderRule merge rule with SignHeaderRule of the client for a request
+func WithPerRequestSignHeaderRule(rule SignHeaderRule) Option {
+	return func(rb *requestBuilder) {
+		rb.SignRule = rb.SignRule.merge(rule)
+	}
+}
+
+// WithPerRequestRetryPolicy use policy instead of RetryPolicy of the client for a request
+func WithPerRequestRetryPolicy(policy RetryPolicy) Option {
+	return func(rb *requestBuilder) {
+		rb.withRetryPolicy(policy)
+	}
+}
+
+// WithPerRequestTimeout limit each attempt of a request with timeout
+func WithPerRequestTimeout(timeout OperationTimeout) Option {
+	return func(rb *requestBuilder) {
+		rb.Timeout = &timeout
+	}
+}
+
 // WithHeader add request http header.
 //
 // NOTICE: use it carefully.
@@ -0,0 +1,249 @@
+package tos
+
+import (
+	"context"
+)
+
+// ListPartsPaginator pages through parts of a multipart upload, call Next while HasNext returns true
+type ListPartsPaginator struct {
+	cli           *ClientV2
+	input         ListPartsInput
+	hasNext       bool
+	lastRequestID string
+}
+
+// NewListPartsPaginator create a ListPartsPaginator, listing starts from input.PartNumberMarker
+func (cli *ClientV2) NewListPartsPaginator(input *ListPartsInput) *ListPartsPaginator {
+	return &ListPartsPaginator{cli: cli, input: *input, hasNext: true}
+}
+
+// HasNext returns whether there are more pages
+func (p *ListPartsPaginator) HasNext() bool {
+	return p.hasNext
+}
+
+// Next lists the next page of parts
+func (p *ListPartsPaginator) Next(ctx context.Context) (*ListPartsOutput, error) {
+	if !p.hasNext {
+		return nil, newTosClientError("tos: no more pages", nil)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, newTosClientError(err.Error(), err)
+	}
+	output, err := p.cli.ListParts(ctx, &p.input)
+	if err != nil {
+		p.lastRequestID = RequestID(err)
+		return nil, err
+	}
+	p.lastRequestID = output.RequestID
+	// stop if the marker does not move forward, to avoid listing the same page forever
+	p.hasNext = output.IsTruncated && output.NextPartNumberMarker > p.input.PartNumberMarker
+	p.input.PartNumberMarker = output.NextPartNumberMarker
+	return output, nil
+}
+
+// LastRequestID returns RequestID of the last ListParts request, for debugging
+func (p *ListPartsPaginator) LastRequestID() string {
+	return p.lastRequestID
+}
+
+// ListAllParts lists all parts of a multipart upload from input.PartNumberMarker.
+// RequestInfo of output is of the last ListParts request.
+func (cli *ClientV2) ListAllParts(ctx context.Context, input *ListPartsInput) (*ListPartsOutput, error) {
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	paginator := cli.NewListPartsPaginator(input)
+	var all *ListPartsOutput
+	for paginator.HasNext() {
+		output, err := paginator.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if all == nil {
+			all = output
+			continue
+		}
+		all.RequestInfo = output.RequestInfo
+		all.Parts = append(all.Parts, output.Parts...)
+		all.IsTruncated = output.IsTruncated
+		all.NextPartNumberMarker = output.NextPartNumberMarker
+	}
+	return all, nil
+}
+
+// ListMultipartUploadsPaginator pages through multipart uploads of a bucket, call Next while HasNext returns true.
+// KeyMarker and UploadIDMarker are passed together to continue listing.
+type ListMultipartUploadsPaginator struct {
+	cli           *ClientV2
+	input         ListMultipartUploadsV2Input
+	hasNext       bool
+	lastRequestID string
+}
+
+// NewListMultipartUploadsPaginator create a ListMultipartUploadsPaginator, listing starts from markers of input
+func (cli *ClientV2) NewListMultipartUploadsPaginator(input *ListMultipartUploadsV2Input) *ListMultipartUploadsPaginator {
+	return &ListMultipartUploadsPaginator{cli: cli, input: *input, hasNext: true}
+}
+
+// HasNext returns whether there are more pages
+func (p *ListMultipartUploadsPaginator) HasNext() bool {
+	return p.hasNext
+}
+
+// Next lists the next page of multipart uploads
+func (p *ListMultipartUploadsPaginator) Next(ctx context.Context) (*ListMultipartUploadsV2Output, error) {
+	if !p.hasNext {
+		return nil, newTosClientError("tos: no more pages", nil)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, newTosClientError(err.Error(), err)
+	}
+	output, err := p.cli.ListMultipartUploadsV2(ctx, &p.input)
+	if err != nil {
+		p.lastRequestID = RequestID(err)
+		return nil, err
+	}
+	p.lastRequestID = output.RequestID
+	// stop if the markers do not move forward, to avoid listing the same page forever
+	p.hasNext = output.IsTruncated &&
+		(output.NextKeyMarker != p.input.KeyMarker || output.NextUploadIDMarker != p.input.UploadIDMarker)
+	p.input.KeyMarker = output.NextKeyMarker
+	p.input.UploadIDMarker = output.NextUploadIDMarker
+	return output, nil
+}
+
+// LastRequestID returns RequestID of the last ListMultipartUploadsV2 request, for debugging
+func (p *ListMultipartUploadsPaginator) LastRequestID() string {
+	return p.lastRequestID
+}
+
+// ListAllMultipartUploads lists all multipart uploads matching Prefix and Delimiter of input,
+// uploads under CommonPrefixes are not included.
+func (cli *ClientV2) ListAllMultipartUploads(ctx context.Context, input *ListMultipartUploadsV2Input) ([]ListedUpload, error) {
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	uploads := make([]ListedUpload, 0)
+	paginator := cli.NewListMultipartUploadsPaginator(input)
+	for paginator.HasNext() {
+		output, err := paginator.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, output.Uploads...)
+	}
+	return uploads, nil
+}
+
+// ListObjectsPaginator pages through objects of a bucket by ListObjectsType2,
+// call NextPage while HasMorePages returns true.
+// If Delimiter of input is set, objects under CommonPrefixes of pages are not listed.
+type ListObjectsPaginator struct {
+	cli           *ClientV2
+	input         ListObjectsType2Input
+	hasMore       bool
+	lastRequestID string
+}
+
+// NewListObjectsPaginator create a ListObjectsPaginator, listing starts from ContinuationToken or StartAfter of input
+func (cli *ClientV2) NewListObjectsPaginator(input *ListObjectsType2Input) *ListObjectsPaginator {
+	return &ListObjectsPaginator{cli: cli, input: *input, hasMore: true}
+}
+
+// HasMorePages returns whether there are more pages
+func (p *ListObjectsPaginator) HasMorePages() bool {
+	return p.hasMore
+}
+
+// NextPage lists the next page of objects
+func (p *ListObjectsPaginator) NextPage(ctx context.Context) (*ListObjectsType2Output, error) {
+	if !p.hasMore {
+		return nil, newTosClientError("tos: no more pages", nil)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, newTosClientError(err.Error(), err)
+	}
+	output, err := p.cli.ListObjectsType2(ctx, &p.input)
+	if err != nil {
+		p.lastRequestID = RequestID(err)
+		return nil, err
+	}
+	p.lastRequestID = output.RequestID
+	// stop if the token does not move forward, to avoid listing the same page forever
+	p.hasMore = output.IsTruncated && output.NextContinuationToken != "" &&
+		output.NextContinuationToken != p.input.ContinuationToken
+	p.input.ContinuationToken = output.NextContinuationToken
+	return output, nil
+}
+
+// LastRequestID returns RequestID of the last ListObjectsType2 request, for debugging
+func (p *ListObjectsPaginator) LastRequestID() string {
+	return p.lastRequestID
+}
+
+// Iterate calls fn for each object of the remaining pages in order, until fn returns false,
+// all objects are listed, or an error occurs. The error of ctx is returned if ctx is done.
+func (p *ListObjectsPaginator) Iterate(ctx context.Context, fn func(object ListedObjectV2) bool) error {
+	for p.HasMorePages() {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, object := range output.Contents {
+			if err = ctx.Err(); err != nil {
+				return newTosClientError(err.Error(), err)
+			}
+			if !fn(object) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// ListObjectVersionsPaginator pages through object versions and delete markers of a bucket,
+// call Next while HasNext returns true. KeyMarker and VersionIDMarker are passed together to continue listing.
+type ListObjectVersionsPaginator struct {
+	cli           *ClientV2
+	input         ListObjectVersionsV2Input
+	hasNext       bool
+	lastRequestID string
+}
+
+// NewListObjectVersionsPaginator create a ListObjectVersionsPaginator, listing starts from markers of input
+func (cli *ClientV2) NewListObjectVersionsPaginator(input *ListObjectVersionsV2Input) *ListObjectVersionsPaginator {
+	return &ListObjectVersionsPaginator{cli: cli, input: *input, hasNext: true}
+}
+
+// HasNext returns whether there are more pages
+func (p *ListObjectVersionsPaginator) HasNext() bool {
+	return p.hasNext
+}
+
+// Next lists the next page of object versions and delete markers
+func (p *ListObjectVersionsPaginator) Next(ctx context.Context) (*ListObjectVersionsV2Output, error) {
+	if !p.hasNext {
+		return nil, newTosClientError("tos: no more pages", nil)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, newTosClientError(err.Error(), err)
+	}
+	output, err := p.cli.ListObjectVersionsV2(ctx, &p.input)
+	if err != nil {
+		p.lastRequestID = RequestID(err)
+		return nil, err
+	}
+	p.lastRequestID = output.RequestID
+	// stop if the markers do not move forward, to avoid listing the same page forever
+	p.hasNext = output.IsTruncated &&
+		(output.NextKeyMarker != p.input.KeyMarker || output.NextVersionIDMarker != p.input.VersionIDMarker)
+	p.input.KeyMarker = output.NextKeyMarker
+	p.input.VersionIDMarker = output.NextVersionIDMarker
+	return output, nil
+}
+
+// LastRequestID returns RequestID of the last ListObjectVersionsV2 request, for debugging
+func (p *ListObjectVersionsPaginator) LastRequestID() string {
+	return p.lastRequestID
+}
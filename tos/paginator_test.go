@@ -0,0 +1,182 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAllParts(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		marker, _ := strconv.Atoi(req.Query.Get("part-number-marker"))
+		if marker >= 4 {
+			return newMockResponse(http.StatusOK, `{"IsTruncated":false,"Parts":[{"PartNumber":5}]}`)()
+		}
+		next := strconv.Itoa(marker + 2)
+		body := `{"IsTruncated":true,"NextPartNumberMarker":` + next + `,"Parts":[{"PartNumber":` +
+			strconv.Itoa(marker+1) + `},{"PartNumber":` + next + `}]}`
+		return newMockResponse(http.StatusOK, body)()
+	}}
+	client := newMockClient(t, transport)
+	input := &ListPartsInput{Bucket: "bucket", Key: "key", UploadID: "upload-id"}
+
+	output, err := client.ListAllParts(context.Background(), input)
+	require.Nil(t, err)
+	require.False(t, output.IsTruncated)
+	require.Equal(t, 5, len(output.Parts))
+	for i, part := range output.Parts {
+		require.Equal(t, i+1, part.PartNumber)
+	}
+	require.Equal(t, 3, len(transport.recorded()))
+	require.Equal(t, "request-id", output.RequestID)
+
+	paginator := client.NewListPartsPaginator(input)
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = paginator.Next(ctx)
+	require.Nil(t, err)
+	require.True(t, paginator.HasNext())
+	require.Equal(t, "request-id", paginator.LastRequestID())
+	cancel()
+	_, err = paginator.Next(ctx)
+	require.NotNil(t, err)
+	require.Equal(t, 4, len(transport.recorded()))
+}
+
+func TestListAllMultipartUploads(t *testing.T) {
+	pages := map[string]string{
+		"|": `{"IsTruncated":true,"NextKeyMarker":"dir/a","NextUploadIdMarker":"id-2",
+			"Uploads":[{"Key":"dir/a","UploadId":"id-1"},{"Key":"dir/a","UploadId":"id-2"}],
+			"CommonPrefixes":[{"Prefix":"dir/sub/"}]}`,
+		"dir/a|id-2": `{"IsTruncated":false,"Uploads":[{"Key":"dir/a","UploadId":"id-3"}]}`,
+	}
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		require.Equal(t, "dir/", req.Query.Get("prefix"))
+		require.Equal(t, "/", req.Query.Get("delimiter"))
+		return newMockResponse(http.StatusOK, pages[req.Query.Get("key-marker")+"|"+req.Query.Get("upload-id-marker")])()
+	}}
+	client := newMockClient(t, transport)
+	input := &ListMultipartUploadsV2Input{Bucket: "bucket", Prefix: "dir/", Delimiter: "/"}
+
+	paginator := client.NewListMultipartUploadsPaginator(input)
+	output, err := paginator.Next(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 1, len(output.CommonPrefixes))
+	require.True(t, paginator.HasNext())
+	_, err = paginator.Next(context.Background())
+	require.Nil(t, err)
+	require.False(t, paginator.HasNext())
+	_, err = paginator.Next(context.Background())
+	require.NotNil(t, err)
+
+	uploads, err := client.ListAllMultipartUploads(context.Background(), input)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(uploads))
+	for i, upload := range uploads {
+		require.Equal(t, "id-"+strconv.Itoa(i+1), upload.UploadID)
+	}
+}
+
+func TestListObjectsPaginator(t *testing.T) {
+	pages := map[string]string{
+		"":        `{"IsTruncated":true,"NextContinuationToken":"token-1","Contents":[{"Key":"dir/a"},{"Key":"dir/b"}],"CommonPrefixes":[{"Prefix":"dir/sub/"}]}`,
+		"token-1": `{"IsTruncated":true,"NextContinuationToken":"token-2"}`,
+		"token-2": `{"IsTruncated":false,"Contents":[{"Key":"dir/c"}]}`,
+	}
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		require.Equal(t, "dir/", req.Query.Get("prefix"))
+		require.Equal(t, "/", req.Query.Get("delimiter"))
+		return newMockResponse(http.StatusOK, pages[req.Query.Get("continuation-token")])()
+	}}
+	client := newMockClient(t, transport)
+	input := &ListObjectsType2Input{Bucket: "bucket", Prefix: "dir/", Delimiter: "/"}
+
+	paginator := client.NewListObjectsPaginator(input)
+	output, err := paginator.NextPage(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "dir/sub/", output.CommonPrefixes[0].Prefix)
+	require.True(t, paginator.HasMorePages())
+	require.Equal(t, "request-id", paginator.LastRequestID())
+
+	var keys []string
+	err = client.NewListObjectsPaginator(input).Iterate(context.Background(), func(object ListedObjectV2) bool {
+		keys = append(keys, object.Key)
+		return true
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"dir/a", "dir/b", "dir/c"}, keys)
+
+	// stop by fn
+	keys = nil
+	err = client.NewListObjectsPaginator(input).Iterate(context.Background(), func(object ListedObjectV2) bool {
+		keys = append(keys, object.Key)
+		return false
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"dir/a"}, keys)
+
+	// stop by context
+	ctx, cancel := context.WithCancel(context.Background())
+	err = client.NewListObjectsPaginator(input).Iterate(ctx, func(object ListedObjectV2) bool {
+		cancel()
+		return true
+	})
+	require.NotNil(t, err)
+
+	// empty page with the same token is not listed again
+	pages["token-1"] = `{"IsTruncated":true,"NextContinuationToken":"token-1"}`
+	count := len(transport.recorded())
+	paginator = client.NewListObjectsPaginator(input)
+	for paginator.HasMorePages() {
+		_, err = paginator.NextPage(context.Background())
+		require.Nil(t, err)
+	}
+	require.Equal(t, count+2, len(transport.recorded()))
+	_, err = paginator.NextPage(context.Background())
+	require.NotNil(t, err)
+}
+
+func TestListObjectVersionsPaginator(t *testing.T) {
+	pages := map[string]string{
+		"|": `{"IsTruncated":true,"NextKeyMarker":"hot","NextVersionIdMarker":"v2",
+			"Versions":[{"Key":"hot","VersionId":"v1","IsLatest":true,"ETag":"\"etag\"","Size":10,
+			"LastModified":"2022-09-09T08:00:00Z","HashCrc64ecma":"123"}],
+			"DeleteMarkers":[{"Key":"hot","VersionId":"v2","LastModified":"2022-09-09T07:00:00Z"}]}`,
+		"hot|v2": `{"IsTruncated":false,"Versions":[{"Key":"hot","VersionId":"v3","Size":20}]}`,
+	}
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		require.Contains(t, req.Query, "versions")
+		require.Equal(t, "hot", req.Query.Get("prefix"))
+		require.Equal(t, "2", req.Query.Get("max-keys"))
+		return newMockResponse(http.StatusOK, pages[req.Query.Get("key-marker")+"|"+req.Query.Get("version-id-marker")])()
+	}}
+	client := newMockClient(t, transport)
+	paginator := client.NewListObjectVersionsPaginator(&ListObjectVersionsV2Input{
+		Bucket:                  "bucket",
+		ListObjectVersionsInput: ListObjectVersionsInput{Prefix: "hot", MaxKeys: 2},
+	})
+
+	output, err := paginator.Next(context.Background())
+	require.Nil(t, err)
+	require.True(t, paginator.HasNext())
+	require.Equal(t, "hot", output.NextKeyMarker)
+	require.Equal(t, "v2", output.NextVersionIDMarker)
+	version := output.Versions[0]
+	require.Equal(t, "v1", version.VersionID)
+	require.True(t, version.IsLatest)
+	require.Equal(t, "\"etag\"", version.ETag)
+	require.Equal(t, int64(10), version.Size)
+	require.Equal(t, uint64(123), version.HashCrc64ecma)
+	require.Equal(t, time.Date(2022, 9, 9, 8, 0, 0, 0, time.UTC), version.LastModified.UTC())
+	require.Equal(t, "v2", output.DeleteMarkers[0].VersionID)
+	require.Equal(t, time.Date(2022, 9, 9, 7, 0, 0, 0, time.UTC), output.DeleteMarkers[0].LastModified.UTC())
+
+	output, err = paginator.Next(context.Background())
+	require.Nil(t, err)
+	require.False(t, paginator.HasNext())
+	require.Equal(t, "v3", output.Versions[0].VersionID)
+	require.Equal(t, 2, len(transport.recorded()))
+}
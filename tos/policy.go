@@ -1,10 +1,14 @@
 package tos
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"strings"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/policy"
 )
 
 type BucketPolicy struct {
@@ -80,3 +84,102 @@ func (cli *Client) DeleteBucketPolicy(ctx context.Context, bucket string) (*Dele
 
 	return &DeleteBucketPolicyOutput{RequestInfo: res.RequestInfo()}, nil
 }
+
+// isValidPolicyRules checks rules have statements and every statement's Effect is Allow or Deny
+func isValidPolicyRules(rules *policy.Rules) error {
+	if len(rules.Statements) == 0 {
+		return newTosClientError("tos: statement of policy is empty", nil)
+	}
+	for _, statement := range rules.Statements {
+		if statement.Effect != policy.Allow && statement.Effect != policy.Deny {
+			return newTosClientError("tos: invalid policy effect, must be Allow or Deny", nil)
+		}
+	}
+	return nil
+}
+
+// Document parse the raw policy to policy.Rules
+func (output *GetBucketPolicyV2Output) Document() (*policy.Rules, error) {
+	var rules policy.Rules
+	if err := json.Unmarshal([]byte(output.Policy), &rules); err != nil {
+		return nil, newTosClientError("tos: unmarshal policy document failed, request id: "+output.RequestID, err)
+	}
+	return &rules, nil
+}
+
+// PutBucketPolicyV2 set bucket access policy, either raw json Policy or typed Document is required
+func (cli *ClientV2) PutBucketPolicyV2(ctx context.Context, input *PutBucketPolicyV2Input) (*PutBucketPolicyV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if (input.Policy == "") == (input.Document == nil) {
+		return nil, newTosClientError("tos: exactly one of Policy and Document is required", nil)
+	}
+	data := []byte(input.Policy)
+	if input.Document != nil {
+		if err := isValidPolicyRules(input.Document); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, _, err = marshalInput("PutBucketPolicyV2Input", input.Document); err != nil {
+			return nil, err
+		}
+	} else if !json.Valid(data) {
+		return nil, newTosClientError("tos: policy is not valid json", nil)
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("policy", "").
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusNoContent))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketPolicyV2Output{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketPolicyV2 get bucket access policy, the raw json document is returned as is
+func (cli *ClientV2) GetBucketPolicyV2(ctx context.Context, input *GetBucketPolicyV2Input) (*GetBucketPolicyV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("policy", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &GetBucketPolicyV2Output{
+		RequestInfo: res.RequestInfo(),
+		Policy:      string(data),
+	}, nil
+}
+
+// DeleteBucketPolicyV2 delete bucket access policy
+func (cli *ClientV2) DeleteBucketPolicyV2(ctx context.Context, input *DeleteBucketPolicyV2Input) (*DeleteBucketPolicyV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("policy", "").
+		Request(ctx, http.MethodDelete, nil, cli.roundTripper(http.StatusNoContent))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &DeleteBucketPolicyV2Output{RequestInfo: res.RequestInfo()}, nil
+}
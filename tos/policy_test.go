@@ -0,0 +1,88 @@
+package tos
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/policy"
+)
+
+func TestBucketPolicyV2(t *testing.T) {
+	var body string
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		switch req.Method {
+		case http.MethodPut:
+			data, _ := ioutil.ReadAll(req.Content)
+			body = string(data)
+		case http.MethodGet:
+			res := newMockResponse(http.StatusOK, "")()
+			res.Body = ioutil.NopCloser(strings.NewReader(body))
+			return res
+		}
+		res := newMockResponse(http.StatusNoContent, "")()
+		res.Body = nil
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	document := &policy.Rules{Statements: []policy.Statement{{
+		Sid:        "read",
+		Effect:     policy.Allow,
+		Principals: policy.SomePrincipals("2100000001"),
+		Actions:    policy.SomeActions("tos:GetObject", "tos:ListBucket"),
+		Resources:  policy.SomeResource("trn:tos:::bucket/*"),
+		Conditions: policy.Conditions{"StringLike": {"tos:prefix": {"doc/", "img/"}}},
+	}, {
+		Effect:     policy.Deny,
+		Principals: policy.AllPrincipals(),
+		Actions:    policy.SomeActions("tos:DeleteObject"),
+		Resources:  policy.SomeResource("trn:tos:::bucket/*"),
+	}}}
+	_, err := client.PutBucketPolicyV2(ctx, &PutBucketPolicyV2Input{Bucket: "bucket", Document: document})
+	require.Nil(t, err)
+	expected, err := json.Marshal(document)
+	require.Nil(t, err)
+
+	get, err := client.GetBucketPolicyV2(ctx, &GetBucketPolicyV2Input{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.JSONEq(t, string(expected), get.Policy)
+	parsed, err := get.Document()
+	require.Nil(t, err)
+	require.Equal(t, document, parsed)
+
+	// raw policy with single string values
+	raw := `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"tos:GetObject","Resource":"trn:tos:::bucket/*"}]}`
+	_, err = client.PutBucketPolicyV2(ctx, &PutBucketPolicyV2Input{Bucket: "bucket", Policy: raw})
+	require.Nil(t, err)
+	get, err = client.GetBucketPolicyV2(ctx, &GetBucketPolicyV2Input{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, raw, get.Policy)
+	parsed, err = get.Document()
+	require.Nil(t, err)
+	_, all := parsed.Statements[0].Principals.Principal().(*policy.AllPrincipal)
+	require.True(t, all)
+	require.Equal(t, policy.SingleAction("tos:GetObject"), parsed.Statements[0].Actions.Action())
+
+	_, err = client.DeleteBucketPolicyV2(ctx, &DeleteBucketPolicyV2Input{Bucket: "bucket"})
+	require.Nil(t, err)
+
+	requests := len(transport.recorded())
+	_, err = client.PutBucketPolicyV2(ctx, &PutBucketPolicyV2Input{Bucket: "bucket"})
+	require.NotNil(t, err)
+	_, err = client.PutBucketPolicyV2(ctx, &PutBucketPolicyV2Input{Bucket: "bucket", Policy: raw, Document: document})
+	require.NotNil(t, err)
+	_, err = client.PutBucketPolicyV2(ctx, &PutBucketPolicyV2Input{Bucket: "bucket", Policy: "{"})
+	require.NotNil(t, err)
+	_, err = client.PutBucketPolicyV2(ctx, &PutBucketPolicyV2Input{Bucket: "bucket", Document: &policy.Rules{}})
+	require.NotNil(t, err)
+	_, err = client.PutBucketPolicyV2(ctx, &PutBucketPolicyV2Input{Bucket: "bucket",
+		Document: &policy.Rules{Statements: []policy.Statement{{Effect: "Permit"}}}})
+	require.NotNil(t, err)
+	require.Equal(t, requests, len(transport.recorded()))
+}
@@ -0,0 +1,135 @@
+package tos
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	postPolicyExpirationLayout = "2006-01-02T15:04:05.000Z"
+	postConditionStartsWith    = "starts-with"
+)
+
+// NewPostSignatureCondition create a condition that the form field must equal to value
+func NewPostSignatureCondition(key, value string) PostSignatureCondition {
+	return PostSignatureCondition{Key: key, Value: value}
+}
+
+// NewPostSignatureStartsWithCondition create a condition that the form field must start with prefix,
+// empty prefix means any value is allowed
+func NewPostSignatureStartsWithCondition(key, prefix string) PostSignatureCondition {
+	return PostSignatureCondition{Key: key, Value: prefix, Operator: postConditionStartsWith}
+}
+
+func (c PostSignatureCondition) policyCondition() (interface{}, error) {
+	if len(c.Key) == 0 {
+		return nil, newTosClientError("tos: post signature condition key is empty", nil)
+	}
+	switch c.Operator {
+	case "":
+		return map[string]string{c.Key: c.Value}, nil
+	case postConditionStartsWith:
+		return []string{postConditionStartsWith, "$" + c.Key, c.Value}, nil
+	default:
+		return nil, newTosClientError("tos: invalid post signature condition operator "+c.Operator, nil)
+	}
+}
+
+type postPolicy struct {
+	Expiration string        `json:"expiration"`
+	Conditions []interface{} `json:"conditions"`
+}
+
+// FormFields returns signature form fields to post with the file,
+// the "key" field and fields of other conditions should be set by the caller
+func (out *PreSignedPostSignatureOutput) FormFields() map[string]string {
+	fields := map[string]string{
+		"policy":           out.Policy,
+		"x-tos-algorithm":  out.Algorithm,
+		"x-tos-credential": out.Credential,
+		"x-tos-date":       out.Date,
+		"x-tos-signature":  out.Signature,
+	}
+	if len(out.SecurityToken) > 0 {
+		fields["x-tos-security-token"] = out.SecurityToken
+	}
+	return fields
+}
+
+// PreSignedPostSignature builds and signs POST policy for browser form uploads, no request is sent.
+// Fields of output must be posted with the file as form fields, see PreSignedPostSignatureOutput.FormFields.
+func (cli *ClientV2) PreSignedPostSignature(input *PreSignedPostSignatureInput) (*PreSignedPostSignatureOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if len(input.Key) > 0 {
+		if err := isValidKey(input.Key); err != nil {
+			return nil, err
+		}
+	}
+	if cli.signer == nil {
+		return nil, newTosClientError("tos: credentials is not set when the tos.Client was created", nil)
+	}
+	signer, ok := cli.signer.(*SignV4)
+	if !ok {
+		return nil, newTosClientError("tos: PreSignedPostSignature requires SignV4 as the Signer of the client", nil)
+	}
+	expires := input.Expires
+	if expires == 0 {
+		expires = DefaultPreSignedURLExpires
+	}
+	if expires < 1 || expires > MaxPreSignedURLExpires {
+		return nil, newTosClientError("tos: invalid expires, the expires must be [1, 604800] seconds", nil)
+	}
+
+	now := signer.now()
+	cred := signer.credentials.Credential()
+	output := &PreSignedPostSignatureOutput{
+		Algorithm:     signPrefix,
+		Credential:    fmt.Sprintf("%s/%s/%s/tos/request", cred.AccessKeyID, now.Format(yyMMdd), signer.region),
+		Date:          now.Format(iso8601Layout),
+		SecurityToken: cred.SecurityToken,
+	}
+
+	policy := postPolicy{Expiration: now.Add(time.Duration(expires) * time.Second).Format(postPolicyExpirationLayout)}
+	policy.Conditions = append(policy.Conditions, map[string]string{"bucket": input.Bucket})
+	if len(input.Key) > 0 {
+		policy.Conditions = append(policy.Conditions, map[string]string{"key": input.Key})
+	}
+	for _, condition := range input.Conditions {
+		c, err := condition.policyCondition()
+		if err != nil {
+			return nil, err
+		}
+		policy.Conditions = append(policy.Conditions, c)
+	}
+	if r := input.ContentLengthRange; r != nil {
+		if r.RangeStart < 0 || r.RangeEnd < r.RangeStart {
+			return nil, newTosClientError("tos: invalid content length range", nil)
+		}
+		policy.Conditions = append(policy.Conditions, []interface{}{"content-length-range", r.RangeStart, r.RangeEnd})
+	}
+	policy.Conditions = append(policy.Conditions,
+		map[string]string{"x-tos-algorithm": output.Algorithm},
+		map[string]string{"x-tos-credential": output.Credential},
+		map[string]string{"x-tos-date": output.Date})
+	if len(cred.SecurityToken) > 0 {
+		policy.Conditions = append(policy.Conditions, map[string]string{"x-tos-security-token": cred.SecurityToken})
+	}
+	data, err := json.Marshal(&policy)
+	if err != nil {
+		return nil, newTosClientError("tos: marshal post policy failed", err)
+	}
+	output.OriginPolicy = string(data)
+	output.Policy = base64.StdEncoding.EncodeToString(data)
+	output.Signature = signer.SignPostPolicy(output.Policy, now, &cred)
+
+	host, path := cli.newBuilder(input.Bucket, "").hostPath()
+	output.PostURL = cli.scheme + "://" + host + path
+	return output, nil
+}
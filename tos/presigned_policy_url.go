@@ -0,0 +1,120 @@
+package tos
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type policyURLPolicy struct {
+	Conditions []interface{} `json:"conditions"`
+}
+
+func (c PolicySignatureCondition) policyCondition() (interface{}, error) {
+	if c.Key != "key" {
+		return nil, newTosClientError("tos: policy signature condition key must be \"key\"", nil)
+	}
+	switch c.Operator {
+	case "":
+		return map[string]string{c.Key: c.Value}, nil
+	case postConditionStartsWith:
+		return []string{postConditionStartsWith, "$" + c.Key, c.Value}, nil
+	default:
+		return nil, newTosClientError("tos: invalid policy signature condition operator "+c.Operator, nil)
+	}
+}
+
+// PreSignedPolicyURL signs a policy once for keys matching Conditions in the bucket, no request is sent.
+// The bucket, keys and expiration are bound by the signature, URLs of objects are generated by
+// GetSignedURLForGetOrHead and GetSignedURLForList of output without signing again.
+func (cli *ClientV2) PreSignedPolicyURL(input *PreSignedPolicyURLInput) (*PreSignedPolicyURLOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if len(input.Conditions) == 0 {
+		return nil, newTosClientError("tos: empty policy signature conditions", nil)
+	}
+	if cli.signer == nil {
+		return nil, newTosClientError("tos: credentials is not set when the tos.Client was created", nil)
+	}
+	signer, ok := cli.signer.(PolicySigner)
+	if !ok {
+		return nil, newTosClientError("tos: the Signer of the client does not implement PolicySigner", nil)
+	}
+	expires := input.Expires
+	if expires == 0 {
+		expires = DefaultPreSignedURLExpires
+	}
+	if expires < 1 || expires > MaxPreSignedURLExpires {
+		return nil, newTosClientError("tos: invalid expires, the expires must be [1, 604800] seconds", nil)
+	}
+
+	policy := policyURLPolicy{Conditions: []interface{}{map[string]string{"bucket": input.Bucket}}}
+	for _, condition := range input.Conditions {
+		c, err := condition.policyCondition()
+		if err != nil {
+			return nil, err
+		}
+		policy.Conditions = append(policy.Conditions, c)
+	}
+	data, err := json.Marshal(&policy)
+	if err != nil {
+		return nil, newTosClientError("tos: marshal policy failed", err)
+	}
+	query := signer.SignPolicyQuery(base64.StdEncoding.EncodeToString(data), time.Second*time.Duration(expires))
+
+	output := &PreSignedPolicyURLOutput{
+		SignatureQuery: query.Encode(),
+		scheme:         cli.scheme,
+		host:           cli.host,
+		bucket:         input.Bucket,
+		urlMode:        cli.urlMode,
+	}
+	if len(input.AlternativeEndpoint) > 0 {
+		output.scheme, output.host, output.urlMode = schemeHost(input.AlternativeEndpoint)
+	}
+	if input.IsCustomDomain {
+		output.urlMode = urlModeCustomDomain
+	}
+	return output, nil
+}
+
+// GetSignedURLForList returns URL to list objects of the bucket, e.g. with "prefix" in additionalQuery
+func (out *PreSignedPolicyURLOutput) GetSignedURLForList(additionalQuery map[string]string) string {
+	return out.signedURL("", additionalQuery)
+}
+
+// GetSignedURLForGetOrHead returns URL to get or head the object key, key must match conditions of the policy
+func (out *PreSignedPolicyURLOutput) GetSignedURLForGetOrHead(key string, additionalQuery map[string]string) string {
+	return out.signedURL(key, additionalQuery)
+}
+
+func (out *PreSignedPolicyURLOutput) signedURL(key string, additionalQuery map[string]string) string {
+	rb := requestBuilder{Host: out.host, Bucket: out.bucket, Object: key, URLMode: out.urlMode}
+	host, path := rb.hostPath()
+	rawQuery := out.SignatureQuery
+	if len(additionalQuery) > 0 {
+		keys := make([]string, 0, len(additionalQuery))
+		for k := range additionalQuery {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var buf strings.Builder
+		buf.WriteString(rawQuery)
+		for _, k := range keys {
+			buf.WriteByte('&')
+			buf.WriteString(url.QueryEscape(k))
+			buf.WriteByte('=')
+			buf.WriteString(url.QueryEscape(additionalQuery[k]))
+		}
+		rawQuery = buf.String()
+	}
+	u := url.URL{Scheme: out.scheme, Host: host, Path: path, RawQuery: rawQuery}
+	return u.String()
+}
@@ -0,0 +1,93 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyBypass(t *testing.T) {
+	proxy := NewProxy("proxy.example.com", 8080)
+	proxy.WithNoProxy("internal.com", ".corp.com", "example.net:8080", "10.0.0.0/8", "192.168.1.1")
+	tests := []struct {
+		target string
+		bypass bool
+	}{
+		{"https://internal.com/key", true},
+		{"https://bucket.internal.com/key", true},
+		{"https://corp.com/key", false},
+		{"https://bucket.corp.com/key", true},
+		{"http://example.net:8080/key", true},
+		{"http://example.net/key", false},
+		{"http://10.1.2.3/key", true},
+		{"http://192.168.1.1:9000/key", true},
+		{"https://tos-cn-beijing.volces.com/key", false},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.target)
+		require.Nil(t, err)
+		require.Equal(t, tt.bypass, proxy.bypass(u), tt.target)
+	}
+	proxy.WithNoProxy("*")
+	require.True(t, proxy.bypass(&url.URL{Scheme: "https", Host: "tos-cn-beijing.volces.com"}))
+}
+
+func TestProxy(t *testing.T) {
+	var (
+		requests int32
+		connects int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect {
+			atomic.AddInt32(&connects, 1)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNz" || r.URL.Host != "bucket.tos-cn-beijing.volces.com" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+	}))
+	defer server.Close()
+
+	proxy, err := NewProxyWithURL(strings.Replace(server.URL, "http://", "http://user:pass@", 1))
+	require.Nil(t, err)
+	client, err := NewClientV2("http://tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithCredentials(NewStaticCredentials("ak", "sk")), WithProxy(proxy))
+	require.Nil(t, err)
+	_, err = client.HeadBucket(context.Background(), &HeadBucketInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// pre-signed URL is not affected by the proxy
+	signed, err := client.PreSignedURL(&PreSignedURLInput{HTTPMethod: http.MethodGet, Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(signed.SignedUrl, "http://bucket.tos-cn-beijing.volces.com/key?"))
+	require.False(t, strings.Contains(signed.SignedUrl, "pass"))
+
+	// CONNECT failures are retried
+	client, err = NewClientV2("https://tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithCredentials(NewStaticCredentials("ak", "sk")), WithProxy(proxy),
+		WithRetryPolicy(RetryPolicy{MaxRetryCount: 2, BackoffBase: time.Millisecond}))
+	require.Nil(t, err)
+	_, err = client.HeadBucket(context.Background(), &HeadBucketInput{Bucket: "bucket"})
+	ce, ok := err.(*TosClientError)
+	require.True(t, ok)
+	pe, ok := ce.Cause.(*ProxyError)
+	require.True(t, ok)
+	require.Equal(t, server.URL, pe.Proxy)
+	require.False(t, strings.Contains(err.Error(), "pass"))
+	require.Equal(t, 3, ce.Attempts)
+	require.Equal(t, int32(3), atomic.LoadInt32(&connects))
+
+	_, err = NewProxyWithURL("socks5://proxy.example.com:1080")
+	require.NotNil(t, err)
+}
@@ -0,0 +1,176 @@
+package tos
+
+import (
+	"context"
+	"io"
+)
+
+// DefaultRangeReaderBufferSize bytes read ahead by each ranged GetObjectV2 request of RangeReader
+const DefaultRangeReaderBufferSize = 1024 * 1024
+
+// RangeReader reads an object by ranged GetObjectV2 requests on demand, it implements io.ReadSeeker and io.Closer,
+// so a remote object can be used by readers which need seeking, e.g. archive/tar.
+// Size and ETag of the object are fetched once by HeadObjectV2, and every ranged request is sent with If-Match,
+// so Read returns error instead of mixed content if the object is replaced during reading.
+// RangeReader is not safe for concurrent use.
+type RangeReader struct {
+	cli        *ClientV2
+	ctx        context.Context
+	bucket     string
+	key        string
+	versionID  string
+	etag       string
+	size       int64
+	offset     int64
+	bufferSize int64
+	buf        []byte
+	bufStart   int64
+	closed     bool
+}
+
+type RangeReaderOption func(*RangeReader)
+
+// WithRangeReaderContext set context used by requests of RangeReader, context.Background() by default
+func WithRangeReaderContext(ctx context.Context) RangeReaderOption {
+	return func(r *RangeReader) {
+		r.ctx = ctx
+	}
+}
+
+// WithRangeReaderBufferSize set bytes read ahead by each request, DefaultRangeReaderBufferSize by default.
+// A request reads at least the size of buffer passed to Read.
+func WithRangeReaderBufferSize(bufferSize int64) RangeReaderOption {
+	return func(r *RangeReader) {
+		r.bufferSize = bufferSize
+	}
+}
+
+// NewRangeReader create a RangeReader of the object, versionID can be empty for the latest version
+func (cli *ClientV2) NewRangeReader(bucket, key, versionID string, options ...RangeReaderOption) (*RangeReader, error) {
+	if err := isValidNames(bucket, key); err != nil {
+		return nil, err
+	}
+	r := &RangeReader{
+		cli:        cli,
+		ctx:        context.Background(),
+		bucket:     bucket,
+		key:        key,
+		versionID:  versionID,
+		bufferSize: DefaultRangeReaderBufferSize,
+	}
+	for _, option := range options {
+		option(r)
+	}
+	if r.bufferSize <= 0 {
+		r.bufferSize = DefaultRangeReaderBufferSize
+	}
+	head, err := cli.HeadObjectV2(r.ctx, &HeadObjectV2Input{Bucket: bucket, Key: key, VersionID: versionID})
+	if err != nil {
+		return nil, err
+	}
+	r.size = head.ContentLength
+	r.etag = head.ETag
+	return r, nil
+}
+
+// Size returns size of the object
+func (r *RangeReader) Size() int64 {
+	return r.size
+}
+
+// ETag returns ETag of the object
+func (r *RangeReader) ETag() string {
+	return r.etag
+}
+
+// Read implements io.Reader, it returns io.EOF at or beyond the end of the object
+func (r *RangeReader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, newTosClientError("tos: read from closed RangeReader", nil)
+	}
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if r.offset < r.bufStart || r.offset >= r.bufStart+int64(len(r.buf)) {
+		if err := r.fill(int64(len(p))); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf[r.offset-r.bufStart:])
+	r.offset += int64(n)
+	return n, nil
+}
+
+// fill reads at least max(bufferSize, min) bytes from current offset into buffer
+func (r *RangeReader) fill(min int64) error {
+	length := r.bufferSize
+	if length < min {
+		length = min
+	}
+	if remaining := r.size - r.offset; length > remaining {
+		length = remaining
+	}
+	if int64(cap(r.buf)) < length {
+		r.buf = make([]byte, length)
+	}
+	r.buf = r.buf[:length]
+	if err := readObjectRange(r.ctx, r.cli, r.bucket, r.key, r.versionID, r.etag, r.offset, r.buf); err != nil {
+		r.buf = r.buf[:0]
+		return err
+	}
+	r.bufStart = r.offset
+	return nil
+}
+
+// Seek implements io.Seeker, seeking beyond the end of the object is allowed and the following Read returns io.EOF
+func (r *RangeReader) Seek(offset int64, whence int) (int64, error) {
+	if r.closed {
+		return 0, newTosClientError("tos: seek on closed RangeReader", nil)
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, newTosClientError("tos: invalid whence", nil)
+	}
+	if offset < 0 {
+		return 0, newTosClientError("tos: negative position", nil)
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// Close implements io.Closer, buffered data is released and Read returns error after that
+func (r *RangeReader) Close() error {
+	r.closed = true
+	r.buf = nil
+	return nil
+}
+
+// readObjectRange reads len(p) bytes of the object from offset into p, the request fails if ETag of the object changed
+func readObjectRange(ctx context.Context, cli *ClientV2, bucket, key, versionID, etag string, offset int64, p []byte) error {
+	output, err := cli.GetObjectV2(ctx, &GetObjectV2Input{
+		Bucket:    bucket,
+		Key:       key,
+		VersionID: versionID,
+		IfMatch:   etag,
+		Range:     &HTTPRange{Start: offset, End: offset + int64(len(p)) - 1},
+	})
+	if err != nil {
+		return err
+	}
+	defer output.Content.Close()
+	if _, err = io.ReadFull(output.Content, p); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return newTosClientError("tos: unexpected end of ranged content, request id: "+output.RequestID, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package tos
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newRangeReaderTransport(data string, etag *string) *mockTransport {
+	return &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodHead {
+			res := newMockResponse(http.StatusOK, "")()
+			res.Body = nil
+			res.Header.Set(HeaderContentLength, strconv.Itoa(len(data)))
+			res.Header.Set(HeaderETag, *etag)
+			return res
+		}
+		if req.Header.Get("If-Match") != *etag {
+			return newMockResponse(http.StatusPreconditionFailed, `{"Code":"PreconditionFailed"}`)()
+		}
+		var start, end int
+		fmt.Sscanf(req.Header.Get(HeaderRange), "bytes=%d-%d", &start, &end)
+		res := newMockResponse(http.StatusPartialContent, data[start:end+1])()
+		res.Header.Set(HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		return res
+	}}
+}
+
+func TestRangeReader(t *testing.T) {
+	etag := "\"etag\""
+	transport := newRangeReaderTransport("0123456789", &etag)
+	client := newMockClient(t, transport)
+	reader, err := client.NewRangeReader("bucket", "key", "", WithRangeReaderBufferSize(4))
+	require.Nil(t, err)
+	require.Equal(t, int64(10), reader.Size())
+
+	p := make([]byte, 3)
+	n, err := reader.Read(p)
+	require.Nil(t, err)
+	require.Equal(t, "012", string(p[:n]))
+	// served from read-ahead buffer
+	n, err = reader.Read(p)
+	require.Nil(t, err)
+	require.Equal(t, "3", string(p[:n]))
+	require.Len(t, transport.recorded(), 2)
+
+	pos, err := reader.Seek(-2, io.SeekEnd)
+	require.Nil(t, err)
+	require.Equal(t, int64(8), pos)
+	rest, err := ioutil.ReadAll(reader)
+	require.Nil(t, err)
+	require.Equal(t, "89", string(rest))
+
+	pos, err = reader.Seek(-5, io.SeekCurrent)
+	require.Nil(t, err)
+	require.Equal(t, int64(5), pos)
+	rest, err = ioutil.ReadAll(reader)
+	require.Nil(t, err)
+	require.Equal(t, "56789", string(rest))
+
+	// seeking beyond the end is allowed, and Read returns io.EOF
+	pos, err = reader.Seek(5, io.SeekEnd)
+	require.Nil(t, err)
+	require.Equal(t, int64(15), pos)
+	n, err = reader.Read(p)
+	require.Equal(t, 0, n)
+	require.Equal(t, io.EOF, err)
+
+	pos, err = reader.Seek(-1, io.SeekStart)
+	require.NotNil(t, err)
+	_, err = reader.Seek(0, 3)
+	require.NotNil(t, err)
+	pos, err = reader.Seek(0, io.SeekCurrent)
+	require.Nil(t, err)
+	require.Equal(t, int64(15), pos)
+
+	// the object is replaced
+	_, err = reader.Seek(0, io.SeekStart)
+	require.Nil(t, err)
+	etag = "\"replaced\""
+	_, err = reader.Read(p)
+	require.Equal(t, http.StatusPreconditionFailed, StatusCode(err))
+
+	require.Nil(t, reader.Close())
+	_, err = reader.Read(p)
+	require.NotNil(t, err)
+}
+
+func TestRangeReaderEmptyObject(t *testing.T) {
+	etag := "\"etag\""
+	transport := newRangeReaderTransport("", &etag)
+	client := newMockClient(t, transport)
+	reader, err := client.NewRangeReader("bucket", "key", "")
+	require.Nil(t, err)
+	n, err := reader.Read(make([]byte, 1))
+	require.Equal(t, 0, n)
+	require.Equal(t, io.EOF, err)
+	require.Len(t, transport.recorded(), 1)
+}
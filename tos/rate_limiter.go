@@ -0,0 +1,109 @@
+package tos
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// minRateLimiterWait avoids spinning when the tokens needed are refilled in a very short time
+const minRateLimiterWait = time.Millisecond
+
+// tokenBucket is the RateLimiter returned by NewDefaultRateLimiter.
+// Tokens may be borrowed: Acquire succeeds as long as there are tokens left, and the tokens go negative
+// if want is larger than tokens left. So that all callers wait for the same condition no matter how many bytes
+// they want, and a caller wanting more than capacity is not starved by callers wanting less.
+type tokenBucket struct {
+	lock     sync.Mutex
+	rate     float64 // tokens refilled per second
+	capacity float64
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// NewDefaultRateLimiter returns a concurrency-safe token bucket RateLimiter,
+// refilled with rate tokens per second, and holding at most capacity tokens for burst.
+// The bucket is full at first, capacity is rate if it is not positive,
+// and the RateLimiter never limits if rate is not positive.
+func NewDefaultRateLimiter(rate, capacity int64) RateLimiter {
+	if capacity <= 0 {
+		capacity = rate
+	}
+	return &tokenBucket{
+		rate:     float64(rate),
+		capacity: float64(capacity),
+		tokens:   float64(capacity),
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+func (b *tokenBucket) Acquire(want int64) (bool, time.Duration) {
+	if b.rate <= 0 || want <= 0 {
+		return true, 0
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := b.now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed.Seconds()*b.rate)
+	}
+	b.last = now
+	if b.tokens > 0 {
+		b.tokens -= float64(want)
+		return true, 0
+	}
+	// wait until the debt is paid and at least one token is refilled
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if wait < minRateLimiterWait {
+		wait = minRateLimiterWait
+	}
+	return false, wait
+}
+
+// granularRateLimiter acquires tokens from base in units of granularity,
+// and tokens acquired but not used yet are kept for the next Acquire.
+type granularRateLimiter struct {
+	lock        sync.Mutex
+	base        RateLimiter
+	granularity int64
+	credit      int64
+}
+
+// NewBandwidthRateLimiter returns a RateLimiter limiting bandwidth to bytesPerSecond, bursting at most one second.
+// Tokens are acquired from the token bucket in units of granularity bytes, DefaultRateLimiterGranularity
+// if it is not positive, so that small reads do not wait for tokens one by one.
+// The RateLimiter never limits if bytesPerSecond is not positive.
+func NewBandwidthRateLimiter(bytesPerSecond, granularity int64) RateLimiter {
+	if granularity <= 0 {
+		granularity = DefaultRateLimiterGranularity
+	}
+	capacity := bytesPerSecond
+	if capacity < granularity {
+		capacity = granularity
+	}
+	return &granularRateLimiter{
+		base:        NewDefaultRateLimiter(bytesPerSecond, capacity),
+		granularity: granularity,
+	}
+}
+
+func (g *granularRateLimiter) Acquire(want int64) (bool, time.Duration) {
+	if want <= 0 {
+		return true, 0
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.credit >= want {
+		g.credit -= want
+		return true, 0
+	}
+	// round up to multiple of granularity
+	units := (want - g.credit + g.granularity - 1) / g.granularity * g.granularity
+	if ok, wait := g.base.Acquire(units); !ok {
+		return false, wait
+	}
+	g.credit += units - want
+	return true, 0
+}
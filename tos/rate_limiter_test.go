@@ -0,0 +1,135 @@
+package tos
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTokenBucket(rate, capacity int64) (*tokenBucket, *time.Time) {
+	bucket := NewDefaultRateLimiter(rate, capacity).(*tokenBucket)
+	now := time.Now()
+	bucket.last = now
+	bucket.now = func() time.Time { return now }
+	return bucket, &now
+}
+
+func TestTokenBucketBurst(t *testing.T) {
+	bucket, now := newTestTokenBucket(100, 300)
+	for i := 0; i < 3; i++ {
+		ok, _ := bucket.Acquire(100)
+		require.True(t, ok)
+	}
+	ok, wait := bucket.Acquire(100)
+	require.False(t, ok)
+	require.Equal(t, 10*time.Millisecond, wait)
+
+	// refilled no more than capacity
+	*now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		ok, _ = bucket.Acquire(100)
+		require.True(t, ok)
+	}
+	ok, _ = bucket.Acquire(1)
+	require.False(t, ok)
+
+	// want more than capacity is borrowed, and the debt is paid before next Acquire
+	*now = now.Add(3 * time.Second)
+	ok, _ = bucket.Acquire(1000)
+	require.True(t, ok)
+	ok, wait = bucket.Acquire(1)
+	require.False(t, ok)
+	require.Equal(t, 7010*time.Millisecond, wait)
+	*now = now.Add(wait)
+	ok, _ = bucket.Acquire(1)
+	require.True(t, ok)
+}
+
+func TestTokenBucketUnlimited(t *testing.T) {
+	for _, rate := range []int64{0, -1} {
+		limiter := NewDefaultRateLimiter(rate, 0)
+		for i := 0; i < 10; i++ {
+			ok, wait := limiter.Acquire(1 << 30)
+			require.True(t, ok)
+			require.Equal(t, time.Duration(0), wait)
+		}
+	}
+	// capacity is rate by default
+	bucket, _ := newTestTokenBucket(100, 0)
+	require.Equal(t, float64(100), bucket.capacity)
+}
+
+func acquireBlocking(limiter RateLimiter, want int64) {
+	for {
+		ok, wait := limiter.Acquire(want)
+		if ok {
+			return
+		}
+		time.Sleep(wait)
+	}
+}
+
+func TestTokenBucketStarvation(t *testing.T) {
+	limiter := NewDefaultRateLimiter(1024*1024, 64*1024)
+	var (
+		stop int32
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&stop) == 0 {
+				acquireBlocking(limiter, 1024)
+			}
+		}()
+	}
+	// a caller wanting more than capacity is not starved by many small callers
+	done := make(chan struct{})
+	go func() {
+		acquireBlocking(limiter, 256*1024)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("large acquire starved")
+	}
+	atomic.StoreInt32(&stop, 1)
+	wg.Wait()
+}
+
+type countingAcquireLimiter struct {
+	calls    int
+	acquired int64
+}
+
+func (c *countingAcquireLimiter) Acquire(want int64) (bool, time.Duration) {
+	c.calls++
+	c.acquired += want
+	return true, 0
+}
+
+func TestBandwidthRateLimiter(t *testing.T) {
+	limiter := NewBandwidthRateLimiter(1024*1024, 1024).(*granularRateLimiter)
+	base := &countingAcquireLimiter{}
+	limiter.base = base
+	for i := 0; i < 100; i++ {
+		ok, _ := limiter.Acquire(10)
+		require.True(t, ok)
+	}
+	require.Equal(t, 1, base.calls)
+	require.Equal(t, int64(1024), base.acquired)
+
+	ok, _ := limiter.Acquire(5000)
+	require.True(t, ok)
+	require.Equal(t, 2, base.calls)
+	require.Equal(t, int64(1024+5120), base.acquired)
+
+	require.Equal(t, int64(DefaultRateLimiterGranularity), NewBandwidthRateLimiter(1, 0).(*granularRateLimiter).granularity)
+	ok, _ = NewBandwidthRateLimiter(0, 0).Acquire(1 << 30)
+	require.True(t, ok)
+}
@@ -0,0 +1,87 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+)
+
+// isValidRealTimeLog checks Role is set, and TLS project and topic are set if service topic is not used
+func isValidRealTimeLog(conf *RealTimeLogConfiguration) error {
+	if conf.Role == "" {
+		return newTosClientError("tos: Role of real time log configuration is required", nil)
+	}
+	if !conf.Configuration.UseServiceTopic && (conf.Configuration.TLSProjectID == "" || conf.Configuration.TLSTopicID == "") {
+		return newTosClientError("tos: TLSProjectID and TLSTopicID are required if UseServiceTopic is false", nil)
+	}
+	return nil
+}
+
+// PutBucketRealTimeLog enable real time access log of bucket, logs are delivered to TLS with Role.
+// Error of the server, e.g. Role has no permission of TLS, is returned as *TosServerError with code as is.
+func (cli *ClientV2) PutBucketRealTimeLog(ctx context.Context, input *PutBucketRealTimeLogInput) (*PutBucketRealTimeLogOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if err := isValidRealTimeLog(&input.Configuration); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("PutBucketRealTimeLogInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("realtimeLog", "").
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketRealTimeLogOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketRealTimeLog get real time access log configuration of bucket,
+// TLSProjectID and TLSTopicID created by TOS are returned if UseServiceTopic is true.
+func (cli *ClientV2) GetBucketRealTimeLog(ctx context.Context, input *GetBucketRealTimeLogInput) (*GetBucketRealTimeLogOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("realtimeLog", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetBucketRealTimeLogOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// DeleteBucketRealTimeLog disable real time access log of bucket
+func (cli *ClientV2) DeleteBucketRealTimeLog(ctx context.Context, input *DeleteBucketRealTimeLogInput) (*DeleteBucketRealTimeLogOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("realtimeLog", "").
+		Request(ctx, http.MethodDelete, nil, cli.roundTripper(http.StatusNoContent))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &DeleteBucketRealTimeLogOutput{RequestInfo: res.RequestInfo()}, nil
+}
@@ -0,0 +1,54 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketRealTimeLog(t *testing.T) {
+	transport := &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, `{"RealTimeLogConfiguration":{"Role":"TOSLogArchiveTLSRole",
+			"Configuration":{"UseServiceTopic":true,"TLSProjectID":"project-id","TLSTopicID":"topic-id"}}}`),
+		newMockResponse(http.StatusNoContent, ""),
+		newMockResponse(http.StatusForbidden, `{"Code":"AccessDenied","Message":"role has no permission"}`),
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.PutBucketRealTimeLog(ctx, &PutBucketRealTimeLogInput{Bucket: "bucket",
+		Configuration: RealTimeLogConfiguration{Role: "TOSLogArchiveTLSRole", Configuration: AccessLogConfiguration{UseServiceTopic: true}}})
+	require.Nil(t, err)
+	req := transport.recorded()[0]
+	require.JSONEq(t, `{"RealTimeLogConfiguration":{"Role":"TOSLogArchiveTLSRole","Configuration":{"UseServiceTopic":true}}}`, requestBody(t, req))
+	require.NotEmpty(t, req.Header.Get(HeaderContentMD5))
+
+	get, err := client.GetBucketRealTimeLog(ctx, &GetBucketRealTimeLogInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, "TOSLogArchiveTLSRole", get.Configuration.Role)
+	require.True(t, get.Configuration.Configuration.UseServiceTopic)
+	require.Equal(t, "project-id", get.Configuration.Configuration.TLSProjectID)
+	require.Equal(t, "topic-id", get.Configuration.Configuration.TLSTopicID)
+
+	_, err = client.DeleteBucketRealTimeLog(ctx, &DeleteBucketRealTimeLogInput{Bucket: "bucket"})
+	require.Nil(t, err)
+
+	_, err = client.PutBucketRealTimeLog(ctx, &PutBucketRealTimeLogInput{Bucket: "bucket",
+		Configuration: RealTimeLogConfiguration{Role: "NoPermissionRole", Configuration: AccessLogConfiguration{UseServiceTopic: true}}})
+	require.Equal(t, "AccessDenied", Code(err))
+	require.Equal(t, http.StatusForbidden, StatusCode(err))
+	require.JSONEq(t, `{"RealTimeLogConfiguration":{"Role":"NoPermissionRole","Configuration":{"UseServiceTopic":true}}}`,
+		requestBody(t, transport.recorded()[3]))
+
+	requests := len(transport.recorded())
+	_, err = client.PutBucketRealTimeLog(ctx, &PutBucketRealTimeLogInput{Bucket: "bucket",
+		Configuration: RealTimeLogConfiguration{Configuration: AccessLogConfiguration{UseServiceTopic: true}}})
+	require.NotNil(t, err)
+	_, err = client.PutBucketRealTimeLog(ctx, &PutBucketRealTimeLogInput{Bucket: "bucket",
+		Configuration: RealTimeLogConfiguration{Role: "TOSLogArchiveTLSRole", Configuration: AccessLogConfiguration{TLSProjectID: "project-id"}}})
+	require.NotNil(t, err)
+	require.Equal(t, requests, len(transport.recorded()))
+}
@@ -0,0 +1,46 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketRename(t *testing.T) {
+	transport := &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, `{"RenameEnable":false}`),
+		newMockResponse(http.StatusConflict, `{"Code":"RenameNotEnabled","Message":"rename is not enabled"}`),
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, `{"RenameEnable":true}`),
+		newMockResponse(http.StatusNoContent, ""),
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	get, err := client.GetBucketRename(ctx, &GetBucketRenameInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.False(t, get.RenameEnable)
+	_, err = client.RenameObject(ctx, &RenameObjectInput{Bucket: "bucket", Key: "a/old", NewKey: "a/new"})
+	require.Equal(t, http.StatusConflict, StatusCode(err))
+	require.Equal(t, "RenameNotEnabled", Code(err))
+
+	_, err = client.PutBucketRename(ctx, &PutBucketRenameInput{Bucket: "bucket", RenameEnable: true})
+	require.Nil(t, err)
+	require.JSONEq(t, `{"RenameEnable":true}`, requestBody(t, transport.recorded()[2]))
+	get, err = client.GetBucketRename(ctx, &GetBucketRenameInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.True(t, get.RenameEnable)
+
+	_, err = client.RenameObject(ctx, &RenameObjectInput{Bucket: "bucket", Key: "a/old", NewKey: "a/new"})
+	require.Nil(t, err)
+	req := transport.recorded()[len(transport.recorded())-1]
+	require.Equal(t, http.MethodPut, req.Method)
+	require.Equal(t, "/a/old", req.Path)
+	require.Equal(t, "a/new", req.Query.Get("name"))
+	require.Contains(t, req.Query, "rename")
+
+	_, err = client.RenameObject(ctx, &RenameObjectInput{Bucket: "bucket", Key: "a/old"})
+	require.NotNil(t, err)
+}
@@ -27,6 +27,8 @@ const (
 	urlModeDefault = 0
 	// urlModePath url pattern is http(s)://domain/{bucket}/{object}
 	urlModePath = 1
+	// urlModeCustomDomain url pattern is http(s)://custom-domain/{object}
+	urlModeCustomDomain = 2
 )
 
 type Request struct {
@@ -38,6 +40,9 @@ type Request struct {
 	Content       io.Reader
 	Query         url.Values
 	Header        http.Header
+	// SignHeaderRule changes headers signed by SignV4
+	SignHeaderRule SignHeaderRule
+	chunkSigner    *chunkSigner // nullable, signs chunks of streaming payload after the request is signed by SignV4
 }
 
 func (req *Request) URL() string {
@@ -61,6 +66,76 @@ func (hr *Range) String() string {
 	return fmt.Sprintf("bytes=%d-%d", hr.Start, hr.End)
 }
 
+// HTTPRange is range of object content to get.
+// Start and End are inclusive offsets, End < 0 means to the end of the object;
+// if Suffix > 0, the last Suffix bytes are requested and Start and End are ignored.
+type HTTPRange struct {
+	Start  int64
+	End    int64
+	Suffix int64
+}
+
+// FormatHTTPRange returns value of HTTP Range header, e.g. "bytes=0-1023", "bytes=1024-" or "bytes=-1024"
+func (hr *HTTPRange) FormatHTTPRange() string {
+	if hr.Suffix > 0 {
+		return fmt.Sprintf("bytes=-%d", hr.Suffix)
+	}
+	if hr.End < 0 {
+		return fmt.Sprintf("bytes=%d-", hr.Start)
+	}
+	return fmt.Sprintf("bytes=%d-%d", hr.Start, hr.End)
+}
+
+func (hr *HTTPRange) validate() error {
+	if hr.Suffix > 0 {
+		return nil
+	}
+	if hr.Start < 0 || (hr.End >= 0 && hr.End < hr.Start) {
+		return newTosClientError("tos: invalid range", nil)
+	}
+	return nil
+}
+
+// ContentRange is parsed from Content-Range header, e.g. "bytes 0-1023/4096".
+// Start and End are -1 if range is unsatisfied, e.g. "bytes */4096", and Total is -1 if it is unknown.
+type ContentRange struct {
+	Start int64
+	End   int64
+	Total int64
+}
+
+func parseContentRange(value string) (*ContentRange, error) {
+	invalid := newTosClientError(fmt.Sprintf("tos: invalid Content-Range %q", value), nil)
+	if !strings.HasPrefix(value, "bytes ") {
+		return nil, invalid
+	}
+	value = strings.TrimSpace(strings.TrimPrefix(value, "bytes "))
+	slash := strings.IndexByte(value, '/')
+	if slash < 0 {
+		return nil, invalid
+	}
+	cr := &ContentRange{Start: -1, End: -1, Total: -1}
+	var err error
+	if total := value[slash+1:]; total != "*" {
+		if cr.Total, err = strconv.ParseInt(total, 10, 64); err != nil {
+			return nil, invalid
+		}
+	}
+	if rng := value[:slash]; rng != "*" {
+		dash := strings.IndexByte(rng, '-')
+		if dash < 0 {
+			return nil, invalid
+		}
+		if cr.Start, err = strconv.ParseInt(rng[:dash], 10, 64); err != nil {
+			return nil, invalid
+		}
+		if cr.End, err = strconv.ParseInt(rng[dash+1:], 10, 64); err != nil {
+			return nil, invalid
+		}
+	}
+	return cr, nil
+}
+
 type CopySource struct {
 	srcBucket    string
 	srcObjectKey string
@@ -68,6 +143,7 @@ type CopySource struct {
 
 type requestBuilder struct {
 	Signer        Signer
+	Provider      CredentialsProvider // nullable, credentials of Signer are retrieved from it before signing
 	Scheme        string
 	Host          string
 	Bucket        string
@@ -81,6 +157,11 @@ type requestBuilder struct {
 	OnRetry       func(req *Request)
 	Classifier    classifier
 	CopySource    *CopySource
+	SignRule      SignHeaderRule
+	signedKeys    []string          // headers set by the last sign
+	Anonymous     bool              // requests are not signed and only GET, HEAD and OPTIONS are allowed
+	Timeout       *OperationTimeout // nullable, timeout of each attempt
+	chunkSigner   *chunkSigner      // nullable, see withTrailingChecksum
 	// CheckETag  bool
 	// CheckCRC32 bool
 }
@@ -99,6 +180,15 @@ func (rb *requestBuilder) WithRetry(onRetry func(req *Request), classifier class
 	return rb
 }
 
+// withRetryPolicy overrides RetryPolicy of the client, the logger of the client is kept
+func (rb *requestBuilder) withRetryPolicy(policy RetryPolicy) {
+	retry := newRetryer(policy)
+	if rb.Retry != nil {
+		retry.logger = rb.Retry.logger
+	}
+	rb.Retry = retry
+}
+
 func (rb *requestBuilder) WithCopySource(srcBucket, srcObjectKey string) *requestBuilder {
 	rb.CopySource = &CopySource{
 		srcBucket:    srcBucket,
@@ -191,6 +281,9 @@ func (rb *requestBuilder) WithContentLength(length int64) *requestBuilder {
 }
 
 func (rb *requestBuilder) hostPath() (string, string) {
+	if rb.URLMode == urlModeCustomDomain {
+		return rb.Host, "/" + rb.Object
+	}
 	if rb.URLMode == urlModePath {
 		if len(rb.Object) > 0 {
 			return rb.Host, "/" + rb.Bucket + "/" + rb.Object
@@ -216,6 +309,8 @@ func (rb *requestBuilder) build(method string, content io.Reader) *Request {
 		Query:   rb.Query,
 		Header:  rb.Header,
 	}
+	req.SignHeaderRule = rb.SignRule
+	req.chunkSigner = rb.chunkSigner
 
 	if content != nil {
 		if rb.ContentLength != nil {
@@ -234,15 +329,27 @@ func (rb *requestBuilder) Build(method string, content io.Reader) *Request {
 		req.Query.Del("versionId")
 		req.Header.Add(HeaderCopySource, copySource(rb.CopySource.srcBucket, rb.CopySource.srcObjectKey, versionID))
 	}
-	if rb.Signer != nil {
-		signed := rb.Signer.SignHeader(req)
-		for key, values := range signed {
-			req.Header[key] = values
-		}
-	}
+	rb.sign(req)
 	return req
 }
 
+// sign signs req with Signer after all headers and queries of req are set, headers returned by
+// the last sign are removed first, so that req can be signed again before retries.
+func (rb *requestBuilder) sign(req *Request) {
+	if rb.Signer == nil {
+		return
+	}
+	for _, key := range rb.signedKeys {
+		delete(req.Header, key)
+	}
+	signed := rb.Signer.SignHeader(req)
+	rb.signedKeys = rb.signedKeys[:0]
+	for key, values := range signed {
+		req.Header[key] = values
+		rb.signedKeys = append(rb.signedKeys, key)
+	}
+}
+
 type roundTripper func(ctx context.Context, req *Request) (*Response, error)
 
 func (rb *requestBuilder) Request(ctx context.Context, method string,
@@ -254,11 +361,38 @@ func (rb *requestBuilder) Request(ctx context.Context, method string,
 		err error
 	)
 
+	if rb.Anonymous && method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions {
+		return nil, newTosClientError("tos: "+method+" request is not allowed by anonymous client, "+
+			"use WithCredentials or WithCredentialsProvider instead of WithAnonymous", nil)
+	}
+	if rule, ok := ctx.Value(signHeaderRuleKey{}).(SignHeaderRule); ok {
+		rb.SignRule = rb.SignRule.merge(rule)
+	}
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		rb.withRetryPolicy(policy)
+	}
+	if timeout, ok := ctx.Value(operationTimeoutKey{}).(OperationTimeout); ok {
+		rb.Timeout = &timeout
+	}
+	if rb.Timeout != nil {
+		roundTripper = rb.Timeout.wrap(roundTripper)
+	}
+	if rb.Provider != nil {
+		if err = rb.withCredential(ctx); err != nil {
+			return nil, err
+		}
+	}
 	req = rb.Build(method, content)
 
 	if rb.Retry != nil {
+		retried := false
 		work := func() (err error) {
 			rb.OnRetry(req)
+			if retried {
+				// date of the signature is refreshed, and changes made by OnRetry are signed
+				rb.sign(req)
+			}
+			retried = true
 			res, err = roundTripper(ctx, req)
 			return err
 		}
@@ -273,8 +407,30 @@ func (rb *requestBuilder) Request(ctx context.Context, method string,
 	return res, err
 }
 
+// withCredential retrieves Credential from Provider and binds it to a copy of SignV4,
+// so that the request is signed with the Credential retrieved with ctx of the request.
+func (rb *requestBuilder) withCredential(ctx context.Context) error {
+	cred, err := rb.Provider.Credentials(ctx)
+	if err != nil {
+		return newTosClientError("tos: failed to retrieve credentials", err)
+	}
+	if sv, ok := rb.Signer.(*SignV4); ok {
+		signer := *sv
+		signer.credentials = &StaticCredentials{
+			accessKey:     cred.AccessKeyID,
+			secretKey:     cred.AccessKeySecret,
+			securityToken: cred.SecurityToken,
+		}
+		rb.Signer = &signer
+	}
+	return nil
+}
+
 func (rb *requestBuilder) PreSignedURL(method string, ttl time.Duration) (string, error) {
 	req := rb.build(method, nil)
+	if rb.Signer == nil && rb.Anonymous {
+		return req.URL(), nil
+	}
 	if rb.Signer == nil {
 		return "", errors.New("tos: credentials is not set when the tos.Client was created")
 	}
@@ -293,6 +449,11 @@ type RequestInfo struct {
 	Header     http.Header
 }
 
+// RequestCharged reports whether the requester is charged for the request to requester-pays bucket
+func (ri RequestInfo) RequestCharged() bool {
+	return ri.Header != nil && strings.EqualFold(ri.Header.Get(HeaderRequestCharged), RequestPayerRequester)
+}
+
 type Response struct {
 	StatusCode    int
 	ContentLength int64
@@ -364,6 +525,11 @@ func fileUnreadLength(file *os.File) (int64, error) {
 		return 0, err
 	}
 
+	// size of pipe, socket or device is not the length of its content
+	if !stat.Mode().IsRegular() {
+		return 0, newTosClientError("tos: length of non-regular file is unknown", nil)
+	}
+
 	size := stat.Size()
 	if offset > size || offset < 0 {
 		return 0, newTosClientError("tos: unexpected file size and(or) offset", nil)
@@ -388,6 +554,12 @@ func tryResolveLength(reader io.Reader) int64 {
 		return length
 	case *io.LimitedReader:
 		return v.N
+	case *io.SectionReader:
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return v.Size() - offset
 	case *net.Buffers:
 		if v != nil {
 			length := int64(0)
@@ -0,0 +1,59 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+// PutBucketRequestPayment set who pays for requests to the bucket, if Payer is Requester,
+// requests must be sent with RequestPayer of input or WithRequestPayer of client set to RequestPayerRequester.
+func (cli *ClientV2) PutBucketRequestPayment(ctx context.Context, input *PutBucketRequestPaymentInput) (*PutBucketRequestPaymentOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if input.Payer != enum.PayerBucketOwner && input.Payer != enum.PayerRequester {
+		return nil, newTosClientError("tos: invalid payer, must be BucketOwner or Requester", nil)
+	}
+	data, contentMD5, err := marshalInput("PutBucketRequestPaymentInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("requestPayment", "").
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketRequestPaymentOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketRequestPayment get who pays for requests to the bucket
+func (cli *ClientV2) GetBucketRequestPayment(ctx context.Context, input *GetBucketRequestPaymentInput) (*GetBucketRequestPaymentOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("requestPayment", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetBucketRequestPaymentOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
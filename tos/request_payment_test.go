@@ -0,0 +1,97 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+func TestBucketRequestPayment(t *testing.T) {
+	transport := &mockTransport{responses: []func() *Response{
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, `{"Payer":"Requester"}`),
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.PutBucketRequestPayment(ctx, &PutBucketRequestPaymentInput{Bucket: "bucket", Payer: enum.PayerRequester})
+	require.Nil(t, err)
+	require.JSONEq(t, `{"Payer":"Requester"}`, requestBody(t, transport.recorded()[0]))
+	get, err := client.GetBucketRequestPayment(ctx, &GetBucketRequestPaymentInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, enum.PayerRequester, get.Payer)
+
+	_, err = client.PutBucketRequestPayment(ctx, &PutBucketRequestPaymentInput{Bucket: "bucket", Payer: "Nobody"})
+	require.NotNil(t, err)
+	require.Equal(t, 2, len(transport.recorded()))
+}
+
+func TestRequestPayer(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		var res *Response
+		switch {
+		case req.Method == http.MethodHead:
+			res = newMockResponse(http.StatusOK, "")()
+			res.Body = nil
+		case req.Method == http.MethodDelete:
+			res = newMockResponse(http.StatusNoContent, "")()
+			res.Body = nil
+		case req.Method == http.MethodGet && req.Path == "/":
+			res = newMockResponse(http.StatusOK, `{"Name":"bucket"}`)()
+		case req.Method == http.MethodPost:
+			res = newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"key","UploadId":"upload"}`)()
+		default:
+			res = newMockResponse(http.StatusOK, "")()
+		}
+		if req.Header.Get(HeaderRequestPayer) == RequestPayerRequester {
+			res.Header.Set(HeaderRequestCharged, RequestPayerRequester)
+		}
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	head, err := client.HeadObjectV2(ctx, &HeadObjectV2Input{Bucket: "bucket", Key: "key", RequestPayer: RequestPayerRequester})
+	require.Nil(t, err)
+	require.True(t, head.RequestCharged())
+	get, err := client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key", RequestPayer: RequestPayerRequester})
+	require.Nil(t, err)
+	require.True(t, get.RequestCharged())
+	get.Content.Close()
+	list, err := client.ListObjectsV2(ctx, &ListObjectsV2Input{Bucket: "bucket", RequestPayer: RequestPayerRequester})
+	require.Nil(t, err)
+	require.True(t, list.RequestCharged())
+	_, err = client.PutObjectV2(ctx, &PutObjectV2Input{PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key",
+		RequestPayer: RequestPayerRequester}, Content: strings.NewReader("data")})
+	require.Nil(t, err)
+	create, err := client.CreateMultipartUploadV2(ctx, &CreateMultipartUploadV2Input{Bucket: "bucket", Key: "key", RequestPayer: RequestPayerRequester})
+	require.Nil(t, err)
+	require.True(t, create.RequestCharged())
+	_, err = client.AbortMultipartUpload(ctx, &AbortMultipartUploadInput{Bucket: "bucket", Key: "key", UploadID: "upload", RequestPayer: RequestPayerRequester})
+	require.Nil(t, err)
+	for _, req := range transport.recorded() {
+		require.Equal(t, RequestPayerRequester, req.Header.Get(HeaderRequestPayer), req.Method)
+	}
+
+	head, err = client.HeadObjectV2(ctx, &HeadObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	require.False(t, head.RequestCharged())
+	requests := transport.recorded()
+	require.Equal(t, "", requests[len(requests)-1].Header.Get(HeaderRequestPayer))
+
+	// client level default, overridden by input
+	transport = &mockTransport{handler: transport.handler}
+	client, err = NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"), WithCredentials(NewStaticCredentials("ak", "sk")),
+		WithTransport(transport), WithRequestPayer(RequestPayerRequester))
+	require.Nil(t, err)
+	head, err = client.HeadObjectV2(ctx, &HeadObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	require.True(t, head.RequestCharged())
+	_, err = client.HeadObjectV2(ctx, &HeadObjectV2Input{Bucket: "bucket", Key: "key", RequestPayer: "owner"})
+	require.Nil(t, err)
+	require.Equal(t, "owner", transport.recorded()[1].Header.Get(HeaderRequestPayer))
+}
@@ -1,13 +1,17 @@
 package tos
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -66,6 +70,11 @@ func TestTryResolveLength(t *testing.T) {
 	buffers := net.Buffers{make([]byte, 1024), make([]byte, 1024)}
 	size = tryResolveLength(&buffers)
 	require.Equal(t, size, int64(2048))
+
+	section := io.NewSectionReader(file, 10, 100)
+	_, err = section.Read(make([]byte, 30))
+	require.Nil(t, err)
+	require.Equal(t, int64(70), tryResolveLength(section))
 }
 
 func TestFileUnreadSize(t *testing.T) {
@@ -122,3 +131,119 @@ func TestFileUnreadSize(t *testing.T) {
 //	}
 //
 // }
+
+func TestHTTPRange(t *testing.T) {
+	require.Equal(t, "bytes=0-1023", (&HTTPRange{Start: 0, End: 1023}).FormatHTTPRange())
+	require.Equal(t, "bytes=1024-", (&HTTPRange{Start: 1024, End: -1}).FormatHTTPRange())
+	require.Equal(t, "bytes=-100", (&HTTPRange{Start: 10, End: 20, Suffix: 100}).FormatHTTPRange())
+	require.NotNil(t, (&HTTPRange{Start: 10, End: 9}).validate())
+	require.NotNil(t, (&HTTPRange{Start: -1, End: 9}).validate())
+
+	cr, err := parseContentRange("bytes 0-1023/4096")
+	require.Nil(t, err)
+	require.Equal(t, ContentRange{Start: 0, End: 1023, Total: 4096}, *cr)
+	cr, err = parseContentRange("bytes */4096")
+	require.Nil(t, err)
+	require.Equal(t, ContentRange{Start: -1, End: -1, Total: 4096}, *cr)
+	cr, err = parseContentRange("bytes 0-1023/*")
+	require.Nil(t, err)
+	require.Equal(t, int64(-1), cr.Total)
+	for _, value := range []string{"", "0-1023/4096", "bytes 0-1023", "bytes 0/4096", "bytes a-b/4096"} {
+		_, err = parseContentRange(value)
+		require.NotNil(t, err, value)
+	}
+}
+
+func TestAnonymousClient(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Path == "/" {
+			return newMockResponse(http.StatusOK, `{"Name":"bucket","Contents":[{"Key":"key","Size":5}]}`)()
+		}
+		return newMockResponse(http.StatusOK, "hello")()
+	}}
+	client, err := NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"), WithAnonymous(),
+		WithCredentials(NewStaticCredentials("ak", "sk")), WithTransport(transport))
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	get, err := client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	data, err := ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, "hello", string(data))
+	list, err := client.ListObjectsType2(ctx, &ListObjectsType2Input{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, "key", list.Contents[0].Key)
+	for _, req := range transport.recorded() {
+		require.Equal(t, "", req.Header.Get("Authorization"))
+	}
+
+	_, err = client.PutObjectV2(ctx, &PutObjectV2Input{PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key"},
+		Content: strings.NewReader("hello")})
+	_, ok := err.(*TosClientError)
+	require.True(t, ok)
+	require.Contains(t, err.Error(), "anonymous")
+	require.Equal(t, 2, len(transport.recorded()))
+
+	presigned, err := client.PreSignedURL(&PreSignedURLInput{HTTPMethod: http.MethodGet, Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	require.Equal(t, "https://bucket.tos-cn-beijing.volces.com/key", presigned.SignedUrl)
+}
+
+func TestRequestRetryPolicy(t *testing.T) {
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusInternalServerError, "")()
+		res.Body = nil
+		return res
+	}}
+	client, err := NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithCredentials(NewStaticCredentials("ak", "sk")), WithTransport(transport),
+		WithRetryPolicy(RetryPolicy{MaxRetryCount: 2, BackoffBase: time.Millisecond, Jitter: JitterFull}))
+	require.Nil(t, err)
+
+	_, err = client.HeadBucket(context.Background(), &HeadBucketInput{Bucket: "bucket"})
+	require.Equal(t, 3, err.(*TosServerError).Attempts)
+	require.Equal(t, 3, len(transport.recorded()))
+
+	ctx := ContextWithRetryPolicy(context.Background(), RetryPolicy{})
+	_, err = client.HeadBucket(ctx, &HeadBucketInput{Bucket: "bucket"})
+	require.Equal(t, 1, err.(*TosServerError).Attempts)
+	require.Equal(t, 4, len(transport.recorded()))
+
+	bkt, err := client.Bucket("bucket")
+	require.Nil(t, err)
+	_, err = bkt.HeadObject(context.Background(), "key",
+		WithPerRequestRetryPolicy(RetryPolicy{MaxRetryCount: 1, BackoffBase: time.Millisecond}))
+	require.Equal(t, 2, err.(*TosServerError).Attempts)
+	require.Equal(t, 6, len(transport.recorded()))
+}
+
+func TestTransportOptions(t *testing.T) {
+	client, err := NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"))
+	require.Nil(t, err)
+	transport := client.transport.(*DefaultTransport).client.Transport.(*http.Transport)
+	require.Equal(t, 1024, transport.MaxIdleConnsPerHost)
+	require.Equal(t, 64*1024, transport.ReadBufferSize)
+	require.True(t, transport.DisableCompression)
+
+	client, err = NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithMaxIdleConns(100), WithMaxIdleConnsPerHost(10), WithMaxConnsPerHost(20), WithIdleConnTimeout(time.Second),
+		WithTLSHandshakeTimeout(2*time.Second), WithBufferSize(8192, 16384), WithDisableCompression(false))
+	require.Nil(t, err)
+	transport = client.transport.(*DefaultTransport).client.Transport.(*http.Transport)
+	require.Equal(t, 100, transport.MaxIdleConns)
+	require.Equal(t, 10, transport.MaxIdleConnsPerHost)
+	require.Equal(t, 20, transport.MaxConnsPerHost)
+	require.Equal(t, time.Second, transport.IdleConnTimeout)
+	require.Equal(t, 2*time.Second, transport.TLSHandshakeTimeout)
+	require.Equal(t, 8192, transport.ReadBufferSize)
+	require.Equal(t, 16384, transport.WriteBufferSize)
+	require.False(t, transport.DisableCompression)
+
+	// custom http.RoundTripper overrides TransportConfig
+	custom := &http.Transport{}
+	client, err = NewClientV2("tos-cn-beijing.volces.com", WithRegion("cn-beijing"),
+		WithHTTPTransport(custom), WithMaxIdleConnsPerHost(10))
+	require.Nil(t, err)
+	require.True(t, client.transport.(*DefaultTransport).client.Transport == custom)
+}
@@ -0,0 +1,400 @@
+package tos
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+// initCopyPartsInfo initialize parts info from source object size, return TosClientError if failed
+func initCopyPartsInfo(objectSize int64, partSize int64) ([]copyPartInfo, error) {
+	partCount := objectSize / partSize
+	if objectSize%partSize != 0 {
+		partCount++
+	}
+	if partCount > MaxPartCount {
+		return nil, newTosClientError("tos: part count too many", nil)
+	}
+	parts := make([]copyPartInfo, 0, partCount)
+	for i := int64(0); i < partCount; i++ {
+		end := (i+1)*partSize - 1
+		if end >= objectSize {
+			end = objectSize - 1
+		}
+		parts = append(parts, copyPartInfo{
+			PartNumber:           int(i + 1),
+			CopySourceRangeStart: i * partSize,
+			CopySourceRangeEnd:   end,
+		})
+	}
+	return parts, nil
+}
+
+// initCopyCheckpoint initialize checkpoint from source object, return TosClientError if failed
+func initCopyCheckpoint(input *ResumableCopyObjectInput, head *HeadObjectV2Output) (*copyCheckpoint, error) {
+	parts, err := initCopyPartsInfo(head.ContentLength, input.PartSize)
+	if err != nil {
+		return nil, err
+	}
+	return &copyCheckpoint{
+		checkpointPath: input.CheckpointFile,
+		SrcBucket:      input.SrcBucket,
+		SrcKey:         input.SrcKey,
+		SrcVersionID:   input.SrcVersionID,
+		Bucket:         input.Bucket,
+		Key:            input.Key,
+		PartSize:       input.PartSize,
+		SSECAlgorithm:  input.SSECAlgorithm,
+		SSECKeyMD5:     input.SSECKeyMD5,
+		EncodingType:   input.ContentEncoding,
+		CopySourceObjectInfo: copySourceObjectInfo{
+			Etag:          head.ETag,
+			HashCrc64ecma: head.HashCrc64ecma,
+			LastModified:  head.LastModified,
+			ObjectSize:    head.ContentLength,
+		},
+		PartsInfo: parts,
+	}, nil
+}
+
+func copyCheckpointFileName(input *ResumableCopyObjectInput) string {
+	replacer := strings.NewReplacer("/", "_", "\\", "_")
+	return strings.Join([]string{input.SrcBucket, replacer.Replace(input.SrcKey),
+		input.Bucket, replacer.Replace(input.Key), "copy"}, ".")
+}
+
+// validateCopyInput validate resumable copy input, return TosClientError failed
+func validateCopyInput(input *ResumableCopyObjectInput) error {
+	if err := isValidNames(input.SrcBucket, input.SrcKey); err != nil {
+		return err
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return err
+	}
+	if input.PartSize == 0 {
+		input.PartSize = MinPartSize
+	}
+	if input.PartSize < MinPartSize || input.PartSize > MaxPartSize {
+		return newTosClientError("tos: the input part size is invalid, please set it range from 5MB to 5GB.", nil)
+	}
+	if input.EnableCheckpoint {
+		// there is no local file, so checkpoint file is put in temp dir by default
+		setDefaultCheckpointFile(&input.CheckpointFile, input.CheckpointStore, os.TempDir(), copyCheckpointFileName(input))
+	}
+	if input.TaskNum < 1 {
+		input.TaskNum = 1
+	}
+	if input.TaskNum > 1000 {
+		input.TaskNum = 1000
+	}
+	return nil
+}
+
+// fillCopyMetadata use metadata of source object if destination metadata is not set,
+// so that objects copied by CopyObject and by multipart copy have the same metadata.
+func fillCopyMetadata(input *ResumableCopyObjectInput, head *HeadObjectV2Output) {
+	if input.ContentType != "" || input.CacheControl != "" || input.ContentDisposition != "" ||
+		input.ContentEncoding != "" || input.ContentLanguage != "" || !input.Expires.IsZero() || len(input.Meta) != 0 {
+		return
+	}
+	input.ContentType = head.ContentType
+	input.CacheControl = head.CacheControl
+	input.ContentDisposition = head.ContentDisposition
+	input.ContentEncoding = head.ContentEncoding
+	input.ContentLanguage = head.ContentLanguage
+	input.Expires = head.Expires
+	if head.Meta != nil {
+		input.Meta = make(map[string]string)
+		head.Meta.Range(func(key, value string) bool {
+			input.Meta[key] = value
+			return true
+		})
+	}
+}
+
+// getCopyCheckpoint get struct checkpoint saved with key from store,
+// or initialize from scratch with function init.
+// A loaded checkpoint is reused only if valid returns true, otherwise it is overwritten.
+func getCopyCheckpoint(store CheckpointStore, enabled bool, key CheckpointKey, init func() (*copyCheckpoint, error),
+	valid func(checkpoint *copyCheckpoint) bool) (checkpoint *copyCheckpoint, err error) {
+	if enabled {
+		checkpoint = &copyCheckpoint{}
+		// if failed to load, assume checkpoint not exists
+		if loadCheckpoint(store, key, checkpoint) == nil && valid(checkpoint) {
+			checkpoint.checkpointPath = key.CheckpointFile
+			checkpoint.store = store
+			return checkpoint, nil
+		}
+	}
+	checkpoint, err = init()
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		checkpoint.store = store
+		if err = checkpoint.Save(); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+func (c *copyPostEvent) postCopyEvent(event *CopyEvent) {
+	if c.input.CopyEventListener != nil {
+		c.input.CopyEventListener.EventChange(event)
+	}
+}
+
+func (c *copyPostEvent) newCopyEvent(eventType enum.CopyEventType, err error) *CopyEvent {
+	return &CopyEvent{
+		Type:           eventType,
+		Err:            err,
+		Bucket:         c.input.Bucket,
+		Key:            c.input.Key,
+		UploadID:       &c.checkPoint.UploadID,
+		SrcBucket:      c.input.SrcBucket,
+		SrcKey:         c.input.SrcKey,
+		SrcVersionID:   c.input.SrcVersionID,
+		CheckpointFile: &c.input.CheckpointFile,
+	}
+}
+
+func (c *copyPostEvent) newFailedEvent(err error, eventType enum.CopyEventType) *CopyEvent {
+	return c.newCopyEvent(eventType, err)
+}
+
+func (c *copyPostEvent) newUploadPartCopySucceedEvent(part copyPartInfo) *CopyEvent {
+	event := c.newCopyEvent(enum.CopyEventUploadPartCopySucceed, nil)
+	event.CopyPartInfo = &CopyPartInfo{
+		PartNumber:           part.PartNumber,
+		CopySourceRangeStart: part.CopySourceRangeStart,
+		CopySourceRangeEnd:   part.CopySourceRangeEnd,
+		ETag:                 &part.ETag,
+	}
+	return event
+}
+
+// ResumableCopyObject copy an object by CopyObject if it is not larger than PartSize,
+// or by multipart copy with UploadPartCopyV2 in parallel.
+// If EnableCheckpoint is true, copied parts are recorded in checkpoint file and skipped on next call,
+// the recorded upload is discarded and aborted if the source object has been changed.
+func (cli *ClientV2) ResumableCopyObject(ctx context.Context, input *ResumableCopyObjectInput) (*ResumableCopyObjectOutput, error) {
+	// avoid modifying on origin pointer
+	copied := *input
+	input = &copied
+
+	if err := validateCopyInput(input); err != nil {
+		return nil, err
+	}
+	head, err := cli.HeadObjectV2(ctx, &HeadObjectV2Input{
+		Bucket:        input.SrcBucket,
+		Key:           input.SrcKey,
+		VersionID:     input.SrcVersionID,
+		SSECAlgorithm: input.CopySourceSSECAlgorithm,
+		SSECKey:       input.CopySourceSSECKey,
+		SSECKeyMD5:    input.CopySourceSSECKeyMD5,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if input.PartSize, _, err = CalcPartSize(head.ContentLength, input.PartSize); err != nil {
+		return nil, err
+	}
+	fillCopyMetadata(input, head)
+	if head.ContentLength <= input.PartSize {
+		return cli.copyObject(ctx, input, head)
+	}
+
+	abort := func(uploadID string) error {
+		_, err := cli.AbortMultipartUpload(ctx, &AbortMultipartUploadInput{
+			Bucket:   input.Bucket,
+			Key:      input.Key,
+			UploadID: uploadID,
+		})
+		return err
+	}
+	init := func() (*copyCheckpoint, error) {
+		return initCopyCheckpoint(input, head)
+	}
+	valid := func(checkpoint *copyCheckpoint) bool {
+		if checkpoint.Valid(input, head) {
+			return true
+		}
+		// the recorded upload can never be completed, abort it to release copied parts
+		if checkpoint.UploadID != "" && checkpoint.Bucket == input.Bucket && checkpoint.Key == input.Key {
+			_ = abort(checkpoint.UploadID)
+		}
+		return false
+	}
+	store := getCheckpointStore(input.CheckpointStore)
+	key := CheckpointKey{Operation: checkpointCopy, Bucket: input.Bucket, Key: input.Key, CheckpointFile: input.CheckpointFile}
+	checkpoint, err := getCopyCheckpoint(store, input.EnableCheckpoint, key, init, valid)
+	if err != nil {
+		return nil, err
+	}
+	event := &copyPostEvent{
+		input:      input,
+		checkPoint: checkpoint,
+	}
+	if checkpoint.UploadID == "" {
+		created, err := cli.CreateMultipartUploadV2(ctx, &input.CreateMultipartUploadV2Input)
+		if err != nil {
+			event.postCopyEvent(event.newFailedEvent(err, enum.CopyEventCreateMultipartUploadFailed))
+			return nil, err
+		}
+		checkpoint.UploadID = created.UploadID
+		if input.EnableCheckpoint {
+			if err = checkpoint.Save(); err != nil {
+				_ = abort(checkpoint.UploadID)
+				return nil, err
+			}
+		}
+		event.postCopyEvent(event.newCopyEvent(enum.CopyEventCreateMultipartUploadSucceed, nil))
+	}
+
+	cleaner := func() {
+		checkpoint.Delete()
+	}
+	bindCancelHookWithCleaner(input.CancelHook, cleaner)
+	output, err := cli.copyPart(ctx, checkpoint, input, head, event, abort)
+	if canceled, isAbort := isCanceled(input.CancelHook); canceled && isAbort {
+		// parts finished after canceling may have rewritten the checkpoint
+		cleaner()
+	}
+	return output, err
+}
+
+// copyObject copy the whole object by CopyObject
+func (cli *ClientV2) copyObject(ctx context.Context, input *ResumableCopyObjectInput, head *HeadObjectV2Output) (*ResumableCopyObjectOutput, error) {
+	output, err := cli.CopyObject(ctx, &CopyObjectInput{
+		Bucket:                    input.Bucket,
+		Key:                       input.Key,
+		SrcBucket:                 input.SrcBucket,
+		SrcKey:                    input.SrcKey,
+		SrcVersionID:              input.SrcVersionID,
+		CacheControl:              input.CacheControl,
+		ContentDisposition:        input.ContentDisposition,
+		ContentEncoding:           input.ContentEncoding,
+		ContentLanguage:           input.ContentLanguage,
+		ContentType:               input.ContentType,
+		Expires:                   input.Expires,
+		ACL:                       input.ACL,
+		GrantFullControl:          input.GrantFullControl,
+		GrantRead:                 input.GrantRead,
+		GrantReadAcp:              input.GrantReadAcp,
+		GrantWriteAcp:             input.GrantWriteAcp,
+		WebsiteRedirectLocation:   input.WebsiteRedirectLocation,
+		StorageClass:              input.StorageClass,
+		CopySourceIfMatch:         head.ETag,
+		CopySourceSSECAlgorithm:   input.CopySourceSSECAlgorithm,
+		CopySourceSSECKey:         input.CopySourceSSECKey,
+		CopySourceSSECKeyMD5:      input.CopySourceSSECKeyMD5,
+		SSECAlgorithm:             input.SSECAlgorithm,
+		SSECKey:                   input.SSECKey,
+		SSECKeyMD5:                input.SSECKeyMD5,
+		ServerSideEncryption:      input.ServerSideEncryption,
+		ServerSideEncryptionKeyID: input.ServerSideEncryptionKeyID,
+		MetadataDirective:         enum.MetadataDirectiveReplace,
+		Meta:                      input.Meta,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ResumableCopyObjectOutput{
+		RequestInfo:   output.RequestInfo,
+		Bucket:        input.Bucket,
+		Key:           input.Key,
+		ETag:          output.ETag,
+		VersionID:     output.VersionID,
+		HashCrc64ecma: head.HashCrc64ecma,
+		SSECAlgorithm: input.SSECAlgorithm,
+		SSECKeyMD5:    input.SSECKeyMD5,
+		EncodingType:  input.ContentEncoding,
+	}, nil
+}
+
+func prepareCopyTasks(cli *ClientV2, ctx context.Context, checkpoint *copyCheckpoint, input *ResumableCopyObjectInput) []task {
+	tasks := make([]task, 0)
+	for _, part := range checkpoint.PartsInfo {
+		if !part.IsCompleted {
+			tasks = append(tasks, &copyTask{
+				cli:        cli,
+				ctx:        ctx,
+				input:      input,
+				UploadID:   checkpoint.UploadID,
+				etag:       checkpoint.CopySourceObjectInfo.Etag,
+				PartNumber: part.PartNumber,
+				RangeStart: part.CopySourceRangeStart,
+				RangeEnd:   part.CopySourceRangeEnd,
+			})
+		}
+	}
+	return tasks
+}
+
+func (cli *ClientV2) copyPart(ctx context.Context, checkpoint *copyCheckpoint, input *ResumableCopyObjectInput,
+	head *HeadObjectV2Output, event *copyPostEvent, abort func(uploadID string) error) (*ResumableCopyObjectOutput, error) {
+	cancelHandle := getCancelHandle(input.CancelHook)
+	taskCtx, cancel := contextWithCancelHandle(ctx, cancelHandle)
+	defer cancel()
+	tasks := prepareCopyTasks(cli, taskCtx, checkpoint, input)
+	routinesNum := min(input.TaskNum, len(tasks))
+	saver := newCheckpointSaver(input.EnableCheckpoint, input.CheckpointSaveInterval, input.CheckpointSavePartCount)
+	tg := newTaskGroup(taskCtx, cancelHandle, routinesNum, checkpoint, event, saver, input.TransferManager, tasks)
+	bindCancelHookWithAborter(input.CancelHook, func() error {
+		return abort(checkpoint.UploadID)
+	})
+
+	tg.RunWorker()
+	tg.Scheduler()
+	success, taskErr := tg.Wait()
+	if canceled, _ := isCanceled(input.CancelHook); canceled {
+		// the upload is aborted by CancelHook if needed, otherwise it is kept to be resumed
+		return nil, CanceledClientError
+	}
+	if taskErr != nil {
+		// source object changed or destination is not accessible, the upload can never be completed
+		if err := abort(checkpoint.UploadID); err != nil {
+			return nil, err
+		}
+		return nil, taskErr
+	}
+	if success < len(tasks) {
+		if !input.EnableCheckpoint {
+			_ = abort(checkpoint.UploadID)
+		}
+		return nil, newTosClientError("tos: some upload part copy tasks failed.", nil)
+	}
+	complete, err := cli.CompleteMultipartUploadV2(ctx, &CompleteMultipartUploadV2Input{
+		Bucket:   input.Bucket,
+		Key:      input.Key,
+		UploadID: checkpoint.UploadID,
+		Parts:    checkpoint.GetParts(),
+	})
+	if err != nil {
+		event.postCopyEvent(event.newFailedEvent(err, enum.CopyEventCompleteMultipartUploadFailed))
+		return nil, err
+	}
+	event.postCopyEvent(event.newCopyEvent(enum.CopyEventCompleteMultipartUploadSucceed, nil))
+
+	if cli.enableCRC && complete.HashCrc64ecma != 0 && head.HashCrc64ecma != 0 && complete.HashCrc64ecma != head.HashCrc64ecma {
+		return nil, newChecksumMismatchError(complete.RequestID, head.HashCrc64ecma, complete.HashCrc64ecma)
+	}
+	checkpoint.Delete()
+
+	return &ResumableCopyObjectOutput{
+		RequestInfo:   complete.RequestInfo,
+		Bucket:        complete.Bucket,
+		Key:           complete.Key,
+		UploadID:      checkpoint.UploadID,
+		ETag:          complete.ETag,
+		Location:      complete.Location,
+		VersionID:     complete.VersionID,
+		HashCrc64ecma: complete.HashCrc64ecma,
+		SSECAlgorithm: checkpoint.SSECAlgorithm,
+		SSECKeyMD5:    checkpoint.SSECKeyMD5,
+		EncodingType:  checkpoint.EncodingType,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitCopyPartsInfo(t *testing.T) {
+	parts, err := initCopyPartsInfo(2*MinPartSize+1, MinPartSize)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(parts))
+	require.Equal(t, int64(MinPartSize-1), parts[0].CopySourceRangeEnd)
+	require.Equal(t, int64(2*MinPartSize), parts[2].CopySourceRangeStart)
+	require.Equal(t, int64(2*MinPartSize), parts[2].CopySourceRangeEnd)
+
+	_, err = initCopyPartsInfo(10001*MinPartSize, MinPartSize)
+	require.NotNil(t, err)
+}
+
+func TestResumableCopyObjectSourceChanged(t *testing.T) {
+	head := newMockResponse(http.StatusOK, "")
+	transport := &mockTransport{responses: []func() *Response{
+		func() *Response {
+			res := head()
+			res.Header.Set(HeaderETag, `"etag"`)
+			res.Header.Set(HeaderContentLength, strconv.Itoa(2*MinPartSize+1))
+			return res
+		},
+		newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"key","UploadId":"upload-id"}`),
+		newMockResponse(http.StatusPreconditionFailed, `{"Code":"PreconditionFailed","Message":"precondition failed"}`),
+		newMockResponse(http.StatusNoContent, ""),
+	}}
+	client := newMockClient(t, transport)
+	_, err := client.ResumableCopyObject(context.Background(), &ResumableCopyObjectInput{
+		CreateMultipartUploadV2Input: CreateMultipartUploadV2Input{Bucket: "bucket", Key: "key"},
+		SrcBucket:                    "src-bucket",
+		SrcKey:                       "src-key",
+	})
+	require.NotNil(t, err)
+	requests := transport.recorded()
+	copyReq := requests[2]
+	require.Equal(t, `"etag"`, copyReq.Header.Get(HeaderCopySourceIfMatch))
+	require.Equal(t, "bytes=0-5242879", copyReq.Header.Get(HeaderCopySourceRange))
+	// the worker may have picked up the next part before the copy is aborted
+	var abortReq *Request
+	for _, req := range requests[3:] {
+		if req.Method == http.MethodDelete {
+			abortReq = req
+		}
+	}
+	require.NotNil(t, abortReq)
+	require.Equal(t, "upload-id", abortReq.Query.Get("uploadId"))
+}
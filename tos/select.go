@@ -0,0 +1,194 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"net/http"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+// Response of SelectObjectContentV2 is a stream of frames:
+//
+//	| version (1) | frame type (3) | payload length (4) | header checksum (4) | payload | payload checksum (4) |
+//
+// integers are big endian, checksums are CRC32 (IEEE) of the first 8 bytes and of the payload.
+// Payload of each frame type starts with the offset (8) of the object scanned, followed by
+//
+//	Records:  records selected
+//	Progress: bytes scanned (8) | bytes processed (8) | bytes returned (8)
+//	Stats:    bytes scanned (8) | bytes processed (8) | bytes returned (8)
+//	End:      nothing
+//	Error:    status code (4) | error in JSON, e.g. {"Code":"InvalidSQL","Message":"..."}
+const (
+	selectFrameVersion       = 1
+	selectFrameHeaderLength  = 12
+	selectFrameOffsetLength  = 8
+	selectFrameChecksumSize  = 4
+	selectFrameMaxPayloadLen = 16 << 20
+
+	selectFrameRecords  = 0x800001
+	selectFrameProgress = 0x800004
+	selectFrameStats    = 0x800005
+	selectFrameEnd      = 0x800006
+	selectFrameError    = 0x800007
+)
+
+// selectEventReader decodes frames of SelectObjectContentV2 response, returns records by Read
+// and sends other events to listener
+type selectEventReader struct {
+	base      io.ReadCloser
+	listener  SelectEventListener
+	requestID string
+	records   []byte
+	header    [selectFrameHeaderLength]byte
+	err       error
+}
+
+func newSelectFrameError(msg string, requestID string) error {
+	return newTosClientError("tos: malformed select frame, "+msg+", request id: "+requestID, nil)
+}
+
+func (r *selectEventReader) Read(p []byte) (int, error) {
+	for len(r.records) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.readFrame()
+	}
+	n := copy(p, r.records)
+	r.records = r.records[n:]
+	return n, nil
+}
+
+// readFrame reads the next frame, returns io.EOF after End frame
+func (r *selectEventReader) readFrame() error {
+	if _, err := io.ReadFull(r.base, r.header[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// the stream must be finished by End or Error frame
+			return newSelectFrameError("unexpected end of stream", r.requestID)
+		}
+		return err
+	}
+	if r.header[0] != selectFrameVersion {
+		return newSelectFrameError("unsupported version", r.requestID)
+	}
+	frameType := binary.BigEndian.Uint32(r.header[0:4]) & 0xffffff
+	length := binary.BigEndian.Uint32(r.header[4:8])
+	if crc32.ChecksumIEEE(r.header[:8]) != binary.BigEndian.Uint32(r.header[8:12]) {
+		return newSelectFrameError("header checksum mismatch", r.requestID)
+	}
+	if length < selectFrameOffsetLength || length > selectFrameMaxPayloadLen {
+		return newSelectFrameError("invalid payload length", r.requestID)
+	}
+	payload := make([]byte, length+selectFrameChecksumSize)
+	if _, err := io.ReadFull(r.base, payload); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return newSelectFrameError("unexpected end of stream", r.requestID)
+		}
+		return err
+	}
+	checksum := binary.BigEndian.Uint32(payload[length:])
+	payload = payload[:length]
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return newSelectFrameError("payload checksum mismatch", r.requestID)
+	}
+	payload = payload[selectFrameOffsetLength:]
+
+	switch frameType {
+	case selectFrameRecords:
+		r.records = payload
+	case selectFrameProgress, selectFrameStats:
+		if len(payload) < 24 {
+			return newSelectFrameError("invalid progress payload", r.requestID)
+		}
+		event := &SelectEvent{Type: enum.SelectEventProgress, Progress: &SelectProgress{
+			BytesScanned:   int64(binary.BigEndian.Uint64(payload[0:8])),
+			BytesProcessed: int64(binary.BigEndian.Uint64(payload[8:16])),
+			BytesReturned:  int64(binary.BigEndian.Uint64(payload[16:24])),
+		}}
+		if frameType == selectFrameStats {
+			event.Type = enum.SelectEventStats
+		}
+		r.postEvent(event)
+	case selectFrameEnd:
+		r.postEvent(&SelectEvent{Type: enum.SelectEventEnd})
+		return io.EOF
+	case selectFrameError:
+		if len(payload) < 4 {
+			return newSelectFrameError("invalid error payload", r.requestID)
+		}
+		se := Error{StatusCode: int(binary.BigEndian.Uint32(payload[:4]))}
+		if err := json.Unmarshal(payload[4:], &se); err != nil {
+			return newSelectFrameError("invalid error payload", r.requestID)
+		}
+		return &TosServerError{
+			TosError:    TosError{se.Message},
+			RequestInfo: RequestInfo{RequestID: r.requestID, StatusCode: se.StatusCode},
+			Code:        se.Code,
+		}
+	default:
+		// unknown frames are skipped for compatibility
+	}
+	return nil
+}
+
+func (r *selectEventReader) postEvent(event *SelectEvent) {
+	if r.listener != nil {
+		r.listener.EventChange(event)
+	}
+}
+
+func (r *selectEventReader) Close() error {
+	return r.base.Close()
+}
+
+// SelectObjectContentV2 runs SQL Expression over CSV or JSON object on server, and returns records selected.
+// Content of output must be closed after reading.
+func (cli *ClientV2) SelectObjectContentV2(ctx context.Context, input *SelectObjectContentV2Input) (*SelectObjectContentV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	if len(input.Expression) == 0 {
+		return nil, newTosClientError("tos: empty select expression", nil)
+	}
+	if (input.InputSerialization.CSV == nil) == (input.InputSerialization.JSON == nil) {
+		return nil, newTosClientError("tos: one of CSV and JSON of InputSerialization must be set", nil)
+	}
+	if (input.OutputSerialization.CSV == nil) == (input.OutputSerialization.JSON == nil) {
+		return nil, newTosClientError("tos: one of CSV and JSON of OutputSerialization must be set", nil)
+	}
+	body := *input
+	if len(body.ExpressionType) == 0 {
+		body.ExpressionType = "SQL"
+	}
+	data, contentMD5, err := marshalInput("SelectObjectContentV2Input", &body)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("select", "").
+		WithQuery("select-type", "2").
+		WithParams(*input).
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPost, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	info := res.RequestInfo()
+	return &SelectObjectContentV2Output{
+		RequestInfo: info,
+		Content: &selectEventReader{
+			base:      res.Body,
+			listener:  input.SelectEventListener,
+			requestID: info.RequestID,
+		},
+	}, nil
+}
@@ -0,0 +1,142 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+func selectFrame(frameType uint32, payload []byte) []byte {
+	var buf bytes.Buffer
+	header := make([]byte, selectFrameHeaderLength)
+	binary.BigEndian.PutUint32(header[0:4], selectFrameVersion<<24|frameType)
+	binary.BigEndian.PutUint32(header[4:8], uint32(selectFrameOffsetLength+len(payload)))
+	binary.BigEndian.PutUint32(header[8:12], crc32.ChecksumIEEE(header[:8]))
+	buf.Write(header)
+	body := append(make([]byte, selectFrameOffsetLength), payload...)
+	buf.Write(body)
+	checksum := make([]byte, selectFrameChecksumSize)
+	binary.BigEndian.PutUint32(checksum, crc32.ChecksumIEEE(body))
+	buf.Write(checksum)
+	return buf.Bytes()
+}
+
+func selectProgressPayload(scanned, processed, returned int64) []byte {
+	payload := make([]byte, 24)
+	binary.BigEndian.PutUint64(payload[0:8], uint64(scanned))
+	binary.BigEndian.PutUint64(payload[8:16], uint64(processed))
+	binary.BigEndian.PutUint64(payload[16:24], uint64(returned))
+	return payload
+}
+
+type selectListenerTest struct {
+	events []*SelectEvent
+}
+
+func (l *selectListenerTest) EventChange(event *SelectEvent) {
+	l.events = append(l.events, event)
+}
+
+func TestSelectObjectContentV2(t *testing.T) {
+	var stream []byte
+	var body []byte
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		body, _ = ioutil.ReadAll(req.Content)
+		res := newMockResponse(http.StatusOK, "")()
+		res.Body = ioutil.NopCloser(bytes.NewReader(stream))
+		return res
+	}}
+	client := newMockClient(t, transport)
+	input := &SelectObjectContentV2Input{
+		Bucket:     "bucket",
+		Key:        "logs.csv",
+		Expression: "select _1, _3 from ossobject",
+		InputSerialization: SelectInputSerialization{
+			CompressionType: enum.CompressionGzip,
+			CSV:             &CSVInput{FileHeaderInfo: enum.FileHeaderInfoIgnore, FieldDelimiter: ","},
+		},
+		OutputSerialization: SelectOutputSerialization{JSON: &JSONOutput{RecordDelimiter: "\n"}},
+	}
+
+	stream = bytes.Join([][]byte{
+		selectFrame(selectFrameRecords, []byte("a,1\n")),
+		selectFrame(selectFrameProgress, selectProgressPayload(10, 10, 4)),
+		selectFrame(0x800099, []byte("unknown")),
+		selectFrame(selectFrameRecords, []byte("b,2\n")),
+		selectFrame(selectFrameStats, selectProgressPayload(20, 20, 8)),
+		selectFrame(selectFrameEnd, nil),
+	}, nil)
+	listener := &selectListenerTest{}
+	input.SelectEventListener = listener
+	output, err := client.SelectObjectContentV2(context.Background(), input)
+	require.Nil(t, err)
+	records, err := ioutil.ReadAll(output.Content)
+	require.Nil(t, err)
+	require.Nil(t, output.Content.Close())
+	require.Equal(t, "a,1\nb,2\n", string(records))
+	require.Len(t, listener.events, 3)
+	require.Equal(t, enum.SelectEventProgress, listener.events[0].Type)
+	require.Equal(t, int64(4), listener.events[0].Progress.BytesReturned)
+	require.Equal(t, enum.SelectEventStats, listener.events[1].Type)
+	require.Equal(t, int64(20), listener.events[1].Progress.BytesScanned)
+	require.Equal(t, enum.SelectEventEnd, listener.events[2].Type)
+
+	req := transport.recorded()[0]
+	require.Equal(t, http.MethodPost, req.Method)
+	require.Contains(t, req.Query, "select")
+	require.Equal(t, "2", req.Query.Get("select-type"))
+	var sent map[string]interface{}
+	require.Nil(t, json.Unmarshal(body, &sent))
+	require.Equal(t, "SQL", sent["ExpressionType"])
+	require.Equal(t, "GZIP", sent["InputSerialization"].(map[string]interface{})["CompressionType"])
+	require.NotContains(t, sent, "Bucket")
+
+	// server error in the middle of stream
+	errorPayload := append([]byte{0, 0, 0x01, 0x90}, []byte(`{"Code":"InvalidSQL","Message":"invalid sql"}`)...)
+	stream = bytes.Join([][]byte{
+		selectFrame(selectFrameRecords, []byte("a,1\n")),
+		selectFrame(selectFrameError, errorPayload),
+	}, nil)
+	input.SelectEventListener = nil
+	output, err = client.SelectObjectContentV2(context.Background(), input)
+	require.Nil(t, err)
+	records, err = ioutil.ReadAll(output.Content)
+	require.Equal(t, "a,1\n", string(records))
+	require.Equal(t, "InvalidSQL", Code(err))
+	require.Equal(t, http.StatusBadRequest, StatusCode(err))
+
+	corrupted := selectFrame(selectFrameRecords, []byte("a,1\n"))
+	corrupted[selectFrameHeaderLength+selectFrameOffsetLength] = 'b'
+	badVersion := selectFrame(selectFrameEnd, nil)
+	badVersion[0] = 2
+	for _, s := range [][]byte{
+		selectFrame(selectFrameRecords, []byte("a,1\n")), // truncated without End frame
+		selectFrame(selectFrameRecords, []byte("a,1\n"))[:10],
+		corrupted,
+		badVersion,
+	} {
+		stream = s
+		output, err = client.SelectObjectContentV2(context.Background(), input)
+		require.Nil(t, err)
+		_, err = ioutil.ReadAll(output.Content)
+		_, ok := err.(*TosClientError)
+		require.True(t, ok)
+	}
+
+	for _, invalid := range []*SelectObjectContentV2Input{
+		{Bucket: "bucket", Key: "key", InputSerialization: input.InputSerialization, OutputSerialization: input.OutputSerialization},
+		{Bucket: "bucket", Key: "key", Expression: "select", OutputSerialization: input.OutputSerialization},
+		{Bucket: "bucket", Key: "key", Expression: "select", InputSerialization: input.InputSerialization},
+	} {
+		_, err = client.SelectObjectContentV2(context.Background(), invalid)
+		require.NotNil(t, err)
+	}
+}
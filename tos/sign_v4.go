@@ -11,6 +11,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -33,8 +35,17 @@ const (
 	v4SignatureLower = "x-tos-signature"
 	v4ContentSHA256  = "X-Tos-Content-Sha256"
 	v4SecurityToken  = "X-Tos-Security-Token"
+	v4Policy         = "X-Tos-Policy"
 
 	v4Prefix = "x-tos"
+
+	// streamingUnsignedPayloadTrailer is the payload hash of chunked body with a trailing checksum
+	streamingUnsignedPayloadTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	// streamingSignedPayloadTrailer is the payload hash of chunked body with a trailing checksum,
+	// in which each chunk and the trailer are signed with the signature of the previous one
+	streamingSignedPayloadTrailer = "STREAMING-TOS4-HMAC-SHA256-PAYLOAD-TRAILER"
+	streamingPayloadPrefix        = "TOS4-HMAC-SHA256-PAYLOAD"
+	streamingTrailerPrefix        = "TOS4-HMAC-SHA256-TRAILER"
 )
 
 func defaultSigningQueryV4(key string) bool {
@@ -49,12 +60,22 @@ func UTCNow() time.Time {
 	return time.Now().UTC()
 }
 
+// Signer signs requests, it's set by WithSigner or WithPerRequestSigner to replace the default SignV4,
+// and a custom Signer can delegate to SignV4 created by NewSignV4.
 type Signer interface {
+	// SignHeader is called after all headers and queries of req are set, and again before each retry,
+	// the returned headers are set to req, e.g. Authorization.
 	SignHeader(req *Request) http.Header
 
+	// SignQuery is called by PreSignedURL, the returned queries are added to the URL
 	SignQuery(req *Request, ttl time.Duration) url.Values
 }
 
+// PolicySigner is implemented by Signer supporting PreSignedPolicyURL, e.g. SignV4
+type PolicySigner interface {
+	SignPolicyQuery(policy string, ttl time.Duration) url.Values
+}
+
 type SigningKeyInfo struct {
 	Date       string
 	Region     string
@@ -68,13 +89,41 @@ type SignV4 struct {
 	signingQuery  func(key string) bool
 	now           func() time.Time
 	signingKey    func(*SigningKeyInfo) []byte
+	keyCache      *signingKeyCache // nullable, caches keys of the default signingKey only
 	logger        logrus.FieldLogger
 }
 
+// cachedSigningKey signing key derived from secret-key of the date and region
+type cachedSigningKey struct {
+	secret string
+	date   string
+	region string
+	key    []byte
+}
+
+// signingKeyCache caches the last derived signing key, it's replaced if the date changes
+// or the credentials return a new secret-key.
+type signingKeyCache struct {
+	cached atomic.Value // *cachedSigningKey
+}
+
+func (kc *signingKeyCache) get(info *SigningKeyInfo) []byte {
+	secret := info.Credential.AccessKeySecret
+	if cached, ok := kc.cached.Load().(*cachedSigningKey); ok &&
+		cached.secret == secret && cached.date == info.Date && cached.region == info.Region {
+		return cached.key
+	}
+	key := SigningKey(info)
+	kc.cached.Store(&cachedSigningKey{secret: secret, date: info.Date, region: info.Region, key: key})
+	return key
+}
+
 type signedRes struct {
 	CanonicalString string
 	StringToSign    string
 	Sign            string
+	key             []byte // signing key
+	scope           string // date/region/tos/request
 }
 
 type signedHeader struct {
@@ -106,6 +155,7 @@ func NewSignV4(credentials Credentials, region string) *SignV4 {
 		signingQuery:  defaultSigningQueryV4,
 		now:           UTCNow,
 		signingKey:    SigningKey,
+		keyCache:      &signingKeyCache{},
 	}
 	return signV4
 }
@@ -113,13 +163,52 @@ func NewSignV4(credentials Credentials, region string) *SignV4 {
 // WithSigningKey for self-defined sign-key generator
 func (sv *SignV4) WithSigningKey(signingKey func(*SigningKeyInfo) []byte) {
 	sv.signingKey = signingKey
+	sv.keyCache = nil
+}
+
+// deriveSigningKey returns the cached signing key if it's derived by the default signingKey
+func (sv *SignV4) deriveSigningKey(info *SigningKeyInfo) []byte {
+	if sv.keyCache != nil {
+		return sv.keyCache.get(info)
+	}
+	return sv.signingKey(info)
 }
 
-func (sv *SignV4) signedHeader(header http.Header, isSignedQuery bool) KVs {
+// SignHeaderRule changes headers signed by SignV4, header names are case-insensitive. By default Content-Type
+// and X-Tos-* headers are signed for requests, and X-Tos-* headers are signed for pre-signed URLs.
+type SignHeaderRule struct {
+	Included []string // signed in addition to the default headers, e.g. custom X- headers
+	Excluded []string // not signed, e.g. headers changed by CDN or proxies in transit, it takes precedence over Included
+}
+
+// merge returns the rule with headers of both rules
+func (r SignHeaderRule) merge(other SignHeaderRule) SignHeaderRule {
+	if len(other.Included) == 0 && len(other.Excluded) == 0 {
+		return r
+	}
+	return SignHeaderRule{
+		Included: append(append([]string{}, r.Included...), other.Included...),
+		Excluded: append(append([]string{}, r.Excluded...), other.Excluded...),
+	}
+}
+
+func containsHeader(headers []string, key string) bool {
+	for _, header := range headers {
+		if strings.EqualFold(header, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (sv *SignV4) signedHeader(header http.Header, isSignedQuery bool, rule *SignHeaderRule) KVs {
 	var signed = make(KVs, 0, 10)
 	for key, values := range header {
 		kk := strings.ToLower(key)
-		if sv.signingHeader(kk, isSignedQuery) {
+		if containsHeader(rule.Excluded, kk) {
+			continue
+		}
+		if sv.signingHeader(kk, isSignedQuery) || containsHeader(rule.Included, kk) {
 			vv := make([]string, 0, len(values))
 			for _, value := range values {
 				vv = append(vv, strings.Join(strings.Fields(value), " "))
@@ -207,22 +296,21 @@ func (sv *SignV4) doSign(method, path, contentSha256 string, header, query KVs,
 	buf.WriteByte(split)
 
 	date := now.Format(yyMMdd)
-	buf.WriteString(date) // yyMMdd + '/' + region + '/' + service + '/' + request
-	buf.WriteByte('/')
-	buf.WriteString(sv.region)
-
-	buf.WriteString("/tos/request")
+	scope := date + "/" + sv.region + "/tos/request" // yyMMdd + '/' + region + '/' + service + '/' + request
+	buf.WriteString(scope)
 	buf.WriteByte(split)
 
 	sum := sha256.Sum256([]byte(canonicalStr))
 	buf.WriteString(hex.EncodeToString(sum[:]))
 
-	signK := sv.signingKey(&SigningKeyInfo{Date: date, Region: sv.region, Credential: cred})
+	signK := sv.deriveSigningKey(&SigningKeyInfo{Date: date, Region: sv.region, Credential: cred})
 	sign := hmacSHA256(signK, buf.Bytes())
 	return signedRes{
 		CanonicalString: canonicalStr,
 		StringToSign:    buf.String(),
 		Sign:            hex.EncodeToString(sign),
+		key:             signK,
+		scope:           scope,
 	}
 
 }
@@ -233,7 +321,7 @@ func (sv *SignV4) SignHeader(req *Request) http.Header {
 	date := now.Format(iso8601Layout)
 	contentSha256 := req.Header.Get(v4ContentSHA256)
 
-	signedHeader := sv.signedHeader(req.Header, false)
+	signedHeader := sv.signedHeader(req.Header, false, &req.SignHeaderRule)
 	signedHeader = append(signedHeader, KV{Key: strings.ToLower(v4Date), Values: []string{date}})
 	signedHeader = append(signedHeader, KV{Key: "date", Values: []string{date}})
 	signedHeader = append(signedHeader, KV{Key: "host", Values: []string{req.Host}})
@@ -258,6 +346,10 @@ func (sv *SignV4) SignHeader(req *Request) http.Header {
 	signed.Set(authorization, auth)
 	signed.Set(v4Date, date)
 	signed.Set("Date", date)
+	if req.chunkSigner != nil {
+		// chunks of the streaming payload are chained to the signature of this attempt
+		req.chunkSigner.reset(signRes.key, date, signRes.scope, signRes.Sign)
+	}
 	if sv.logger != nil {
 		sv.logger.Debug("[tos] CanonicalString:" + "\n" + signRes.CanonicalString + "\n")
 		sv.logger.Debug("[tos] StringToSign:" + "\n" + signRes.StringToSign + "\n")
@@ -282,7 +374,7 @@ func (sv *SignV4) SignQuery(req *Request, ttl time.Duration) url.Values {
 		extra.Add(v4SecurityToken, sts)
 	}
 
-	signedHeader := sv.signedHeader(req.Header, true)
+	signedHeader := sv.signedHeader(req.Header, true, &req.SignHeaderRule)
 	signedHeader = append(signedHeader, KV{Key: "host", Values: []string{req.Host}})
 	sort.Sort(signedHeader)
 
@@ -298,6 +390,73 @@ func (sv *SignV4) SignQuery(req *Request, ttl time.Duration) url.Values {
 	return extra
 }
 
+// chunkSigner signs chunks and the trailer of streaming payload, each signature is chained to the previous one,
+// starting from the signature of the request. It's reset by SignV4 each time the request is signed.
+type chunkSigner struct {
+	lock      sync.Mutex
+	key       []byte
+	timestamp string
+	scope     string
+	seed      string
+}
+
+func (cs *chunkSigner) reset(key []byte, timestamp, scope, seed string) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	cs.key, cs.timestamp, cs.scope, cs.seed = key, timestamp, scope, seed
+}
+
+// seedSignature returns the signature of the request, which is the previous signature of the first chunk
+func (cs *chunkSigner) seedSignature() string {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	return cs.seed
+}
+
+// signChunk returns the signature of a chunk, data is empty for the last chunk
+func (cs *chunkSigner) signChunk(previous string, data []byte) string {
+	sum := sha256.Sum256(data)
+	return cs.sign(streamingPayloadPrefix, previous, emptySHA256+"\n"+hex.EncodeToString(sum[:]))
+}
+
+// signTrailer returns the signature of trailing headers, e.g. "x-tos-hash-crc64ecma:123\n"
+func (cs *chunkSigner) signTrailer(previous string, trailer string) string {
+	sum := sha256.Sum256([]byte(trailer))
+	return cs.sign(streamingTrailerPrefix, previous, hex.EncodeToString(sum[:]))
+}
+
+func (cs *chunkSigner) sign(prefix, previous, hash string) string {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	stringToSign := prefix + "\n" + cs.timestamp + "\n" + cs.scope + "\n" + previous + "\n" + hash
+	return hex.EncodeToString(hmacSHA256(cs.key, []byte(stringToSign)))
+}
+
+// SignPolicyQuery signs base64 encoded policy of PreSignedPolicyURL, returns query of signature
+func (sv *SignV4) SignPolicyQuery(policy string, ttl time.Duration) url.Values {
+	now := sv.now()
+	cred := sv.credentials.Credential()
+	extra := make(url.Values)
+	extra.Add(v4Algorithm, signPrefix)
+	extra.Add(v4Credential, fmt.Sprintf("%s/%s/%s/tos/request", cred.AccessKeyID, now.Format(yyMMdd), sv.region))
+	extra.Add(v4Date, now.Format(iso8601Layout))
+	extra.Add(v4Expires, strconv.FormatInt(ttl.Milliseconds()/1000, 10))
+	extra.Add(v4Policy, policy)
+	if sts := cred.SecurityToken; len(sts) > 0 {
+		extra.Add(v4SecurityToken, sts)
+	}
+	// neither method, path nor headers are signed, the bucket and keys are bound by the policy
+	signRes := sv.doSign("GET", "", unsignedPayload, nil, sv.signedQuery(extra, nil), now, &cred)
+	extra.Add(v4Signature, signRes.Sign)
+	return extra
+}
+
+// SignPostPolicy signs base64 encoded POST policy with the signing key of date, returns hex encoded signature
+func (sv *SignV4) SignPostPolicy(policy string, date time.Time, cred *Credential) string {
+	signK := sv.deriveSigningKey(&SigningKeyInfo{Date: date.Format(yyMMdd), Region: sv.region, Credential: cred})
+	return hex.EncodeToString(hmacSHA256(signK, []byte(policy)))
+}
+
 type KV struct {
 	Key    string
 	Values []string
@@ -0,0 +1,258 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// StreamUploader uploads data of unknown length by multipart upload, it implements io.WriteCloser.
+// Written data is buffered and uploaded as a part once PartSize bytes are buffered,
+// at most TaskNum parts are uploaded in parallel, so memory used is bounded by (TaskNum + 1) * PartSize.
+// Close must be called to complete the multipart upload, and the upload is aborted if any part failed.
+// StreamUploader is not safe for concurrent Write.
+type StreamUploader struct {
+	cli         *ClientV2
+	ctx         context.Context
+	input       CreateMultipartUploadV2Input
+	partSize    int64
+	taskNum     int
+	uploadID    string
+	partNumber  int
+	buf         []byte
+	buffers     chan []byte
+	inflight    chan struct{}
+	wg          sync.WaitGroup
+	lock        sync.Mutex
+	parts       []UploadedPartV2
+	err         error
+	closed      bool
+	output      *CompleteMultipartUploadV2Output
+	completeErr error
+}
+
+type StreamUploaderOption func(*StreamUploader)
+
+// WithStreamUploaderContext set context used by requests of StreamUploader, context.Background() by default
+func WithStreamUploaderContext(ctx context.Context) StreamUploaderOption {
+	return func(s *StreamUploader) {
+		s.ctx = ctx
+	}
+}
+
+// WithStreamUploaderTaskNum set max number of parts uploaded in parallel, 1 by default
+func WithStreamUploaderTaskNum(taskNum int) StreamUploaderOption {
+	return func(s *StreamUploader) {
+		s.taskNum = taskNum
+	}
+}
+
+// WithStreamUploaderCreateInput set input of CreateMultipartUploadV2, e.g. metadata, ACL and SSE-C headers.
+// Bucket and Key of input are ignored.
+func WithStreamUploaderCreateInput(input CreateMultipartUploadV2Input) StreamUploaderOption {
+	return func(s *StreamUploader) {
+		s.input = input
+	}
+}
+
+// NewStreamUploader create a StreamUploader, the multipart upload is created on first part uploaded.
+// partSize must range from 5MB to 5GB.
+func (cli *ClientV2) NewStreamUploader(bucket, key string, partSize int64, options ...StreamUploaderOption) (*StreamUploader, error) {
+	if err := isValidNames(bucket, key); err != nil {
+		return nil, err
+	}
+	if partSize < MinPartSize || partSize > MaxPartSize {
+		return nil, newTosClientError("tos: the input part size is invalid, please set it range from 5MB to 5GB.", nil)
+	}
+	s := &StreamUploader{
+		cli:      cli,
+		ctx:      context.Background(),
+		partSize: partSize,
+		taskNum:  1,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	if s.taskNum < 1 {
+		s.taskNum = 1
+	}
+	s.input.Bucket = bucket
+	s.input.Key = key
+	s.inflight = make(chan struct{}, s.taskNum)
+	s.buffers = make(chan []byte, s.taskNum)
+	return s, nil
+}
+
+// Write buffers p, and uploads a part each time PartSize bytes are buffered.
+// It blocks if TaskNum parts are being uploaded, and returns error if any part failed.
+func (s *StreamUploader) Write(p []byte) (n int, err error) {
+	if s.closed {
+		return 0, newTosClientError("tos: write to closed StreamUploader", nil)
+	}
+	for len(p) > 0 {
+		if err = s.getErr(); err != nil {
+			return n, err
+		}
+		if s.buf == nil {
+			s.buf = s.getBuffer()
+		}
+		size := len(p)
+		if free := int(s.partSize) - len(s.buf); size > free {
+			size = free
+		}
+		s.buf = append(s.buf, p[:size]...)
+		n += size
+		p = p[size:]
+		if int64(len(s.buf)) == s.partSize {
+			s.flush()
+		}
+	}
+	return n, s.getErr()
+}
+
+func (s *StreamUploader) getBuffer() []byte {
+	select {
+	case buf := <-s.buffers:
+		return buf[:0]
+	default:
+		return nil
+	}
+}
+
+func (s *StreamUploader) putBuffer(buf []byte) {
+	select {
+	case s.buffers <- buf:
+	default:
+	}
+}
+
+func (s *StreamUploader) getErr() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+func (s *StreamUploader) setErr(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// flush uploads buffered data as next part in background
+func (s *StreamUploader) flush() {
+	if s.uploadID == "" {
+		created, err := s.cli.CreateMultipartUploadV2(s.ctx, &s.input)
+		if err != nil {
+			s.setErr(err)
+			return
+		}
+		s.uploadID = created.UploadID
+	}
+	s.partNumber++
+	buf, partNumber := s.buf, s.partNumber
+	s.buf = nil
+	// wait until less than TaskNum parts are being uploaded
+	s.inflight <- struct{}{}
+	s.wg.Add(1)
+	go func() {
+		defer func() {
+			<-s.inflight
+			s.wg.Done()
+		}()
+		output, err := s.cli.UploadPartV2(s.ctx, &UploadPartV2Input{
+			UploadPartBasicInput: UploadPartBasicInput{
+				Bucket:               s.input.Bucket,
+				Key:                  s.input.Key,
+				UploadID:             s.uploadID,
+				PartNumber:           partNumber,
+				SSECAlgorithm:        s.input.SSECAlgorithm,
+				SSECKey:              s.input.SSECKey,
+				SSECKeyMD5:           s.input.SSECKeyMD5,
+				ServerSideEncryption: s.input.ServerSideEncryption,
+			},
+			Content:       bytes.NewReader(buf),
+			ContentLength: int64(len(buf)),
+		})
+		s.putBuffer(buf)
+		if err != nil {
+			s.setErr(err)
+			return
+		}
+		s.lock.Lock()
+		// CRC64 of the object is checked with parts on completing
+		s.parts = append(s.parts, UploadedPartV2{
+			PartNumber:    output.PartNumber,
+			ETag:          output.ETag,
+			Size:          int64(len(buf)),
+			HashCrc64ecma: output.HashCrc64ecma,
+		})
+		s.lock.Unlock()
+	}()
+}
+
+// UploadID returns UploadID of the multipart upload, it is empty before the first part is uploaded
+func (s *StreamUploader) UploadID() string {
+	return s.uploadID
+}
+
+// Complete uploads buffered data as the last part, waits for all parts uploaded and completes the multipart upload.
+// The multipart upload is aborted if any part failed.
+// It can be called more than once, and returns the same result.
+func (s *StreamUploader) Complete() (*CompleteMultipartUploadV2Output, error) {
+	if s.closed {
+		return s.output, s.completeErr
+	}
+	s.closed = true
+	// the last part may be smaller than PartSize, and an empty object has one empty part
+	if s.getErr() == nil && (len(s.buf) > 0 || s.partNumber == 0) {
+		s.flush()
+	}
+	s.wg.Wait()
+	if err := s.getErr(); err != nil {
+		s.abort()
+		s.completeErr = err
+		return nil, err
+	}
+	s.output, s.completeErr = s.cli.CompleteMultipartUploadV2(s.ctx, &CompleteMultipartUploadV2Input{
+		Bucket:   s.input.Bucket,
+		Key:      s.input.Key,
+		UploadID: s.uploadID,
+		Parts:    s.parts,
+	})
+	if s.completeErr != nil {
+		s.abort()
+	}
+	return s.output, s.completeErr
+}
+
+// Close implements io.Closer, it is the same as Complete
+func (s *StreamUploader) Close() error {
+	_, err := s.Complete()
+	return err
+}
+
+// Abort discards buffered data and aborts the multipart upload, Write and Complete return error after that
+func (s *StreamUploader) Abort() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.setErr(newTosClientError("tos: StreamUploader is aborted", nil))
+	s.wg.Wait()
+	s.completeErr = s.getErr()
+	return s.abort()
+}
+
+func (s *StreamUploader) abort() error {
+	if s.uploadID == "" {
+		return nil
+	}
+	_, err := s.cli.AbortMultipartUpload(s.ctx, &AbortMultipartUploadInput{
+		Bucket:   s.input.Bucket,
+		Key:      s.input.Key,
+		UploadID: s.uploadID,
+	})
+	return err
+}
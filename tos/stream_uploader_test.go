@@ -0,0 +1,95 @@
+package tos
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamUploaderTransport(t *testing.T, failPart int, partSizes map[int]int) *mockTransport {
+	return &mockTransport{handler: func(req *Request) *Response {
+		switch {
+		case req.Method == http.MethodPost && req.Query.Get("uploadId") == "":
+			return newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"key","UploadId":"upload-id"}`)()
+		case req.Method == http.MethodPut:
+			partNumber, _ := strconv.Atoi(req.Query.Get("partNumber"))
+			data, err := ioutil.ReadAll(req.Content)
+			assert.Nil(t, err)
+			partSizes[partNumber] = len(data)
+			if len(data) > 0 {
+				assert.Equal(t, strconv.Itoa(len(data)), req.Header.Get(HeaderContentLength))
+			}
+			if partNumber == failPart {
+				return newMockResponse(http.StatusForbidden, `{"Code":"AccessDenied"}`)()
+			}
+			res := newMockResponse(http.StatusOK, "")()
+			res.Header.Set(HeaderETag, "etag-"+strconv.Itoa(partNumber))
+			return res
+		case req.Method == http.MethodPost:
+			var parts partsToComplete
+			assert.Nil(t, json.NewDecoder(req.Content).Decode(&parts))
+			partSizes[0] = len(parts.Parts)
+			return newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"key","ETag":"etag"}`)()
+		default:
+			return newMockResponse(http.StatusNoContent, "")()
+		}
+	}}
+}
+
+func TestStreamUploader(t *testing.T) {
+	partSizes := make(map[int]int)
+	transport := newStreamUploaderTransport(t, 0, partSizes)
+	client := newMockClient(t, transport)
+	uploader, err := client.NewStreamUploader("bucket", "key", MinPartSize, WithStreamUploaderTaskNum(2))
+	require.Nil(t, err)
+	chunk := make([]byte, 1024*1024+1)
+	written := 0
+	for written < 2*MinPartSize+10 {
+		n, err := uploader.Write(chunk)
+		require.Nil(t, err)
+		written += n
+	}
+	output, err := uploader.Complete()
+	require.Nil(t, err)
+	require.Equal(t, "etag", output.ETag)
+	require.Nil(t, uploader.Close())
+
+	transport.lock.Lock()
+	defer transport.lock.Unlock()
+	require.Equal(t, 3, partSizes[0])
+	require.Equal(t, MinPartSize, partSizes[1])
+	require.Equal(t, MinPartSize, partSizes[2])
+	require.Equal(t, written-2*MinPartSize, partSizes[3])
+}
+
+func TestStreamUploaderEmpty(t *testing.T) {
+	partSizes := make(map[int]int)
+	client := newMockClient(t, newStreamUploaderTransport(t, 0, partSizes))
+	uploader, err := client.NewStreamUploader("bucket", "key", MinPartSize)
+	require.Nil(t, err)
+	require.Nil(t, uploader.Close())
+	require.Equal(t, 1, partSizes[0])
+	require.Equal(t, 0, partSizes[1])
+}
+
+func TestStreamUploaderAbortOnError(t *testing.T) {
+	partSizes := make(map[int]int)
+	transport := newStreamUploaderTransport(t, 1, partSizes)
+	client := newMockClient(t, transport)
+	uploader, err := client.NewStreamUploader("bucket", "key", MinPartSize)
+	require.Nil(t, err)
+	// the failed part may not be finished when Write returns
+	_, _ = uploader.Write(make([]byte, MinPartSize+1))
+	err = uploader.Close()
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusForbidden, StatusCode(err))
+	requests := transport.recorded()
+	last := requests[len(requests)-1]
+	require.Equal(t, http.MethodDelete, last.Method)
+	require.Equal(t, "upload-id", last.Query.Get("uploadId"))
+}
@@ -0,0 +1,65 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// PutSymlinkV2 create a symlink object pointing to SymlinkTargetKey,
+// GetObjectV2 and HeadObjectV2 on the symlink follow it to the target.
+func (cli *ClientV2) PutSymlinkV2(ctx context.Context, input *PutSymlinkV2Input) (*PutSymlinkV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	if err := isValidKey(input.SymlinkTargetKey); err != nil {
+		return nil, err
+	}
+	rb := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("symlink", "").
+		WithParams(*input).
+		WithHeader(HeaderSymlinkTarget, url.PathEscape(input.SymlinkTargetKey))
+	if input.ForbidOverwrite {
+		rb.WithHeader(HeaderForbidOverwrite, "true")
+	}
+	res, err := rb.Request(ctx, http.MethodPut, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutSymlinkV2Output{
+		RequestInfo: res.RequestInfo(),
+		VersionID:   res.Header.Get(HeaderVersionID),
+	}, nil
+}
+
+// GetSymlinkV2 get the target of a symlink object
+func (cli *ClientV2) GetSymlinkV2(ctx context.Context, input *GetSymlinkV2Input) (*GetSymlinkV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("symlink", "").
+		WithParams(*input).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	lastModified, _ := time.ParseInLocation(http.TimeFormat, res.Header.Get(HeaderLastModified), time.UTC)
+	return &GetSymlinkV2Output{
+		RequestInfo:         res.RequestInfo(),
+		VersionID:           res.Header.Get(HeaderVersionID),
+		SymlinkTargetKey:    symlinkTarget(res.Header),
+		SymlinkTargetBucket: res.Header.Get(HeaderSymlinkBucket),
+		LastModified:        lastModified,
+		ETag:                res.Header.Get(HeaderETag),
+	}, nil
+}
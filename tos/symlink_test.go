@@ -0,0 +1,56 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymlinkV2(t *testing.T) {
+	var header http.Header
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		res := newMockResponse(http.StatusOK, "")()
+		if req.Method == http.MethodPut {
+			require.Contains(t, req.Query, "symlink")
+			header = req.Header
+			res.Header.Set(HeaderVersionID, "version")
+			return res
+		}
+		res.Header.Set(HeaderSymlinkTarget, header.Get(HeaderSymlinkTarget))
+		res.Header.Set(HeaderSymlinkBucket, header.Get(HeaderSymlinkBucket))
+		res.Header.Set(HeaderLastModified, "Fri, 09 Sep 2022 08:00:00 GMT")
+		res.Header.Set(HeaderObjectType, "Symlink")
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	put, err := client.PutSymlinkV2(ctx, &PutSymlinkV2Input{
+		Bucket:              "bucket",
+		Key:                 "latest",
+		SymlinkTargetKey:    "builds/构建 1.tar.gz",
+		SymlinkTargetBucket: "artifacts",
+		ForbidOverwrite:     true,
+		Meta:                map[string]string{"k": "v"},
+	})
+	require.Nil(t, err)
+	require.Equal(t, "version", put.VersionID)
+	require.Equal(t, "true", header.Get(HeaderForbidOverwrite))
+	require.Equal(t, "v", header.Get(HeaderMetaPrefix+"k"))
+
+	get, err := client.GetSymlinkV2(ctx, &GetSymlinkV2Input{Bucket: "bucket", Key: "latest"})
+	require.Nil(t, err)
+	require.Equal(t, "builds/构建 1.tar.gz", get.SymlinkTargetKey)
+	require.Equal(t, "artifacts", get.SymlinkTargetBucket)
+	require.Equal(t, 2022, get.LastModified.Year())
+
+	head, err := client.HeadObjectV2(ctx, &HeadObjectV2Input{Bucket: "bucket", Key: "latest"})
+	require.Nil(t, err)
+	require.Equal(t, "builds/构建 1.tar.gz", head.SymlinkTargetKey)
+	require.Equal(t, "artifacts", head.SymlinkTargetBucket)
+
+	_, err = client.PutSymlinkV2(ctx, &PutSymlinkV2Input{Bucket: "bucket", Key: "latest"})
+	require.NotNil(t, err)
+}
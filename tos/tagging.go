@@ -0,0 +1,140 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxTagCount       = 10
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+// Encode encodes tags in URL query form, e.g. "k1=v1&k2=v2", which can be used as Tagging of PutObjectV2Input
+func (ts *TagSet) Encode() string {
+	values := make(url.Values, len(ts.Tags))
+	for _, tag := range ts.Tags {
+		values.Add(tag.Key, tag.Value)
+	}
+	return values.Encode()
+}
+
+// validate checks tags: at most 10 tags with unique keys, key is 1 to 128 characters and value is at most 256 characters,
+// consisting of letters, digits, spaces and + - = . _ : / @
+func (ts *TagSet) validate() error {
+	if len(ts.Tags) > maxTagCount {
+		return newTosClientError("tos: too many tags, at most 10 tags are allowed", nil)
+	}
+	keys := make(map[string]struct{}, len(ts.Tags))
+	for _, tag := range ts.Tags {
+		if length := utf8.RuneCountInString(tag.Key); length == 0 || length > maxTagKeyLength {
+			return newTosClientError("tos: the length of tag key must be 1 to 128 characters", nil)
+		}
+		if utf8.RuneCountInString(tag.Value) > maxTagValueLength {
+			return newTosClientError("tos: the length of tag value must be at most 256 characters", nil)
+		}
+		if !isValidTagString(tag.Key) || !isValidTagString(tag.Value) {
+			return newTosClientError("tos: invalid character in tag "+tag.Key, nil)
+		}
+		if _, ok := keys[tag.Key]; ok {
+			return newTosClientError("tos: duplicate tag key "+tag.Key, nil)
+		}
+		keys[tag.Key] = struct{}{}
+	}
+	return nil
+}
+
+func isValidTagString(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' {
+			continue
+		}
+		switch r {
+		case '+', '-', '=', '.', '_', ':', '/', '@':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// PutObjectTaggingV2 set tags of an object, existing tags are replaced
+func (cli *ClientV2) PutObjectTaggingV2(ctx context.Context, input *PutObjectTaggingV2Input) (*PutObjectTaggingV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	if err := input.TagSet.validate(); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("PutObjectTaggingV2Input", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("tagging", "").
+		WithParams(*input).
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutObjectTaggingV2Output{
+		RequestInfo: res.RequestInfo(),
+		VersionID:   res.Header.Get(HeaderVersionID),
+	}, nil
+}
+
+// GetObjectTaggingV2 get tags of an object
+func (cli *ClientV2) GetObjectTaggingV2(ctx context.Context, input *GetObjectTaggingV2Input) (*GetObjectTaggingV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("tagging", "").
+		WithParams(*input).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetObjectTaggingV2Output{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	output.VersionID = res.Header.Get(HeaderVersionID)
+	return &output, nil
+}
+
+// DeleteObjectTaggingV2 delete all tags of an object
+func (cli *ClientV2) DeleteObjectTaggingV2(ctx context.Context, input *DeleteObjectTaggingV2Input) (*DeleteObjectTaggingV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, input.Key).
+		WithQuery("tagging", "").
+		WithParams(*input).
+		Request(ctx, http.MethodDelete, nil, cli.roundTripper(http.StatusNoContent))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &DeleteObjectTaggingV2Output{
+		RequestInfo: res.RequestInfo(),
+		VersionID:   res.Header.Get(HeaderVersionID),
+	}, nil
+}
@@ -0,0 +1,84 @@
+package tos
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagSetValidate(t *testing.T) {
+	valid := TagSet{Tags: []Tag{{Key: "env", Value: "prod"}, {Key: "标签 a+b=c._:/@-", Value: ""}}}
+	require.Nil(t, valid.validate())
+	require.Equal(t, "env=prod&%E6%A0%87%E7%AD%BE+a%2Bb%3Dc._%3A%2F%40-=", valid.Encode())
+
+	tooMany := TagSet{}
+	for i := 0; i <= maxTagCount; i++ {
+		tooMany.Tags = append(tooMany.Tags, Tag{Key: strings.Repeat("k", i+1)})
+	}
+	for _, tags := range []TagSet{
+		tooMany,
+		{Tags: []Tag{{Key: ""}}},
+		{Tags: []Tag{{Key: strings.Repeat("k", maxTagKeyLength+1)}}},
+		{Tags: []Tag{{Key: "k", Value: strings.Repeat("v", maxTagValueLength+1)}}},
+		{Tags: []Tag{{Key: "k", Value: "v#"}}},
+		{Tags: []Tag{{Key: "k"}, {Key: "k"}}},
+	} {
+		require.NotNil(t, tags.validate())
+	}
+}
+
+func TestObjectTaggingV2(t *testing.T) {
+	var tagging string
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodPut && req.Query.Get("versionId") == "" {
+			tagging = req.Header.Get(HeaderTagging)
+			return newMockResponse(http.StatusOK, "")()
+		}
+		require.Contains(t, req.Query, "tagging")
+		require.Equal(t, "version", req.Query.Get("versionId"))
+		res := newMockResponse(http.StatusOK, "")()
+		res.Header.Set(HeaderVersionID, "version")
+		switch req.Method {
+		case http.MethodPut:
+			data, _ := ioutil.ReadAll(req.Content)
+			tagging = string(data)
+		case http.MethodGet:
+			res.Body = ioutil.NopCloser(strings.NewReader(tagging))
+		case http.MethodDelete:
+			res.StatusCode = http.StatusNoContent
+		}
+		return res
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	tags := TagSet{Tags: []Tag{{Key: "env", Value: "prod"}}}
+	put, err := client.PutObjectTaggingV2(ctx, &PutObjectTaggingV2Input{Bucket: "bucket", Key: "key", VersionID: "version", TagSet: tags})
+	require.Nil(t, err)
+	require.Equal(t, "version", put.VersionID)
+	require.Equal(t, `{"TagSet":{"Tags":[{"Key":"env","Value":"prod"}]}}`, tagging)
+
+	get, err := client.GetObjectTaggingV2(ctx, &GetObjectTaggingV2Input{Bucket: "bucket", Key: "key", VersionID: "version"})
+	require.Nil(t, err)
+	require.Equal(t, tags, get.TagSet)
+	require.Equal(t, "version", get.VersionID)
+
+	_, err = client.DeleteObjectTaggingV2(ctx, &DeleteObjectTaggingV2Input{Bucket: "bucket", Key: "key", VersionID: "version"})
+	require.Nil(t, err)
+
+	_, err = client.PutObjectTaggingV2(ctx, &PutObjectTaggingV2Input{Bucket: "bucket", Key: "key", TagSet: TagSet{Tags: []Tag{{Key: ""}}}})
+	_, ok := err.(*TosClientError)
+	require.True(t, ok)
+
+	// tags set at write time
+	_, err = client.PutObjectV2(ctx, &PutObjectV2Input{
+		PutObjectBasicInput: PutObjectBasicInput{Bucket: "bucket", Key: "key", Tagging: tags.Encode()},
+		Content:             strings.NewReader("hello"),
+	})
+	require.Nil(t, err)
+	require.Equal(t, "env=prod", tagging)
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
 )
 
-func TestObjectACLV2(t *testing.T) {
+func TestObjectACL(t *testing.T) {
 	var (
 		env     = newTestEnv(t)
 		bucket  = generateBucketName("put-object-acl")
@@ -26,6 +26,7 @@ func TestObjectACLV2(t *testing.T) {
 	acl, err := client.PutObjectACL(context.Background(), &tos.PutObjectACLInput{
 		Bucket: bucket,
 		Key:    key,
+		Owner:  tos.Owner{ID: ownerID},
 		Grants: []tos.GrantV2{{
 			GranteeV2: tos.GranteeV2{
 				ID:   ownerID,
@@ -21,8 +21,9 @@ func TestHeadNonExistentBucket(t *testing.T) {
 	head, err := client.HeadBucket(context.Background(), &tos.HeadBucketInput{Bucket: bucket})
 	require.NotNil(t, err)
 	require.Nil(t, head)
-	terr, ok := err.(*tos.TosServerError)
+	terr, ok := err.(*tos.BucketNotFoundError)
 	require.True(t, ok)
+	require.Equal(t, bucket, terr.Bucket)
 	require.True(t, strings.Contains(terr.Message, "unexpected"))
 }
 
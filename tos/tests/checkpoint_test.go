@@ -181,7 +181,7 @@ func TestUploadFileCancelHook(t *testing.T) {
 	input.UploadEventListener = listener
 	upload, err := client.UploadFile(context.Background(), input)
 	require.Nil(t, upload)
-	require.NotNil(t, err)
+	require.Equal(t, tos.CanceledClientError, err)
 	// checkpoint file still exist
 	stat, err := os.Stat(strings.Join([]string{fileName, bucket, key, "upload"}, "."))
 	require.Nil(t, err)
@@ -216,7 +216,7 @@ func TestDownloadFile(t *testing.T) {
 		cleanBucket(t, client, bucket)
 		cleanTestFile(t, fileName)
 		cleanTestFile(t, fileName+".file")
-		cleanTestFile(t, fileName+".file"+".temp")
+		cleanTestFile(t, fileName+".file"+tos.TempFileSuffix)
 		cleanTestFile(t, strings.Join([]string{fileName + ".file", bucket, key, "download"}, "."))
 	}()
 	file, err := os.Create(fileName)
@@ -274,7 +274,7 @@ func TestDownloadFileWithCheckpoint(t *testing.T) {
 		cleanBucket(t, client, bucket)
 		cleanTestFile(t, fileName)
 		cleanTestFile(t, fileName+".file")
-		cleanTestFile(t, fileName+".file"+".temp")
+		cleanTestFile(t, fileName+".file"+tos.TempFileSuffix)
 		cleanTestFile(t, strings.Join([]string{fileName + ".file", bucket, key, "download"}, "."))
 	}()
 	file, err := os.Create(fileName)
@@ -375,7 +375,7 @@ func TestDownloadCancelHook(t *testing.T) {
 	_, err = client.DownloadFile(context.Background(), input)
 	require.Equal(t, 2, listener.count)
 
-	stat, err := os.Stat(fileName + ".file" + ".temp")
+	stat, err := os.Stat(fileName + ".file" + tos.TempFileSuffix)
 	require.Nil(t, err)
 	input.CancelHook = tos.NewCancelHook()
 	_, err = client.DownloadFile(context.Background(), input)
@@ -449,3 +449,46 @@ func TestLargeFile(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, md5Sum, md5s(string(buffer)))
 }
+
+func TestResumableCopyObject(t *testing.T) {
+	var (
+		env     = newTestEnv(t)
+		bucket  = generateBucketName("resumable-copy")
+		srcKey  = "key123"
+		copyKey = "key123-copy"
+		value   = randomString(12 * 1024 * 1024)
+		client  = env.prepareClient(bucket, LongTimeOutClientOption...)
+	)
+	defer func() {
+		cleanBucket(t, client, bucket)
+	}()
+	put, err := client.PutObjectV2(context.Background(), &tos.PutObjectV2Input{
+		PutObjectBasicInput: tos.PutObjectBasicInput{Bucket: bucket, Key: srcKey, ContentType: "text/plain"},
+		Content:             strings.NewReader(value),
+	})
+	checkSuccess(t, put, err, 200)
+	copied, err := client.ResumableCopyObject(context.Background(), &tos.ResumableCopyObjectInput{
+		CreateMultipartUploadV2Input: tos.CreateMultipartUploadV2Input{
+			Bucket:       bucket,
+			Key:          copyKey,
+			StorageClass: enum.StorageClassIa,
+		},
+		SrcBucket:        bucket,
+		SrcKey:           srcKey,
+		PartSize:         5 * 1024 * 1024,
+		TaskNum:          3,
+		EnableCheckpoint: true,
+	})
+	checkSuccess(t, copied, err, 200)
+	require.NotEqual(t, "", copied.UploadID)
+	get, err := client.GetObjectV2(context.Background(), &tos.GetObjectV2Input{
+		Bucket: bucket,
+		Key:    copyKey,
+	})
+	checkSuccess(t, get, err, 200)
+	content, err := ioutil.ReadAll(get.Content)
+	require.Nil(t, err)
+	require.Equal(t, md5s(value), md5s(string(content)))
+	require.Equal(t, "text/plain", get.ContentType)
+	require.Equal(t, enum.StorageClassIa, get.StorageClass)
+}
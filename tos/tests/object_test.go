@@ -1056,7 +1056,8 @@ func TestGetWithModify(t *testing.T) {
 		IfModifiedSince: time.Now(),
 	})
 	require.NotNil(t, err)
-	tosErr := err.(*tos.TosServerError)
+	tosErr, ok := err.(*tos.NotModifiedError)
+	require.True(t, ok)
 	require.Equal(t, tosErr.StatusCode, http.StatusNotModified)
 }
 func checkDataListener(t *testing.T, listener *dataTransferListenerTest) {
@@ -0,0 +1,182 @@
+package tos
+
+import (
+	"context"
+	"io"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// OperationTimeout is the timeout of each attempt of a request, so that a slow attempt doesn't consume
+// the budget of retries. Zero fields mean no limit, and timeouts of TransportConfig still apply.
+type OperationTimeout struct {
+	// Timeout limits the total time of an attempt, including reading the response body
+	Timeout time.Duration
+	// ConnectTimeout limits the time of getting a connection, including DNS, dialing and TLS handshake
+	ConnectTimeout time.Duration
+	// ResponseHeaderTimeout limits the time of waiting response headers after the request is written,
+	// reading the response body is not limited by it
+	ResponseHeaderTimeout time.Duration
+}
+
+type operationTimeoutKey struct{}
+
+// ContextWithOperationTimeout returns a context with which each attempt of requests is limited by timeout
+func ContextWithOperationTimeout(ctx context.Context, timeout OperationTimeout) context.Context {
+	return context.WithValue(ctx, operationTimeoutKey{}, timeout)
+}
+
+// timeoutError is the cause of TosClientError returned by attempts exceeding OperationTimeout
+type timeoutError struct {
+	kind    string
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return "tos: " + e.kind + " " + e.timeout.String() + " exceeded"
+}
+
+// Timeout implements the interface checked by StatusCodeClassifier, so that the attempt is retried
+func (e *timeoutError) Timeout() bool {
+	return true
+}
+
+// attemptTimer cancels the context of an attempt if any timeout of OperationTimeout is exceeded
+type attemptTimer struct {
+	cancel context.CancelFunc
+	lock   sync.Mutex
+	total  *time.Timer
+	conn   *time.Timer
+	header *time.Timer
+	err    *timeoutError
+	done   bool
+}
+
+// start starts a timer canceling the attempt once the timeout is exceeded
+func (at *attemptTimer) start(kind string, timeout time.Duration) *time.Timer {
+	return time.AfterFunc(timeout, func() {
+		at.lock.Lock()
+		if at.err == nil && !at.done {
+			at.err = &timeoutError{kind: kind, timeout: timeout}
+		}
+		at.lock.Unlock()
+		at.cancel()
+	})
+}
+
+// exceeded returns the timeout exceeded by the attempt, nil if there is none
+func (at *attemptTimer) exceeded() *timeoutError {
+	at.lock.Lock()
+	defer at.lock.Unlock()
+	return at.err
+}
+
+// stopHeader stops the timer of response header timeout once the response is returned
+func (at *attemptTimer) stopHeader() {
+	at.lock.Lock()
+	defer at.lock.Unlock()
+	stopTimer(at.header)
+}
+
+// finish stops all timers and releases the context of the attempt
+func (at *attemptTimer) finish() {
+	at.lock.Lock()
+	at.done = true
+	stopTimer(at.total)
+	stopTimer(at.conn)
+	stopTimer(at.header)
+	at.lock.Unlock()
+	at.cancel()
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
+
+// wrap returns a roundTripper limiting each call with the OperationTimeout
+func (ot OperationTimeout) wrap(next roundTripper) roundTripper {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		at := &attemptTimer{}
+		ctx, at.cancel = context.WithCancel(ctx)
+		if ot.Timeout > 0 {
+			at.total = at.start("operation timeout", ot.Timeout)
+		}
+		if ot.ConnectTimeout > 0 || ot.ResponseHeaderTimeout > 0 {
+			ctx = httptrace.WithClientTrace(ctx, ot.clientTrace(at))
+		}
+		res, err := next(ctx, req)
+		if err != nil {
+			at.finish()
+			if te := at.exceeded(); te != nil {
+				return nil, newTosClientError(te.Error(), te)
+			}
+			return nil, err
+		}
+		at.stopHeader()
+		if res.Body == nil {
+			at.finish()
+			return res, nil
+		}
+		res.Body = &timeoutReadCloser{ReadCloser: res.Body, timer: at}
+		return res, nil
+	}
+}
+
+func (ot OperationTimeout) clientTrace(at *attemptTimer) *httptrace.ClientTrace {
+	trace := &httptrace.ClientTrace{}
+	if ot.ConnectTimeout > 0 {
+		trace.GetConn = func(hostPort string) {
+			at.lock.Lock()
+			defer at.lock.Unlock()
+			if !at.done {
+				at.conn = at.start("connect timeout", ot.ConnectTimeout)
+			}
+		}
+		trace.GotConn = func(info httptrace.GotConnInfo) {
+			at.lock.Lock()
+			defer at.lock.Unlock()
+			stopTimer(at.conn)
+		}
+	}
+	if ot.ResponseHeaderTimeout > 0 {
+		trace.WroteRequest = func(info httptrace.WroteRequestInfo) {
+			at.lock.Lock()
+			defer at.lock.Unlock()
+			if !at.done && info.Err == nil {
+				at.header = at.start("response header timeout", ot.ResponseHeaderTimeout)
+			}
+		}
+		trace.GotFirstResponseByte = func() {
+			at.lock.Lock()
+			defer at.lock.Unlock()
+			stopTimer(at.header)
+		}
+	}
+	return trace
+}
+
+// timeoutReadCloser keeps the context of the attempt alive until the body is closed,
+// and reports the timeout exceeded while reading the body.
+type timeoutReadCloser struct {
+	io.ReadCloser
+	timer *attemptTimer
+}
+
+func (r *timeoutReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		if te := r.timer.exceeded(); te != nil {
+			return n, newTosClientError(te.Error(), te)
+		}
+	}
+	return n, err
+}
+
+func (r *timeoutReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.timer.finish()
+	return err
+}
@@ -0,0 +1,64 @@
+package tos
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationTimeout(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Method == http.MethodHead {
+			time.Sleep(300 * time.Millisecond)
+			return
+		}
+		// headers are returned at once, and the body is streamed slowly
+		w.Header().Set("Content-Length", "6")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("abc"))
+		w.(http.Flusher).Flush()
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("def"))
+	}))
+	defer server.Close()
+	client, err := NewClientV2(server.URL, WithRegion("cn-beijing"),
+		WithCredentials(NewStaticCredentials("ak", "sk")), WithMaxRetryCount(1))
+	require.Nil(t, err)
+
+	// each attempt is limited by the response header timeout
+	ctx := ContextWithOperationTimeout(context.Background(), OperationTimeout{ResponseHeaderTimeout: 50 * time.Millisecond})
+	start := time.Now()
+	_, err = client.HeadBucket(ctx, &HeadBucketInput{Bucket: "bucket"})
+	require.True(t, time.Since(start) < 300*time.Millisecond)
+	ce, ok := err.(*TosClientError)
+	require.True(t, ok)
+	require.True(t, ce.Timeout())
+	require.Equal(t, 2, ce.Attempts)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// streaming body is not limited by the response header timeout
+	output, err := client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	data, err := ioutil.ReadAll(output.Content)
+	require.Nil(t, err)
+	require.Equal(t, "abcdef", string(data))
+	require.Nil(t, output.Content.Close())
+
+	// but limited by the total timeout
+	ctx = ContextWithOperationTimeout(context.Background(), OperationTimeout{Timeout: 100 * time.Millisecond})
+	output, err = client.GetObjectV2(ctx, &GetObjectV2Input{Bucket: "bucket", Key: "key"})
+	require.Nil(t, err)
+	_, err = ioutil.ReadAll(output.Content)
+	ce, ok = err.(*TosClientError)
+	require.True(t, ok)
+	require.True(t, ce.Timeout())
+	output.Content.Close()
+}
@@ -0,0 +1,312 @@
+//go:build go1.16
+// +build go1.16
+
+// Package tosfs provides a read-only io/fs.FS view of objects under a prefix of a bucket, example:
+//
+//	fsys := tosfs.New(client, bucket, "templates/")
+//	tmpl, err := template.ParseFS(fsys, "*.html")
+//
+// "/" in keys is the path separator, a directory exists if any key starts with its path and "/",
+// keys ending with "/" are directory placeholders and can be used to create empty directories.
+package tosfs
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos"
+)
+
+// FS is a read-only file system of objects under a prefix, it implements fs.FS, fs.StatFS and fs.ReadDirFS
+type FS struct {
+	client *tos.ClientV2
+	ctx    context.Context
+	bucket string
+	prefix string
+}
+
+type Option func(*FS)
+
+// WithContext set context used by requests of FS, context.Background() by default
+func WithContext(ctx context.Context) Option {
+	return func(fsys *FS) {
+		fsys.ctx = ctx
+	}
+}
+
+// New create a FS of objects under prefix, the prefix is the root directory of FS,
+// "/" is appended to prefix if it is not empty and does not end with "/"
+func New(client *tos.ClientV2, bucket, prefix string, options ...Option) *FS {
+	prefix = strings.TrimPrefix(prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	fsys := &FS{client: client, ctx: context.Background(), bucket: bucket, prefix: prefix}
+	for _, option := range options {
+		option(fsys)
+	}
+	return fsys
+}
+
+// key returns object key of file name
+func (fsys *FS) key(name string) string {
+	if name == "." {
+		return fsys.prefix
+	}
+	return fsys.prefix + name
+}
+
+// dirKey returns prefix of keys in directory name
+func (fsys *FS) dirKey(name string) string {
+	if name == "." {
+		return fsys.prefix
+	}
+	return fsys.prefix + name + "/"
+}
+
+// Open implements fs.FS, the returned file is a *File of object, or a directory implementing fs.ReadDirFile
+func (fsys *FS) Open(name string) (fs.File, error) {
+	info, err := fsys.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &dir{fsys: fsys, name: name, info: info}, nil
+	}
+	return &File{fsys: fsys, name: name, info: info}, nil
+}
+
+// Stat implements fs.StatFS, a file is looked up by HeadObjectV2 and then as a directory by listing
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	info, err := fsys.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (fsys *FS) stat(op, name string) (*fileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &fileInfo{name: ".", isDir: true}, nil
+	}
+	head, err := fsys.client.HeadObjectV2(fsys.ctx, &tos.HeadObjectV2Input{Bucket: fsys.bucket, Key: fsys.key(name)})
+	if err == nil {
+		return &fileInfo{name: path.Base(name), size: head.ContentLength, modTime: head.LastModified, etag: head.ETag}, nil
+	}
+	if tos.StatusCode(err) != http.StatusNotFound {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	list, err := fsys.client.ListObjectsType2(fsys.ctx, &tos.ListObjectsType2Input{
+		Bucket:  fsys.bucket,
+		Prefix:  fsys.dirKey(name),
+		MaxKeys: 1,
+	})
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	if len(list.Contents) == 0 {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return &fileInfo{name: path.Base(name), isDir: true}, nil
+}
+
+// ReadDir implements fs.ReadDirFS, entries are listed with delimiter "/" and sorted by name.
+// If a name is both a file and a directory, only the directory is returned.
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	entries, found, err := fsys.readDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	if !found && name != "." {
+		// name may be a file or not exist
+		if _, err = fsys.stat("readdir", name); err != nil {
+			return nil, err
+		}
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return entries, nil
+}
+
+// readDir lists entries of directory name, found is false if no key starts with prefix of the directory
+func (fsys *FS) readDir(name string) (entries []fs.DirEntry, found bool, err error) {
+	prefix := fsys.dirKey(name)
+	dirs := make(map[string]bool)
+	files := make(map[string]*fileInfo)
+	paginator := fsys.client.NewListObjectsPaginator(&tos.ListObjectsType2Input{
+		Bucket:    fsys.bucket,
+		Prefix:    prefix,
+		Delimiter: "/",
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(fsys.ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, cp := range output.CommonPrefixes {
+			found = true
+			dirName := strings.TrimSuffix(strings.TrimPrefix(cp.Prefix, prefix), "/")
+			if fs.ValidPath(dirName) {
+				dirs[dirName] = true
+			}
+		}
+		for _, object := range output.Contents {
+			found = true
+			fileName := strings.TrimPrefix(object.Key, prefix)
+			// the placeholder of directory itself and keys not valid as path are skipped
+			if fileName == "" || !fs.ValidPath(fileName) {
+				continue
+			}
+			files[fileName] = &fileInfo{name: fileName, size: object.Size, modTime: object.LastModified, etag: object.ETag}
+		}
+	}
+	for dirName := range dirs {
+		entries = append(entries, &fileInfo{name: dirName, isDir: true})
+	}
+	for fileName, info := range files {
+		if !dirs[fileName] {
+			entries = append(entries, info)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, found, nil
+}
+
+// fileInfo implements fs.FileInfo and fs.DirEntry
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	etag    string
+	isDir   bool
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fileInfo) IsDir() bool        { return fi.isDir }
+func (fi *fileInfo) Sys() interface{}   { return nil }
+
+func (fi *fileInfo) Mode() fs.FileMode {
+	if fi.isDir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (fi *fileInfo) Type() fs.FileMode          { return fi.Mode().Type() }
+func (fi *fileInfo) Info() (fs.FileInfo, error) { return fi, nil }
+
+// File is an object opened by FS, its content is downloaded on first Read
+// and fails if the object is replaced after Open
+type File struct {
+	fsys    *FS
+	name    string
+	info    *fileInfo
+	content io.ReadCloser
+	closed  bool
+}
+
+// Stat implements fs.File, it returns FileInfo fetched by Open
+func (f *File) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// Read implements fs.File
+func (f *File) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	if f.content == nil {
+		if f.info.size == 0 {
+			return 0, io.EOF
+		}
+		output, err := f.fsys.client.GetObjectV2(f.fsys.ctx, &tos.GetObjectV2Input{
+			Bucket:  f.fsys.bucket,
+			Key:     f.fsys.key(f.name),
+			IfMatch: f.info.etag,
+		})
+		if err != nil {
+			return 0, &fs.PathError{Op: "read", Path: f.name, Err: err}
+		}
+		f.content = output.Content
+	}
+	return f.content.Read(p)
+}
+
+// Close implements fs.File
+func (f *File) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	if f.content != nil {
+		return f.content.Close()
+	}
+	return nil
+}
+
+// dir is a directory opened by FS, it implements fs.ReadDirFile
+type dir struct {
+	fsys    *FS
+	name    string
+	info    *fileInfo
+	entries []fs.DirEntry
+	listed  bool
+	closed  bool
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile, entries are listed on first call
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.closed {
+		return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: fs.ErrClosed}
+	}
+	if !d.listed {
+		entries, _, err := d.fsys.readDir(d.name)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+		d.entries, d.listed = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+func (d *dir) Close() error {
+	if d.closed {
+		return &fs.PathError{Op: "close", Path: d.name, Err: fs.ErrClosed}
+	}
+	d.closed = true
+	return nil
+}
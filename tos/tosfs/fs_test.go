@@ -0,0 +1,211 @@
+//go:build go1.16
+// +build go1.16
+
+package tosfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos"
+)
+
+// bucketTransport serves HeadObject, GetObject and ListObjectsType2 from objects in memory,
+// listing returns at most pageSize keys and common prefixes in a page
+type bucketTransport struct {
+	objects  map[string]string
+	modTime  time.Time
+	pageSize int
+}
+
+func (bt *bucketTransport) response(statusCode int, body string) *tos.Response {
+	return &tos.Response{
+		StatusCode:    statusCode,
+		ContentLength: int64(len(body)),
+		Header:        http.Header{tos.HeaderRequestID: []string{"request-id"}},
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func (bt *bucketTransport) RoundTrip(_ context.Context, req *tos.Request) (*tos.Response, error) {
+	if req.Query.Get("list-type") == "2" {
+		return bt.list(req), nil
+	}
+	key := strings.TrimPrefix(req.Path, "/")
+	if strings.Contains(key, "private/") {
+		return bt.response(http.StatusForbidden, `{"Code":"AccessDenied"}`), nil
+	}
+	data, ok := bt.objects[key]
+	if !ok {
+		return bt.response(http.StatusNotFound, `{"Code":"NoSuchKey"}`), nil
+	}
+	if req.Header.Get("If-None-Match") == "\"etag\"" {
+		res := bt.response(http.StatusNotModified, "")
+		res.Header.Set(tos.HeaderETag, "\"etag\"")
+		res.Header.Set(tos.HeaderLastModified, bt.modTime.Format(http.TimeFormat))
+		return res, nil
+	}
+	// 304 without validators, which are fetched by Handler with HeadObjectV2
+	if since, err := http.ParseTime(req.Header.Get("If-Modified-Since")); err == nil && !bt.modTime.After(since) {
+		return bt.response(http.StatusNotModified, ""), nil
+	}
+	statusCode, contentRange := http.StatusOK, ""
+	if value := req.Header.Get(tos.HeaderRange); value != "" {
+		var start, end int
+		fmt.Sscanf(value, "bytes=%d-%d", &start, &end)
+		if start >= len(data) {
+			res := bt.response(http.StatusRequestedRangeNotSatisfiable, `{"Code":"InvalidRange"}`)
+			res.Header.Set(tos.HeaderContentRange, "bytes */"+strconv.Itoa(len(data)))
+			return res, nil
+		}
+		if end >= len(data) {
+			end = len(data) - 1
+		}
+		statusCode = http.StatusPartialContent
+		contentRange = fmt.Sprintf("bytes %d-%d/%d", start, end, len(data))
+		data = data[start : end+1]
+	}
+	res := bt.response(statusCode, data)
+	if contentRange != "" {
+		res.Header.Set(tos.HeaderContentRange, contentRange)
+	}
+	res.Header.Set(tos.HeaderETag, "\"etag\"")
+	res.Header.Set(tos.HeaderContentLength, strconv.Itoa(len(data)))
+	res.Header.Set(tos.HeaderLastModified, bt.modTime.Format(http.TimeFormat))
+	if req.Method == http.MethodHead {
+		res.Body = nil
+	}
+	return res, nil
+}
+
+func (bt *bucketTransport) list(req *tos.Request) *tos.Response {
+	prefix, delimiter := req.Query.Get("prefix"), req.Query.Get("delimiter")
+	maxKeys := bt.pageSize
+	if n, _ := strconv.Atoi(req.Query.Get("max-keys")); n > 0 && n < maxKeys {
+		maxKeys = n
+	}
+	keys := make([]string, 0, len(bt.objects))
+	for key := range bt.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	type content struct {
+		Key          string
+		LastModified time.Time
+		ETag         string
+		Size         int64
+	}
+	var output struct {
+		IsTruncated           bool
+		NextContinuationToken string
+		Contents              []content
+		CommonPrefixes        []tos.ListedCommonPrefix
+	}
+	last := req.Query.Get("continuation-token")
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) || key <= last {
+			continue
+		}
+		if len(output.Contents)+len(output.CommonPrefixes) == maxKeys {
+			output.IsTruncated = true
+			break
+		}
+		if i := strings.Index(key[len(prefix):], delimiter); delimiter != "" && i >= 0 {
+			cp := key[:len(prefix)+i+1]
+			output.CommonPrefixes = append(output.CommonPrefixes, tos.ListedCommonPrefix{Prefix: cp})
+			// skip all keys of the common prefix
+			last = cp + "\xff"
+			output.NextContinuationToken = last
+			continue
+		}
+		output.Contents = append(output.Contents, content{Key: key, LastModified: bt.modTime, ETag: "\"etag\"", Size: int64(len(bt.objects[key]))})
+		last = key
+		output.NextContinuationToken = last
+	}
+	if !output.IsTruncated {
+		output.NextContinuationToken = ""
+	}
+	data, _ := json.Marshal(output)
+	return bt.response(http.StatusOK, string(data))
+}
+
+func newTestFS(t *testing.T, objects map[string]string, prefix string) *FS {
+	transport := &bucketTransport{objects: objects, modTime: time.Date(2022, 9, 9, 8, 0, 0, 0, time.UTC), pageSize: 2}
+	client, err := tos.NewClientV2("tos-cn-beijing.volces.com", tos.WithRegion("cn-beijing"),
+		tos.WithCredentials(tos.NewStaticCredentials("ak", "sk")), tos.WithTransport(transport))
+	require.Nil(t, err)
+	return New(client, "bucket", prefix)
+}
+
+func TestFS(t *testing.T) {
+	fsys := newTestFS(t, map[string]string{
+		"root.txt":             "outside of prefix",
+		"site/index.html":      "index",
+		"site/empty.txt":       "",
+		"site/css/":            "",
+		"site/css/main.css":    "body {}",
+		"site/css/reset.css":   "* {}",
+		"site/img/":            "",
+		"site/js/app/main.js":  "main()",
+		"site/js/app/util.js":  "util()",
+		"site/js/vendor/a.js":  "a()",
+		"site/zzz/deep/x/y.md": "y",
+	}, "site")
+	require.Nil(t, fstest.TestFS(fsys, "index.html", "empty.txt", "css/main.css", "css/reset.css", "img",
+		"js/app/main.js", "js/app/util.js", "js/vendor/a.js", "zzz/deep/x/y.md"))
+
+	data, err := fs.ReadFile(fsys, "css/main.css")
+	require.Nil(t, err)
+	require.Equal(t, "body {}", string(data))
+
+	entries, err := fs.ReadDir(fsys, ".")
+	require.Nil(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	require.Equal(t, []string{"css", "empty.txt", "img", "index.html", "js", "zzz"}, names)
+
+	// directory created by placeholder only
+	info, err := fs.Stat(fsys, "img")
+	require.Nil(t, err)
+	require.True(t, info.IsDir())
+	entries, err = fs.ReadDir(fsys, "img")
+	require.Nil(t, err)
+	require.Len(t, entries, 0)
+
+	info, err = fs.Stat(fsys, "index.html")
+	require.Nil(t, err)
+	require.Equal(t, int64(5), info.Size())
+	require.False(t, info.IsDir())
+
+	_, err = fsys.Open("missing.html")
+	require.True(t, errors.Is(err, fs.ErrNotExist))
+	_, err = fs.Stat(fsys, "root.txt")
+	require.True(t, errors.Is(err, fs.ErrNotExist))
+	_, err = fs.ReadDir(fsys, "missing")
+	require.True(t, errors.Is(err, fs.ErrNotExist))
+	_, err = fs.ReadDir(fsys, "index.html")
+	require.NotNil(t, err)
+	_, err = fsys.Open("../root.txt")
+	require.True(t, errors.Is(err, fs.ErrInvalid))
+
+	var walked []string
+	require.Nil(t, fs.WalkDir(fsys, "js", func(path string, d fs.DirEntry, err error) error {
+		walked = append(walked, path)
+		return err
+	}))
+	require.Equal(t, []string{"js", "js/app", "js/app/main.js", "js/app/util.js", "js/vendor", "js/vendor/a.js"}, walked)
+}
@@ -0,0 +1,237 @@
+package tosfs
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos"
+)
+
+// Handler serves objects under a prefix of a bucket over HTTP, URL path "/a/b.html" is served by key prefix + "a/b.html".
+// Range, If-None-Match and If-Modified-Since of GET and HEAD requests are sent to TOS,
+// and content is streamed to the client without buffering.
+type Handler struct {
+	client *tos.ClientV2
+	bucket string
+	prefix string
+	index  string
+}
+
+type HandlerOption func(*Handler)
+
+// WithIndex set key served for directory requests, e.g. "index.html", relative to the directory.
+// Directory requests are responded with 404 if index is not set.
+func WithIndex(index string) HandlerOption {
+	return func(h *Handler) {
+		h.index = strings.TrimPrefix(index, "/")
+	}
+}
+
+// NewHandler create a Handler serving objects under prefix,
+// "/" is appended to prefix if it is not empty and does not end with "/"
+func NewHandler(client *tos.ClientV2, bucket, prefix string, options ...HandlerOption) *Handler {
+	prefix = strings.TrimPrefix(prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	h := &Handler{client: client, bucket: bucket, prefix: prefix}
+	for _, option := range options {
+		option(h)
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	name := path.Clean("/" + r.URL.Path)
+	isDir := strings.HasSuffix(r.URL.Path, "/") || name == "/"
+	name = strings.TrimPrefix(name, "/")
+	if isDir {
+		if h.index == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if name != "" {
+			name += "/"
+		}
+		name += h.index
+	}
+	if h.serveObject(w, r, h.prefix+name) {
+		return
+	}
+	// redirect "/dir" to "/dir/" like http.FileServer, if index of the directory exists
+	if isDir || h.index == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if _, err := h.client.HeadObjectV2(r.Context(), &tos.HeadObjectV2Input{Bucket: h.bucket, Key: h.prefix + name + "/" + h.index}); err == nil {
+		target := path.Base(name) + "/"
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// serveObject writes the object to w, it returns false without writing anything if the object does not exist
+func (h *Handler) serveObject(w http.ResponseWriter, r *http.Request, key string) bool {
+	var (
+		meta    tos.GetObjectBasicOutput
+		content io.ReadCloser
+	)
+	if r.Method == http.MethodHead {
+		output, err := h.client.HeadObjectV2(r.Context(), &tos.HeadObjectV2Input{
+			Bucket:          h.bucket,
+			Key:             key,
+			IfNoneMatch:     r.Header.Get("If-None-Match"),
+			IfModifiedSince: parseHTTPTime(r.Header.Get("If-Modified-Since")),
+		})
+		if err != nil {
+			return h.serveError(w, r, key, err)
+		}
+		meta = tos.GetObjectBasicOutput{RequestInfo: output.RequestInfo, ObjectMetaV2: output.ObjectMetaV2}
+	} else {
+		input := &tos.GetObjectV2Input{
+			Bucket:          h.bucket,
+			Key:             key,
+			IfNoneMatch:     r.Header.Get("If-None-Match"),
+			IfModifiedSince: parseHTTPTime(r.Header.Get("If-Modified-Since")),
+		}
+		// Range is ignored if If-Range is set, the whole object is always a valid response
+		if r.Header.Get("If-Range") == "" {
+			input.Range = parseRange(r.Header.Get("Range"))
+		}
+		output, err := h.client.GetObjectV2(r.Context(), input)
+		if err != nil {
+			return h.serveError(w, r, key, err)
+		}
+		defer output.Content.Close()
+		meta, content = output.GetObjectBasicOutput, output.Content
+	}
+
+	header := w.Header()
+	contentType := meta.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(key))
+	}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("Content-Length", strconv.FormatInt(meta.ContentLength, 10))
+	setHeader(header, "ETag", meta.ETag)
+	if !meta.LastModified.IsZero() {
+		header.Set("Last-Modified", meta.LastModified.UTC().Format(http.TimeFormat))
+	}
+	setHeader(header, "Cache-Control", meta.CacheControl)
+	setHeader(header, "Content-Disposition", meta.ContentDisposition)
+	setHeader(header, "Content-Encoding", meta.ContentEncoding)
+	setHeader(header, "Content-Language", meta.ContentLanguage)
+	if meta.ContentRange != "" {
+		header.Set("Content-Range", meta.ContentRange)
+		w.WriteHeader(http.StatusPartialContent)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	if content != nil {
+		io.Copy(w, content)
+	}
+	return true
+}
+
+// serveError writes response of err, it returns false if err is 404 so that other keys can be tried
+func (h *Handler) serveError(w http.ResponseWriter, r *http.Request, key string, err error) bool {
+	switch tos.StatusCode(err) {
+	case http.StatusNotFound:
+		return false
+	case http.StatusNotModified:
+		h.serveNotModified(w, r, key, err)
+	case http.StatusForbidden:
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	case http.StatusPreconditionFailed:
+		http.Error(w, http.StatusText(http.StatusPreconditionFailed), http.StatusPreconditionFailed)
+	case http.StatusRequestedRangeNotSatisfiable:
+		if re, ok := err.(*tos.InvalidRangeError); ok && re.ObjectSize > 0 {
+			w.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(re.ObjectSize, 10))
+		}
+		http.Error(w, http.StatusText(http.StatusRequestedRangeNotSatisfiable), http.StatusRequestedRangeNotSatisfiable)
+	default:
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+	}
+	return true
+}
+
+// serveNotModified writes 304 with the validators and cache headers that a 200 response would have,
+// as RFC 9110 requires. They are taken from the 304 response of TOS, or from HeadObjectV2 if ETag is absent.
+func (h *Handler) serveNotModified(w http.ResponseWriter, r *http.Request, key string, err error) {
+	var header http.Header
+	if notModified, ok := err.(*tos.NotModifiedError); ok {
+		header = notModified.Header
+	}
+	if header.Get("ETag") == "" {
+		if output, err := h.client.HeadObjectV2(r.Context(), &tos.HeadObjectV2Input{Bucket: h.bucket, Key: key}); err == nil {
+			header = output.Header
+		}
+	}
+	for _, name := range []string{"ETag", "Last-Modified", "Cache-Control", "Expires"} {
+		setHeader(w.Header(), name, header.Get(name))
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+func setHeader(header http.Header, key, value string) {
+	if value != "" {
+		header.Set(key, value)
+	}
+}
+
+// parseHTTPTime returns zero time if value is empty or invalid, so that the condition is not sent
+func parseHTTPTime(value string) time.Time {
+	t, _ := http.ParseTime(value)
+	return t
+}
+
+// parseRange parses a single range of Range header, nil is returned for multiple or invalid ranges,
+// which are ignored and the whole object is served
+func parseRange(value string) *tos.HTTPRange {
+	if !strings.HasPrefix(value, "bytes=") || strings.Contains(value, ",") {
+		return nil
+	}
+	spec := strings.TrimSpace(strings.TrimPrefix(value, "bytes="))
+	i := strings.Index(spec, "-")
+	if i < 0 {
+		return nil
+	}
+	start, end := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+	if start == "" {
+		suffix, err := strconv.ParseInt(end, 10, 64)
+		if err != nil || suffix <= 0 {
+			return nil
+		}
+		return &tos.HTTPRange{Suffix: suffix}
+	}
+	first, err := strconv.ParseInt(start, 10, 64)
+	if err != nil || first < 0 {
+		return nil
+	}
+	if end == "" {
+		return &tos.HTTPRange{Start: first, End: -1}
+	}
+	last, err := strconv.ParseInt(end, 10, 64)
+	if err != nil || last < first {
+		return nil
+	}
+	return &tos.HTTPRange{Start: first, End: last}
+}
@@ -0,0 +1,103 @@
+//go:build go1.16
+// +build go1.16
+
+package tosfs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos"
+)
+
+func newTestHandler(t *testing.T, options ...HandlerOption) *Handler {
+	transport := &bucketTransport{objects: map[string]string{
+		"site/index.html":      "<html>index</html>",
+		"site/docs/index.html": "<html>docs</html>",
+		"site/docs/guide.md":   "0123456789",
+		"site/private/key.pem": "secret",
+	}, modTime: time.Date(2022, 9, 9, 8, 0, 0, 0, time.UTC), pageSize: 2}
+	client, err := tos.NewClientV2("tos-cn-beijing.volces.com", tos.WithRegion("cn-beijing"),
+		tos.WithCredentials(tos.NewStaticCredentials("ak", "sk")), tos.WithTransport(transport))
+	require.Nil(t, err)
+	return NewHandler(client, "bucket", "site", options...)
+}
+
+func serve(h http.Handler, method, target string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	h := newTestHandler(t, WithIndex("index.html"))
+
+	w := serve(h, http.MethodGet, "/docs/guide.md", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "0123456789", w.Body.String())
+	require.Equal(t, "10", w.Header().Get("Content-Length"))
+	require.Equal(t, "\"etag\"", w.Header().Get("ETag"))
+	require.Equal(t, "Fri, 09 Sep 2022 08:00:00 GMT", w.Header().Get("Last-Modified"))
+	require.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+
+	w = serve(h, http.MethodGet, "/docs/guide.md", map[string]string{"Range": "bytes=2-4"})
+	require.Equal(t, http.StatusPartialContent, w.Code)
+	require.Equal(t, "234", w.Body.String())
+	require.Equal(t, "bytes 2-4/10", w.Header().Get("Content-Range"))
+	require.Equal(t, "3", w.Header().Get("Content-Length"))
+
+	w = serve(h, http.MethodGet, "/docs/guide.md", map[string]string{"Range": "bytes=20-"})
+	require.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+	require.Equal(t, "bytes */10", w.Header().Get("Content-Range"))
+
+	// multiple ranges are ignored
+	w = serve(h, http.MethodGet, "/docs/guide.md", map[string]string{"Range": "bytes=0-1,3-4"})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "0123456789", w.Body.String())
+
+	w = serve(h, http.MethodGet, "/docs/guide.md", map[string]string{"If-None-Match": "\"etag\""})
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Equal(t, 0, w.Body.Len())
+	require.Equal(t, "\"etag\"", w.Header().Get("ETag"))
+	require.Equal(t, "Fri, 09 Sep 2022 08:00:00 GMT", w.Header().Get("Last-Modified"))
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		w = serve(h, method, "/docs/guide.md", map[string]string{"If-Modified-Since": "Fri, 09 Sep 2022 08:00:00 GMT"})
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Equal(t, "\"etag\"", w.Header().Get("ETag"))
+		require.Equal(t, "Fri, 09 Sep 2022 08:00:00 GMT", w.Header().Get("Last-Modified"))
+	}
+
+	w = serve(h, http.MethodHead, "/docs/guide.md", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "10", w.Header().Get("Content-Length"))
+	require.Equal(t, 0, w.Body.Len())
+
+	w = serve(h, http.MethodGet, "/", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "<html>index</html>", w.Body.String())
+	require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = serve(h, http.MethodGet, "/docs/", nil)
+	require.Equal(t, "<html>docs</html>", w.Body.String())
+
+	w = serve(h, http.MethodGet, "/docs?lang=en", nil)
+	require.Equal(t, http.StatusMovedPermanently, w.Code)
+	require.Equal(t, "/docs/?lang=en", w.Header().Get("Location"))
+
+	require.Equal(t, http.StatusNotFound, serve(h, http.MethodGet, "/missing.html", nil).Code)
+	require.Equal(t, http.StatusForbidden, serve(h, http.MethodGet, "/private/key.pem", nil).Code)
+	require.Equal(t, http.StatusMethodNotAllowed, serve(h, http.MethodPost, "/index.html", nil).Code)
+
+	// directory requests are not served without index
+	h = newTestHandler(t)
+	require.Equal(t, http.StatusNotFound, serve(h, http.MethodGet, "/docs/", nil).Code)
+	require.Equal(t, http.StatusNotFound, serve(h, http.MethodGet, "/docs", nil).Code)
+}
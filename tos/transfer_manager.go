@@ -0,0 +1,145 @@
+package tos
+
+import (
+	"context"
+	"sync"
+)
+
+// TransferManager bounds the number of parts transferred in parallel by all transfers sharing it,
+// e.g. UploadFile, DownloadFile, ResumableCopyObject and UploadFromReaderAt with the same TransferManager.
+// Parts are scheduled round-robin across transfers, so a transfer with many parts does not starve others.
+// TaskNum of each transfer still limits parts of the transfer in flight.
+type TransferManager struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	ready   []*transferQueue // transfers with pending parts, in round-robin order
+	closed  bool
+	workers sync.WaitGroup
+}
+
+// transferQueue holds pending parts of a transfer
+type transferQueue struct {
+	works []*transferWork
+}
+
+type transferWork struct {
+	run  func()
+	done chan struct{}
+}
+
+// NewTransferManager creates a TransferManager transferring at most concurrency parts in parallel.
+// Close must be called to release its goroutines.
+func NewTransferManager(concurrency int) *TransferManager {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	m := &TransferManager{}
+	m.cond = sync.NewCond(&m.lock)
+	m.workers.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go m.worker()
+	}
+	return m
+}
+
+func (m *TransferManager) worker() {
+	defer m.workers.Done()
+	for {
+		m.lock.Lock()
+		for len(m.ready) == 0 && !m.closed {
+			m.cond.Wait()
+		}
+		if len(m.ready) == 0 {
+			m.lock.Unlock()
+			return
+		}
+		queue := m.ready[0]
+		work := queue.works[0]
+		queue.works = queue.works[1:]
+		m.ready = m.ready[1:]
+		// the transfer waits for its turn again if it has more parts
+		if len(queue.works) > 0 {
+			m.ready = append(m.ready, queue)
+		}
+		m.lock.Unlock()
+		work.run()
+		close(work.done)
+	}
+}
+
+// run executes work of the transfer identified by queue in the pool and waits for it finished.
+// If ctx is done before work is started, work is dropped from the queue and the error of ctx is returned,
+// so that the transfer doesn't wait for the pool after it stops.
+func (m *TransferManager) run(ctx context.Context, queue *transferQueue, work func()) error {
+	pending := &transferWork{run: work, done: make(chan struct{})}
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return newTosClientError("tos: TransferManager is closed", nil)
+	}
+	queue.works = append(queue.works, pending)
+	if len(queue.works) == 1 {
+		m.ready = append(m.ready, queue)
+	}
+	m.cond.Signal()
+	m.lock.Unlock()
+	select {
+	case <-pending.done:
+		return nil
+	case <-ctx.Done():
+	}
+	m.lock.Lock()
+	if m.drop(queue, pending) {
+		m.lock.Unlock()
+		return newTosClientError(ctx.Err().Error(), ctx.Err())
+	}
+	m.lock.Unlock()
+	// work is started, it uses ctx and returns soon
+	<-pending.done
+	return nil
+}
+
+// drop removes work not started from queue, and returns false if it is started. m.lock must be held
+func (m *TransferManager) drop(queue *transferQueue, work *transferWork) bool {
+	for i, w := range queue.works {
+		if w != work {
+			continue
+		}
+		queue.works = append(queue.works[:i:i], queue.works[i+1:]...)
+		if len(queue.works) == 0 {
+			for j, q := range m.ready {
+				if q == queue {
+					m.ready = append(m.ready[:j:j], m.ready[j+1:]...)
+					break
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Close stops accepting new parts, and waits until parts already scheduled are finished.
+// Parts scheduled after Close fail with TosClientError.
+func (m *TransferManager) Close() {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return
+	}
+	m.closed = true
+	m.cond.Broadcast()
+	m.lock.Unlock()
+	m.workers.Wait()
+}
+
+// runTask runs task in TransferManager if manager is not nil, otherwise in the current goroutine
+func runTask(ctx context.Context, manager *TransferManager, queue *transferQueue, t task) (result interface{}, err error) {
+	if manager == nil {
+		return t.do()
+	}
+	if runErr := manager.run(ctx, queue, func() { result, err = t.do() }); runErr != nil {
+		return nil, runErr
+	}
+	return result, err
+}
@@ -0,0 +1,123 @@
+package tos
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferManager(t *testing.T) {
+	manager := NewTransferManager(2)
+	var (
+		running int32
+		maxRun  int32
+		lock    sync.Mutex
+		order   []string
+		wg      sync.WaitGroup
+	)
+	work := func(name string) func() {
+		return func() {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRun)
+				if n <= max || atomic.CompareAndSwapInt32(&maxRun, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			lock.Lock()
+			order = append(order, name)
+			lock.Unlock()
+			atomic.AddInt32(&running, -1)
+		}
+	}
+	// transfer a schedules 10 parts with 10 goroutines before transfer b schedules one
+	first, second := &transferQueue{}, &transferQueue{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.Nil(t, manager.run(context.Background(), first, work("a")))
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		require.Nil(t, manager.run(context.Background(), second, work("b")))
+	}()
+	wg.Wait()
+	require.Equal(t, int32(2), maxRun)
+	require.Len(t, order, 11)
+	// b is not starved until all parts of a are finished
+	index := 0
+	for i, name := range order {
+		if name == "b" {
+			index = i
+		}
+	}
+	require.True(t, index < 6, "order: %v", order)
+
+	manager.Close()
+	require.NotNil(t, manager.run(context.Background(), first, work("a")))
+	manager.Close()
+}
+
+func TestTransferManagerCloseDrain(t *testing.T) {
+	manager := NewTransferManager(1)
+	var (
+		finished int32
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = manager.run(context.Background(), &transferQueue{}, func() {
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&finished, 1)
+			})
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	manager.Close()
+	wg.Wait()
+	require.Equal(t, int32(3), atomic.LoadInt32(&finished))
+}
+
+func TestTransferManagerCanceled(t *testing.T) {
+	manager := NewTransferManager(1)
+	defer manager.Close()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = manager.run(context.Background(), &transferQueue{}, func() {
+			close(started)
+			<-release
+		})
+	}()
+	<-started
+
+	// the pool is busy, the part waiting for it is dropped once its transfer stops
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran int32
+	errCh := make(chan error)
+	go func() {
+		errCh <- manager.run(ctx, &transferQueue{}, func() { atomic.AddInt32(&ran, 1) })
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-errCh:
+		require.NotNil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("run is blocked after the context is canceled")
+	}
+	close(release)
+	require.Nil(t, manager.run(context.Background(), &transferQueue{}, func() {}))
+	require.Equal(t, int32(0), atomic.LoadInt32(&ran))
+}
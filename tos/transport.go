@@ -3,9 +3,13 @@ package tos
 import (
 	"context"
 	"crypto/tls"
+	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -16,10 +20,11 @@ type TransportConfig struct {
 	// MaxIdleConns same as http.Transport MaxIdleConns. Default is 1024.
 	MaxIdleConns int
 
-	// MaxIdleConnsPerHost same as http.Transport MaxIdleConnsPerHost. Default is 1024.
+	// MaxIdleConnsPerHost same as http.Transport MaxIdleConnsPerHost. Default is 1024,
+	// instead of 2 of http.DefaultTransport, so that connections are reused by highly concurrent requests.
 	MaxIdleConnsPerHost int
 
-	// MaxConnsPerHost same as http.Transport MaxConnsPerHost. Default is no limit.
+	// MaxConnsPerHost same as http.Transport MaxConnsPerHost. Default is 1024, zero means no limit.
 	MaxConnsPerHost int
 
 	// RequestTimeout same as http.Client Timeout
@@ -31,10 +36,10 @@ type TransportConfig struct {
 	// KeepAlive same as net.Dialer KeepAlive
 	KeepAlive time.Duration
 
-	// IdleConnTimeout same as http.Transport IdleConnTimeout
+	// IdleConnTimeout same as http.Transport IdleConnTimeout. Default is 60s.
 	IdleConnTimeout time.Duration
 
-	// TLSHandshakeTimeout same as http.Transport TLSHandshakeTimeout
+	// TLSHandshakeTimeout same as http.Transport TLSHandshakeTimeout. Default is 10s.
 	TLSHandshakeTimeout time.Duration
 
 	// ResponseHeaderTimeout same as http.Transport ResponseHeaderTimeout
@@ -51,6 +56,33 @@ type TransportConfig struct {
 
 	// InsecureSkipVerify set tls.Config InsecureSkipVerify
 	InsecureSkipVerify bool
+
+	// ReadBufferSize same as http.Transport ReadBufferSize. Default is 64KiB, instead of 4KiB of http.Transport,
+	// to reduce syscalls of downloading objects.
+	ReadBufferSize int
+
+	// WriteBufferSize same as http.Transport WriteBufferSize. Default is 64KiB, instead of 4KiB of http.Transport,
+	// to reduce syscalls of uploading objects.
+	WriteBufferSize int
+
+	// EnableCompression is the opposite of http.Transport DisableCompression. Default is false, so that
+	// objects are downloaded as they are stored, and Content-Length and CRC64 of the response are kept.
+	EnableCompression bool
+
+	// Proxy through which requests are sent, nullable. Default is nil, requests are sent directly.
+	Proxy *Proxy
+
+	// ProxyFromEnvironment set whether proxy is resolved from HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// environment variables if Proxy is nil. Default is false.
+	ProxyFromEnvironment bool
+
+	// EnableDNSCache set whether hosts are resolved once per DNSCacheTTL instead of for each connection,
+	// cached IPs are used in turn and evicted if connecting them keeps failing. Default is false.
+	EnableDNSCache bool
+
+	// DNSCacheTTL is the time-to-live of cached IPs, which are refreshed asynchronously before expiring
+	// and still used if refreshing fails. Default is 15 minutes.
+	DNSCacheTTL time.Duration
 }
 
 type Transport interface {
@@ -60,6 +92,7 @@ type Transport interface {
 type DefaultTransport struct {
 	client http.Client
 	logger logrus.FieldLogger
+	proxy  func(*http.Request) (*url.URL, error) // nullable, Proxy of the http.Transport built by the SDK
 }
 
 func (d *DefaultTransport) WithDefaultTransportLogger(logger logrus.FieldLogger) {
@@ -68,7 +101,13 @@ func (d *DefaultTransport) WithDefaultTransportLogger(logger logrus.FieldLogger)
 
 // NewDefaultTransport create a DefaultTransport with config
 func NewDefaultTransport(config *TransportConfig) *DefaultTransport {
+	proxy := config.proxyFunc()
+	var dnsCache *dnsCache
+	if config.EnableDNSCache {
+		dnsCache = newDNSCache(config.DNSCacheTTL)
+	}
 	return &DefaultTransport{
+		proxy: proxy,
 		client: http.Client{
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 				return http.ErrUseLastResponse
@@ -81,7 +120,9 @@ func NewDefaultTransport(config *TransportConfig) *DefaultTransport {
 					},
 					ReadTimeout:  config.ReadTimeout,
 					WriteTimeout: config.WriteTimeout,
+					dnsCache:     dnsCache,
 				}).DialContext,
+				Proxy:                 proxy,
 				MaxIdleConns:          config.MaxIdleConns,
 				MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
 				MaxConnsPerHost:       config.MaxConnsPerHost,
@@ -89,7 +130,9 @@ func NewDefaultTransport(config *TransportConfig) *DefaultTransport {
 				TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
 				ResponseHeaderTimeout: config.ResponseHeaderTimeout,
 				ExpectContinueTimeout: config.ExpectContinueTimeout,
-				DisableCompression:    true,
+				DisableCompression:    !config.EnableCompression,
+				ReadBufferSize:        config.ReadBufferSize,
+				WriteBufferSize:       config.WriteBufferSize,
 				// #nosec G402
 				TLSClientConfig: &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify},
 			},
@@ -115,7 +158,12 @@ func NewDefaultTransportWithClient(client http.Client) *DefaultTransport {
 }
 
 func (dt *DefaultTransport) RoundTrip(ctx context.Context, req *Request) (*Response, error) {
-	hr, err := http.NewRequestWithContext(ctx, req.Method, req.URL(), req.Content)
+	body := req.Content
+	if rc, ok := body.(io.ReadCloser); ok {
+		// content is owned by the caller and rewound on retrying, so it's not closed by http.Client
+		body = ioutil.NopCloser(rc)
+	}
+	hr, err := http.NewRequestWithContext(ctx, req.Method, req.URL(), body)
 	if err != nil {
 		return nil, newTosClientError(err.Error(), err)
 	}
@@ -128,6 +176,19 @@ func (dt *DefaultTransport) RoundTrip(ctx context.Context, req *Request) (*Respo
 		hr.Header[key] = values
 	}
 
+	var (
+		proxyURL *url.URL
+		gotConn  int32
+	)
+	if dt.proxy != nil {
+		if proxyURL, _ = dt.proxy(hr); proxyURL != nil {
+			ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+				GotConn: func(httptrace.GotConnInfo) { atomic.StoreInt32(&gotConn, 1) },
+			})
+			hr = hr.WithContext(ctx)
+		}
+	}
+
 	var accessLog *accessLogRequest
 	if dt.logger != nil {
 		var trace *httptrace.ClientTrace
@@ -142,6 +203,10 @@ func (dt *DefaultTransport) RoundTrip(ctx context.Context, req *Request) (*Respo
 	}
 
 	if err != nil {
+		if proxyURL != nil && atomic.LoadInt32(&gotConn) == 0 && ctx.Err() == nil {
+			// failed to connect the endpoint through the proxy
+			err = &ProxyError{Proxy: proxyURL.Scheme + "://" + proxyURL.Host, Err: err}
+		}
 		return nil, newTosClientError(err.Error(), err)
 	}
 
@@ -198,10 +263,19 @@ type TimeoutDialer struct {
 	net.Dialer
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	dnsCache     *dnsCache // nullable, set if EnableDNSCache of TransportConfig is true
 }
 
 func (d *TimeoutDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
-	conn, err := d.Dialer.DialContext(ctx, network, address)
+	var (
+		conn net.Conn
+		err  error
+	)
+	if d.dnsCache != nil {
+		conn, err = d.dnsCache.DialContext(ctx, &d.Dialer, network, address)
+	} else {
+		conn, err = d.Dialer.DialContext(ctx, network, address)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/policy"
 )
 
 type Grantee struct {
@@ -84,8 +85,8 @@ type PutObjectACLInput struct {
 	GrantReadAcp     string       `location:"header" locationName:"X-Tos-Grant-Read-Acp"`     // optional
 	GrantWrite       string       `location:"header" locationName:"X-Tos-Grant-Write"`        // optional
 	GrantWriteAcp    string       `location:"header" locationName:"X-Tos-Grant-Write-Acp"`    // optional
-	Owner            Owner
-	Grants           []GrantV2
+	Owner            Owner        // owner of the object, required if Grants is set
+	Grants           []GrantV2    // explicit grants, can not be set with ACL or grant headers
 }
 
 type PutObjectAclOutput struct {
@@ -96,89 +97,826 @@ type PutObjectACLOutput struct {
 	PutObjectAclOutput
 }
 
+type GetBucketACLInput struct {
+	Bucket string
+}
+
+type GetBucketACLOutput struct {
+	RequestInfo `json:"-"`
+	Owner       Owner     `json:"Owner,omitempty"`
+	Grants      []GrantV2 `json:"Grants,omitempty"`
+	// BucketAclDelivered 为 true 时桶内对象继承桶的 ACL
+	BucketAclDelivered bool `json:"BucketAclDelivered,omitempty"`
+}
+
+// PutBucketACLInput ACL and Grants can not set both.
+type PutBucketACLInput struct {
+	Bucket             string
+	ACL                enum.ACLType `location:"header" locationName:"X-Tos-Acl"` // canned ACL, optional
+	Owner              Owner        // owner of the bucket, required if Grants is set
+	Grants             []GrantV2    // explicit grants, optional
+	BucketAclDelivered bool         // objects inherit the bucket ACL, only used with Grants
+}
+
+type PutBucketACLOutput struct {
+	RequestInfo `json:"-"`
+}
+
 type PreSignedURLInput struct {
-	HTTPMethod enum.HttpMethodType
+	HTTPMethod enum.HttpMethodType // default GET
 	Bucket     string
 	Key        string
 	Expires    int64 // Expiration time in seconds, default 3600 seconds, max 7 days, range [1, 604800]
 	Header     map[string]string
 	Query      map[string]string
+	Process    string // data process parameters, signed as query x-tos-process, e.g. image/resize,w_100
+	// AlternativeEndpoint is used instead of the endpoint of client, e.g. a custom domain, optional
+	AlternativeEndpoint string
+	// IsCustomDomain means AlternativeEndpoint is a custom domain bound to the bucket,
+	// and the bucket is not in the host or path of the URL
+	IsCustomDomain bool
+	// SignHeaderRule is merged with SignHeaderRule of client, e.g. exclude headers added by browsers
+	SignHeaderRule SignHeaderRule
+}
+
+type PreSignedURLOutput struct {
+	SignedUrl    string            //  Pre-signed URL
+	SignedHeader map[string]string // The actual header fields contained in the pre-signature
+}
+
+type PreSignedMultipartUploadURLsInput struct {
+	Bucket    string
+	Key       string
+	UploadID  string
+	PartCount int   // number of parts to upload, range [1, 10000]
+	Expires   int64 // Expiration time in seconds, default 3600 seconds, max 7 days, range [1, 604800]
+	// AlternativeEndpoint and IsCustomDomain are the same as PreSignedURLInput
+	AlternativeEndpoint string
+	IsCustomDomain      bool
+}
+
+type PreSignedMultipartUploadURLsOutput struct {
+	PartURLs    []string // PUT URL of part N is PartURLs[N-1]
+	CompleteURL string   // POST URL to complete the multipart upload
+	AbortURL    string   // DELETE URL to abort the multipart upload
+}
+
+// PolicySignatureCondition is a condition of object key in policy of PreSignedPolicyURL.
+// Key must be "key", Operator is empty for exact match, or "starts-with" to match the prefix.
+type PolicySignatureCondition struct {
+	Key      string
+	Value    string
+	Operator string
+}
+
+type PreSignedPolicyURLInput struct {
+	Bucket     string
+	Expires    int64 // Expiration time in seconds, default 3600 seconds, max 7 days, range [1, 604800]
+	Conditions []PolicySignatureCondition
+	// AlternativeEndpoint and IsCustomDomain are the same as PreSignedURLInput
+	AlternativeEndpoint string
+	IsCustomDomain      bool
+}
+
+// PreSignedPolicyURLOutput use GetSignedURLForList and GetSignedURLForGetOrHead to get URLs signed by the policy
+type PreSignedPolicyURLOutput struct {
+	SignatureQuery string // query of signature, shared by all URLs
+	scheme         string
+	host           string
+	bucket         string
+	urlMode        urlMode
+}
+
+// PostSignatureCondition is a condition of POST policy.
+// Operator is empty for exact match, or "starts-with" to match the prefix;
+// use NewPostSignatureCondition and NewPostSignatureStartsWithCondition to create it.
+type PostSignatureCondition struct {
+	Key      string // form field name without "$", e.g. "key", "Content-Type", "x-tos-meta-xxx"
+	Value    string
+	Operator string
+}
+
+// ContentLengthRange limits size of the uploaded content, both RangeStart and RangeEnd are inclusive
+type ContentLengthRange struct {
+	RangeStart int64
+	RangeEnd   int64
+}
+
+type PreSignedPostSignatureInput struct {
+	Bucket             string
+	Key                string // exact key of the uploaded object, optional, use starts-with condition of "key" to limit key prefix
+	Expires            int64  // Expiration time in seconds, default 3600 seconds, max 7 days, range [1, 604800]
+	Conditions         []PostSignatureCondition
+	ContentLengthRange *ContentLengthRange
+}
+
+type PreSignedPostSignatureOutput struct {
+	PostURL       string // URL the form is posted to
+	OriginPolicy  string // policy document in JSON
+	Policy        string // base64 encoded policy document, form field "policy"
+	Algorithm     string // form field "x-tos-algorithm"
+	Credential    string // form field "x-tos-credential"
+	Date          string // form field "x-tos-date"
+	Signature     string // form field "x-tos-signature"
+	SecurityToken string // form field "x-tos-security-token", empty if the credentials has no security token
+}
+
+type CreateBucketInput struct {
+	Bucket           string `json:"Bucket,omitempty"`           // required
+	ACL              string `json:"ACL,omitempty"`              // optional
+	GrantFullControl string `json:"GrantFullControl,omitempty"` // optional
+	GrantRead        string `json:"GrantRead,omitempty"`        // optional
+	GrantReadAcp     string `json:"GrantReadAcp,omitempty"`     // optional
+	GrantWrite       string `json:"GrantWrite,omitempty"`       // optional
+	GrantWriteAcp    string `json:"GrantWriteAcp,omitempty"`    // optional
+}
+
+type CreateBucketV2Input struct {
+	Bucket           string                // required
+	ACL              enum.ACLType          `location:"header" locationName:"X-Tos-Acl"`                // optional
+	GrantFullControl string                `location:"header" locationName:"X-Tos-Grant-Full-Control"` // optional
+	GrantRead        string                `location:"header" locationName:"X-Tos-Grant-Read"`         // optional
+	GrantReadAcp     string                `location:"header" locationName:"X-Tos-Grant-Read-Acp"`     // optional
+	GrantWrite       string                `location:"header" locationName:"X-Tos-Grant-Write"`        // optional
+	GrantWriteAcp    string                `location:"header" locationName:"X-Tos-Grant-Write-Acp"`    // optional
+	StorageClass     enum.StorageClassType `location:"header" locationName:"X-Tos-Storage-Class"`      // setting the default storage type for buckets
+	AzRedundancy     enum.AzRedundancyType `location:"header" locationName:"X-Tos-Az-Redundancy"`      // setting the AZ type for buckets
+	ProjectName      string                `location:"header" locationName:"X-Tos-Project-Name"`       // project the bucket belongs to, "default" if it is not set
+	BucketType       enum.BucketType       `location:"header" locationName:"X-Tos-Bucket-Type"`        // "fns" by default, or "hns"
+}
+
+type CreateBucketOutput struct {
+	RequestInfo `json:"-"`
+	Location    string `json:"Location,omitempty"`
+}
+
+type CreateBucketV2Output struct {
+	CreateBucketOutput
+}
+
+type HeadBucketOutput struct {
+	RequestInfo  `json:"-"`
+	Region       string                `json:"Region,omitempty"`
+	StorageClass enum.StorageClassType `json:"StorageClass,omitempty"`
+	AzRedundancy enum.AzRedundancyType `json:"AzRedundancy,omitempty"`
+	ProjectName  string                `json:"ProjectName,omitempty"`
+	BucketType   enum.BucketType       `json:"BucketType,omitempty"`
+}
+
+type GetBucketCORSInput struct {
+	Bucket string
+}
+
+type CorsRule struct {
+	AllowedOrigin []string `json:"AllowedOrigins,omitempty"`
+	AllowedMethod []string `json:"AllowedMethods,omitempty"`
+	AllowedHeader []string `json:"AllowedHeaders,omitempty"`
+	ExposeHeader  []string `json:"ExposeHeaders,omitempty"`
+	MaxAgeSeconds int      `json:"MaxAgeSeconds,omitempty"`
+}
+
+type GetBucketCORSOutput struct {
+	RequestInfo `json:"-"`
+	CORSRules   []CorsRule `json:"CORSRules,omitempty"`
+}
+
+type PutBucketCORSInput struct {
+	Bucket    string     `json:"-"`
+	CORSRules []CorsRule `json:"CORSRules,omitempty"`
+}
+
+type PutBucketCORSOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type DeleteBucketCORSInput struct {
+	Bucket string
+}
+
+type DeleteBucketCORSOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type PutBucketPolicyV2Input struct {
+	Bucket string
+	// Policy json 格式的 policy 文档, 与 Document 二选一
+	Policy string
+	// Document 结构化的 policy 文档, 与 Policy 二选一
+	Document *policy.Rules
+}
+
+type PutBucketPolicyV2Output struct {
+	RequestInfo
+}
+
+type GetBucketPolicyV2Input struct {
+	Bucket string
+}
+
+type GetBucketPolicyV2Output struct {
+	RequestInfo
+	// Policy is the raw json policy document, use Document to parse it
+	Policy string
+}
+
+type DeleteBucketPolicyV2Input struct {
+	Bucket string
+}
+
+type DeleteBucketPolicyV2Output struct {
+	RequestInfo
+}
+
+type MirrorBackCondition struct {
+	// HttpCode 触发回源的状态码, 目前仅支持 404
+	HttpCode  int64  `json:"HttpCode"`
+	KeyPrefix string `json:"KeyPrefix,omitempty"`
+	KeySuffix string `json:"KeySuffix,omitempty"`
+}
+
+type MirrorSourceEndpoint struct {
+	Primary  []string `json:"Primary,omitempty"`
+	Follower []string `json:"Follower,omitempty"`
+}
+
+type MirrorPublicSource struct {
+	SourceEndpoint MirrorSourceEndpoint `json:"SourceEndpoint"`
+	// FixedEndpoint 为 true 时仅回源到 Primary, 不在源站之间轮询
+	FixedEndpoint bool `json:"FixedEndpoint,omitempty"`
+}
+
+type MirrorHeaderKeyValue struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+type MirrorHeader struct {
+	// PassAll 透传客户端所有请求头到源站
+	PassAll bool `json:"PassAll,omitempty"`
+	// Pass 透传的请求头
+	Pass []string `json:"Pass,omitempty"`
+	// Remove 不透传的请求头
+	Remove []string `json:"Remove,omitempty"`
+	// Set 固定设置的请求头
+	Set []MirrorHeaderKeyValue `json:"Set,omitempty"`
+}
+
+type MirrorReplaceKeyPrefix struct {
+	KeyPrefix   string `json:"KeyPrefix,omitempty"`
+	ReplaceWith string `json:"ReplaceWith,omitempty"`
+}
+
+type MirrorTransform struct {
+	WithKeyPrefix    string                 `json:"WithKeyPrefix,omitempty"`
+	WithKeySuffix    string                 `json:"WithKeySuffix,omitempty"`
+	ReplaceKeyPrefix MirrorReplaceKeyPrefix `json:"ReplaceKeyPrefix"`
+}
+
+type MirrorRedirect struct {
+	RedirectType enum.RedirectType `json:"RedirectType"`
+	// FetchSourceOnRedirect 源站返回 3xx 时是否继续回源
+	FetchSourceOnRedirect bool `json:"FetchSourceOnRedirect,omitempty"`
+	// PassQuery 回源时是否携带请求的 query string
+	PassQuery bool `json:"PassQuery,omitempty"`
+	// FollowRedirect 源站返回 3xx 时是否跟随跳转
+	FollowRedirect bool               `json:"FollowRedirect,omitempty"`
+	MirrorHeader   MirrorHeader       `json:"MirrorHeader"`
+	PublicSource   MirrorPublicSource `json:"PublicSource"`
+	Transform      MirrorTransform    `json:"Transform"`
+}
+
+type MirrorBackRule struct {
+	ID        string              `json:"ID,omitempty"`
+	Condition MirrorBackCondition `json:"Condition"`
+	Redirect  MirrorRedirect      `json:"Redirect"`
+}
+
+type PutBucketMirrorBackInput struct {
+	Bucket string           `json:"-"`
+	Rules  []MirrorBackRule `json:"Rules"`
+}
+
+type PutBucketMirrorBackOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type GetBucketMirrorBackInput struct {
+	Bucket string
+}
+
+type GetBucketMirrorBackOutput struct {
+	RequestInfo `json:"-"`
+	Rules       []MirrorBackRule `json:"Rules,omitempty"`
+}
+
+type DeleteBucketMirrorBackInput struct {
+	Bucket string
+}
+
+type DeleteBucketMirrorBackOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type PutBucketVersioningInput struct {
+	Bucket string                    `json:"-"`
+	Status enum.VersioningStatusType `json:"Status"`
+}
+
+type PutBucketVersioningOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type GetBucketVersioningV2Input struct {
+	Bucket string
+}
+
+type GetBucketVersioningV2Output struct {
+	RequestInfo `json:"-"`
+	// Status is VersioningStatusNotSet if versioning has never been enabled
+	Status enum.VersioningStatusType `json:"Status,omitempty"`
+}
+
+type WaitBucketVersioningInput struct {
+	Bucket string
+	// Status 期望的多版本状态
+	Status enum.VersioningStatusType
+	// Timeout 最长等待时间, 默认 60s
+	Timeout time.Duration
+	// Interval 轮询间隔, 默认 1s
+	Interval time.Duration
+}
+
+type IndexDocument struct {
+	Suffix string `json:"Suffix"`
+	// ForbiddenSubDir 为 true 时访问 "dir/" 不返回 "dir/" + Suffix
+	ForbiddenSubDir bool `json:"ForbiddenSubDir"`
+}
+
+type ErrorDocument struct {
+	Key string `json:"Key,omitempty"`
+}
+
+type RedirectAllRequestsTo struct {
+	HostName string            `json:"HostName"`
+	Protocol enum.ProtocolType `json:"Protocol,omitempty"`
+}
+
+type RoutingRuleCondition struct {
+	KeyPrefixEquals             string `json:"KeyPrefixEquals,omitempty"`
+	HttpErrorCodeReturnedEquals int    `json:"HttpErrorCodeReturnedEquals,omitempty"`
+}
+
+type RoutingRuleRedirect struct {
+	Protocol             enum.ProtocolType `json:"Protocol,omitempty"`
+	HostName             string            `json:"HostName,omitempty"`
+	ReplaceKeyPrefixWith string            `json:"ReplaceKeyPrefixWith,omitempty"`
+	ReplaceKeyWith       string            `json:"ReplaceKeyWith,omitempty"`
+	HttpRedirectCode     int               `json:"HttpRedirectCode,omitempty"`
+}
+
+type RoutingRule struct {
+	Condition RoutingRuleCondition `json:"Condition"`
+	Redirect  RoutingRuleRedirect  `json:"Redirect"`
+}
+
+type PutBucketWebsiteInput struct {
+	Bucket string `json:"-"`
+	// RedirectAllRequestsTo 与 IndexDocument, ErrorDocument, RoutingRules 互斥
+	RedirectAllRequestsTo *RedirectAllRequestsTo `json:"RedirectAllRequestsTo,omitempty"`
+	IndexDocument         *IndexDocument         `json:"IndexDocument,omitempty"`
+	ErrorDocument         *ErrorDocument         `json:"ErrorDocument,omitempty"`
+	RoutingRules          []RoutingRule          `json:"RoutingRules,omitempty"`
+}
+
+type PutBucketWebsiteOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type GetBucketWebsiteInput struct {
+	Bucket string
+}
+
+type GetBucketWebsiteOutput struct {
+	RequestInfo           `json:"-"`
+	RedirectAllRequestsTo *RedirectAllRequestsTo `json:"RedirectAllRequestsTo,omitempty"`
+	IndexDocument         *IndexDocument         `json:"IndexDocument,omitempty"`
+	ErrorDocument         *ErrorDocument         `json:"ErrorDocument,omitempty"`
+	RoutingRules          []RoutingRule          `json:"RoutingRules,omitempty"`
+}
+
+type DeleteBucketWebsiteInput struct {
+	Bucket string
+}
+
+type DeleteBucketWebsiteOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type NotificationFilterRule struct {
+	Name  enum.FilterRuleNameType `json:"Name"`
+	Value string                  `json:"Value"`
+}
+
+type NotificationFilterKey struct {
+	FilterRules []NotificationFilterRule `json:"FilterRules,omitempty"`
+}
+
+type NotificationFilter struct {
+	TOSKey NotificationFilterKey `json:"TOSKey"`
+}
+
+type CloudFunctionConfiguration struct {
+	ID     string             `json:"RuleId,omitempty"`
+	Events []enum.EventType   `json:"Events"`
+	Filter NotificationFilter `json:"Filter"`
+	// CloudFunction 函数服务的函数 ID
+	CloudFunction string `json:"CloudFunction"`
+}
+
+type RocketMQConf struct {
+	InstanceID  string `json:"InstanceId"`
+	Topic       string `json:"Topic"`
+	AccessKeyID string `json:"AccessKeyId,omitempty"`
+}
+
+type RocketMQConfiguration struct {
+	ID     string             `json:"RuleId,omitempty"`
+	Events []enum.EventType   `json:"Events"`
+	Filter NotificationFilter `json:"Filter"`
+	// Role TOS 投递消息时扮演的角色 trn
+	Role     string       `json:"Role"`
+	RocketMQ RocketMQConf `json:"RocketMQ"`
+}
+
+type PutBucketNotificationInput struct {
+	Bucket                      string                       `json:"-"`
+	CloudFunctionConfigurations []CloudFunctionConfiguration `json:"CloudFunctionConfigurations,omitempty"`
+	RocketMQConfigurations      []RocketMQConfiguration      `json:"RocketMQConfigurations,omitempty"`
+}
+
+type PutBucketNotificationOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type GetBucketNotificationInput struct {
+	Bucket string
+}
+
+type GetBucketNotificationOutput struct {
+	RequestInfo                 `json:"-"`
+	CloudFunctionConfigurations []CloudFunctionConfiguration `json:"CloudFunctionConfigurations,omitempty"`
+	RocketMQConfigurations      []RocketMQConfiguration      `json:"RocketMQConfigurations,omitempty"`
+}
+
+type DestinationVeFaaS struct {
+	FunctionID string `json:"FunctionId"`
+}
+
+type DestinationRocketMQ struct {
+	Role        string `json:"Role"`
+	InstanceID  string `json:"InstanceId"`
+	Topic       string `json:"Topic"`
+	AccessKeyID string `json:"AccessKeyId,omitempty"`
+	Region      string `json:"Region,omitempty"`
+}
+
+type NotificationDestination struct {
+	VeFaaS   []DestinationVeFaaS   `json:"VeFaaS,omitempty"`
+	RocketMQ []DestinationRocketMQ `json:"RocketMQ,omitempty"`
+}
+
+type NotificationRule struct {
+	RuleID      string                  `json:"RuleId"`
+	Events      []enum.EventType        `json:"Events"`
+	Filter      NotificationFilter      `json:"Filter"`
+	Destination NotificationDestination `json:"Destination"`
+}
+
+type PutBucketNotificationType2Input struct {
+	Bucket  string             `json:"-"`
+	Rules   []NotificationRule `json:"Rules"`
+	Version string             `json:"Version,omitempty"`
+}
+
+type PutBucketNotificationType2Output struct {
+	RequestInfo `json:"-"`
+}
+
+type GetBucketNotificationType2Input struct {
+	Bucket string
+}
+
+type GetBucketNotificationType2Output struct {
+	RequestInfo `json:"-"`
+	Rules       []NotificationRule `json:"Rules,omitempty"`
+	Version     string             `json:"Version,omitempty"`
+}
+
+type AccessLogConfiguration struct {
+	// UseServiceTopic 为 true 时由 TOS 创建日志服务的 project 和 topic, 其 ID 通过 GetBucketRealTimeLog 获取
+	UseServiceTopic bool   `json:"UseServiceTopic"`
+	TLSProjectID    string `json:"TLSProjectID,omitempty"`
+	TLSTopicID      string `json:"TLSTopicID,omitempty"`
+}
+
+type RealTimeLogConfiguration struct {
+	// Role TOS 投递日志时扮演的角色名
+	Role          string                 `json:"Role"`
+	Configuration AccessLogConfiguration `json:"Configuration"`
+}
+
+type PutBucketRealTimeLogInput struct {
+	Bucket        string                   `json:"-"`
+	Configuration RealTimeLogConfiguration `json:"RealTimeLogConfiguration"`
+}
+
+type PutBucketRealTimeLogOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type GetBucketRealTimeLogInput struct {
+	Bucket string
+}
+
+type GetBucketRealTimeLogOutput struct {
+	RequestInfo   `json:"-"`
+	Configuration RealTimeLogConfiguration `json:"RealTimeLogConfiguration"`
+}
+
+type DeleteBucketRealTimeLogInput struct {
+	Bucket string
+}
+
+type DeleteBucketRealTimeLogOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type CustomDomainRule struct {
+	Domain string `json:"Domain"`
+	// CertID 证书 ID, 为空时仅支持 http 访问
+	CertID string `json:"CertId,omitempty"`
+}
+
+type ListedCustomDomainRule struct {
+	CustomDomainRule
+	Cname string `json:"Cname,omitempty"`
+	// Forbidden 为 true 时域名被禁用, 原因见 ForbiddenReason
+	Forbidden       bool      `json:"Forbidden"`
+	ForbiddenReason string    `json:"ForbiddenReason,omitempty"`
+	CertStatus      string    `json:"CertStatus,omitempty"`
+	CertExpiration  time.Time `json:"CertExpiration"`
+}
+
+type PutBucketCustomDomainInput struct {
+	Bucket string           `json:"-"`
+	Rule   CustomDomainRule `json:"CustomDomainRule"`
+}
+
+type PutBucketCustomDomainOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type ListBucketCustomDomainInput struct {
+	Bucket string
+}
+
+type ListBucketCustomDomainOutput struct {
+	RequestInfo `json:"-"`
+	Rules       []ListedCustomDomainRule `json:"CustomDomainRules"`
+}
+
+type DeleteBucketCustomDomainInput struct {
+	Bucket string
+	Domain string
+}
+
+type DeleteBucketCustomDomainOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type PutBucketStorageClassInput struct {
+	Bucket       string
+	StorageClass enum.StorageClassType `location:"header" locationName:"X-Tos-Storage-Class"`
+}
+
+type PutBucketStorageClassOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type ApplyServerSideEncryptionByDefault struct {
+	// SSEAlgorithm "kms" 或 "AES256"
+	SSEAlgorithm string `json:"SSEAlgorithm"`
+	// KMSMasterKeyID 仅在 SSEAlgorithm 为 kms 时可设置, 为空时使用 TOS 默认的 KMS 密钥
+	KMSMasterKeyID string `json:"KMSMasterKeyID,omitempty"`
+}
+
+type BucketEncryptionRule struct {
+	ApplyServerSideEncryptionByDefault ApplyServerSideEncryptionByDefault `json:"ApplyServerSideEncryptionByDefault"`
+}
+
+type PutBucketEncryptionInput struct {
+	Bucket string               `json:"-"`
+	Rule   BucketEncryptionRule `json:"Rule"`
+}
+
+type PutBucketEncryptionOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type GetBucketEncryptionInput struct {
+	Bucket string
+}
+
+type GetBucketEncryptionOutput struct {
+	RequestInfo `json:"-"`
+	Rule        BucketEncryptionRule `json:"Rule"`
+}
+
+type DeleteBucketEncryptionInput struct {
+	Bucket string
+}
+
+type DeleteBucketEncryptionOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type InventoryFilter struct {
+	Prefix string `json:"Prefix,omitempty"`
+}
+
+type TOSBucketDestination struct {
+	Format enum.InventoryFormatType `json:"Format"`
+	// AccountID 目标桶所属账号
+	AccountID string `json:"AccountId"`
+	// Role TOS 写入清单文件时扮演的角色名
+	Role   string `json:"Role"`
+	Bucket string `json:"Bucket"`
+	Prefix string `json:"Prefix,omitempty"`
+}
+
+type InventoryDestination struct {
+	TOSBucketDestination TOSBucketDestination `json:"TOSBucketDestination"`
+}
+
+type InventorySchedule struct {
+	Frequency enum.InventoryFrequencyType `json:"Frequency"`
+}
+
+type InventoryOptionalFields struct {
+	Field []enum.InventoryOptionalFieldType `json:"Field,omitempty"`
+}
+
+type BucketInventoryConfiguration struct {
+	ID                     string                                   `json:"Id"`
+	IsEnabled              bool                                     `json:"IsEnabled"`
+	Filter                 *InventoryFilter                         `json:"Filter,omitempty"`
+	Destination            InventoryDestination                     `json:"Destination"`
+	Schedule               InventorySchedule                        `json:"Schedule"`
+	IncludedObjectVersions enum.InventoryIncludedObjectVersionsType `json:"IncludedObjectVersions"`
+	OptionalFields         *InventoryOptionalFields                 `json:"OptionalFields,omitempty"`
+}
+
+type PutBucketInventoryInput struct {
+	Bucket string `json:"-"`
+	BucketInventoryConfiguration
+}
+
+type PutBucketInventoryOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type GetBucketInventoryInput struct {
+	Bucket string
+	ID     string
+}
+
+type GetBucketInventoryOutput struct {
+	RequestInfo `json:"-"`
+	BucketInventoryConfiguration
+}
+
+type ListBucketInventoryInput struct {
+	Bucket            string
+	ContinuationToken string `location:"query" locationName:"continuation-token"`
 }
 
-type PreSignedURLOutput struct {
-	SignedUrl    string            //  Pre-signed URL
-	SignedHeader map[string]string // The actual header fields contained in the pre-signature
+type ListBucketInventoryOutput struct {
+	RequestInfo           `json:"-"`
+	Configurations        []BucketInventoryConfiguration `json:"InventoryConfigurations,omitempty"`
+	IsTruncated           bool                           `json:"IsTruncated"`
+	NextContinuationToken string                         `json:"NextContinuationToken,omitempty"`
 }
 
-type CreateBucketInput struct {
-	Bucket           string `json:"Bucket,omitempty"`           // required
-	ACL              string `json:"ACL,omitempty"`              // optional
-	GrantFullControl string `json:"GrantFullControl,omitempty"` // optional
-	GrantRead        string `json:"GrantRead,omitempty"`        // optional
-	GrantReadAcp     string `json:"GrantReadAcp,omitempty"`     // optional
-	GrantWrite       string `json:"GrantWrite,omitempty"`       // optional
-	GrantWriteAcp    string `json:"GrantWriteAcp,omitempty"`    // optional
+type DeleteBucketInventoryInput struct {
+	Bucket string
+	ID     string
 }
 
-type CreateBucketV2Input struct {
-	Bucket           string                // required
-	ACL              enum.ACLType          `location:"header" locationName:"X-Tos-Acl"`                // optional
-	GrantFullControl string                `location:"header" locationName:"X-Tos-Grant-Full-Control"` // optional
-	GrantRead        string                `location:"header" locationName:"X-Tos-Grant-Read"`         // optional
-	GrantReadAcp     string                `location:"header" locationName:"X-Tos-Grant-Read-Acp"`     // optional
-	GrantWrite       string                `location:"header" locationName:"X-Tos-Grant-Write"`        // optional
-	GrantWriteAcp    string                `location:"header" locationName:"X-Tos-Grant-Write-Acp"`    // optional
-	StorageClass     enum.StorageClassType `location:"header" locationName:"X-Tos-Storage-Class"`      // setting the default storage type for buckets
-	AzRedundancy     enum.AzRedundancyType `location:"header" locationName:"X-Tos-Az-Redundancy"`      // setting the AZ type for buckets
+type DeleteBucketInventoryOutput struct {
+	RequestInfo `json:"-"`
 }
 
-type CreateBucketOutput struct {
+type PutBucketRequestPaymentInput struct {
+	Bucket string         `json:"-"`
+	Payer  enum.PayerType `json:"Payer"`
+}
+
+type PutBucketRequestPaymentOutput struct {
 	RequestInfo `json:"-"`
-	Location    string `json:"Location,omitempty"`
 }
 
-type CreateBucketV2Output struct {
-	CreateBucketOutput
+type GetBucketRequestPaymentInput struct {
+	Bucket string
 }
 
-type HeadBucketOutput struct {
+type GetBucketRequestPaymentOutput struct {
+	RequestInfo `json:"-"`
+	Payer       enum.PayerType `json:"Payer"`
+}
+
+type PutBucketRenameInput struct {
+	Bucket       string `json:"-"`
+	RenameEnable bool   `json:"RenameEnable"`
+}
+
+type PutBucketRenameOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type GetBucketRenameInput struct {
+	Bucket string
+}
+
+type GetBucketRenameOutput struct {
 	RequestInfo  `json:"-"`
-	Region       string                `json:"Region,omitempty"`
-	StorageClass enum.StorageClassType `json:"StorageClass,omitempty"`
+	RenameEnable bool `json:"RenameEnable"`
 }
 
-type GetBucketCORSInput struct {
+type RenameObjectInput struct {
 	Bucket string
+	Key    string
+	// NewKey 重命名后的对象名, 已存在时被覆盖
+	NewKey string
 }
 
-type CorsRule struct {
-	AllowedOrigin []string `json:"AllowedOrigins,omitempty"`
-	AllowedMethod []string `json:"AllowedMethods,omitempty"`
-	AllowedHeader []string `json:"AllowedHeaders,omitempty"`
-	ExposeHeader  []string `json:"ExposeHeaders,omitempty"`
-	MaxAgeSeconds int      `json:"MaxAgeSeconds,omitempty"`
+type RenameObjectOutput struct {
+	RequestInfo
 }
 
-type GetBucketCORSOutput struct {
+type PutBucketAccessMonitorInput struct {
+	Bucket string          `json:"-"`
+	Status enum.StatusType `json:"Status"`
+}
+
+type PutBucketAccessMonitorOutput struct {
 	RequestInfo `json:"-"`
-	CORSRules   []CorsRule `json:"CORSRules,omitempty"`
 }
 
-type PutBucketCORSInput struct {
-	Bucket    string     `json:"-"`
-	CORSRules []CorsRule `json:"CORSRules,omitempty"`
+type GetBucketAccessMonitorInput struct {
+	Bucket string
 }
 
-type PutBucketCORSOutput struct {
+type GetBucketAccessMonitorOutput struct {
 	RequestInfo `json:"-"`
+	Status      enum.StatusType `json:"Status"`
 }
 
-type DeleteBucketCORSInput struct {
+// IntelligentTieringTransition objects not accessed for Days are moved to AccessTier
+type IntelligentTieringTransition struct {
+	Days       int                 `json:"Days"`
+	AccessTier enum.AccessTierType `json:"AccessTier"`
+}
+
+type PutBucketIntelligentTieringInput struct {
+	Bucket      string                         `json:"-"`
+	Status      enum.StatusType                `json:"Status"`
+	Transitions []IntelligentTieringTransition `json:"Transitions,omitempty"` // default thresholds of server are used if it is empty
+}
+
+type PutBucketIntelligentTieringOutput struct {
+	RequestInfo `json:"-"`
+}
+
+type GetBucketIntelligentTieringInput struct {
 	Bucket string
 }
 
-type DeleteBucketCORSOutput struct {
+type GetBucketIntelligentTieringOutput struct {
 	RequestInfo `json:"-"`
+	Status      enum.StatusType                `json:"Status"`
+	Transitions []IntelligentTieringTransition `json:"Transitions,omitempty"`
+	EnabledTime time.Time                      `json:"EnabledTime"` // when intelligent tiering was last enabled, zero if it never was
+}
+
+type GetBucketLocationInput struct {
+	Bucket string
+}
+
+type GetBucketLocationOutput struct {
+	RequestInfo      `json:"-"`
+	Region           string `json:"Region"`
+	ExtranetEndpoint string `json:"ExtranetEndpoint"`
+	IntranetEndpoint string `json:"IntranetEndpoint"`
 }
 
 type HeadBucketInput struct {
@@ -209,18 +947,24 @@ type Owner struct {
 }
 
 type ListedBucket struct {
-	CreationDate     string `json:"CreationDate,omitempty"`
-	Name             string `json:"Name,omitempty"`
-	Location         string `json:"Location,omitempty"`
-	ExtranetEndpoint string `json:"ExtranetEndpoint,omitempty"`
-	IntranetEndpoint string `json:"IntranetEndpoint,omitempty"`
+	CreationDate     string          `json:"CreationDate,omitempty"`
+	Name             string          `json:"Name,omitempty"`
+	Location         string          `json:"Location,omitempty"`
+	ExtranetEndpoint string          `json:"ExtranetEndpoint,omitempty"`
+	IntranetEndpoint string          `json:"IntranetEndpoint,omitempty"`
+	ProjectName      string          `json:"ProjectName,omitempty"`
+	BucketType       enum.BucketType `json:"BucketType,omitempty"`
+	CreationTime     time.Time       `json:"-"` // CreationDate parsed by ListBuckets of ClientV2
 }
 
-type ListBucketsInput struct{}
+type ListBucketsInput struct {
+	ProjectName string `location:"header" locationName:"X-Tos-Project-Name"` // list buckets of the project only, ignored by Client
+}
 
 type PutObjectBasicInput struct {
 	Bucket             string
 	Key                string
+	RequestPayer       string       `location:"header" locationName:"X-Tos-Request-Payer"` // RequestPayerRequester for requester-pays bucket
 	ContentLength      int64        `location:"header" locationName:"Content-Length"`
 	ContentMD5         string       `location:"header" locationName:"Content-MD5"`
 	ContentSHA256      string       `location:"header" locationName:"X-Tos-Content-Sha256"`
@@ -242,10 +986,19 @@ type PutObjectBasicInput struct {
 	SSECAlgorithm           string                `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Algorithm"`
 	SSECKey                 string                `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key"`
 	SSECKeyMD5              string                `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key-MD5"`
-	ServerSideEncryption    string                `location:"header" locationName:"X-Tos-Server-Side-Encryption"`
-	Meta                    map[string]string     `location:"headers"`
-	DataTransferListener    DataTransferListener
-	RateLimiter             RateLimiter
+	ServerSideEncryption    string                `location:"header" locationName:"X-Tos-Server-Side-Encryption"` // "AES256" or "kms"
+	// KMS key ID when ServerSideEncryption is "kms", the default key is used if it is empty
+	ServerSideEncryptionKeyID string            `location:"header" locationName:"X-Tos-Server-Side-Encryption-Kms-Key-Id"`
+	Tagging                   string            `location:"header" locationName:"X-Tos-Tagging"` // e.g. "k1=v1&k2=v2", see TagSet.Encode
+	Meta                      map[string]string `location:"headers"`
+	DataTransferListener      DataTransferListener
+	RateLimiter               RateLimiter
+	EnableContentMD5          bool // calculate Content-MD5 if it is empty, see WithEnableContentMD5
+	// send content in chunks with a trailing CRC64 checksum, for content of unknown length.
+	// Chunks and the trailer are signed with chained signatures if the client signs requests with SignV4,
+	// otherwise they are sent unsigned and the content is only checked with the trailing checksum
+	EnableTrailingChecksum bool
+	AllowChunked           bool // send content of unknown length with Transfer-Encoding: chunked, otherwise an error is returned
 }
 
 type PutObjectV2Input struct {
@@ -255,11 +1008,13 @@ type PutObjectV2Input struct {
 
 type PutObjectV2Output struct {
 	RequestInfo
-	ETag          string
-	SSECAlgorithm string
-	SSECKeyMD5    string
-	VersionID     string
-	HashCrc64ecma uint64
+	ETag                      string
+	SSECAlgorithm             string
+	SSECKeyMD5                string
+	ServerSideEncryption      string
+	ServerSideEncryptionKeyID string
+	VersionID                 string
+	HashCrc64ecma             uint64
 }
 
 type PutObjectOutput struct {
@@ -273,6 +1028,8 @@ type PutObjectOutput struct {
 type PutObjectFromFileInput struct {
 	PutObjectBasicInput
 	FilePath string
+	Offset   int64 // offset of the file to upload from
+	Length   int64 // length of the file to upload, to the end of the file if it is not set
 }
 
 type PutObjectFromFileOutput struct {
@@ -291,63 +1048,322 @@ type CommonHeaders struct {
 	Expires            time.Time    `location:"header" locationName:"Expires"`
 	ACL                enum.ACLType `location:"header" locationName:"X-Tos-Acl"`
 
-	GrantFullControl string `location:"header" locationName:"X-Tos-Grant-Full-Control"` // optional
-	GrantRead        string `location:"header" locationName:"X-Tos-Grant-Read"`         // optional
-	GrantReadAcp     string `location:"header" locationName:"X-Tos-Grant-Read-Acp"`     // optional
-	GrantWriteAcp    string `location:"header" locationName:"X-Tos-Grant-Write-Acp"`    // optional
+	GrantFullControl string `location:"header" locationName:"X-Tos-Grant-Full-Control"` // optional
+	GrantRead        string `location:"header" locationName:"X-Tos-Grant-Read"`         // optional
+	GrantReadAcp     string `location:"header" locationName:"X-Tos-Grant-Read-Acp"`     // optional
+	GrantWriteAcp    string `location:"header" locationName:"X-Tos-Grant-Write-Acp"`    // optional
+
+	WebsiteRedirectLocation string                `location:"header" locationName:"X-Tos-Website-Redirect-Location"`
+	StorageClass            enum.StorageClassType `location:"header" locationName:"X-Tos-Storage-Class"`
+}
+
+type SSEHeaders struct {
+	SSECAlgorithm        string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Algorithm"`
+	SSECKey              string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key"`
+	SSECKeyMD5           string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key-MD5"`
+	ServerSideEncryption string `location:"header" locationName:"X-Tos-Server-Side-Encryption"`
+	// KMS key ID when ServerSideEncryption is "kms"
+	ServerSideEncryptionKeyID string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Kms-Key-Id"`
+}
+
+type AppendObjectV2Input struct {
+	Bucket             string
+	Key                string
+	Offset             int64 `location:"query" locationName:"offset" default:"0"`
+	Content            io.Reader
+	ContentLength      int64        `location:"header" locationName:"Content-Length"`
+	ContentMD5         string       `location:"header" locationName:"Content-MD5"`
+	ContentSHA256      string       `location:"header" locationName:"X-Tos-Content-Sha256"`
+	CacheControl       string       `location:"header" locationName:"Cache-Control"`
+	ContentDisposition string       `location:"header" locationName:"Content-Disposition" encodeChinese:"true"`
+	ContentEncoding    string       `location:"header" locationName:"Content-Encoding"`
+	ContentLanguage    string       `location:"header" locationName:"Content-Language"`
+	ContentType        string       `location:"header" locationName:"Content-Type"`
+	Expires            time.Time    `location:"header" locationName:"Expires"`
+	ACL                enum.ACLType `location:"header" locationName:"X-Tos-Acl"`
+
+	GrantFullControl string `location:"header" locationName:"X-Tos-Grant-Full-Control"` // optional
+	GrantRead        string `location:"header" locationName:"X-Tos-Grant-Read"`         // optional
+	GrantReadAcp     string `location:"header" locationName:"X-Tos-Grant-Read-Acp"`     // optional
+	GrantWriteAcp    string `location:"header" locationName:"X-Tos-Grant-Write-Acp"`    // optional
+
+	WebsiteRedirectLocation string                `location:"header" locationName:"X-Tos-Website-Redirect-Location"`
+	StorageClass            enum.StorageClassType `location:"header" locationName:"X-Tos-Storage-Class"`
+
+	// 服务端加密，只在创建对象（Offset 为 0）时生效
+	ServerSideEncryption      string `location:"header" locationName:"X-Tos-Server-Side-Encryption"`
+	ServerSideEncryptionKeyID string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Kms-Key-Id"`
+
+	Meta                 map[string]string `location:"headers"`
+	DataTransferListener DataTransferListener
+	RateLimiter          RateLimiter
+	PreHashCrc64ecma     uint64
+}
+
+type AppendObjectOutput struct {
+	RequestInfo      `json:"-"`
+	ETag             string `json:"ETag,omitempty"`
+	NextAppendOffset int64  `json:"NextAppendOffset,omitempty"`
+}
+
+type AppendObjectV2Output struct {
+	RequestInfo      `json:"-"`
+	VersionID        string `json:"VersionID,omitempty"`
+	NextAppendOffset int64  `json:"NextAppendOffset,omitempty"`
+	HashCrc64ecma    uint64 `json:"HashCrc64Ecma,omitempty"`
+
+	ServerSideEncryption      string `json:"ServerSideEncryption,omitempty"`
+	ServerSideEncryptionKeyID string `json:"ServerSideEncryptionKeyID,omitempty"`
+}
+
+type ModifyObjectV2Input struct {
+	Bucket        string
+	Key           string
+	Offset        int64 `location:"query" locationName:"offset" default:"0"`
+	Content       io.Reader
+	ContentLength int64
+	TrafficLimit  int64 `location:"header" locationName:"X-Tos-Traffic-Limit"` // bandwidth limit of the request in bit/s, optional
+
+	DataTransferListener DataTransferListener
+	RateLimiter          RateLimiter
+}
+
+type ModifyObjectV2Output struct {
+	RequestInfo      `json:"-"`
+	NextModifyOffset int64  `json:"NextModifyOffset,omitempty"`
+	HashCrc64ecma    uint64 `json:"HashCrc64Ecma,omitempty"`
+}
+
+type FetchObjectV2Input struct {
+	Bucket        string
+	Key           string
+	URL           string // 源对象的 URL，必选
+	IgnoreSameKey bool   // 对象已存在时是否跳过，默认覆盖
+	ContentMD5    string // 源对象的 MD5，可选，不一致时抓取失败
+
+	ACL              enum.ACLType          `location:"header" locationName:"X-Tos-Acl"`
+	GrantFullControl string                `location:"header" locationName:"X-Tos-Grant-Full-Control"` // optional
+	GrantRead        string                `location:"header" locationName:"X-Tos-Grant-Read"`         // optional
+	GrantReadAcp     string                `location:"header" locationName:"X-Tos-Grant-Read-Acp"`     // optional
+	GrantWriteAcp    string                `location:"header" locationName:"X-Tos-Grant-Write-Acp"`    // optional
+	StorageClass     enum.StorageClassType `location:"header" locationName:"X-Tos-Storage-Class"`
+
+	SSECAlgorithm string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Algorithm"`
+	SSECKey       string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key"`
+	SSECKeyMD5    string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key-MD5"`
+
+	Meta map[string]string `location:"headers"`
+}
+
+type FetchObjectV2Output struct {
+	RequestInfo   `json:"-"`
+	VersionID     string `json:"VersionId,omitempty"`
+	ETag          string `json:"ETag,omitempty"`
+	SSECAlgorithm string `json:"SSECAlgorithm,omitempty"`
+	SSECKeyMD5    string `json:"SSECKeyMD5,omitempty"`
+}
+
+type PutFetchTaskV2Input struct {
+	Bucket        string
+	Key           string
+	URL           string // 源对象的 URL，必选
+	IgnoreSameKey bool   // 对象已存在时是否跳过，默认覆盖
+	ContentMD5    string // 源对象的 MD5，可选，不一致时抓取失败
+
+	ACL              enum.ACLType          `location:"header" locationName:"X-Tos-Acl"`
+	GrantFullControl string                `location:"header" locationName:"X-Tos-Grant-Full-Control"` // optional
+	GrantRead        string                `location:"header" locationName:"X-Tos-Grant-Read"`         // optional
+	GrantReadAcp     string                `location:"header" locationName:"X-Tos-Grant-Read-Acp"`     // optional
+	GrantWriteAcp    string                `location:"header" locationName:"X-Tos-Grant-Write-Acp"`    // optional
+	StorageClass     enum.StorageClassType `location:"header" locationName:"X-Tos-Storage-Class"`
+
+	SSECAlgorithm string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Algorithm"`
+	SSECKey       string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key"`
+	SSECKeyMD5    string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key-MD5"`
+
+	Meta map[string]string `location:"headers"`
+}
+
+type PutFetchTaskV2Output struct {
+	RequestInfo `json:"-"`
+	TaskID      string `json:"TaskId,omitempty"`
+}
+
+type GetFetchTaskV2Input struct {
+	Bucket string
+	TaskID string `location:"query" locationName:"taskId"`
+}
+
+type FetchTask struct {
+	URL           string `json:"URL,omitempty"`
+	Key           string `json:"Object,omitempty"`
+	IgnoreSameKey bool   `json:"IgnoreSameKey,omitempty"`
+	ContentMD5    string `json:"ContentMD5,omitempty"`
+}
+
+type GetFetchTaskV2Output struct {
+	RequestInfo `json:"-"`
+	State       string    `json:"State,omitempty"` // FetchTaskStateSucceed, FetchTaskStateRunning or FetchTaskStateExpired
+	Task        FetchTask `json:"Task,omitempty"`
+}
+
+type PutSymlinkV2Input struct {
+	Bucket              string
+	Key                 string
+	SymlinkTargetKey    string                // 目标对象名，必选
+	SymlinkTargetBucket string                `location:"header" locationName:"X-Tos-Symlink-Bucket"` // 目标对象所在桶，默认与软链接相同
+	ForbidOverwrite     bool                  // 软链接已存在时禁止覆盖
+	ACL                 enum.ACLType          `location:"header" locationName:"X-Tos-Acl"`
+	StorageClass        enum.StorageClassType `location:"header" locationName:"X-Tos-Storage-Class"`
+
+	GrantFullControl string `location:"header" locationName:"X-Tos-Grant-Full-Control"` // optional
+	GrantRead        string `location:"header" locationName:"X-Tos-Grant-Read"`         // optional
+	GrantReadAcp     string `location:"header" locationName:"X-Tos-Grant-Read-Acp"`     // optional
+	GrantWriteAcp    string `location:"header" locationName:"X-Tos-Grant-Write-Acp"`    // optional
+
+	Meta map[string]string `location:"headers"`
+}
+
+type PutSymlinkV2Output struct {
+	RequestInfo
+	VersionID string
+}
+
+type GetSymlinkV2Input struct {
+	Bucket    string
+	Key       string
+	VersionID string `location:"query" locationName:"versionId"`
+}
+
+type GetSymlinkV2Output struct {
+	RequestInfo
+	VersionID           string
+	SymlinkTargetKey    string
+	SymlinkTargetBucket string // empty if the target is in the same bucket
+	LastModified        time.Time
+	ETag                string
+}
+
+type RestoreJobParameters struct {
+	Tier enum.TierType `json:"Tier,omitempty"` // 取回方式
+}
+
+type RestoreObjectV2Input struct {
+	Bucket               string                `json:"-"`
+	Key                  string                `json:"-"`
+	VersionID            string                `json:"-" location:"query" locationName:"versionId"`
+	Days                 int                   `json:"Days,omitempty"` // 取回后副本的保存天数
+	RestoreJobParameters *RestoreJobParameters `json:"RestoreJobParameters,omitempty"`
+}
+
+type RestoreObjectV2Output struct {
+	RequestInfo
+	Accepted bool // true if a new restore is started, false if the restored copy exists and its expiry is updated
+}
+
+type Tag struct {
+	Key   string `json:"Key,omitempty"`
+	Value string `json:"Value,omitempty"`
+}
+
+type TagSet struct {
+	Tags []Tag `json:"Tags,omitempty"`
+}
+
+type PutObjectTaggingV2Input struct {
+	Bucket    string `json:"-"`
+	Key       string `json:"-"`
+	VersionID string `json:"-" location:"query" locationName:"versionId"`
+	TagSet    TagSet `json:"TagSet"`
+}
+
+type PutObjectTaggingV2Output struct {
+	RequestInfo
+	VersionID string
+}
+
+type GetObjectTaggingV2Input struct {
+	Bucket    string
+	Key       string
+	VersionID string `location:"query" locationName:"versionId"`
+}
+
+type GetObjectTaggingV2Output struct {
+	RequestInfo `json:"-"`
+	VersionID   string `json:"-"`
+	TagSet      TagSet `json:"TagSet"`
+}
+
+type DeleteObjectTaggingV2Input struct {
+	Bucket    string
+	Key       string
+	VersionID string `location:"query" locationName:"versionId"`
+}
+
+type DeleteObjectTaggingV2Output struct {
+	RequestInfo
+	VersionID string
+}
 
-	WebsiteRedirectLocation string                `location:"header" locationName:"X-Tos-Website-Redirect-Location"`
-	StorageClass            enum.StorageClassType `location:"header" locationName:"X-Tos-Storage-Class"`
+// ObjectRetention is the retention of an object version, it can not be deleted or overwritten before RetainUntilDate
+type ObjectRetention struct {
+	Mode            enum.ObjectLockModeType `json:"Mode,omitempty"`
+	RetainUntilDate time.Time               `json:"RetainUntilDate"`
 }
 
-type SSEHeaders struct {
-	SSECAlgorithm        string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Algorithm"`
-	SSECKey              string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key"`
-	SSECKeyMD5           string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key-MD5"`
-	ServerSideEncryption string `location:"header" locationName:"X-Tos-Server-Side-Encryption"`
+type PutObjectRetentionInput struct {
+	Bucket    string          `json:"-"`
+	Key       string          `json:"-"`
+	VersionID string          `json:"-" location:"query" locationName:"versionId"`
+	Retention ObjectRetention `json:"Retention"`
+	// 缩短 GOVERNANCE 模式的保留期或修改模式时需要设置为 true
+	BypassGovernanceRetention bool `json:"-"`
 }
 
-type AppendObjectV2Input struct {
-	Bucket             string
-	Key                string
-	Offset             int64 `location:"query" locationName:"offset" default:"0"`
-	Content            io.Reader
-	ContentLength      int64        `location:"header" locationName:"Content-Length"`
-	ContentMD5         string       `location:"header" locationName:"Content-MD5"`
-	ContentSHA256      string       `location:"header" locationName:"X-Tos-Content-Sha256"`
-	CacheControl       string       `location:"header" locationName:"Cache-Control"`
-	ContentDisposition string       `location:"header" locationName:"Content-Disposition" encodeChinese:"true"`
-	ContentEncoding    string       `location:"header" locationName:"Content-Encoding"`
-	ContentLanguage    string       `location:"header" locationName:"Content-Language"`
-	ContentType        string       `location:"header" locationName:"Content-Type"`
-	Expires            time.Time    `location:"header" locationName:"Expires"`
-	ACL                enum.ACLType `location:"header" locationName:"X-Tos-Acl"`
+type PutObjectRetentionOutput struct {
+	RequestInfo
+	VersionID string
+}
 
-	GrantFullControl string `location:"header" locationName:"X-Tos-Grant-Full-Control"` // optional
-	GrantRead        string `location:"header" locationName:"X-Tos-Grant-Read"`         // optional
-	GrantReadAcp     string `location:"header" locationName:"X-Tos-Grant-Read-Acp"`     // optional
-	GrantWriteAcp    string `location:"header" locationName:"X-Tos-Grant-Write-Acp"`    // optional
+type GetObjectRetentionInput struct {
+	Bucket    string
+	Key       string
+	VersionID string `location:"query" locationName:"versionId"`
+}
 
-	WebsiteRedirectLocation string                `location:"header" locationName:"X-Tos-Website-Redirect-Location"`
-	StorageClass            enum.StorageClassType `location:"header" locationName:"X-Tos-Storage-Class"`
+type GetObjectRetentionOutput struct {
+	RequestInfo `json:"-"`
+	VersionID   string          `json:"-"`
+	Retention   ObjectRetention `json:"Retention"`
+}
 
-	Meta                 map[string]string `location:"headers"`
-	DataTransferListener DataTransferListener
-	RateLimiter          RateLimiter
-	PreHashCrc64ecma     uint64
+// ObjectLegalHold is the legal hold of an object version, it can not be deleted or overwritten while Status is ON
+type ObjectLegalHold struct {
+	Status enum.LegalHoldStatusType `json:"Status,omitempty"`
 }
 
-type AppendObjectOutput struct {
-	RequestInfo      `json:"-"`
-	ETag             string `json:"ETag,omitempty"`
-	NextAppendOffset int64  `json:"NextAppendOffset,omitempty"`
+type PutObjectLegalHoldInput struct {
+	Bucket    string          `json:"-"`
+	Key       string          `json:"-"`
+	VersionID string          `json:"-" location:"query" locationName:"versionId"`
+	LegalHold ObjectLegalHold `json:"LegalHold"`
 }
 
-type AppendObjectV2Output struct {
-	RequestInfo      `json:"-"`
-	VersionID        string `json:"VersionID,omitempty"`
-	NextAppendOffset int64  `json:"NextAppendOffset,omitempty"`
-	HashCrc64ecma    uint64 `json:"HashCrc64Ecma,omitempty"`
+type PutObjectLegalHoldOutput struct {
+	RequestInfo
+	VersionID string
+}
+
+type GetObjectLegalHoldInput struct {
+	Bucket    string
+	Key       string
+	VersionID string `location:"query" locationName:"versionId"`
+}
+
+type GetObjectLegalHoldOutput struct {
+	RequestInfo `json:"-"`
+	VersionID   string          `json:"-"`
+	LegalHold   ObjectLegalHold `json:"LegalHold"`
 }
 
 type SetObjectMetaInput struct {
@@ -369,8 +1385,28 @@ type SetObjectMetaOutput struct {
 	RequestInfo `json:"-"`
 }
 
+type SetObjectMetaV2Input struct {
+	Bucket    string
+	Key       string
+	VersionID string `location:"query" locationName:"versionId"`
+
+	CacheControl       string    `location:"header" locationName:"Cache-Control"`
+	ContentDisposition string    `location:"header" locationName:"Content-Disposition" encodeChinese:"true"`
+	ContentEncoding    string    `location:"header" locationName:"Content-Encoding"`
+	ContentLanguage    string    `location:"header" locationName:"Content-Language"`
+	ContentType        string    `location:"header" locationName:"Content-Type"`
+	Expires            time.Time `location:"header" locationName:"Expires"`
+
+	Meta map[string]string `location:"headers"`
+}
+
+type SetObjectMetaV2Output struct {
+	RequestInfo
+}
+
 type ListObjectsV2Input struct {
-	Bucket string
+	Bucket       string
+	RequestPayer string `location:"header" locationName:"X-Tos-Request-Payer"` // RequestPayerRequester for requester-pays bucket
 	ListObjectsInput
 }
 
@@ -459,6 +1495,50 @@ type listObjectsV2Output struct {
 	Contents       []listedObjectV2     `json:"Contents,omitempty"`
 }
 
+type ListObjectsType2Input struct {
+	Bucket            string
+	RequestPayer      string `location:"header" locationName:"X-Tos-Request-Payer"` // RequestPayerRequester for requester-pays bucket
+	Prefix            string `location:"query" locationName:"prefix"`
+	Delimiter         string `location:"query" locationName:"delimiter"`
+	StartAfter        string `location:"query" locationName:"start-after"`
+	ContinuationToken string `location:"query" locationName:"continuation-token"`
+	MaxKeys           int    `location:"query" locationName:"max-keys"`
+	FetchOwner        bool   `location:"query" locationName:"fetch-owner"`
+	EncodingType      string `location:"query" locationName:"encoding-type"` // "" or "url", keys in output are decoded if "url"
+}
+
+type ListObjectsType2Output struct {
+	RequestInfo           `json:"-"`
+	Name                  string               `json:"Name,omitempty"`
+	Prefix                string               `json:"Prefix,omitempty"`
+	StartAfter            string               `json:"StartAfter,omitempty"`
+	ContinuationToken     string               `json:"ContinuationToken,omitempty"`
+	MaxKeys               int64                `json:"MaxKeys,omitempty"`
+	Delimiter             string               `json:"Delimiter,omitempty"`
+	IsTruncated           bool                 `json:"IsTruncated,omitempty"`
+	EncodingType          string               `json:"EncodingType,omitempty"`
+	KeyCount              int64                `json:"KeyCount,omitempty"`
+	NextContinuationToken string               `json:"NextContinuationToken,omitempty"`
+	CommonPrefixes        []ListedCommonPrefix `json:"CommonPrefixes,omitempty"`
+	Contents              []ListedObjectV2     `json:"Contents,omitempty"`
+}
+
+type listObjectsType2Output struct {
+	RequestInfo           `json:"-"`
+	Name                  string               `json:"Name,omitempty"`
+	Prefix                string               `json:"Prefix,omitempty"`
+	StartAfter            string               `json:"StartAfter,omitempty"`
+	ContinuationToken     string               `json:"ContinuationToken,omitempty"`
+	MaxKeys               int64                `json:"MaxKeys,omitempty"`
+	Delimiter             string               `json:"Delimiter,omitempty"`
+	IsTruncated           bool                 `json:"IsTruncated,omitempty"`
+	EncodingType          string               `json:"EncodingType,omitempty"`
+	KeyCount              int64                `json:"KeyCount,omitempty"`
+	NextContinuationToken string               `json:"NextContinuationToken,omitempty"`
+	CommonPrefixes        []ListedCommonPrefix `json:"CommonPrefixes,omitempty"`
+	Contents              []listedObjectV2     `json:"Contents,omitempty"`
+}
+
 type ListObjectVersionsInput struct {
 	Prefix          string `location:"query" locationName:"prefix"`
 	Delimiter       string `location:"query" locationName:"delimiter"`
@@ -469,7 +1549,7 @@ type ListObjectVersionsInput struct {
 }
 
 type ListObjectVersionsV2Input struct {
-	Bucket string `json:"Prefix,omitempty"`
+	Bucket string
 	ListObjectVersionsInput
 }
 
@@ -584,10 +1664,11 @@ type GetObjectOutput struct {
 }
 
 type GetObjectV2Input struct {
-	Bucket     string
-	Key        string
-	VersionID  string `location:"query" locationName:"versionId"`
-	PartNumber int    `location:"query" locationName:"partNumber"`
+	Bucket       string
+	Key          string
+	VersionID    string `location:"query" locationName:"versionId"`
+	PartNumber   int    `location:"query" locationName:"partNumber"`
+	RequestPayer string `location:"header" locationName:"X-Tos-Request-Payer"` // RequestPayerRequester for requester-pays bucket
 
 	IfMatch           string    `location:"header" locationName:"If-Match"`
 	IfModifiedSince   time.Time `location:"header" locationName:"If-Modified-Since"`
@@ -605,8 +1686,26 @@ type GetObjectV2Input struct {
 	ResponseContentType        string    `location:"query" locationName:"Content-Type"`
 	ResponseExpires            time.Time `location:"query" locationName:"Expires"`
 
+	// 数据处理参数，如 image/resize,w_100/format,png，返回处理后的数据，输出的 ContentType 为处理后数据的类型
+	Process string `location:"query" locationName:"x-tos-process"`
+	// 将处理结果保存为对象，SaveBucket 为空时保存到当前桶，设置后输出的 SaveResult 不为空
+	SaveBucket string
+	SaveObject string
+
 	RangeStart int64
 	RangeEnd   int64
+	Range      *HTTPRange // 下载范围，优先于 RangeStart 和 RangeEnd
+
+	// 客户端开启 CRC 校验时，读完对象内容或关闭 Content 会校验 CRC64，范围下载和数据处理不校验。
+	// 只读取部分内容时可设置为 true 关闭校验
+	DisableCRCCheck bool
+	// 读取内容出错时，从已读位置以 If-Match 重新下载的最大次数，默认 0 不续传；
+	// 对象在续传前被覆盖时读取返回 *ObjectChangedError。数据处理和按 PartNumber 下载不支持续传
+	MaxResumeCount int
+	// 响应 Content-Encoding 为 gzip 时返回解压后的内容，输出的 Decompressed 为 true。
+	// 此时 ContentLength 和 HashCrc64ecma 仍为压缩数据的值，不做 CRC64 校验，DataTransferListener 收到解压后的字节数且 TotalBytes 为 -1；
+	// 其他编码和范围下载的内容原样返回
+	AutoDecompress bool
 
 	DataTransferListener DataTransferListener
 	RateLimiter          RateLimiter
@@ -614,18 +1713,31 @@ type GetObjectV2Input struct {
 
 type GetObjectBasicOutput struct {
 	RequestInfo
-	ContentRange string // don't move into ObjectMetaV2
+	ContentRange string        // don't move into ObjectMetaV2
+	Range        *ContentRange // parsed from ContentRange, nil if ContentRange is not returned
 	ObjectMetaV2
 }
 
 type GetObjectV2Output struct {
 	GetObjectBasicOutput
-	Content io.ReadCloser
+	Content      io.ReadCloser
+	SaveResult   *ProcessSaveResult // result of saving processed data, set if SaveObject of input is set
+	Decompressed bool               // Content is decompressed from gzip by AutoDecompress, ContentLength is size of compressed data
+}
+
+// ProcessSaveResult describes the object saved by GetObjectV2 with SaveObject
+type ProcessSaveResult struct {
+	Bucket string `json:"bucket,omitempty"`
+	Key    string `json:"object,omitempty"`
+	Size   int64  `json:"fileSize,omitempty"`
+	Status string `json:"status,omitempty"`
 }
 
 type GetObjectToFileInput struct {
 	GetObjectV2Input
-	FilePath string
+	FilePath        string
+	TempFileSuffix  string // suffix of the temp file, TempFileSuffix by default
+	CreateParentDir bool   // create parent directories of FilePath if they don't exist
 }
 
 type GetObjectToFileOutput struct {
@@ -633,9 +1745,10 @@ type GetObjectToFileOutput struct {
 }
 
 type HeadObjectV2Input struct {
-	Bucket    string
-	Key       string
-	VersionID string `location:"query" locationName:"versionId"`
+	Bucket       string
+	Key          string
+	VersionID    string `location:"query" locationName:"versionId"`
+	RequestPayer string `location:"header" locationName:"X-Tos-Request-Payer"` // RequestPayerRequester for requester-pays bucket
 
 	IfMatch           string    `location:"header" locationName:"If-Match"`
 	IfModifiedSince   time.Time `location:"header" locationName:"If-Modified-Since"`
@@ -662,6 +1775,8 @@ type DeleteObjectV2Input struct {
 	Bucket    string
 	Key       string
 	VersionID string `location:"query" locationName:"versionId"`
+	// 删除处于 GOVERNANCE 保留期内的对象版本，需要相应权限
+	BypassGovernanceRetention bool
 }
 
 type DeleteObjectOutput struct {
@@ -683,6 +1798,8 @@ type DeleteMultiObjectsInput struct {
 	Bucket  string
 	Objects []ObjectTobeDeleted `json:"Objects,omitempty"`
 	Quiet   bool                `json:"Quiet,omitempty"`
+	// 删除处于 GOVERNANCE 保留期内的对象版本，需要相应权限
+	BypassGovernanceRetention bool `json:"-"`
 }
 
 type Deleted struct {
@@ -712,12 +1829,31 @@ type DeleteMultiObjectsOutput struct {
 	Error       []DeleteError `json:"Error,omitempty"`   // 删除失败的Object列表
 }
 
+type DeleteObjectsInput struct {
+	Bucket        string
+	Objects       []ObjectTobeDeleted      // 待删除的对象
+	ObjectsChan   <-chan ObjectTobeDeleted // 待删除的对象，读取至关闭，先删除 Objects 再读取 ObjectsChan
+	TaskNum       int                      // 并发的批量删除请求数，默认 DefaultDeleteTaskNum
+	MaxRetryCount int                      // 对象因 InternalError、SlowDown 等可重试错误删除失败时的最大重试次数，默认 DefaultDeleteMaxRetryCount
+}
+
+type DeleteObjectAllVersionsOutput struct {
+	DeletedVersionCount      int64         // 删除的对象版本数
+	DeletedDeleteMarkerCount int64         // 删除的删除标记数
+	Failed                   []DeleteError // 删除失败的版本及错误码
+}
+
+type DeleteObjectsOutput struct {
+	DeletedCount int64
+	Failed       []DeleteError // 最终删除失败的对象及错误码，整个请求失败时 Code 为空，Message 为错误信息
+}
+
 type CopyObjectInput struct {
 	Bucket             string
 	Key                string
 	SrcBucket          string
 	SrcKey             string
-	SrcVersionID       string
+	SrcVersionID       string       `location:"query" locationName:"versionId"`
 	CacheControl       string       `location:"header" locationName:"Cache-Control"`
 	ContentDisposition string       `location:"header" locationName:"Content-Disposition" encodeChinese:"true"`
 	ContentEncoding    string       `location:"header" locationName:"Content-Encoding"`
@@ -739,13 +1875,22 @@ type CopyObjectInput struct {
 	CopySourceIfNoneMatch       string    `location:"header" locationName:"X-Tos-Copy-Source-If-None-Match"`
 	CopySourceIfUnmodifiedSince time.Time `location:"header" locationName:"X-Tos-Copy-Source-If-Unmodified-Since"`
 
-	CopySourceSSECAlgorithm string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Algorithm"`
-	CopySourceSSECKey       string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key"`
-	CopySourceSSECKeyMD5    string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key-MD5"`
+	CopySourceSSECAlgorithm string `location:"header" locationName:"X-Tos-Copy-Source-Server-Side-Encryption-Customer-Algorithm"`
+	CopySourceSSECKey       string `location:"header" locationName:"X-Tos-Copy-Source-Server-Side-Encryption-Customer-Key"`
+	CopySourceSSECKeyMD5    string `location:"header" locationName:"X-Tos-Copy-Source-Server-Side-Encryption-Customer-Key-MD5"`
+	SSECAlgorithm           string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Algorithm"`
+	SSECKey                 string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key"`
+	SSECKeyMD5              string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key-MD5"`
 	ServerSideEncryption    string `location:"header" locationName:"X-Tos-Server-Side-Encryption"`
+	// KMS key ID when ServerSideEncryption is "kms"
+	ServerSideEncryptionKeyID string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Kms-Key-Id"`
 
+	// 为 REPLACE 时使用 CacheControl、ContentType 等标准头和 Meta 替换源对象元数据，否则复制源对象元数据并忽略这些字段
 	MetadataDirective enum.MetadataDirectiveType `location:"header" locationName:"X-Tos-Metadata-Directive"`
-	Meta              map[string]string          `location:"headers"`
+	// 为 REPLACE 时使用 Tagging 替换源对象标签，否则复制源对象标签并忽略 Tagging
+	TaggingDirective enum.TaggingDirectiveType `location:"header" locationName:"X-Tos-Tagging-Directive"`
+	Tagging          string                    `location:"header" locationName:"X-Tos-Tagging"` // e.g. "k1=v1&k2=v2", see TagSet.Encode
+	Meta             map[string]string         `location:"headers"`
 }
 
 type CopyObjectOutput struct {
@@ -754,6 +1899,9 @@ type CopyObjectOutput struct {
 	SourceVersionID string `json:"SourceVersionId,omitempty"`
 	ETag            string `json:"ETag,omitempty"`         // at body
 	LastModified    string `json:"LastModified,omitempty"` // at body
+
+	ServerSideEncryption      string `json:"-"`
+	ServerSideEncryptionKeyID string `json:"-"`
 }
 
 type UploadPartCopyInput struct {
@@ -785,6 +1933,7 @@ type UploadPartCopyV2Input struct {
 	SrcBucket            string
 	SrcKey               string
 	SrcVersionID         string `location:"query" locationName:"versionId"`
+	CopySourceRange      string // e.g. "bytes=0-1023", takes precedence over CopySourceRangeStart and CopySourceRangeEnd
 	CopySourceRangeStart int64
 	CopySourceRangeEnd   int64
 
@@ -793,9 +1942,14 @@ type UploadPartCopyV2Input struct {
 	CopySourceIfNoneMatch       string    `location:"header" locationName:"X-Tos-Copy-Source-If-None-Match"`
 	CopySourceIfUnmodifiedSince time.Time `location:"header" locationName:"X-Tos-Copy-Source-If-Unmodified-Since"`
 
-	CopySourceSSECAlgorithm string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Algorithm"`
-	CopySourceSSECKey       string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key"`
-	CopySourceSSECKeyMD5    string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key-MD5"`
+	CopySourceSSECAlgorithm string `location:"header" locationName:"X-Tos-Copy-Source-Server-Side-Encryption-Customer-Algorithm"`
+	CopySourceSSECKey       string `location:"header" locationName:"X-Tos-Copy-Source-Server-Side-Encryption-Customer-Key"`
+	CopySourceSSECKeyMD5    string `location:"header" locationName:"X-Tos-Copy-Source-Server-Side-Encryption-Customer-Key-MD5"`
+
+	// SSE-C key of the destination object, must be the same as CreateMultipartUploadV2
+	SSECAlgorithm string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Algorithm"`
+	SSECKey       string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key"`
+	SSECKeyMD5    string `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key-MD5"`
 }
 
 type UploadPartCopyV2Output struct {
@@ -804,12 +1958,15 @@ type UploadPartCopyV2Output struct {
 	ETag                string
 	LastModified        time.Time
 	CopySourceVersionID string
+	SSECAlgorithm       string
+	SSECKeyMD5          string
 }
 
 type CreateMultipartUploadV2Input struct {
 	Bucket             string
 	Key                string
-	EncodingType       string       `location:"query" locationName:"encoding-type"` // "" or "url"
+	RequestPayer       string       `location:"header" locationName:"X-Tos-Request-Payer"` // RequestPayerRequester for requester-pays bucket
+	EncodingType       string       `location:"query" locationName:"encoding-type"`        // "" or "url"
 	CacheControl       string       `location:"header" locationName:"Cache-Control"`
 	ContentDisposition string       `location:"header" locationName:"Content-Disposition" encodeChinese:"true"`
 	ContentEncoding    string       `location:"header" locationName:"Content-Encoding"`
@@ -828,8 +1985,11 @@ type CreateMultipartUploadV2Input struct {
 	SSECAlgorithm           string                `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Algorithm"`
 	SSECKey                 string                `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key"`
 	SSECKeyMD5              string                `location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key-MD5"`
-	ServerSideEncryption    string                `location:"header" locationName:"X-Tos-Server-Side-Encryption"`
-	Meta                    map[string]string     `location:"headers"`
+	ServerSideEncryption    string                `location:"header" locationName:"X-Tos-Server-Side-Encryption"` // "AES256" or "kms"
+	// KMS key ID when ServerSideEncryption is "kms", the default key is used if it is empty
+	ServerSideEncryptionKeyID string            `location:"header" locationName:"X-Tos-Server-Side-Encryption-Kms-Key-Id"`
+	Tagging                   string            `location:"header" locationName:"X-Tos-Tagging"` // e.g. "k1=v1&k2=v2", see TagSet.Encode
+	Meta                      map[string]string `location:"headers"`
 }
 
 type CreateMultipartUploadOutput struct {
@@ -849,6 +2009,9 @@ type CreateMultipartUploadV2Output struct {
 	SSECAlgorithm string `json:"SSECAlgorithm,omitempty"`
 	SSECKeyMD5    string `json:"SSECKeyMD5,omitempty"`
 	EncodingType  string `json:"EncodingType,omitempty"`
+
+	ServerSideEncryption      string `json:"ServerSideEncryption,omitempty"`
+	ServerSideEncryptionKeyID string `json:"ServerSideEncryptionKeyID,omitempty"`
 }
 
 type UploadPartInput struct {
@@ -871,10 +2034,11 @@ func (up *UploadPartOutput) uploadedPart() uploadedPart {
 }
 
 type UploadPartBasicInput struct {
-	Bucket     string
-	Key        string
-	UploadID   string `location:"query" locationName:"uploadId"`
-	PartNumber int    `location:"query" locationName:"partNumber"`
+	Bucket       string
+	Key          string
+	UploadID     string `location:"query" locationName:"uploadId"`
+	PartNumber   int    `location:"query" locationName:"partNumber"`
+	RequestPayer string `location:"header" locationName:"X-Tos-Request-Payer"` // RequestPayerRequester for requester-pays bucket
 
 	ContentMD5 string `location:"header" locationName:"Content-MD5"`
 
@@ -885,6 +2049,10 @@ type UploadPartBasicInput struct {
 
 	DataTransferListener DataTransferListener
 	RateLimiter          RateLimiter
+	EnableContentMD5     bool // calculate Content-MD5 if it is empty, see WithEnableContentMD5
+	// send content in chunks with a trailing CRC64 checksum, for content of unknown length,
+	// see PutObjectBasicInput.EnableTrailingChecksum
+	EnableTrailingChecksum bool
 }
 
 type UploadPartV2Input struct {
@@ -932,10 +2100,11 @@ func (part *UploadedPart) uploadedPart() uploadedPart {
 }
 
 type UploadedPartV2 struct {
-	PartNumber   int       `json:"PartNumber,omitempty"`   // Part编号
-	ETag         string    `json:"ETag,omitempty"`         // ETag
-	LastModified time.Time `json:"LastModified,omitempty"` // 最后一次修改时间
-	Size         int64     `json:"Size,omitempty"`         // Part大小
+	PartNumber    int       `json:"PartNumber,omitempty"`    // Part编号
+	ETag          string    `json:"ETag,omitempty"`          // ETag
+	LastModified  time.Time `json:"LastModified,omitempty"`  // 最后一次修改时间
+	Size          int64     `json:"Size,omitempty"`          // Part大小
+	HashCrc64ecma uint64    `json:"HashCrc64ecma,omitempty"` // Part的CRC64, 与Size同时设置时CompleteMultipartUploadV2会校验对象的CRC64
 }
 
 func (part UploadedPartV2) uploadedPart() uploadedPart {
@@ -955,30 +2124,38 @@ type CompleteMultipartUploadInput struct {
 type CompleteMultipartUploadOutput struct {
 	RequestInfo `json:"-"`
 	VersionID   string `json:"VersionId,omitempty"`
+	Bucket      string `json:"Bucket,omitempty"`
+	Key         string `json:"Key,omitempty"`
+	ETag        string `json:"ETag,omitempty"`
+	Location    string `json:"Location,omitempty"`
 }
 
 type CompleteMultipartUploadV2Input struct {
-	Bucket   string
-	Key      string
-	UploadID string `location:"query" locationName:"uploadId"`
-	Parts    []UploadedPartV2
+	Bucket       string
+	Key          string
+	UploadID     string `location:"query" locationName:"uploadId"`
+	RequestPayer string `location:"header" locationName:"X-Tos-Request-Payer"` // RequestPayerRequester for requester-pays bucket
+	Parts        []UploadedPartV2
+	CompleteAll  bool // complete with all uploaded parts, Parts must be empty
 }
 
 type CompleteMultipartUploadV2Output struct {
-	RequestInfo
-	Bucket        string
-	Key           string
-	ETag          string
-	Location      string
-	VersionID     string
-	HashCrc64ecma uint64
+	RequestInfo    `json:"-"`
+	Bucket         string           `json:"Bucket,omitempty"`
+	Key            string           `json:"Key,omitempty"`
+	ETag           string           `json:"ETag,omitempty"`
+	Location       string           `json:"Location,omitempty"`
+	VersionID      string           `json:"-"`                        // from header
+	HashCrc64ecma  uint64           `json:"-"`                        // from header
+	CompletedParts []UploadedPartV2 `json:"CompletedParts,omitempty"` // only returned if CompleteAll is set
 }
 
 type AbortMultipartUploadInput struct {
 	// Bucket is needed in V2 api
-	Bucket   string
-	Key      string
-	UploadID string `location:"query" locationName:"uploadId"`
+	Bucket       string
+	Key          string
+	UploadID     string `location:"query" locationName:"uploadId"`
+	RequestPayer string `location:"header" locationName:"X-Tos-Request-Payer"` // RequestPayerRequester for requester-pays bucket
 }
 
 type AbortMultipartUploadOutput struct {
@@ -1022,7 +2199,7 @@ type ListMultipartUploadsOutput struct {
 
 type ListMultipartUploadsV2Input struct {
 	Bucket         string
-	Prefix         string `location:"query" locationName:"uploads"`
+	Prefix         string `location:"query" locationName:"prefix"`
 	Delimiter      string `location:"query" locationName:"delimiter"`
 	KeyMarker      string `location:"query" locationName:"key-marker"`
 	UploadIDMarker string `location:"query" locationName:"upload-id-marker"`
@@ -1054,6 +2231,92 @@ type ListMultipartUploadsV2Output struct {
 	Uploads            []ListedUpload
 }
 
+type AbortedUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+}
+
+type AbortUploadError struct {
+	Key      string
+	UploadID string
+	Err      error
+}
+
+type AbortStaleMultipartUploadsOutput struct {
+	Aborted []AbortedUpload
+	Failed  []AbortUploadError // uploads failed to abort, e.g. completed or aborted by others
+}
+
+type UploadDirectoryInput struct {
+	Bucket   string
+	Prefix   string // 对象名前缀，对象名为 Prefix 加上文件相对 LocalDir 的路径
+	LocalDir string
+	// 自定义文件相对路径（以 / 分隔，目录以 / 结尾）到对象名的映射，设置后忽略 Prefix，返回空字符串时跳过该文件
+	KeyMapper          func(relPath string) string
+	FollowSymlinks     bool             // 是否跟随符号链接，默认跳过符号链接
+	KeepEmptyDirectory bool             // 是否为空目录创建以 / 结尾的空对象
+	TaskNum            int              // 并发上传的文件数，默认 DefaultDirectoryTaskNum
+	MultipartThreshold int64            // 大于该值的文件使用分片上传，默认 DefaultMultipartThreshold
+	PartSize           int64            // 分片上传的分片大小，默认 MinPartSize
+	TransferManager    *TransferManager // 大文件分片上传共享的分片并发池，可选
+
+	ACL                  enum.ACLType
+	StorageClass         enum.StorageClassType
+	ServerSideEncryption string
+	Meta                 map[string]string
+}
+
+type UploadedDirectoryFile struct {
+	FilePath      string
+	Key           string
+	ETag          string
+	VersionID     string
+	HashCrc64ecma uint64
+}
+
+type UploadDirectoryFileError struct {
+	FilePath string
+	Key      string
+	Err      error
+}
+
+type UploadDirectoryOutput struct {
+	Succeeded []UploadedDirectoryFile
+	Failed    []UploadDirectoryFileError
+}
+
+type DownloadDirectoryInput struct {
+	Bucket   string
+	Prefix   string // 下载该前缀下的所有对象，对象名去掉 Prefix 后作为相对 LocalDir 的路径
+	LocalDir string
+	// 跳过本地文件大小和 CRC64 与对象相同的对象
+	SkipUnchanged      bool
+	TaskNum            int              // 并发下载的对象数，默认 DefaultDirectoryTaskNum
+	MultipartThreshold int64            // 大于该值的对象分段下载，默认 DefaultMultipartThreshold
+	PartSize           int64            // 分段下载的分段大小，默认 MinPartSize
+	PartTaskNum        int              // 每个对象分段下载的并发数，默认 1
+	TransferManager    *TransferManager // 大文件分段下载共享的分片并发池，可选
+}
+
+type DownloadedDirectoryFile struct {
+	Key      string
+	FilePath string
+	Size     int64
+}
+
+type DownloadDirectoryFileError struct {
+	Key      string
+	FilePath string
+	Err      error
+}
+
+type DownloadDirectoryOutput struct {
+	Succeeded []DownloadedDirectoryFile
+	Skipped   []DownloadedDirectoryFile // 本地文件与对象相同而跳过的对象，仅在 SkipUnchanged 时有值
+	Failed    []DownloadDirectoryFileError
+}
+
 type ListUploadedPartsInput struct {
 	Key              string `json:"Key,omitempty"`
 	UploadID         string `json:"UploadId,omitempty"`
@@ -1079,6 +2342,7 @@ type ListPartsInput struct {
 	Bucket           string
 	Key              string
 	UploadID         string `location:"query" locationName:"uploadId"`
+	RequestPayer     string `location:"header" locationName:"X-Tos-Request-Payer"` // RequestPayerRequester for requester-pays bucket
 	PartNumberMarker int    `location:"query" locationName:"part-number-marker"`
 	MaxParts         int    `location:"query" locationName:"max-parts"`
 	EncodingType     string `location:"query" locationName:"encoding-type"` // "" or "url"
@@ -1102,23 +2366,57 @@ type ListPartsOutput struct {
 
 type CancelHook interface {
 	// Cancel 取消断点上传\断点下载事, isAbort 为 true 时删除上下文信息和临时文件，为 false 时只是中断当前执行，该接口只能调用一次
+	// 调用后不再调度新的分片，执行中的分片请求通过 context 取消，UploadFile/DownloadFile 返回 CanceledClientError
 	Cancel(isAbort bool)
 	// to make user unable to implement this interface
 	internal()
 }
 
+// CheckpointKey identifies checkpoint of an UploadFile, DownloadFile or ResumableCopyObject task
+type CheckpointKey struct {
+	Operation string // upload, download or copy
+	Bucket    string // destination bucket of copy
+	Key       string // destination key of copy
+	// UploadID is the multipart upload of UploadFile and ResumableCopyObject, it's empty for DownloadFile,
+	// and it's empty in Load since the upload to resume is recorded in the checkpoint
+	UploadID string
+	// CheckpointFile is CheckpointFile of the input, the local file used by the default store
+	CheckpointFile string
+}
+
+// String returns "operation/bucket/key", which is the same before and after the upload is created
+func (k CheckpointKey) String() string {
+	return k.Operation + "/" + k.Bucket + "/" + k.Key
+}
+
+// CheckpointStore stores checkpoint of UploadFile, DownloadFile and ResumableCopyObject.
+// Checkpoints are saved in local file CheckpointFile by default, custom stores should identify them by
+// Operation, Bucket and Key, so that a task can be resumed by other workers. Finished parts are recorded in the saved data.
+type CheckpointStore interface {
+	// Load returns the checkpoint saved with key, nil data and nil error are returned if it does not exist
+	Load(key CheckpointKey) ([]byte, error)
+	Save(key CheckpointKey, data []byte) error
+	Delete(key CheckpointKey) error
+}
+
 type DownloadFileInput struct {
 	HeadObjectV2Input
-	FilePath              string
-	PartSize              int64
-	TaskNum               int
-	EnableCheckpoint      bool
-	CheckpointFile        string
-	tempFile              string
-	DownloadEventListener DownloadEventListener
-	DataTransferListener  DataTransferListener
-	RateLimiter           RateLimiter
-	CancelHook            CancelHook // user can not set this filed
+	FilePath                string
+	PartSize                int64
+	TaskNum                 int
+	EnableCheckpoint        bool
+	CheckpointFile          string
+	CheckpointStore         CheckpointStore
+	CheckpointSaveInterval  time.Duration
+	CheckpointSavePartCount int
+	TempFileSuffix          string // 临时文件后缀，默认 TempFileSuffix
+	tempFile                string
+	DownloadEventListener   DownloadEventListener
+	DataTransferListener    DataTransferListener
+	RateLimiter             RateLimiter
+	TransferManager         *TransferManager // 与其他任务共享的分片并发池，可选
+	// cancelHook 支持取消断点续传任务, 通过 NewCancelHook 创建
+	CancelHook CancelHook
 }
 
 func (d *DownloadFileInput) withCancelHook(hook CancelHook) {
@@ -1156,14 +2454,21 @@ type DownloadEventListener interface {
 type UploadFileInput struct {
 	CreateMultipartUploadV2Input
 
-	FilePath             string
-	PartSize             int64
-	TaskNum              int
-	EnableCheckpoint     bool
-	CheckpointFile       string
-	DataTransferListener DataTransferListener
-	UploadEventListener  UploadEventListener
-	RateLimiter          RateLimiter
+	FilePath                string
+	PartSize                int64
+	TaskNum                 int
+	EnableCheckpoint        bool
+	CheckpointFile          string
+	CheckpointStore         CheckpointStore // 默认保存到本地文件
+	CheckpointSaveInterval  time.Duration   // 保存断点信息的最小间隔，与 CheckpointSavePartCount 都不设置时每个分片完成后保存
+	CheckpointSavePartCount int             // 每完成多少个分片保存一次断点信息
+	MaxPartRetries          int             // 每个分片的最大重试次数，默认 3 次，小于 0 时不重试
+	PartRetryBackoffBase    time.Duration   // 分片重试的指数退避基数，默认 100ms
+	PartRetryBackoffCap     time.Duration   // 分片重试的最大退避时间，默认 10s
+	DataTransferListener    DataTransferListener
+	UploadEventListener     UploadEventListener
+	RateLimiter             RateLimiter
+	TransferManager         *TransferManager // 与其他任务共享的分片并发池，可选
 	// cancelHook 支持取消断点续传任务
 	CancelHook CancelHook
 }
@@ -1213,10 +2518,116 @@ type UploadFileOutput struct {
 	EncodingType  string
 }
 
+type UploadFromReaderAtInput struct {
+	CreateMultipartUploadV2Input
+
+	ReaderAt             io.ReaderAt
+	Size                 int64         // 上传数据的总长度
+	PartSize             int64         // 默认 5MB，分片数超过 10000 时自动调大
+	TaskNum              int           // 并发上传的分片数，默认 1
+	MaxPartRetries       int           // 每个分片的最大重试次数，默认 3 次，小于 0 时不重试
+	PartRetryBackoffBase time.Duration // 分片重试的指数退避基数，默认 100ms
+	PartRetryBackoffCap  time.Duration // 分片重试的最大退避时间，默认 10s
+	DataTransferListener DataTransferListener
+	RateLimiter          RateLimiter
+	TransferManager      *TransferManager // 与其他任务共享的分片并发池，可选
+}
+
+type UploadFromReaderAtOutput struct {
+	UploadFileOutput
+}
+
+type DownloadToWriterAtInput struct {
+	// HeadObjectV2Input describes the object, ETag got by HeadObjectV2 is sent as If-Match of every range
+	HeadObjectV2Input
+
+	WriterAt             io.WriterAt
+	PartSize             int64         // 每个范围下载的大小，默认 5MB，范围数超过 10000 时自动调大
+	TaskNum              int           // 并发下载的范围数，默认 1
+	MaxPartRetries       int           // 每个范围的最大重试次数，默认 3 次，小于 0 时不重试
+	PartRetryBackoffBase time.Duration // 范围重试的指数退避基数，默认 100ms
+	PartRetryBackoffCap  time.Duration // 范围重试的最大退避时间，默认 10s
+	DataTransferListener DataTransferListener
+	RateLimiter          RateLimiter
+	TransferManager      *TransferManager // 与其他任务共享的分片并发池，可选
+}
+
+type DownloadToWriterAtOutput struct {
+	HeadObjectV2Output
+}
+
+type ResumableCopyObjectInput struct {
+	// CreateMultipartUploadV2Input describes the destination object, metadata of source object is used if not set
+	CreateMultipartUploadV2Input
+
+	SrcBucket               string
+	SrcKey                  string
+	SrcVersionID            string
+	CopySourceSSECAlgorithm string
+	CopySourceSSECKey       string
+	CopySourceSSECKeyMD5    string
+
+	PartSize                int64 // source object not larger than PartSize is copied by CopyObject
+	TaskNum                 int
+	EnableCheckpoint        bool
+	CheckpointFile          string
+	CheckpointStore         CheckpointStore
+	CheckpointSaveInterval  time.Duration
+	CheckpointSavePartCount int
+	CopyEventListener       CopyEventListener
+	TransferManager         *TransferManager // 与其他任务共享的分片并发池，可选
+	// cancelHook 支持取消断点续传任务
+	CancelHook CancelHook
+}
+
+// CopyPartInfo is returned when CopyEvent occur
+type CopyPartInfo struct {
+	PartNumber           int
+	CopySourceRangeStart int64
+	CopySourceRangeEnd   int64
+	// upload part copy succeed 事件发生时有值
+	ETag *string
+}
+
+type CopyEvent struct {
+	Type           enum.CopyEventType
+	Err            error // failed, aborted 事件发生时不为空
+	Bucket         string
+	Key            string
+	UploadID       *string
+	SrcBucket      string
+	SrcKey         string
+	SrcVersionID   string
+	CheckpointFile *string // 断点续传文件全路径
+	// upload part copy 相关事件发生时有值
+	CopyPartInfo *CopyPartInfo
+}
+
+type CopyEventListener interface {
+	EventChange(event *CopyEvent)
+}
+
+type ResumableCopyObjectOutput struct {
+	RequestInfo
+	Bucket        string
+	Key           string
+	UploadID      string // empty if the object is copied by CopyObject
+	ETag          string
+	Location      string
+	VersionID     string
+	HashCrc64ecma uint64
+	SSECAlgorithm string
+	SSECKeyMD5    string
+	EncodingType  string
+}
+
 type DataTransferStatus struct {
 	TotalBytes    int64
 	ConsumedBytes int64 // bytes read/written
 	RWOnceBytes   int64 // bytes read/written this time
+	PartSize      int64 // effective part size, only set in DataTransferStarted event of UploadFile and DownloadFile
+	RetryCount    int   // times data is rewound, only set in DataTransferRetried event
+	RollbackBytes int64 // bytes to subtract from ConsumedBytes reported before, only set in DataTransferRetried event
 	Type          enum.DataTransferType
 }
 
@@ -1224,8 +2635,91 @@ type DataTransferListener interface {
 	DataTransferStatusChange(status *DataTransferStatus)
 }
 
+// RateLimiter limits bytes read from request and response bodies, see NewDefaultRateLimiter.
+// It may be shared by many requests, so it must be safe for concurrent use.
 type RateLimiter interface {
-	// Acquire try to get a token.
+	// Acquire try to get want tokens, one token for one byte.
 	// If ok, caller can read want bytes, else wait timeToWait and try again.
+	// timeToWait should be positive if not ok, otherwise caller spins.
 	Acquire(want int64) (ok bool, timeToWait time.Duration)
 }
+
+type CSVInput struct {
+	FileHeaderInfo             enum.FileHeaderInfoType `json:"FileHeaderInfo,omitempty"`
+	RecordDelimiter            string                  `json:"RecordDelimiter,omitempty"`
+	FieldDelimiter             string                  `json:"FieldDelimiter,omitempty"`
+	QuoteCharacter             string                  `json:"QuoteCharacter,omitempty"`
+	QuoteEscapeCharacter       string                  `json:"QuoteEscapeCharacter,omitempty"`
+	Comments                   string                  `json:"Comments,omitempty"`
+	AllowQuotedRecordDelimiter bool                    `json:"AllowQuotedRecordDelimiter,omitempty"`
+}
+
+type JSONInput struct {
+	Type enum.JSONType `json:"Type,omitempty"`
+}
+
+// SelectInputSerialization one of CSV and JSON must be set
+type SelectInputSerialization struct {
+	CompressionType enum.CompressionType `json:"CompressionType,omitempty"`
+	CSV             *CSVInput            `json:"CSV,omitempty"`
+	JSON            *JSONInput           `json:"JSON,omitempty"`
+}
+
+type CSVOutput struct {
+	RecordDelimiter      string `json:"RecordDelimiter,omitempty"`
+	FieldDelimiter       string `json:"FieldDelimiter,omitempty"`
+	QuoteCharacter       string `json:"QuoteCharacter,omitempty"`
+	QuoteEscapeCharacter string `json:"QuoteEscapeCharacter,omitempty"`
+}
+
+type JSONOutput struct {
+	RecordDelimiter string `json:"RecordDelimiter,omitempty"`
+}
+
+// SelectOutputSerialization one of CSV and JSON must be set
+type SelectOutputSerialization struct {
+	CSV          *CSVOutput  `json:"CSV,omitempty"`
+	JSON         *JSONOutput `json:"JSON,omitempty"`
+	OutputHeader bool        `json:"OutputHeader,omitempty"`
+}
+
+type SelectObjectContentV2Input struct {
+	Bucket    string `json:"-"`
+	Key       string `json:"-"`
+	VersionID string `json:"-" location:"query" locationName:"versionId"`
+
+	Expression          string                    `json:"Expression"`
+	ExpressionType      string                    `json:"ExpressionType,omitempty"` // SQL by default
+	InputSerialization  SelectInputSerialization  `json:"InputSerialization"`
+	OutputSerialization SelectOutputSerialization `json:"OutputSerialization"`
+
+	SSECAlgorithm string `json:"-" location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Algorithm"`
+	SSECKey       string `json:"-" location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key"`
+	SSECKeyMD5    string `json:"-" location:"header" locationName:"X-Tos-Server-Side-Encryption-Customer-Key-MD5"`
+
+	// SelectEventListener receives Progress, Stats and End events while reading Content of output, optional
+	SelectEventListener SelectEventListener `json:"-"`
+}
+
+type SelectProgress struct {
+	BytesScanned   int64
+	BytesProcessed int64
+	BytesReturned  int64
+}
+
+type SelectEvent struct {
+	Type enum.SelectEventType
+	// Progress is set for Progress and Stats events
+	Progress *SelectProgress
+}
+
+type SelectEventListener interface {
+	EventChange(event *SelectEvent)
+}
+
+type SelectObjectContentV2Output struct {
+	RequestInfo
+	// Content is records selected, read it until io.EOF to make sure all records are received,
+	// malformed frames and server errors in the middle of stream are returned by Read
+	Content io.ReadCloser
+}
@@ -2,18 +2,25 @@ package tos
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"hash"
 	"hash/crc64"
 	"io"
 	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/sirupsen/logrus"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/codes"
 	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
 )
 
@@ -84,8 +91,15 @@ type accessControlList struct {
 	Grants []GrantV2
 }
 
+type bucketAccessControlList struct {
+	Owner              Owner
+	Grants             []GrantV2
+	BucketAclDelivered bool
+}
+
 type canceler struct {
 	called       int32
+	isAbort      bool
 	cancelHandle chan struct{}
 	// cleaner will clean all files need to be deleted
 	cleaner func()
@@ -98,6 +112,9 @@ func (c *canceler) Cancel(isAbort bool) {
 		return
 	}
 	if atomic.CompareAndSwapInt32(&c.called, 0, 1) {
+		c.isAbort = isAbort
+		// stop scheduling new parts and interrupt parts in flight first
+		close(c.cancelHandle)
 		if isAbort {
 			if c.cleaner != nil {
 				c.cleaner()
@@ -106,7 +123,16 @@ func (c *canceler) Cancel(isAbort bool) {
 				c.aborter()
 			}
 		}
-		close(c.cancelHandle)
+	}
+}
+
+// canceled returns whether Cancel has been called, and whether it is called with isAbort true
+func (c *canceler) canceled() (canceled bool, isAbort bool) {
+	select {
+	case <-c.cancelHandle:
+		return true, c.isAbort
+	default:
+		return false, false
 	}
 }
 
@@ -135,11 +161,12 @@ type downloadPartInfo struct {
 }
 
 type downloadCheckpoint struct {
-	checkpointPath string // this filed should not be marshaled
-	Bucket         string `json:"Bucket,omitempty"`
-	Key            string `json:"Key,omitempty"`
-	VersionID      string `json:"VersionID,omitempty"`
-	PartSize       int64  `json:"PartSize,omitempty"`
+	checkpointPath string          // this filed should not be marshaled
+	store          CheckpointStore // this filed should not be marshaled
+	Bucket         string          `json:"Bucket,omitempty"`
+	Key            string          `json:"Key,omitempty"`
+	VersionID      string          `json:"VersionID,omitempty"`
+	PartSize       int64           `json:"PartSize,omitempty"`
 
 	IfMatch           string    `json:"IfMatch,omitempty"`
 	IfModifiedSince   time.Time `json:"IfModifiedSince,omitempty"`
@@ -159,20 +186,27 @@ func (c *downloadCheckpoint) UpdatePartsInfo(result interface{}) {
 
 }
 
-func (c *downloadCheckpoint) GetCheckPointFilePath() string {
-	return c.checkpointPath
+// completedBytes returns bytes of parts already downloaded
+func (c *downloadCheckpoint) completedBytes() int64 {
+	var completed int64
+	for _, part := range c.PartsInfo {
+		if part.IsCompleted {
+			completed += part.RangeEnd - part.RangeStart + 1
+		}
+	}
+	return completed
 }
 
-func (c *downloadCheckpoint) WriteToFile() error {
-	buffer, err := json.Marshal(c)
-	if err != nil {
-		return newTosClientError(err.Error(), err)
-	}
-	err = ioutil.WriteFile(c.checkpointPath, buffer, 0666)
-	if err != nil {
-		return newTosClientError(err.Error(), err)
-	}
-	return nil
+func (c *downloadCheckpoint) Delete() {
+	deleteCheckpoint(c.store, c.storeKey())
+}
+
+func (c *downloadCheckpoint) Save() error {
+	return saveCheckpoint(c.store, c.storeKey(), c)
+}
+
+func (c *downloadCheckpoint) storeKey() CheckpointKey {
+	return CheckpointKey{Operation: checkpointDownload, Bucket: c.Bucket, Key: c.Key, CheckpointFile: c.checkpointPath}
 }
 
 func (c *downloadCheckpoint) Valid(input *DownloadFileInput, head *HeadObjectV2Output) bool {
@@ -211,6 +245,7 @@ type uploadPartInfo struct {
 
 type uploadCheckpoint struct {
 	checkpointPath string           // this filed should not be marshaled
+	store          CheckpointStore  // this filed should not be marshaled
 	Bucket         string           `json:"Bucket,omitempty"`
 	Key            string           `json:"Key,omitempty"`
 	UploadID       string           `json:"UploadID,omitempty"`
@@ -229,8 +264,19 @@ func (u *uploadCheckpoint) UpdatePartsInfo(result interface{}) {
 
 }
 
-func (u *uploadCheckpoint) GetCheckPointFilePath() string {
-	return u.FilePath
+// completedBytes returns bytes of parts already uploaded
+func (u *uploadCheckpoint) completedBytes() int64 {
+	var completed int64
+	for _, part := range u.PartsInfo {
+		if part.IsCompleted {
+			completed += part.PartSize
+		}
+	}
+	return completed
+}
+
+func (u *uploadCheckpoint) Delete() {
+	deleteCheckpoint(u.store, u.storeKey())
 }
 
 func (u *uploadCheckpoint) Valid(uploadFileStat os.FileInfo, bucketName, key, uploadFile string) bool {
@@ -254,16 +300,12 @@ func (u *uploadCheckpoint) GetParts() []UploadedPartV2 {
 	return parts
 }
 
-func (u *uploadCheckpoint) WriteToFile() error {
-	result, err := json.Marshal(u)
-	if err != nil {
-		return newTosClientError(err.Error(), err)
-	}
-	err = ioutil.WriteFile(u.checkpointPath, result, 0666)
-	if err != nil {
-		return newTosClientError(err.Error(), err)
-	}
-	return nil
+func (u *uploadCheckpoint) Save() error {
+	return saveCheckpoint(u.store, u.storeKey(), u)
+}
+
+func (u *uploadCheckpoint) storeKey() CheckpointKey {
+	return CheckpointKey{Operation: checkpointUpload, Bucket: u.Bucket, Key: u.Key, UploadID: u.UploadID, CheckpointFile: u.checkpointPath}
 }
 
 type downloadEvent struct {
@@ -290,12 +332,11 @@ type downloadTask struct {
 	cli         *ClientV2
 	ctx         context.Context
 	input       *DownloadFileInput
-	consumed    *int64
-	subtotal    *int64
-	total       int64
+	progress    *transferProgress
 	partNumber  int
 	rangeStart  int64
 	rangeEnd    int64
+	etag        string
 	enableCRC64 bool
 }
 
@@ -306,6 +347,7 @@ func (t *downloadTask) do() (result interface{}, err error) {
 	if err != nil {
 		return nil, err
 	}
+	defer output.Content.Close()
 	file, err := os.OpenFile(t.input.tempFile, os.O_RDWR, DefaultFilePerm)
 	if err != nil {
 		return nil, err
@@ -313,16 +355,14 @@ func (t *downloadTask) do() (result interface{}, err error) {
 	defer func(file *os.File) {
 		_ = file.Close()
 	}(file)
-	var wrapped = output.Content
-	if t.input.DataTransferListener != nil {
-		wrapped = &parallelReadCloserWithListener{
-			listener: t.input.DataTransferListener,
-			base:     wrapped,
-			consumed: t.consumed,
-			total:    t.total,
-			subtotal: t.subtotal,
+	listened := &readCloserWithProgress{base: output.Content, progress: t.progress}
+	var wrapped io.ReadCloser = listened
+	defer func() {
+		if err != nil {
+			// the part is not finished, its bytes will be downloaded again on resuming
+			t.progress.rollback(listened.read)
 		}
-	}
+	}()
 	if t.input.RateLimiter != nil {
 		wrapped = &ReadCloserWithLimiter{
 			limiter: t.input.RateLimiter,
@@ -362,11 +402,16 @@ func (t *downloadTask) do() (result interface{}, err error) {
 }
 
 func (t *downloadTask) getBaseInput() interface{} {
+	ifMatch := t.input.IfMatch
+	if ifMatch == "" {
+		// make sure all parts come from the same object
+		ifMatch = t.etag
+	}
 	return GetObjectV2Input{
 		Bucket:            t.input.Bucket,
 		Key:               t.input.Key,
 		VersionID:         t.input.VersionID,
-		IfMatch:           t.input.IfMatch,
+		IfMatch:           ifMatch,
 		IfModifiedSince:   t.input.IfModifiedSince,
 		IfNoneMatch:       t.input.IfNoneMatch,
 		IfUnmodifiedSince: t.input.IfUnmodifiedSince,
@@ -404,12 +449,11 @@ func (u *uploadPostEvent) PostEvent(eventType int, result interface{}, taskErr e
 
 type uploadTask struct {
 	cli        *ClientV2
+	retry      partRetryPolicy
 	input      *UploadFileInput
-	consumed   *int64
-	subtotal   *int64
+	progress   *transferProgress
 	mutex      *sync.Mutex
 	ctx        context.Context
-	total      int64
 	UploadID   string
 	ContentMD5 string
 	PartNumber int
@@ -417,26 +461,43 @@ type uploadTask struct {
 	PartSize   int64
 }
 
-// Do the uploadTask, and return uploadPartInfo
+// Do the uploadTask with retries, and return uploadPartInfo
 func (t *uploadTask) do() (interface{}, error) {
+	var output *UploadPartV2Output
+	err := t.retry.Run(t.ctx, func(ctx context.Context) (err error) {
+		output, err = t.uploadPart(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uploadPartInfo{
+		uploadID:      &t.UploadID,
+		PartNumber:    output.PartNumber,
+		PartSize:      t.PartSize,
+		Offset:        t.Offset,
+		ETag:          output.ETag,
+		HashCrc64ecma: output.HashCrc64ecma,
+		IsCompleted:   true,
+	}, nil
+}
+
+// uploadPart reads the part from file and uploads it once
+func (t *uploadTask) uploadPart(ctx context.Context) (*UploadPartV2Output, error) {
 	file, err := os.Open(t.input.FilePath)
 	if err != nil {
 		return nil, newTosClientError(err.Error(), err)
 	}
+	defer file.Close()
 	_, err = file.Seek(int64(t.Offset), io.SeekStart)
 	if err != nil {
 		return nil, newTosClientError(err.Error(), err)
 	}
-	var wrapped = ioutil.NopCloser(io.LimitReader(file, t.input.PartSize))
-	if t.input.DataTransferListener != nil {
-		wrapped = &parallelReadCloserWithListener{
-			listener: t.input.DataTransferListener,
-			base:     wrapped,
-			total:    t.total,
-			subtotal: t.subtotal,
-			consumed: t.consumed,
-		}
+	listened := &readCloserWithProgress{
+		base:     ioutil.NopCloser(io.LimitReader(file, t.PartSize)),
+		progress: t.progress,
 	}
+	var wrapped io.ReadCloser = listened
 	if t.input.RateLimiter != nil {
 		wrapped = &ReadCloserWithLimiter{
 			limiter: t.input.RateLimiter,
@@ -444,24 +505,16 @@ func (t *uploadTask) do() (interface{}, error) {
 		}
 	}
 	input := t.getBaseInput().(UploadPartV2Input)
-	input.Content = wrapped
-	output, err := t.cli.UploadPartV2(t.ctx, &UploadPartV2Input{
+	output, err := t.cli.UploadPartV2(ctx, &UploadPartV2Input{
 		UploadPartBasicInput: input.UploadPartBasicInput,
 		Content:              wrapped,
 		ContentLength:        input.ContentLength,
 	})
 	if err != nil {
-		return nil, err
+		// the part will be read again on retrying
+		t.progress.rollback(listened.read)
 	}
-	return uploadPartInfo{
-		uploadID:      &t.UploadID,
-		PartNumber:    output.PartNumber,
-		PartSize:      t.PartSize,
-		Offset:        t.Offset,
-		ETag:          output.ETag,
-		HashCrc64ecma: output.HashCrc64ecma,
-		IsCompleted:   true,
-	}, nil
+	return output, err
 }
 
 func (t *uploadTask) getBaseInput() interface{} {
@@ -481,6 +534,149 @@ func (t *uploadTask) getBaseInput() interface{} {
 	}
 }
 
+type copySourceObjectInfo struct {
+	Etag          string    `json:"Etag,omitempty"`
+	HashCrc64ecma uint64    `json:"HashCrc64Ecma,omitempty"`
+	LastModified  time.Time `json:"LastModified,omitempty"`
+	ObjectSize    int64     `json:"ObjectSize"`
+}
+
+// copyPartInfo is for checkpoint
+type copyPartInfo struct {
+	PartNumber           int    `json:"PartNumber"`
+	CopySourceRangeStart int64  `json:"CopySourceRangeStart"`
+	CopySourceRangeEnd   int64  `json:"CopySourceRangeEnd"`
+	ETag                 string `json:"ETag,omitempty"`
+	IsCompleted          bool   `json:"IsCompleted"`
+}
+
+type copyCheckpoint struct {
+	checkpointPath       string               // this filed should not be marshaled
+	store                CheckpointStore      // this filed should not be marshaled
+	SrcBucket            string               `json:"SrcBucket,omitempty"`
+	SrcKey               string               `json:"SrcKey,omitempty"`
+	SrcVersionID         string               `json:"SrcVersionID,omitempty"`
+	Bucket               string               `json:"Bucket,omitempty"`
+	Key                  string               `json:"Key,omitempty"`
+	UploadID             string               `json:"UploadID,omitempty"`
+	PartSize             int64                `json:"PartSize"`
+	SSECAlgorithm        string               `json:"SSECAlgorithm,omitempty"`
+	SSECKeyMD5           string               `json:"SSECKeyMD5,omitempty"`
+	EncodingType         string               `json:"EncodingType,omitempty"`
+	CopySourceObjectInfo copySourceObjectInfo `json:"CopySourceObjectInfo"`
+	PartsInfo            []copyPartInfo       `json:"PartsInfo,omitempty"`
+}
+
+func (c *copyCheckpoint) UpdatePartsInfo(result interface{}) {
+	part := result.(copyPartInfo)
+	c.PartsInfo[part.PartNumber-1] = part
+}
+
+func (c *copyCheckpoint) Delete() {
+	deleteCheckpoint(c.store, c.storeKey())
+}
+
+// Valid checks whether the recorded upload copies the same source object to the same destination
+func (c *copyCheckpoint) Valid(input *ResumableCopyObjectInput, head *HeadObjectV2Output) bool {
+	if c.UploadID == "" || c.SrcBucket != input.SrcBucket || c.SrcKey != input.SrcKey ||
+		c.SrcVersionID != input.SrcVersionID || c.Bucket != input.Bucket || c.Key != input.Key ||
+		c.PartSize != input.PartSize || c.SSECAlgorithm != input.SSECAlgorithm || c.SSECKeyMD5 != input.SSECKeyMD5 {
+		return false
+	}
+	if c.CopySourceObjectInfo.Etag != head.ETag || c.CopySourceObjectInfo.HashCrc64ecma != head.HashCrc64ecma ||
+		!c.CopySourceObjectInfo.LastModified.Equal(head.LastModified) || c.CopySourceObjectInfo.ObjectSize != head.ContentLength {
+		return false
+	}
+	return true
+}
+
+func (c *copyCheckpoint) GetParts() []UploadedPartV2 {
+	parts := make([]UploadedPartV2, 0, len(c.PartsInfo))
+	for _, p := range c.PartsInfo {
+		parts = append(parts, UploadedPartV2{
+			PartNumber: p.PartNumber,
+			ETag:       p.ETag,
+		})
+	}
+	return parts
+}
+
+func (c *copyCheckpoint) Save() error {
+	return saveCheckpoint(c.store, c.storeKey(), c)
+}
+
+func (c *copyCheckpoint) storeKey() CheckpointKey {
+	return CheckpointKey{Operation: checkpointCopy, Bucket: c.Bucket, Key: c.Key, UploadID: c.UploadID, CheckpointFile: c.checkpointPath}
+}
+
+type copyPostEvent struct {
+	input      *ResumableCopyObjectInput
+	checkPoint *copyCheckpoint
+}
+
+func (c *copyPostEvent) PostEvent(eventType int, result interface{}, taskErr error) {
+	switch eventType {
+	case EventPartSucceed:
+		part, ok := result.(copyPartInfo)
+		if !ok {
+			return
+		}
+		c.postCopyEvent(c.newUploadPartCopySucceedEvent(part))
+	case EventPartFailed:
+		c.postCopyEvent(c.newFailedEvent(taskErr, enum.CopyEventUploadPartCopyFailed))
+	case EventPartAborted:
+		c.postCopyEvent(c.newFailedEvent(taskErr, enum.CopyEventUploadPartCopyAborted))
+	}
+}
+
+type copyTask struct {
+	cli        *ClientV2
+	ctx        context.Context
+	input      *ResumableCopyObjectInput
+	UploadID   string
+	etag       string
+	PartNumber int
+	RangeStart int64
+	RangeEnd   int64
+}
+
+// Do the copyTask, and return copyPartInfo
+func (t *copyTask) do() (interface{}, error) {
+	input := t.getBaseInput().(UploadPartCopyV2Input)
+	output, err := t.cli.UploadPartCopyV2(t.ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+	return copyPartInfo{
+		PartNumber:           t.PartNumber,
+		CopySourceRangeStart: t.RangeStart,
+		CopySourceRangeEnd:   t.RangeEnd,
+		ETag:                 output.ETag,
+		IsCompleted:          true,
+	}, nil
+}
+
+func (t *copyTask) getBaseInput() interface{} {
+	return UploadPartCopyV2Input{
+		Bucket:       t.input.Bucket,
+		Key:          t.input.Key,
+		UploadID:     t.UploadID,
+		PartNumber:   t.PartNumber,
+		SrcBucket:    t.input.SrcBucket,
+		SrcKey:       t.input.SrcKey,
+		SrcVersionID: t.input.SrcVersionID,
+		// parts copied from a changed source object will fail with 412, and the copy is aborted
+		CopySourceIfMatch:       t.etag,
+		CopySourceRange:         fmt.Sprintf("bytes=%d-%d", t.RangeStart, t.RangeEnd),
+		CopySourceSSECAlgorithm: t.input.CopySourceSSECAlgorithm,
+		CopySourceSSECKey:       t.input.CopySourceSSECKey,
+		CopySourceSSECKeyMD5:    t.input.CopySourceSSECKeyMD5,
+		SSECAlgorithm:           t.input.SSECAlgorithm,
+		SSECKey:                 t.input.SSECKey,
+		SSECKeyMD5:              t.input.SSECKeyMD5,
+	}
+}
+
 type retryAction int
 
 const (
@@ -490,38 +686,65 @@ const (
 
 const (
 	DefaultRetryBackoffBase = 100 * time.Millisecond
+	DefaultRetryBackoffCap  = 10 * time.Second
+	DefaultMaxRetryAfter    = 30 * time.Second
+	DefaultPartRetryCount   = 3
+	DefaultPartRetryCap     = 10 * time.Second
 )
 
 type classifier interface {
 	Classify(error) retryAction
 }
 
-func exponentialBackoff(n int, base time.Duration) []time.Duration {
-	backoffs := make([]time.Duration, n)
-	for i := 0; i < len(backoffs); i++ {
-		backoffs[i] = base
-		base *= 1
-	}
-	return backoffs
-}
+// JitterMode decides how backoff of retries is randomized
+type JitterMode int
 
-type retryer struct {
-	backoff []time.Duration
-	jitter  float64
+const (
+	// JitterNone waits exactly the backoff
+	JitterNone JitterMode = iota
+	// JitterFull waits a random duration in [0, backoff]
+	JitterFull
+	// JitterEqual waits a random duration in [backoff/2, backoff]
+	JitterEqual
+)
+
+// RetryPolicy is the retry policy of requests failed with retryable errors, e.g. 5xx, 429 and network errors.
+// The backoff of the i-th retry is BackoffBase * 2^i, which is at most BackoffCap, and randomized by Jitter.
+type RetryPolicy struct {
+	MaxRetryCount int           // max retries after the first attempt, 0 disables retry
+	BackoffBase   time.Duration // DefaultRetryBackoffBase is used if it's not positive
+	BackoffCap    time.Duration // DefaultRetryBackoffCap is used if it's not positive
+	Jitter        JitterMode
+	// MaxRetryAfter caps the wait required by Retry-After of throttled responses, i.e. 429, 503 and SlowDown,
+	// DefaultMaxRetryAfter is used if it's not positive
+	MaxRetryAfter time.Duration
+	// OnThrottle is called with the wait before retrying throttled responses, e.g. to collect metrics, nullable
+	OnThrottle func(wait time.Duration, err error)
 }
 
-func (r *retryer) SetBackoff(backoff []time.Duration) {
-	r.backoff = backoff
+type retryer struct {
+	policy RetryPolicy
+	logger logrus.FieldLogger // nullable, throttle waits are logged if it's set
 }
 
-// newRetryer constructs a retryer with the given backoff pattern and classifier. The length of the backoff pattern
-// indicates how many times an action will be retried, and the value at each index indicates the amount of time
-// waited before each subsequent retry. The classifier is used to determine which errors should be retried and
-// which should cause the retrier to fail fast. The DefaultClassifier is used if nil is passed.
-func newRetryer(backoff []time.Duration) *retryer {
-	return &retryer{
-		backoff: backoff,
+// newRetryer constructs a retryer with the given policy, default values are used for zero fields of the policy.
+func newRetryer(policy RetryPolicy) *retryer {
+	if policy.MaxRetryCount < 0 {
+		policy.MaxRetryCount = 0
+	}
+	if policy.BackoffBase <= 0 {
+		policy.BackoffBase = DefaultRetryBackoffBase
 	}
+	if policy.BackoffCap <= 0 {
+		policy.BackoffCap = DefaultRetryBackoffCap
+	}
+	if policy.BackoffCap < policy.BackoffBase {
+		policy.BackoffCap = policy.BackoffBase
+	}
+	if policy.MaxRetryAfter <= 0 {
+		policy.MaxRetryAfter = DefaultMaxRetryAfter
+	}
+	return &retryer{policy: policy}
 }
 
 func worthToRetry(ctx context.Context, waitTime time.Duration) bool {
@@ -540,38 +763,247 @@ func worthToRetry(ctx context.Context, waitTime time.Duration) bool {
 }
 
 // Run executes the given work function, then classifies its return value based on the classifier.
-// If the result is Succeed or Fail, the return value of the work function is
-// returned to the caller. If the result is Retry, then Run sleeps according to its backoff policy
-// before retrying. If the total number of retries is exceeded then the return value of the work function
-// is returned to the caller regardless.
+// If the result is NoRetry, the return value of the work function is returned to the caller.
+// If the result is Retry, then Run sleeps according to its backoff policy before retrying, or at least
+// Retry-After of throttled responses, unless ctx is done or its deadline would be exceeded by sleeping.
+// If the total number of retries is exceeded then the return value of the work function
+// is returned to the caller regardless. The number of attempts is recorded in the returned
+// TosServerError or TosClientError.
 func (r *retryer) Run(ctx context.Context, work func() error, classifier classifier) error {
 	// run
 	ferr := work()
+	attempts := 1
 	// try retry
-	for i := 0; i < len(r.backoff) && classifier.Classify(ferr) == Retry; i++ {
-		// 重试
+	for i := 0; i < r.policy.MaxRetryCount && classifier.Classify(ferr) == Retry; i++ {
 		sleepTime := r.calcSleep(i)
+		if wait, throttled := throttleWait(ferr, time.Now()); throttled {
+			if wait > r.policy.MaxRetryAfter {
+				wait = r.policy.MaxRetryAfter
+			}
+			if wait > sleepTime {
+				sleepTime = wait
+			}
+			r.onThrottle(sleepTime, ferr)
+		}
 		if !worthToRetry(ctx, sleepTime) {
-			return ferr
+			break
+		}
+		if !sleepWithContext(ctx, sleepTime) {
+			break
 		}
-		time.Sleep(sleepTime)
 		ferr = work()
+		attempts++
 	}
-	return ferr
+	return withAttempts(ferr, attempts)
 }
 
+// calcSleep returns backoff of the i-th retry
 func (r *retryer) calcSleep(i int) time.Duration {
-	// take a random float in the range (-r.jitter, +r.jitter) and multiply it by the base amount
-	return r.backoff[i]
+	p := r.policy
+	d := p.BackoffCap
+	if i < 32 && p.BackoffBase<<uint(i) > 0 && p.BackoffBase<<uint(i) < p.BackoffCap {
+		d = p.BackoffBase << uint(i)
+	}
+	switch p.Jitter {
+	case JitterFull:
+		return time.Duration(rand.Int63n(int64(d) + 1))
+	case JitterEqual:
+		return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	default:
+		return d
+	}
 }
 
-// SetJitter sets the amount of jitter on each back-off to a factor between 0.0 and 1.0 (values outside this range
-// are silently ignored). When a retry occurs, the back-off is adjusted by a random amount up to this value.
-func (r *retryer) SetJitter(jit float64) {
-	if jit < 0 || jit > 1 {
-		return
+func (r *retryer) onThrottle(wait time.Duration, err error) {
+	if r.logger != nil {
+		requestID := ""
+		if e, ok := asServerError(err); ok {
+			requestID = e.RequestID
+		}
+		r.logger.Infof("[tos] request throttled, RequestId:%s, retry after %d ms", requestID, wait.Milliseconds())
+	}
+	if r.policy.OnThrottle != nil {
+		r.policy.OnThrottle(wait, err)
+	}
+}
+
+// throttleWait reports whether err is a throttled response, i.e. 429, 503 and SlowDown,
+// and returns the wait required by its Retry-After header, which is in seconds or HTTP-date
+func throttleWait(err error, now time.Time) (time.Duration, bool) {
+	e, ok := asServerError(err)
+	if !ok || (e.StatusCode != http.StatusTooManyRequests && e.StatusCode != http.StatusServiceUnavailable && e.Code != codes.SlowDown) {
+		return 0, false
+	}
+	if e.Header == nil {
+		return 0, true
+	}
+	value := strings.TrimSpace(e.Header.Get(HeaderRetryAfter))
+	if len(value) == 0 {
+		return 0, true
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds <= 0 {
+			return 0, true
+		}
+		if seconds > int64(math.MaxInt64/time.Second) {
+			return time.Duration(math.MaxInt64), true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now), true
+	}
+	return 0, true
+}
+
+// sleepWithContext sleeps d, and returns false if ctx is done before that
+func sleepWithContext(ctx context.Context, d time.Duration) bool {
+	if ctx == nil {
+		time.Sleep(d)
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// withAttempts returns a copy of err with the number of attempts made, err is returned as is if it's not
+// *TosClientError, *TosServerError or a typed error embedding TosServerError
+func withAttempts(err error, attempts int) error {
+	if e, ok := err.(*TosClientError); ok {
+		ce := *e
+		ce.Attempts = attempts
+		return &ce
+	}
+	if _, ok := asServerError(err); !ok {
+		return err
+	}
+	// copy the typed error as is, e.g. *PreconditionFailedError, and set Attempts of the embedded TosServerError
+	value := reflect.ValueOf(err).Elem()
+	copied := reflect.New(value.Type())
+	copied.Elem().Set(value)
+	err = copied.Interface().(error)
+	se, _ := asServerError(err)
+	se.Attempts = attempts
+	return err
+}
+
+// partRetryPolicy is the retry policy of each part in high-level transfers,
+// so that every part has its own retry budget.
+type partRetryPolicy struct {
+	maxRetries int
+	base       time.Duration
+	backoffCap time.Duration
+}
+
+// newPartRetryPolicy returns a partRetryPolicy, default values are used for zero arguments and negative maxRetries disables retry
+func newPartRetryPolicy(maxRetries int, base, backoffCap time.Duration) partRetryPolicy {
+	if maxRetries == 0 {
+		maxRetries = DefaultPartRetryCount
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if base <= 0 {
+		base = DefaultRetryBackoffBase
+	}
+	if backoffCap <= 0 {
+		backoffCap = DefaultPartRetryCap
+	}
+	return partRetryPolicy{maxRetries: maxRetries, base: base, backoffCap: backoffCap}
+}
+
+// backoff returns exponential backoff of the i-th retry with jitter, the result ranges in [d/2, d] where d is at most backoffCap
+func (p partRetryPolicy) backoff(i int) time.Duration {
+	d := p.backoffCap
+	if i < 32 && p.base<<uint(i) > 0 && p.base<<uint(i) < p.backoffCap {
+		d = p.base << uint(i)
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// Run executes work, and retries it if the error is retryable until maxRetries is exhausted or ctx is done.
+// Retries of the client are disabled in the context passed to work, so that attempts are not multiplied.
+func (p partRetryPolicy) Run(ctx context.Context, work func(ctx context.Context) error) error {
+	workCtx := ctx
+	if p.maxRetries > 0 {
+		workCtx = ContextWithRetryPolicy(ctx, RetryPolicy{})
+	}
+	err := work(workCtx)
+	for i := 0; i < p.maxRetries && (partErrorClassifier{}).Classify(err) == Retry; i++ {
+		timer := time.NewTimer(p.backoff(i))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		err = work(workCtx)
+	}
+	return err
+}
+
+// partErrorClassifier classify errors of a part in high-level transfers.
+// TosServerError and typed errors embedding it with status code 304 or 4xx other than 408 and 429 are not retryable
+// and fail fast, e.g. 403, 412 and InvalidPart;
+// errors caused by canceled context are not retryable;
+// other errors, e.g. 5xx, network errors and crc mismatch, are retryable.
+type partErrorClassifier struct{}
+
+// Classify implements the classifier interface.
+func (classifier partErrorClassifier) Classify(err error) retryAction {
+	if err == nil {
+		return NoRetry
+	}
+	if e, ok := asServerError(err); ok {
+		if e.StatusCode == http.StatusNotModified || isFailFastStatusCode(e.StatusCode) {
+			return NoRetry
+		}
+		return Retry
+	}
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return NoRetry
+	}
+	if e, ok := err.(*TosClientError); ok && (e.Cause == context.Canceled || e.Cause == context.DeadlineExceeded) {
+		return NoRetry
 	}
-	r.jitter = jit
+	return Retry
+}
+
+// isFailFastStatusCode returns true for status code 4xx other than 408 and 429, which can not be fixed by retrying
+func isFailFastStatusCode(code int) bool {
+	return code >= 400 && code < 500 && code != 408 && code != 429
+}
+
+// seekableReadCloser keeps io.Seeker of the base reader wrapped by wrapReader,
+// so that the request body can be rewound on retrying.
+// Rewinding to the start position resets CRC64 checker too,
+// and rewinding DataTransferListener progress posts DataTransferRetried event.
+type seekableReadCloser struct {
+	io.ReadCloser
+	seeker   io.Seeker
+	start    int64
+	checker  hash.Hash64
+	listened *readCloserWithListener
+}
+
+func (r *seekableReadCloser) Seek(offset int64, whence int) (int64, error) {
+	pos, err := r.seeker.Seek(offset, whence)
+	if err != nil || (offset == 0 && whence == io.SeekCurrent) {
+		return pos, err
+	}
+	if pos == r.start && r.checker != nil {
+		r.checker.Reset()
+	}
+	if r.listened != nil && pos >= r.start {
+		r.listened.rewind(pos - r.start)
+	}
+	return pos, nil
 }
 
 // readCloserWithCRC warp io.ReadCloser with crc checker
@@ -594,62 +1026,382 @@ func (r *readCloserWithCRC) Close() error {
 	return r.base.Close()
 }
 
-// parallelReadCloserWithListener warp multiple io.ReadCloser will be R/W in parallel with a same DataTransferListener
-type parallelReadCloserWithListener struct {
+// readCloserWithCRCCheck computes CRC64 of data read, and checks it with the expected one
+// when EOF is reached or it is closed, a *TosClientError caused by *ChecksumError is returned on mismatch.
+type readCloserWithCRCCheck struct {
+	base      io.ReadCloser
+	checker   hash.Hash64
+	expected  uint64
+	requestID string
+	verified  bool
+	err       error
+}
+
+func (r *readCloserWithCRCCheck) Read(p []byte) (n int, err error) {
+	n, err = r.base.Read(p)
+	if n > 0 {
+		_, _ = r.checker.Write(p[:n])
+	}
+	if err == io.EOF {
+		if verifyErr := r.verify(); verifyErr != nil {
+			return n, verifyErr
+		}
+	}
+	return
+}
+
+func (r *readCloserWithCRCCheck) verify() error {
+	if !r.verified {
+		r.verified = true
+		if actual := r.checker.Sum64(); actual != r.expected {
+			r.err = newChecksumMismatchError(r.requestID, r.expected, actual)
+		}
+	}
+	return r.err
+}
+
+// Close closes base ReadCloser, and returns checksum error if data read mismatches,
+// so data must be read entirely before Close unless CRC check is disabled.
+func (r *readCloserWithCRCCheck) Close() error {
+	err := r.base.Close()
+	if verifyErr := r.verify(); verifyErr != nil {
+		return verifyErr
+	}
+	return err
+}
+
+// readCloserWithResume reads content of GetObjectV2, and on read error requests the rest of the content again
+// from the offset read with If-Match of the original ETag, at most MaxResumeCount times.
+type readCloserWithResume struct {
+	ctx       context.Context
+	cli       *ClientV2
+	input     GetObjectV2Input
+	base      io.ReadCloser
+	etag      string
+	start     int64 // offset of the first byte of the original response in the object
+	end       int64 // offset of the last byte in the object, -1 means to the end of the object
+	read      int64
+	remaining int
+	err       error // error of resuming, returned by all following Read
+}
+
+func newReadCloserWithResume(ctx context.Context, cli *ClientV2, input *GetObjectV2Input, base io.ReadCloser,
+	basic *GetObjectBasicOutput) *readCloserWithResume {
+	r := &readCloserWithResume{
+		ctx:       ctx,
+		cli:       cli,
+		input:     *input,
+		base:      base,
+		etag:      basic.ETag,
+		end:       -1,
+		remaining: input.MaxResumeCount,
+	}
+	if basic.Range != nil && basic.Range.Start >= 0 {
+		r.start, r.end = basic.Range.Start, basic.Range.End
+	}
+	// the rest of content is requested without listener, limiter and CRC check of its own,
+	// they are applied to the whole content by wrappers of this reader
+	r.input.RangeStart, r.input.RangeEnd = 0, 0
+	r.input.IfMatch = basic.ETag
+	r.input.DisableCRCCheck = true
+	r.input.MaxResumeCount = 0
+	r.input.DataTransferListener = nil
+	r.input.RateLimiter = nil
+	return r
+}
+
+func (r *readCloserWithResume) Read(p []byte) (int, error) {
+	for {
+		if r.err != nil {
+			return 0, r.err
+		}
+		n, err := r.base.Read(p)
+		r.read += int64(n)
+		if err == nil || err == io.EOF || r.remaining <= 0 || r.ctx.Err() != nil {
+			return n, err
+		}
+		if r.err = r.resume(err); r.err != nil {
+			return n, r.err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// resume requests the rest of content, it returns *ObjectChangedError if the object is replaced
+func (r *readCloserWithResume) resume(err error) error {
+	_ = r.base.Close()
+	// the closed base is not closed again if resuming failed
+	r.base = ioutil.NopCloser(strings.NewReader(""))
+	for r.remaining > 0 {
+		r.remaining--
+		r.input.Range = &HTTPRange{Start: r.start + r.read, End: r.end}
+		output, getErr := r.cli.GetObjectV2(r.ctx, &r.input)
+		if getErr == nil {
+			r.base = output.Content
+			return nil
+		}
+		if pe, ok := getErr.(*PreconditionFailedError); ok {
+			return &ObjectChangedError{TosServerError: pe.TosServerError, ETag: r.etag}
+		}
+		err = getErr
+		if (partErrorClassifier{}).Classify(err) == NoRetry {
+			break
+		}
+	}
+	return err
+}
+
+func (r *readCloserWithResume) Close() error {
+	return r.base.Close()
+}
+
+// copyBufferSize size of buffers used by WriteTo of object content,
+// larger than 32KB of io.Copy to reduce syscalls of writing to files
+const copyBufferSize = 1024 * 1024
+
+var copyBufferPool = sync.Pool{New: func() interface{} {
+	buf := make([]byte, copyBufferSize)
+	return &buf
+}}
+
+// readCloserWithWriterTo implements io.WriterTo with a pooled buffer, so that io.Copy neither allocates a buffer
+// nor uses ReadFrom of dst. Data is read by base, so CRC64 is checked and DataTransferListener is notified.
+// If dst is a regular file and the body is a file or connection only wrapped by DataTransferListener and RateLimiter,
+// the body is copied by ReadFrom of dst instead, so that the runtime can copy with copy_file_range or splice,
+// the listener and limiter are fed with bytes copied in each segment of copyBufferSize.
+// CRC64 can't be checked without reading data, so content with CRC64 check is always copied with the buffer.
+type readCloserWithWriterTo struct {
+	io.ReadCloser
+	raw      io.Reader // the body if it can be copied by ReadFrom of *os.File, nil otherwise
+	listened *readCloserWithListener
+	limiter  RateLimiter
+}
+
+func newReadCloserWithWriterTo(content io.ReadCloser) *readCloserWithWriterTo {
+	r := &readCloserWithWriterTo{ReadCloser: content}
+	for {
+		switch base := content.(type) {
+		case *seekableReadCloser:
+			content = base.ReadCloser
+		case *ReadCloserWithLimiter:
+			r.limiter, content = base.limiter, base.base
+		case *readCloserWithListener:
+			r.listened, content = base, base.base
+		case syscall.Conn:
+			r.raw = content
+			return r
+		default:
+			return r
+		}
+	}
+}
+
+func (r *readCloserWithWriterTo) WriteTo(w io.Writer) (written int64, err error) {
+	if file, ok := w.(*os.File); ok && r.raw != nil {
+		// ReadFrom of non-regular files such as /dev/null and pipes falls back to copying with a 32KB buffer
+		if stat, err := file.Stat(); err == nil && stat.Mode().IsRegular() {
+			return r.readFrom(file)
+		}
+	}
+	bufp := copyBufferPool.Get().(*[]byte)
+	defer copyBufferPool.Put(bufp)
+	buf := *bufp
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			nw, werr := w.Write(buf[:n])
+			written += int64(nw)
+			if werr != nil {
+				return written, werr
+			}
+			if nw != n {
+				return written, io.ErrShortWrite
+			}
+		}
+		if rerr == io.EOF {
+			return written, nil
+		}
+		if rerr != nil {
+			return written, rerr
+		}
+	}
+}
+
+// readFrom copies the body to file by ReadFrom of file in segments of copyBufferSize,
+// ReadFrom returns less than the segment only at EOF or on error.
+func (r *readCloserWithWriterTo) readFrom(file *os.File) (written int64, err error) {
+	if r.listened != nil {
+		r.listened.start()
+	}
+	segment := &io.LimitedReader{R: r.raw}
+	for {
+		if r.limiter != nil {
+			acquireRate(r.limiter, copyBufferSize)
+		}
+		segment.N = copyBufferSize
+		n, err := file.ReadFrom(segment)
+		written += n
+		if err == nil && n < copyBufferSize {
+			err = io.EOF
+		}
+		if r.listened != nil {
+			r.listened.advance(int(n), err)
+		}
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// transferProgress aggregates bytes R/W by parts in parallel into a single DataTransferListener stream.
+// ConsumedBytes reported never decreases: bytes of a failed part are rolled back,
+// and the progress is reported again only after it exceeds the reported one.
+// Events are queued under lock and posted after unlocking by one goroutine at a time, in order,
+// so that a slow listener doesn't block parts R/W by other goroutines.
+type transferProgress struct {
 	listener DataTransferListener
-	base     io.ReadCloser
-	consumed *int64
-	subtotal *int64
+	lock     sync.Mutex
 	total    int64
+	consumed int64 // bytes R/W by now, excluding rolled back bytes
+	reported int64 // ConsumedBytes of the last reported DataTransferRW event
+	finished bool
+	pending  []DataTransferStatus // events not posted yet
+	posting  bool                 // whether a goroutine is posting pending events
 }
 
-func (r *parallelReadCloserWithListener) Read(p []byte) (n int, err error) {
-	n, err = r.base.Read(p)
-	if err != nil && err != io.EOF {
-		postDataTransferStatus(r.listener, &DataTransferStatus{
-			Type: enum.DataTransferFailed,
-		})
-		return n, err
+func newTransferProgress(listener DataTransferListener, total int64) *transferProgress {
+	return &transferProgress{listener: listener, total: total}
+}
+
+// start posts DataTransferStarted event, consumed is bytes of parts finished before, e.g. resumed from checkpoint
+func (p *transferProgress) start(consumed int64, partSize int64) {
+	p.lock.Lock()
+	p.consumed = consumed
+	p.reported = consumed
+	p.enqueue(DataTransferStatus{
+		TotalBytes:    p.total,
+		ConsumedBytes: consumed,
+		PartSize:      partSize,
+		Type:          enum.DataTransferStarted,
+	})
+	p.unlockAndPost()
+}
+
+func (p *transferProgress) add(n int64) {
+	p.lock.Lock()
+	p.consumed += n
+	if p.consumed > p.total {
+		p.consumed = p.total
+	}
+	if p.consumed-p.reported >= DefaultProgressCallbackSize {
+		p.report()
 	}
-	if n <= 0 {
+	p.unlockAndPost()
+}
+
+// rollback subtracts bytes of a part which will be R/W again or never finished
+func (p *transferProgress) rollback(n int64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.consumed -= n
+}
+
+func (p *transferProgress) report() {
+	if p.consumed <= p.reported {
 		return
 	}
-	subtotal := atomic.AddInt64(r.subtotal, int64(n))
-	consumed := atomic.AddInt64(r.consumed, int64(n))
+	p.enqueue(DataTransferStatus{
+		Type:          enum.DataTransferRW,
+		RWOnceBytes:   p.consumed - p.reported,
+		ConsumedBytes: p.consumed,
+		TotalBytes:    p.total,
+	})
+	p.reported = p.consumed
+}
 
-	if subtotal >= DefaultProgressCallbackSize && atomic.CompareAndSwapInt64(r.subtotal, subtotal, 0) {
-		postDataTransferStatus(r.listener, &DataTransferStatus{
-			Type:          enum.DataTransferRW,
-			RWOnceBytes:   subtotal,
-			ConsumedBytes: consumed,
-			TotalBytes:    r.total,
-		})
+// succeed reports the rest bytes and posts DataTransferSucceed event once
+func (p *transferProgress) succeed() {
+	p.lock.Lock()
+	if p.finished {
+		p.lock.Unlock()
+		return
 	}
+	p.finished = true
+	p.consumed = p.total
+	p.report()
+	p.enqueue(DataTransferStatus{
+		Type:          enum.DataTransferSucceed,
+		ConsumedBytes: p.total,
+		TotalBytes:    p.total,
+	})
+	p.unlockAndPost()
+}
 
-	if consumed == r.total {
-		for subtotal != 0 {
-			if atomic.CompareAndSwapInt64(r.subtotal, subtotal, 0) {
-				postDataTransferStatus(r.listener, &DataTransferStatus{
-					Type:          enum.DataTransferRW,
-					RWOnceBytes:   subtotal,
-					ConsumedBytes: consumed,
-					TotalBytes:    r.total,
-				})
-				break
-			} else {
-				subtotal = atomic.LoadInt64(r.subtotal)
-			}
+// fail posts DataTransferFailed event once
+func (p *transferProgress) fail() {
+	p.lock.Lock()
+	if p.finished {
+		p.lock.Unlock()
+		return
+	}
+	p.finished = true
+	p.enqueue(DataTransferStatus{
+		Type:          enum.DataTransferFailed,
+		ConsumedBytes: p.reported,
+		TotalBytes:    p.total,
+	})
+	p.unlockAndPost()
+}
+
+// enqueue queues an event to post, lock must be held
+func (p *transferProgress) enqueue(status DataTransferStatus) {
+	if p.listener != nil {
+		p.pending = append(p.pending, status)
+	}
+}
+
+// unlockAndPost releases lock, and posts pending events unless another goroutine is posting them
+func (p *transferProgress) unlockAndPost() {
+	if p.posting {
+		p.lock.Unlock()
+		return
+	}
+	p.posting = true
+	for len(p.pending) > 0 {
+		events := p.pending
+		p.pending = nil
+		p.lock.Unlock()
+		for i := range events {
+			p.listener.DataTransferStatusChange(&events[i])
 		}
-		postDataTransferStatus(r.listener, &DataTransferStatus{
-			Type:          enum.DataTransferSucceed,
-			ConsumedBytes: consumed,
-			TotalBytes:    r.total,
-		})
+		p.lock.Lock()
+	}
+	p.posting = false
+	p.lock.Unlock()
+}
+
+// readCloserWithProgress warp io.ReadCloser of a part with transferProgress
+type readCloserWithProgress struct {
+	base     io.ReadCloser
+	progress *transferProgress
+	read     int64 // bytes read from this reader
+}
+
+func (r *readCloserWithProgress) Read(p []byte) (n int, err error) {
+	n, err = r.base.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.progress.add(int64(n))
 	}
 	return
 }
 
-func (r *parallelReadCloserWithListener) Close() error {
+func (r *readCloserWithProgress) Close() error {
 	return r.base.Close()
 }
 
@@ -658,39 +1410,53 @@ type readCloserWithListener struct {
 	listener DataTransferListener
 	base     io.ReadCloser
 	consumed int64
-	subtotal int64
-	total    int64
+	subtotal int64 // bytes consumed but not reported yet
+	total    int64 // negative if unknown, e.g. decompressed content, which succeeds at EOF
+	started  bool
+	finished bool
+	retries  int
 }
 
 func (r *readCloserWithListener) Read(p []byte) (n int, err error) {
-	if r.consumed == 0 {
+	r.start()
+	n, err = r.base.Read(p)
+	r.advance(n, err)
+	return
+}
+
+func (r *readCloserWithListener) start() {
+	if !r.started {
+		r.started = true
 		postDataTransferStatus(r.listener, &DataTransferStatus{
 			Type: enum.DataTransferStarted,
 		})
 	}
-	n, err = r.base.Read(p)
+}
+
+// advance posts events for n bytes and err read from base, either by Read or by readCloserWithWriterTo
+func (r *readCloserWithListener) advance(n int, err error) {
 	if err != nil && err != io.EOF {
 		postDataTransferStatus(r.listener, &DataTransferStatus{
 			Type: enum.DataTransferFailed,
 		})
-		return n, err
-	}
-	if n <= 0 {
 		return
 	}
-	r.consumed += int64(n)
-	r.subtotal += int64(n)
-	if r.subtotal >= DefaultProgressCallbackSize {
-		postDataTransferStatus(r.listener, &DataTransferStatus{
-			Type:          enum.DataTransferRW,
-			RWOnceBytes:   r.subtotal,
-			ConsumedBytes: r.consumed,
-			TotalBytes:    r.total,
-		})
-		r.subtotal = 0
+	if n > 0 {
+		r.consumed += int64(n)
+		r.subtotal += int64(n)
+		if r.subtotal >= DefaultProgressCallbackSize {
+			postDataTransferStatus(r.listener, &DataTransferStatus{
+				Type:          enum.DataTransferRW,
+				RWOnceBytes:   r.subtotal,
+				ConsumedBytes: r.consumed,
+				TotalBytes:    r.total,
+			})
+			r.subtotal = 0
+		}
 	}
 
-	if r.consumed == r.total {
+	if (n > 0 && r.consumed == r.total) || (r.total < 0 && err == io.EOF && !r.finished) {
+		r.finished = true
 		if r.subtotal != 0 {
 			postDataTransferStatus(r.listener, &DataTransferStatus{
 				Type:          enum.DataTransferRW,
@@ -706,7 +1472,30 @@ func (r *readCloserWithListener) Read(p []byte) (n int, err error) {
 			TotalBytes:    r.total,
 		})
 	}
-	return
+}
+
+// rewind rolls consumed bytes back to offset when the data is R/W again on retrying,
+// and posts DataTransferRetried event with bytes rolled back from ConsumedBytes reported before.
+func (r *readCloserWithListener) rewind(offset int64) {
+	if offset < 0 || offset >= r.consumed {
+		return
+	}
+	r.retries++
+	reported := r.consumed - r.subtotal
+	var rollback int64
+	if offset < reported {
+		rollback = reported - offset
+		reported = offset
+	}
+	r.consumed = offset
+	r.subtotal = offset - reported
+	postDataTransferStatus(r.listener, &DataTransferStatus{
+		Type:          enum.DataTransferRetried,
+		ConsumedBytes: reported,
+		TotalBytes:    r.total,
+		RetryCount:    r.retries,
+		RollbackBytes: rollback,
+	})
 }
 
 func (r *readCloserWithListener) Close() error {
@@ -720,15 +1509,19 @@ type ReadCloserWithLimiter struct {
 }
 
 func (r ReadCloserWithLimiter) Read(p []byte) (n int, err error) {
-	want := len(p)
+	acquireRate(r.limiter, int64(len(p)))
+	return r.base.Read(p)
+}
+
+// acquireRate blocks until want bytes are acquired from limiter
+func acquireRate(limiter RateLimiter, want int64) {
 	for {
-		ok, timeToWait := r.limiter.Acquire(int64(want))
+		ok, timeToWait := limiter.Acquire(want)
 		if ok {
-			break
+			return
 		}
 		time.Sleep(timeToWait)
 	}
-	return r.base.Read(p)
 }
 
 func (r ReadCloserWithLimiter) Close() error {
@@ -0,0 +1,192 @@
+package tos
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// directoryEntry is a file or an empty directory to upload
+type directoryEntry struct {
+	path  string
+	key   string
+	size  int64
+	isDir bool
+}
+
+// UploadDirectory uploads files under LocalDir to keys under Prefix.
+// Files are uploaded in parallel, files larger than MultipartThreshold are uploaded by multipart upload,
+// and a file failed to upload is recorded in output rather than stopping others.
+// Error is returned only if walking LocalDir failed or ctx is done, along with files handled before that.
+func (cli *ClientV2) UploadDirectory(ctx context.Context, input *UploadDirectoryInput) (*UploadDirectoryOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(input.LocalDir)
+	if err != nil {
+		return nil, newTosClientError("tos: stat directory to upload failed", err)
+	}
+	if !stat.IsDir() {
+		return nil, newTosClientError("tos: the local path to upload is not a directory", nil)
+	}
+	taskNum := input.TaskNum
+	if taskNum < 1 {
+		taskNum = DefaultDirectoryTaskNum
+	}
+	var (
+		output   UploadDirectoryOutput
+		lock     sync.Mutex
+		wg       sync.WaitGroup
+		inflight = make(chan struct{}, taskNum)
+	)
+	err = walkDirectory(ctx, input, func(entry directoryEntry) {
+		inflight <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-inflight
+				wg.Done()
+			}()
+			uploaded, err := cli.uploadDirectoryEntry(ctx, input, entry)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				output.Failed = append(output.Failed, UploadDirectoryFileError{FilePath: entry.path, Key: entry.key, Err: err})
+				return
+			}
+			output.Succeeded = append(output.Succeeded, *uploaded)
+		}()
+	})
+	wg.Wait()
+	return &output, err
+}
+
+// walkDirectory walks LocalDir and calls handle for each file and empty directory to upload.
+// Symlinks are followed only if FollowSymlinks is set, files are uploaded with keys of the paths walked through,
+// and a symlink to a directory being walked, i.e. an ancestor of the symlink, is skipped to avoid loops.
+func walkDirectory(ctx context.Context, input *UploadDirectoryInput, handle func(entry directoryEntry)) error {
+	ancestors := make(map[string]bool)
+	var walk func(dir, rel string) error
+	walk = func(dir, rel string) error {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			if ancestors[real] {
+				return nil
+			}
+			ancestors[real] = true
+			defer delete(ancestors, real)
+		}
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return newTosClientError("tos: read directory to upload failed", err)
+		}
+		handled := 0
+		for _, info := range infos {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+			path := filepath.Join(dir, info.Name())
+			relPath := rel + info.Name()
+			if info.Mode()&os.ModeSymlink != 0 {
+				if !input.FollowSymlinks {
+					continue
+				}
+				if info, err = os.Stat(path); err != nil {
+					// dangling symlink is skipped
+					continue
+				}
+			}
+			if info.IsDir() {
+				if err = walk(path, relPath+"/"); err != nil {
+					return err
+				}
+				handled++
+				continue
+			}
+			if !info.Mode().IsRegular() {
+				continue
+			}
+			if key := directoryKey(input, relPath); key != "" {
+				handle(directoryEntry{path: path, key: key, size: info.Size()})
+			}
+			handled++
+		}
+		if handled == 0 && rel != "" && input.KeepEmptyDirectory {
+			if key := directoryKey(input, rel); key != "" {
+				handle(directoryEntry{path: dir, key: key, isDir: true})
+			}
+		}
+		return nil
+	}
+	return walk(input.LocalDir, "")
+}
+
+func directoryKey(input *UploadDirectoryInput, relPath string) string {
+	if input.KeyMapper != nil {
+		return input.KeyMapper(relPath)
+	}
+	return input.Prefix + relPath
+}
+
+func (cli *ClientV2) uploadDirectoryEntry(ctx context.Context, input *UploadDirectoryInput, entry directoryEntry) (*UploadedDirectoryFile, error) {
+	threshold := input.MultipartThreshold
+	if threshold <= 0 {
+		threshold = DefaultMultipartThreshold
+	}
+	uploaded := &UploadedDirectoryFile{FilePath: entry.path, Key: entry.key}
+	if entry.isDir || entry.size <= threshold {
+		basic := PutObjectBasicInput{
+			Bucket:               input.Bucket,
+			Key:                  entry.key,
+			ACL:                  input.ACL,
+			StorageClass:         input.StorageClass,
+			ServerSideEncryption: input.ServerSideEncryption,
+			Meta:                 input.Meta,
+		}
+		var (
+			output *PutObjectV2Output
+			err    error
+		)
+		if entry.isDir {
+			output, err = cli.PutObjectV2(ctx, &PutObjectV2Input{PutObjectBasicInput: basic, Content: strings.NewReader("")})
+		} else {
+			var fileOutput *PutObjectFromFileOutput
+			fileOutput, err = cli.PutObjectFromFile(ctx, &PutObjectFromFileInput{PutObjectBasicInput: basic, FilePath: entry.path})
+			if err == nil {
+				output = &fileOutput.PutObjectV2Output
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		uploaded.ETag = output.ETag
+		uploaded.VersionID = output.VersionID
+		uploaded.HashCrc64ecma = output.HashCrc64ecma
+		return uploaded, nil
+	}
+	output, err := cli.UploadFile(ctx, &UploadFileInput{
+		CreateMultipartUploadV2Input: CreateMultipartUploadV2Input{
+			Bucket:               input.Bucket,
+			Key:                  entry.key,
+			ACL:                  input.ACL,
+			StorageClass:         input.StorageClass,
+			ServerSideEncryption: input.ServerSideEncryption,
+			Meta:                 input.Meta,
+		},
+		FilePath:        entry.path,
+		PartSize:        input.PartSize,
+		TransferManager: input.TransferManager,
+	})
+	if err != nil {
+		return nil, err
+	}
+	uploaded.ETag = output.ETag
+	uploaded.VersionID = output.VersionID
+	uploaded.HashCrc64ecma = output.HashCrc64ecma
+	return uploaded, nil
+}
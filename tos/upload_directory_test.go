@@ -0,0 +1,97 @@
+package tos
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadDirectory(t *testing.T) {
+	root, err := ioutil.TempDir("", "upload-directory")
+	require.Nil(t, err)
+	defer os.RemoveAll(root)
+	require.Nil(t, os.MkdirAll(filepath.Join(root, "sub", "empty"), 0755))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(root, "sub", "b.txt"), []byte("b"), 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(root, "large"), make([]byte, MinPartSize+1), 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(root, "sub", "fail"), []byte("fail"), 0644))
+	require.Nil(t, os.Symlink(filepath.Join(root, "sub"), filepath.Join(root, "link")))
+	require.Nil(t, os.Symlink(root, filepath.Join(root, "sub", "loop")))
+
+	var (
+		lock sync.Mutex
+		puts []string
+	)
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if _, ok := req.Query["uploads"]; ok {
+			return newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"prefix/large","UploadId":"upload-id"}`)()
+		}
+		if req.Method == http.MethodPost {
+			return newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"prefix/large","ETag":"\"etag\""}`)()
+		}
+		_, err := ioutil.ReadAll(req.Content)
+		assert.Nil(t, err)
+		if strings.HasSuffix(req.Path, "/fail") {
+			return newMockResponse(http.StatusForbidden, `{"Code":"AccessDenied"}`)()
+		}
+		if _, ok := req.Query["partNumber"]; !ok {
+			lock.Lock()
+			puts = append(puts, strings.TrimPrefix(req.Path, "/"))
+			lock.Unlock()
+		}
+		res := newMockResponse(http.StatusOK, "")()
+		res.Header.Set(HeaderETag, `"etag"`)
+		return res
+	}}
+	client := newMockClient(t, transport)
+
+	output, err := client.UploadDirectory(context.Background(), &UploadDirectoryInput{
+		Bucket:             "bucket",
+		Prefix:             "prefix/",
+		LocalDir:           root,
+		KeepEmptyDirectory: true,
+		MultipartThreshold: MinPartSize,
+	})
+	require.Nil(t, err)
+	sort.Strings(puts)
+	// symlink is skipped, empty directory is uploaded as key ends with "/", and large file by multipart upload
+	require.Equal(t, []string{"prefix/a.txt", "prefix/sub/b.txt", "prefix/sub/empty/"}, puts)
+	require.Len(t, output.Succeeded, 4)
+	for _, uploaded := range output.Succeeded {
+		require.NotEmpty(t, uploaded.ETag)
+	}
+	require.Len(t, output.Failed, 1)
+	require.Equal(t, "prefix/sub/fail", output.Failed[0].Key)
+	require.Equal(t, http.StatusForbidden, StatusCode(output.Failed[0].Err))
+
+	// follow symlinks and map keys
+	puts = nil
+	output, err = client.UploadDirectory(context.Background(), &UploadDirectoryInput{
+		Bucket:         "bucket",
+		LocalDir:       root,
+		FollowSymlinks: true,
+		KeyMapper: func(relPath string) string {
+			if strings.HasSuffix(relPath, ".txt") {
+				return "mapped/" + relPath
+			}
+			return ""
+		},
+	})
+	require.Nil(t, err)
+	sort.Strings(puts)
+	// files under the linked directory are uploaded with keys of both paths, and the link to the ancestor is skipped
+	require.Equal(t, []string{"mapped/a.txt", "mapped/link/b.txt", "mapped/sub/b.txt"}, puts)
+	require.Len(t, output.Failed, 0)
+
+	_, err = client.UploadDirectory(context.Background(), &UploadDirectoryInput{Bucket: "bucket", LocalDir: filepath.Join(root, "a.txt")})
+	require.NotNil(t, err)
+}
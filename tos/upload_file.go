@@ -4,7 +4,6 @@ import (
 	"context"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
 )
@@ -16,7 +15,7 @@ func initUploadPartsInfo(uploadFileStat os.FileInfo, partSize int64) ([]uploadPa
 	if lastPartSize != 0 {
 		partCount++
 	}
-	if partCount > 10000 {
+	if partCount > MaxPartCount {
 		return nil, newTosClientError("tos: part count too many", nil)
 	}
 	parts := make([]uploadPartInfo, 0, partCount)
@@ -67,12 +66,6 @@ func validateUploadInput(input *UploadFileInput) error {
 	if err := isValidNames(input.Bucket, input.Key); err != nil {
 		return err
 	}
-	if input.PartSize == 0 {
-		input.PartSize = MinPartSize
-	}
-	if input.PartSize < MinPartSize || input.PartSize > MaxPartSize {
-		return newTosClientError("tos: the input part size is invalid, please set it range from 5MB to 5GB.", nil)
-	}
 	stat, err := os.Stat(input.FilePath)
 	if err != nil {
 		return newTosClientError("tos: stat file to upload failed", err)
@@ -80,15 +73,15 @@ func validateUploadInput(input *UploadFileInput) error {
 	if stat.IsDir() {
 		return newTosClientError("tos: does not support directory, please specific your file path.", nil)
 	}
+	// fail fast rather than at the 10001st part
+	if input.PartSize, _, err = CalcPartSize(stat.Size(), input.PartSize); err != nil {
+		return err
+	}
 	if input.EnableCheckpoint {
 		// get correct checkpoint path
-		if len(input.CheckpointFile) == 0 {
-			dirName, _ := filepath.Split(input.FilePath)
-			fileName := strings.Join([]string{input.FilePath, input.Bucket, input.Key, "upload"}, ".")
-			input.CheckpointFile = filepath.Join(dirName, fileName)
-		} else {
-			mustFile(&input.CheckpointFile, strings.Join([]string{input.FilePath, input.Bucket, input.Key, "upload"}, "."))
-		}
+		fileName := checkpointFileName(input.FilePath, input.Bucket, input.Key, "upload")
+		dirName, _ := filepath.Split(input.FilePath)
+		setDefaultCheckpointFile(&input.CheckpointFile, input.CheckpointStore, dirName, fileName)
 	}
 	if input.TaskNum < 1 {
 		input.TaskNum = 1
@@ -105,23 +98,18 @@ func (u *uploadPostEvent) postUploadEvent(event *UploadEvent) {
 	}
 }
 
-// getUploadCheckpoint get struct checkpoint from checkpoint file if checkpointPath is valid,
-// or initialize from scratch with function init
-func getUploadCheckpoint(enabled bool, checkpointPath string,
-	init func() (*uploadCheckpoint, error)) (checkpoint *uploadCheckpoint, err error) {
+// getUploadCheckpoint get struct checkpoint saved with key from store,
+// or initialize from scratch with function init.
+// A loaded checkpoint is reused only if valid returns true, otherwise it is overwritten.
+func getUploadCheckpoint(store CheckpointStore, enabled bool, key CheckpointKey, init func() (*uploadCheckpoint, error),
+	valid func(checkpoint *uploadCheckpoint) bool) (checkpoint *uploadCheckpoint, err error) {
 	if enabled {
-		_, err = os.Stat(checkpointPath)
-		// if err is not empty, assume checkpoint not exists
-		if err == nil {
-			checkpoint = &uploadCheckpoint{}
-			loadCheckPoint(checkpointPath, checkpoint)
-			if checkpoint != nil {
-				return
-			}
-		}
-		_, err = os.Create(checkpointPath)
-		if err != nil {
-			return nil, newTosClientError("tos: create checkpoint file failed", err)
+		checkpoint = &uploadCheckpoint{}
+		// if failed to load, assume checkpoint not exists
+		if loadCheckpoint(store, key, checkpoint) == nil && valid(checkpoint) {
+			checkpoint.checkpointPath = key.CheckpointFile
+			checkpoint.store = store
+			return checkpoint, nil
 		}
 	}
 	checkpoint, err = init()
@@ -129,8 +117,8 @@ func getUploadCheckpoint(enabled bool, checkpointPath string,
 		return nil, err
 	}
 	if enabled {
-		err = checkpoint.WriteToFile()
-		if err != nil {
+		checkpoint.store = store
+		if err = checkpoint.Save(); err != nil {
 			return nil, err
 		}
 	}
@@ -155,17 +143,28 @@ func bindCancelHookWithCleaner(hook CancelHook, cleaner func()) {
 
 func (cli *ClientV2) UploadFile(ctx context.Context, input *UploadFileInput) (output *UploadFileOutput, err error) {
 	// avoid modifying on origin pointer
-	input = &(*input)
+	copied := *input
+	input = &copied
 
 	if err = validateUploadInput(input); err != nil {
 		return nil, err
 	}
-
+	stat, err := os.Stat(input.FilePath)
+	if err != nil {
+		return nil, newTosClientError("tos: stat file to upload failed", err)
+	}
 	init := func() (*uploadCheckpoint, error) {
 		return initUploadCheckpoint(input)
 	}
+	// the recorded UploadID and part ETags can be reused only if the file to upload is unchanged
+	valid := func(checkpoint *uploadCheckpoint) bool {
+		return checkpoint.Valid(stat, input.Bucket, input.Key, input.FilePath) && checkpoint.PartSize == input.PartSize &&
+			checkpoint.SSECAlgorithm == input.SSECAlgorithm && checkpoint.SSECKeyMD5 == input.SSECKeyMD5
+	}
 	// if the checkpoint file not exist, here we will create it
-	checkpoint, err := getUploadCheckpoint(input.EnableCheckpoint, input.CheckpointFile, init)
+	store := getCheckpointStore(input.CheckpointStore)
+	key := CheckpointKey{Operation: checkpointUpload, Bucket: input.Bucket, Key: input.Key, CheckpointFile: input.CheckpointFile}
+	checkpoint, err := getUploadCheckpoint(store, input.EnableCheckpoint, key, init, valid)
 	if err != nil {
 		return nil, err
 	}
@@ -197,28 +196,31 @@ func (cli *ClientV2) UploadFile(ctx context.Context, input *UploadFileInput) (ou
 	}
 
 	cleaner := func() {
-		_ = os.Remove(input.CheckpointFile)
+		checkpoint.Delete()
 	}
 	event.checkPoint = checkpoint
 	bindCancelHookWithCleaner(input.CancelHook, cleaner)
-	return cli.uploadPart(ctx, checkpoint, input, event)
+	output, err = cli.uploadPart(ctx, checkpoint, input, event)
+	if canceled, isAbort := isCanceled(input.CancelHook); canceled && isAbort {
+		// parts finished after canceling may have rewritten the checkpoint
+		cleaner()
+	}
+	return output, err
 }
 
-func prepareUploadTasks(cli *ClientV2, ctx context.Context, checkpoint *uploadCheckpoint, input *UploadFileInput) []task {
+func prepareUploadTasks(cli *ClientV2, ctx context.Context, checkpoint *uploadCheckpoint, input *UploadFileInput, progress *transferProgress) []task {
 	tasks := make([]task, 0)
-	consumed := int64(0)
-	subtotal := int64(0)
+	retry := newPartRetryPolicy(input.MaxPartRetries, input.PartRetryBackoffBase, input.PartRetryBackoffCap)
 	for _, part := range checkpoint.PartsInfo {
 		if !part.IsCompleted {
 			tasks = append(tasks, &uploadTask{
 				cli:        cli,
 				ctx:        ctx,
 				input:      input,
-				total:      checkpoint.FileInfo.Size,
+				retry:      retry,
+				progress:   progress,
 				UploadID:   checkpoint.UploadID,
 				PartNumber: part.PartNumber,
-				subtotal:   &subtotal,
-				consumed:   &consumed,
 				Offset:     part.Offset,
 				PartSize:   part.PartSize,
 			})
@@ -300,6 +302,28 @@ func getCancelHandle(hook CancelHook) chan struct{} {
 	return make(chan struct{})
 }
 
+// isCanceled returns whether hook is canceled, and whether it is canceled with isAbort true
+func isCanceled(hook CancelHook) (canceled bool, isAbort bool) {
+	if c, ok := hook.(*canceler); ok {
+		return c.canceled()
+	}
+	return false, false
+}
+
+// contextWithCancelHandle returns a context which is done once cancelHandle is closed,
+// so that requests of parts in flight are interrupted promptly.
+func contextWithCancelHandle(ctx context.Context, cancelHandle chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-cancelHandle:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
 func combineCRCInDownload(parts []downloadPartInfo) uint64 {
 	if len(parts) == 0 {
 		return 0
@@ -323,13 +347,21 @@ func combineCRCInParts(parts []uploadPartInfo) uint64 {
 	return crc
 }
 
-func (cli *ClientV2) uploadPart(ctx context.Context, checkpoint *uploadCheckpoint, input *UploadFileInput, event *uploadPostEvent) (*UploadFileOutput, error) {
+func (cli *ClientV2) uploadPart(ctx context.Context, checkpoint *uploadCheckpoint, input *UploadFileInput, event *uploadPostEvent) (output *UploadFileOutput, err error) {
 	// prepare tasks
-	// if amount of tasks >= 10000, err "tos: part count too many" will be raised.
-	tasks := prepareUploadTasks(cli, ctx, checkpoint, input)
-	routinesNum := min(input.TaskNum, len(tasks))
 	cancelHandle := getCancelHandle(input.CancelHook)
-	tg := newTaskGroup(cancelHandle, routinesNum, checkpoint, event, input.EnableCheckpoint, tasks)
+	taskCtx, cancel := contextWithCancelHandle(ctx, cancelHandle)
+	defer cancel()
+	progress := newTransferProgress(input.DataTransferListener, checkpoint.FileInfo.Size)
+	defer func() {
+		if err != nil {
+			progress.fail()
+		}
+	}()
+	tasks := prepareUploadTasks(cli, taskCtx, checkpoint, input, progress)
+	routinesNum := min(input.TaskNum, len(tasks))
+	saver := newCheckpointSaver(input.EnableCheckpoint, input.CheckpointSaveInterval, input.CheckpointSavePartCount)
+	tg := newTaskGroup(taskCtx, cancelHandle, routinesNum, checkpoint, event, saver, input.TransferManager, tasks)
 	abort := func() error {
 		_, err := cli.AbortMultipartUpload(ctx,
 			&AbortMultipartUploadInput{
@@ -342,14 +374,17 @@ func (cli *ClientV2) uploadPart(ctx context.Context, checkpoint *uploadCheckpoin
 
 	tg.RunWorker()
 	// start adding tasks
-	postDataTransferStatus(input.DataTransferListener, &DataTransferStatus{
-		TotalBytes: checkpoint.FileInfo.Size,
-		Type:       enum.DataTransferStarted,
-	})
+	progress.start(checkpoint.completedBytes(), checkpoint.PartSize)
 
 	tg.Scheduler()
 	success, taskErr := tg.Wait()
+	if canceled, _ := isCanceled(input.CancelHook); canceled {
+		// the upload is aborted by CancelHook if needed, otherwise it is kept to be resumed
+		return nil, CanceledClientError
+	}
 	if taskErr != nil {
+		// fail fast, interrupt parts in flight
+		cancel()
 		if err := abort(); err != nil {
 			return nil, err
 		}
@@ -357,6 +392,10 @@ func (cli *ClientV2) uploadPart(ctx context.Context, checkpoint *uploadCheckpoin
 	}
 	// handle results
 	if success < len(tasks) {
+		// without checkpoint the upload can never be resumed, so abort it to release uploaded parts
+		if !input.EnableCheckpoint {
+			_ = abort()
+		}
 		return nil, newTosClientError("tos: some upload tasks failed.", nil)
 	}
 	complete, err := cli.CompleteMultipartUploadV2(ctx, &CompleteMultipartUploadV2Input{
@@ -371,11 +410,13 @@ func (cli *ClientV2) uploadPart(ctx context.Context, checkpoint *uploadCheckpoin
 	}
 	event.postUploadEvent(newCompleteMultipartUploadSucceedEvent(input, checkpoint.UploadID))
 
-	if cli.enableCRC && complete.HashCrc64ecma != 0 && combineCRCInParts(checkpoint.PartsInfo) != complete.HashCrc64ecma {
-		return nil, newTosClientError("tos: crc of entire file mismatch.", nil)
-
+	if cli.enableCRC && complete.HashCrc64ecma != 0 {
+		if combined := combineCRCInParts(checkpoint.PartsInfo); combined != complete.HashCrc64ecma {
+			return nil, newChecksumMismatchError(complete.RequestID, complete.HashCrc64ecma, combined)
+		}
 	}
-	_ = os.Remove(input.CheckpointFile)
+	checkpoint.Delete()
+	progress.succeed()
 
 	return &UploadFileOutput{
 		RequestInfo:   complete.RequestInfo,
@@ -0,0 +1,254 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+func TestGetUploadCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tos-upload-checkpoint")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "file")
+	require.Nil(t, ioutil.WriteFile(filePath, make([]byte, 3*MinPartSize/2), 0644))
+	input := &UploadFileInput{
+		CreateMultipartUploadV2Input: CreateMultipartUploadV2Input{Bucket: "bucket", Key: "key"},
+		FilePath:                     filePath,
+		EnableCheckpoint:             true,
+	}
+	require.Nil(t, validateUploadInput(input))
+
+	stat, err := os.Stat(filePath)
+	require.Nil(t, err)
+	init := func() (*uploadCheckpoint, error) { return initUploadCheckpoint(input) }
+	valid := func(checkpoint *uploadCheckpoint) bool {
+		return checkpoint.Valid(stat, input.Bucket, input.Key, input.FilePath)
+	}
+
+	key := CheckpointKey{Operation: checkpointUpload, Bucket: input.Bucket, Key: input.Key, CheckpointFile: input.CheckpointFile}
+	checkpoint, err := getUploadCheckpoint(fileCheckpointStore{}, true, key, init, valid)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(checkpoint.PartsInfo))
+	require.Equal(t, int64(MinPartSize/2), checkpoint.PartsInfo[1].PartSize)
+
+	// a recorded upload is reused while the file is unchanged
+	checkpoint.UploadID = "upload-id"
+	checkpoint.PartsInfo[0].IsCompleted = true
+	checkpoint.PartsInfo[0].ETag = "etag"
+	require.Nil(t, checkpoint.Save())
+	loaded, err := getUploadCheckpoint(fileCheckpointStore{}, true, key, init, valid)
+	require.Nil(t, err)
+	require.Equal(t, "upload-id", loaded.UploadID)
+	require.Equal(t, input.CheckpointFile, loaded.checkpointPath)
+	require.True(t, loaded.PartsInfo[0].IsCompleted)
+
+	// the recorded upload is dropped once the file is modified
+	modified := time.Now().Add(time.Hour)
+	require.Nil(t, os.Chtimes(filePath, modified, modified))
+	stat, err = os.Stat(filePath)
+	require.Nil(t, err)
+	loaded, err = getUploadCheckpoint(fileCheckpointStore{}, true, key, init, valid)
+	require.Nil(t, err)
+	require.Equal(t, "", loaded.UploadID)
+	require.False(t, loaded.PartsInfo[0].IsCompleted)
+}
+
+type blockingTask struct {
+	ctx context.Context
+}
+
+func (t *blockingTask) do() (interface{}, error) {
+	<-t.ctx.Done()
+	return nil, t.ctx.Err()
+}
+
+func (t *blockingTask) getBaseInput() interface{} {
+	return nil
+}
+
+func TestCancelHookInterruptsParts(t *testing.T) {
+	hook := NewCancelHook()
+	cancelHandle := getCancelHandle(hook)
+	ctx, cancel := contextWithCancelHandle(context.Background(), cancelHandle)
+	defer cancel()
+	tasks := []task{&blockingTask{ctx: ctx}, &blockingTask{ctx: ctx}, &blockingTask{ctx: ctx}}
+	event := &uploadPostEvent{input: &UploadFileInput{}, checkPoint: &uploadCheckpoint{}}
+	tg := newTaskGroup(context.Background(), cancelHandle, 2, event.checkPoint, event, newCheckpointSaver(false, 0, 0), nil, tasks)
+	tg.RunWorker()
+	tg.Scheduler()
+	go hook.Cancel(false)
+	success, err := tg.Wait()
+	require.Nil(t, err)
+	require.Equal(t, 0, success)
+	<-ctx.Done()
+	canceled, isAbort := isCanceled(hook)
+	require.True(t, canceled)
+	require.False(t, isAbort)
+
+	hook = NewCancelHook()
+	cleaned, aborted := false, false
+	bindCancelHookWithCleaner(hook, func() { cleaned = true })
+	bindCancelHookWithAborter(hook, func() error { aborted = true; return nil })
+	hook.Cancel(true)
+	canceled, isAbort = isCanceled(hook)
+	require.True(t, canceled && isAbort)
+	require.True(t, cleaned)
+	require.True(t, aborted)
+}
+
+func TestPartRetryPolicy(t *testing.T) {
+	retry := newPartRetryPolicy(2, time.Millisecond, 4*time.Millisecond)
+	for i := 0; i < 5; i++ {
+		backoff := retry.backoff(i)
+		require.True(t, backoff <= 4*time.Millisecond)
+		require.True(t, backoff >= time.Millisecond/2)
+	}
+
+	serverError := &TosServerError{RequestInfo: RequestInfo{StatusCode: 500}}
+	calls := 0
+	err := retry.Run(context.Background(), func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return serverError
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, 3, calls)
+
+	// budget exhausted
+	calls = 0
+	err = retry.Run(context.Background(), func(context.Context) error {
+		calls++
+		return serverError
+	})
+	require.Equal(t, serverError, err)
+	require.Equal(t, 3, calls)
+
+	// fail fast on non-retryable client error
+	calls = 0
+	err = retry.Run(context.Background(), func(context.Context) error {
+		calls++
+		return &TosServerError{RequestInfo: RequestInfo{StatusCode: 403}}
+	})
+	require.Equal(t, 403, StatusCode(err))
+	require.Equal(t, 1, calls)
+
+	// typed server errors fail fast the same, e.g. 416 of a range beyond the object
+	calls = 0
+	rangeErr := &InvalidRangeError{TosServerError: TosServerError{RequestInfo: RequestInfo{StatusCode: 416}}, ObjectSize: 10}
+	err = retry.Run(context.Background(), func(context.Context) error {
+		calls++
+		return rangeErr
+	})
+	require.Equal(t, rangeErr, err)
+	require.Equal(t, 1, calls)
+
+	calls = 0
+	_ = newPartRetryPolicy(-1, 0, 0).Run(context.Background(), func(context.Context) error {
+		calls++
+		return serverError
+	})
+	require.Equal(t, 1, calls)
+
+	// retries of the client are disabled for each attempt of the part
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		return newMockResponse(500, "")()
+	}}
+	cli := newMockClient(t, transport)
+	err = retry.Run(context.Background(), func(ctx context.Context) error {
+		_, err := cli.UploadPartV2(ctx, &UploadPartV2Input{UploadPartBasicInput: UploadPartBasicInput{
+			Bucket: "bucket", Key: "key", UploadID: "upload-id", PartNumber: 1}})
+		return err
+	})
+	require.Equal(t, 500, StatusCode(err))
+	require.Equal(t, 3, len(transport.recorded()))
+}
+
+type recordingListener struct {
+	statuses []DataTransferStatus
+}
+
+func (l *recordingListener) DataTransferStatusChange(status *DataTransferStatus) {
+	l.statuses = append(l.statuses, *status)
+}
+
+func TestTransferProgress(t *testing.T) {
+	listener := &recordingListener{}
+	total := int64(3 * DefaultProgressCallbackSize)
+	progress := newTransferProgress(listener, total)
+	progress.start(DefaultProgressCallbackSize, MinPartSize)
+	require.Equal(t, enum.DataTransferStarted, listener.statuses[0].Type)
+	require.Equal(t, int64(DefaultProgressCallbackSize), listener.statuses[0].ConsumedBytes)
+
+	part := &readCloserWithProgress{
+		base:     ioutil.NopCloser(bytes.NewReader(make([]byte, DefaultProgressCallbackSize))),
+		progress: progress,
+	}
+	_, err := ioutil.ReadAll(part)
+	require.Nil(t, err)
+	// the part is retried, progress is not reported again until it exceeds the reported one
+	progress.rollback(part.read)
+	progress.add(DefaultProgressCallbackSize / 2)
+	progress.add(DefaultProgressCallbackSize)
+	progress.succeed()
+	progress.fail()
+
+	var rw, consumed int64
+	for _, status := range listener.statuses[1 : len(listener.statuses)-1] {
+		require.Equal(t, enum.DataTransferRW, status.Type)
+		require.True(t, status.ConsumedBytes > consumed)
+		require.True(t, status.ConsumedBytes <= total)
+		consumed = status.ConsumedBytes
+		rw += status.RWOnceBytes
+	}
+	require.Equal(t, total-DefaultProgressCallbackSize, rw)
+	last := listener.statuses[len(listener.statuses)-1]
+	require.Equal(t, enum.DataTransferSucceed, last.Type)
+	require.Equal(t, total, last.ConsumedBytes)
+}
+
+type blockingListener struct {
+	recordingListener
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (l *blockingListener) DataTransferStatusChange(status *DataTransferStatus) {
+	if status.Type == enum.DataTransferRW && len(l.statuses) == 1 {
+		close(l.entered)
+		<-l.release
+	}
+	l.recordingListener.DataTransferStatusChange(status)
+}
+
+func TestTransferProgressSlowListener(t *testing.T) {
+	listener := &blockingListener{entered: make(chan struct{}), release: make(chan struct{})}
+	progress := newTransferProgress(listener, int64(3*DefaultProgressCallbackSize))
+	progress.start(0, MinPartSize)
+
+	done := make(chan struct{})
+	go func() {
+		progress.add(DefaultProgressCallbackSize)
+		close(done)
+	}()
+	<-listener.entered
+	// other parts are not blocked by the listener, and their events are posted in order
+	progress.add(DefaultProgressCallbackSize)
+	progress.add(DefaultProgressCallbackSize)
+	close(listener.release)
+	<-done
+	require.Equal(t, 4, len(listener.statuses))
+	for i := 1; i < 4; i++ {
+		require.Equal(t, int64(i*DefaultProgressCallbackSize), listener.statuses[i].ConsumedBytes)
+	}
+}
@@ -0,0 +1,217 @@
+package tos
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+type readerAtPart struct {
+	partNumber int
+	offset     int64
+	size       int64
+}
+
+// UploadFromReaderAt uploads data of io.ReaderAt by multipart upload, parts are read by io.SectionReader and uploaded in parallel.
+// Each part is retried independently, and the multipart upload is aborted if any part failed.
+// CRC64 of the object is checked with parts on completing if CRC is enabled.
+func (cli *ClientV2) UploadFromReaderAt(ctx context.Context, input *UploadFromReaderAtInput) (*UploadFromReaderAtOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := isValidNames(input.Bucket, input.Key); err != nil {
+		return nil, err
+	}
+	if input.ReaderAt == nil {
+		return nil, newTosClientError("tos: ReaderAt is nil", nil)
+	}
+	partSize, partCount, err := CalcPartSize(input.Size, input.PartSize)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]readerAtPart, 0, partCount+1)
+	for i := 0; i < partCount; i++ {
+		offset := int64(i) * partSize
+		size := partSize
+		if offset+size > input.Size {
+			size = input.Size - offset
+		}
+		parts = append(parts, readerAtPart{partNumber: i + 1, offset: offset, size: size})
+	}
+	if len(parts) == 0 {
+		// an empty object has one empty part
+		parts = append(parts, readerAtPart{partNumber: 1})
+	}
+	taskNum := input.TaskNum
+	if taskNum < 1 {
+		taskNum = 1
+	}
+	if taskNum > len(parts) {
+		taskNum = len(parts)
+	}
+
+	created, err := cli.CreateMultipartUploadV2(ctx, &input.CreateMultipartUploadV2Input)
+	if err != nil {
+		return nil, err
+	}
+	abort := func() {
+		_, _ = cli.AbortMultipartUpload(ctx, &AbortMultipartUploadInput{Bucket: input.Bucket, Key: input.Key, UploadID: created.UploadID})
+	}
+	progress := newTransferProgress(input.DataTransferListener, input.Size)
+	progress.start(0, partSize)
+
+	uploaded, err := cli.uploadReaderAtParts(ctx, input, created.UploadID, parts, taskNum, progress)
+	if err != nil {
+		abort()
+		progress.fail()
+		return nil, err
+	}
+	complete, err := cli.CompleteMultipartUploadV2(ctx, &CompleteMultipartUploadV2Input{
+		Bucket:   input.Bucket,
+		Key:      input.Key,
+		UploadID: created.UploadID,
+		Parts:    uploaded,
+	})
+	if err != nil {
+		progress.fail()
+		return nil, err
+	}
+	progress.succeed()
+	return &UploadFromReaderAtOutput{UploadFileOutput{
+		RequestInfo:   complete.RequestInfo,
+		Bucket:        complete.Bucket,
+		Key:           complete.Key,
+		UploadID:      created.UploadID,
+		ETag:          complete.ETag,
+		Location:      complete.Location,
+		VersionID:     complete.VersionID,
+		HashCrc64ecma: complete.HashCrc64ecma,
+		SSECAlgorithm: created.SSECAlgorithm,
+		SSECKeyMD5:    created.SSECKeyMD5,
+		EncodingType:  created.EncodingType,
+	}}, nil
+}
+
+// uploadReaderAtParts uploads parts with taskNum goroutines, and stops on the first error
+func (cli *ClientV2) uploadReaderAtParts(ctx context.Context, input *UploadFromReaderAtInput, uploadID string,
+	parts []readerAtPart, taskNum int, progress *transferProgress) ([]UploadedPartV2, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		uploaded = make([]UploadedPartV2, len(parts))
+		jobs     = make(chan int)
+		retry    = newPartRetryPolicy(input.MaxPartRetries, input.PartRetryBackoffBase, input.PartRetryBackoffCap)
+		queue    = &transferQueue{}
+	)
+	for i := 0; i < taskNum; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				part := parts[index]
+				var output *UploadPartV2Output
+				err := retry.Run(ctx, func(ctx context.Context) (err error) {
+					if input.TransferManager == nil {
+						output, err = cli.uploadReaderAtPart(ctx, input, uploadID, part, progress)
+						return err
+					}
+					if runErr := input.TransferManager.run(ctx, queue, func() {
+						output, err = cli.uploadReaderAtPart(ctx, input, uploadID, part, progress)
+					}); runErr != nil {
+						return runErr
+					}
+					return err
+				})
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						// interrupt parts in flight
+						cancel()
+					})
+					continue
+				}
+				uploaded[index] = UploadedPartV2{
+					PartNumber:    part.partNumber,
+					ETag:          output.ETag,
+					Size:          part.size,
+					HashCrc64ecma: output.HashCrc64ecma,
+				}
+			}
+		}()
+	}
+schedule:
+	for index := range parts {
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			break schedule
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, newTosClientError(err.Error(), err)
+	}
+	return uploaded, nil
+}
+
+// uploadReaderAtPart uploads a part once, every attempt reads the part from a new io.SectionReader
+func (cli *ClientV2) uploadReaderAtPart(ctx context.Context, input *UploadFromReaderAtInput, uploadID string,
+	part readerAtPart, progress *transferProgress) (*UploadPartV2Output, error) {
+	listened := &sectionReaderWithProgress{
+		SectionReader: io.NewSectionReader(input.ReaderAt, part.offset, part.size),
+		progress:      progress,
+	}
+	output, err := cli.UploadPartV2(ctx, &UploadPartV2Input{
+		UploadPartBasicInput: UploadPartBasicInput{
+			Bucket:               input.Bucket,
+			Key:                  input.Key,
+			UploadID:             uploadID,
+			PartNumber:           part.partNumber,
+			SSECAlgorithm:        input.SSECAlgorithm,
+			SSECKey:              input.SSECKey,
+			SSECKeyMD5:           input.SSECKeyMD5,
+			ServerSideEncryption: input.ServerSideEncryption,
+			RateLimiter:          input.RateLimiter,
+		},
+		Content:       listened,
+		ContentLength: part.size,
+	})
+	if err != nil {
+		// the part will be read again on retrying
+		progress.rollback(listened.read)
+	}
+	return output, err
+}
+
+// sectionReaderWithProgress reports bytes read from io.SectionReader to transferProgress,
+// bytes read are rolled back if it is rewound on retrying.
+type sectionReaderWithProgress struct {
+	*io.SectionReader
+	progress *transferProgress
+	read     int64
+}
+
+func (r *sectionReaderWithProgress) Read(p []byte) (n int, err error) {
+	n, err = r.SectionReader.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.progress.add(int64(n))
+	}
+	return
+}
+
+func (r *sectionReaderWithProgress) Seek(offset int64, whence int) (int64, error) {
+	pos, err := r.SectionReader.Seek(offset, whence)
+	if err == nil && pos != r.read {
+		r.progress.rollback(r.read - pos)
+		r.read = pos
+	}
+	return pos, err
+}
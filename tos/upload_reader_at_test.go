@@ -0,0 +1,77 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func crc64Of(data []byte) uint64 {
+	checker := NewCRC(DefaultCrcTable(), 0)
+	checker.Write(data)
+	return checker.Sum64()
+}
+
+func TestUploadFromReaderAt(t *testing.T) {
+	data := make([]byte, 2*MinPartSize+MinPartSize/2)
+	rand.Read(data)
+
+	var lock sync.Mutex
+	parts := make(map[string][]byte)
+	failed := false
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if _, ok := req.Query["uploads"]; ok {
+			return newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"key","UploadId":"upload-id"}`)()
+		}
+		if req.Method == http.MethodPost {
+			res := newMockResponse(http.StatusOK, `{"Bucket":"bucket","Key":"key","ETag":"\"etag\""}`)()
+			res.Header.Set(HeaderHashCrc64ecma, strconv.FormatUint(crc64Of(data), 10))
+			return res
+		}
+		body, err := ioutil.ReadAll(req.Content)
+		assert.Nil(t, err)
+		partNumber := req.Query.Get("partNumber")
+		lock.Lock()
+		defer lock.Unlock()
+		if partNumber == "2" && !failed {
+			// the part is rewound and uploaded again
+			failed = true
+			return newMockResponse(http.StatusInternalServerError, `{"Code":"InternalError"}`)()
+		}
+		parts[partNumber] = body
+		res := newMockResponse(http.StatusOK, "")()
+		res.Header.Set(HeaderETag, `"etag-`+partNumber+`"`)
+		res.Header.Set(HeaderHashCrc64ecma, strconv.FormatUint(crc64Of(body), 10))
+		return res
+	}}
+	client := newMockClient(t, transport)
+	listener := &recordingListener{}
+	output, err := client.UploadFromReaderAt(context.Background(), &UploadFromReaderAtInput{
+		CreateMultipartUploadV2Input: CreateMultipartUploadV2Input{Bucket: "bucket", Key: "key"},
+		ReaderAt:                     bytes.NewReader(data),
+		Size:                         int64(len(data)),
+		TaskNum:                      3,
+		DataTransferListener:         listener,
+	})
+	require.Nil(t, err)
+	require.Equal(t, "upload-id", output.UploadID)
+	require.Equal(t, crc64Of(data), output.HashCrc64ecma)
+	require.True(t, failed)
+	require.Equal(t, data[:MinPartSize], parts["1"])
+	require.Equal(t, data[MinPartSize:2*MinPartSize], parts["2"])
+	require.Equal(t, data[2*MinPartSize:], parts["3"])
+
+	last := listener.statuses[len(listener.statuses)-1]
+	require.Equal(t, int64(len(data)), last.ConsumedBytes)
+	for _, status := range listener.statuses {
+		require.True(t, status.ConsumedBytes <= int64(len(data)))
+	}
+}
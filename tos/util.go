@@ -1,8 +1,7 @@
 package tos
 
 import (
-	"fmt"
-	"os"
+	"context"
 	"time"
 )
 
@@ -16,7 +15,7 @@ func min(a int, b int) int {
 const (
 	EventPartSucceed = 3
 	EventPartFailed  = 4
-	EventPartAborted = 5 // The task needs to be interrupted in case of 403, 404, 405 errors
+	EventPartAborted = 5 // The task needs to be interrupted in case of 4xx errors other than 408 and 429
 )
 
 type task interface {
@@ -25,9 +24,9 @@ type task interface {
 }
 
 type checkPoint interface {
-	WriteToFile() error
+	Save() error
 	UpdatePartsInfo(result interface{})
-	GetCheckPointFilePath() string
+	Delete()
 }
 
 type taskGroup interface {
@@ -44,16 +43,19 @@ type postEvent interface {
 }
 
 type taskGroupImpl struct {
-	cancelHandle     chan struct{}
-	abortHandle      chan struct{}
-	errCh            chan error
-	resultsCh        chan interface{}
-	tasksCh          chan task
-	routinesNum      int
-	tasks            []task
-	checkPoint       checkPoint
-	enableCheckPoint bool
-	postEvent        postEvent
+	ctx          context.Context // context of tasks, done once the group is canceled or aborted
+	cancelHandle chan struct{}
+	abortHandle  chan struct{}
+	errCh        chan error
+	resultsCh    chan interface{}
+	tasksCh      chan task
+	routinesNum  int
+	tasks        []task
+	checkPoint   checkPoint
+	saver        *checkpointSaver
+	postEvent    postEvent
+	manager      *TransferManager
+	queue        *transferQueue
 }
 
 func (t *taskGroupImpl) Wait() (int, error) {
@@ -61,6 +63,12 @@ func (t *taskGroupImpl) Wait() (int, error) {
 	failNum := 0
 Loop:
 	for successNum+failNum < len(t.tasks) {
+		// results arrived after canceling should not be recorded
+		select {
+		case <-t.cancelHandle:
+			break Loop
+		default:
+		}
 		select {
 		case <-t.abortHandle:
 			break Loop
@@ -69,40 +77,42 @@ Loop:
 		case part := <-t.resultsCh:
 			successNum++
 			t.checkPoint.UpdatePartsInfo(part)
-			if t.enableCheckPoint {
-				t.checkPoint.WriteToFile()
-			}
+			t.saver.partFinished(t.checkPoint)
 			t.postEvent.PostEvent(EventPartSucceed, part, nil)
 		case taskErr := <-t.errCh:
-			if StatusCode(taskErr) == 403 || StatusCode(taskErr) == 404 || StatusCode(taskErr) == 405 {
+			if isFailFastStatusCode(StatusCode(taskErr)) {
 				close(t.abortHandle)
-				_ = os.Remove(t.checkPoint.GetCheckPointFilePath())
+				t.checkPoint.Delete()
 				t.postEvent.PostEvent(EventPartAborted, nil, taskErr)
-
-				return successNum, fmt.Errorf("status code not service error, err:%s. ", taskErr.Error())
-
+				return successNum, taskErr
 			}
 			t.postEvent.PostEvent(EventPartFailed, nil, taskErr)
 			failNum++
 		}
 	}
+	// make sure finished parts are recorded before returning, e.g. on canceling
+	t.saver.flush(t.checkPoint)
 	return successNum, nil
 }
 
-func newTaskGroup(cancelHandle chan struct{}, routinesNum int, checkPoint checkPoint, postEvent postEvent, enableCheckPoint bool, tasks []task) taskGroup {
+func newTaskGroup(ctx context.Context, cancelHandle chan struct{}, routinesNum int, checkPoint checkPoint, postEvent postEvent,
+	saver *checkpointSaver, manager *TransferManager, tasks []task) taskGroup {
 	taskBufferSize := min(routinesNum, DefaultTaskBufferSize)
 	tasksCh := make(chan task, taskBufferSize)
 	return &taskGroupImpl{
-		cancelHandle:     cancelHandle,
-		abortHandle:      make(chan struct{}),
-		errCh:            make(chan error),
-		resultsCh:        make(chan interface{}),
-		tasksCh:          tasksCh,
-		routinesNum:      routinesNum,
-		tasks:            tasks,
-		checkPoint:       checkPoint,
-		enableCheckPoint: enableCheckPoint,
-		postEvent:        postEvent,
+		ctx:          ctx,
+		cancelHandle: cancelHandle,
+		abortHandle:  make(chan struct{}),
+		errCh:        make(chan error),
+		resultsCh:    make(chan interface{}),
+		tasksCh:      tasksCh,
+		routinesNum:  routinesNum,
+		tasks:        tasks,
+		checkPoint:   checkPoint,
+		saver:        saver,
+		postEvent:    postEvent,
+		manager:      manager,
+		queue:        &transferQueue{},
 	}
 }
 
@@ -121,8 +131,7 @@ func (t *taskGroupImpl) Scheduler() {
 				return
 			case <-t.abortHandle:
 				return
-			default:
-				t.tasksCh <- task
+			case t.tasksCh <- task:
 			}
 		}
 
@@ -142,17 +151,54 @@ func (t *taskGroupImpl) worker() {
 			if !ok {
 				return
 			}
-			result, err := task.do()
+			result, err := runTask(t.ctx, t.manager, t.queue, task)
+			// Wait stops receiving once canceled or aborted, do not block on sending
 			if err != nil {
-				t.errCh <- err
+				select {
+				case t.errCh <- err:
+				case <-t.cancelHandle:
+					return
+				case <-t.abortHandle:
+					return
+				}
 			}
 			if result != nil {
-				t.resultsCh <- result
+				select {
+				case t.resultsCh <- result:
+				case <-t.cancelHandle:
+					return
+				case <-t.abortHandle:
+					return
+				}
 			}
 		}
 	}
 }
 
+// CalcPartSize returns the part size and part count to split total bytes into parts.
+// requested is MinPartSize if it is 0, and must range from MinPartSize to MaxPartSize.
+// The part size is rounded up so that part count does not exceed MaxPartCount,
+// TosClientError is returned if total is too large even with MaxPartSize.
+func CalcPartSize(total, requested int64) (int64, int, error) {
+	if requested == 0 {
+		requested = MinPartSize
+	}
+	if requested < MinPartSize || requested > MaxPartSize {
+		return 0, 0, newTosClientError("tos: the input part size is invalid, please set it range from 5MB to 5GB.", nil)
+	}
+	if total < 0 {
+		return 0, 0, newTosClientError("tos: the total size is invalid.", nil)
+	}
+	partSize := requested
+	if (total+partSize-1)/partSize > MaxPartCount {
+		partSize = (total + MaxPartCount - 1) / MaxPartCount
+	}
+	if partSize > MaxPartSize {
+		return 0, 0, newTosClientError("tos: part count too many, the total size exceeds MaxPartCount * MaxPartSize.", nil)
+	}
+	return partSize, int((total + partSize - 1) / partSize), nil
+}
+
 func GetUnixTimeMs() int64 {
 	return ToMillis(time.Now())
 }
@@ -0,0 +1,31 @@
+package tos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalcPartSize(t *testing.T) {
+	partSize, partCount, err := CalcPartSize(2*MinPartSize+1, 0)
+	require.Nil(t, err)
+	require.Equal(t, int64(MinPartSize), partSize)
+	require.Equal(t, 3, partCount)
+
+	partSize, partCount, err = CalcPartSize(0, MinPartSize)
+	require.Nil(t, err)
+	require.Equal(t, 0, partCount)
+
+	// 200GB with 8MB parts needs 25600 parts, part size is rounded up
+	total := int64(200 << 30)
+	partSize, partCount, err = CalcPartSize(total, 8<<20)
+	require.Nil(t, err)
+	require.Equal(t, MaxPartCount, partCount)
+	require.True(t, partSize*MaxPartCount >= total)
+	require.True(t, (partSize-1)*MaxPartCount < total)
+
+	_, _, err = CalcPartSize(int64(MaxPartSize)*MaxPartCount+1, MinPartSize)
+	require.NotNil(t, err)
+	_, _, err = CalcPartSize(1024, MinPartSize-1)
+	require.NotNil(t, err)
+}
@@ -1,8 +1,17 @@
 package tos
 
 import (
+	"bytes"
 	"context"
 	"net/http"
+	"time"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+const (
+	DefaultWaitVersioningTimeout  = 60 * time.Second
+	DefaultWaitVersioningInterval = time.Second
 )
 
 const (
@@ -35,3 +44,91 @@ func (cli *Client) GetBucketVersioning(ctx context.Context, bucket string) (*Get
 	}
 	return &output, nil
 }
+
+// PutBucketVersioning set the multi-version status of a bucket to Enabled or Suspended,
+// versioning can not be disabled once enabled.
+// The change is eventually consistent, use WaitBucketVersioning to wait until it takes effect.
+func (cli *ClientV2) PutBucketVersioning(ctx context.Context, input *PutBucketVersioningInput) (*PutBucketVersioningOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if input.Status != enum.VersioningStatusEnabled && input.Status != enum.VersioningStatusSuspended {
+		return nil, newTosClientError("tos: invalid versioning status, must be Enabled or Suspended", nil)
+	}
+	data, contentMD5, err := marshalInput("PutBucketVersioningInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("versioning", "").
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketVersioningOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketVersioningV2 get the multi-version status of a bucket
+func (cli *ClientV2) GetBucketVersioningV2(ctx context.Context, input *GetBucketVersioningV2Input) (*GetBucketVersioningV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("versioning", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	output := GetBucketVersioningV2Output{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// WaitBucketVersioning polls the multi-version status of a bucket until it is input.Status,
+// the last output is returned along with TosClientError if timeout elapses or ctx is done.
+func (cli *ClientV2) WaitBucketVersioning(ctx context.Context, input *WaitBucketVersioningInput) (*GetBucketVersioningV2Output, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	timeout := input.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWaitVersioningTimeout
+	}
+	interval := input.Interval
+	if interval <= 0 {
+		interval = DefaultWaitVersioningInterval
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		output, err := cli.GetBucketVersioningV2(ctx, &GetBucketVersioningV2Input{Bucket: input.Bucket})
+		if err != nil || output.Status == input.Status {
+			return output, err
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return output, newTosClientError("tos: wait bucket versioning canceled, status is still "+string(output.Status), ctx.Err())
+		case <-deadline.C:
+			timer.Stop()
+			return output, newTosClientError("tos: wait bucket versioning timeout, status is still "+string(output.Status), nil)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package tos
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+func TestBucketVersioning(t *testing.T) {
+	var status string
+	gets := 0
+	transport := &mockTransport{handler: func(req *Request) *Response {
+		if req.Method == http.MethodPut {
+			data, _ := ioutil.ReadAll(req.Content)
+			status = string(data)
+			return newMockResponse(http.StatusOK, "")()
+		}
+		gets++
+		// the first read after put is still stale
+		if status == "" || gets == 1 {
+			return newMockResponse(http.StatusOK, `{}`)()
+		}
+		return newMockResponse(http.StatusOK, status)()
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.PutBucketVersioning(ctx, &PutBucketVersioningInput{Bucket: "bucket", Status: enum.VersioningStatusEnabled})
+	require.Nil(t, err)
+	require.JSONEq(t, `{"Status":"Enabled"}`, status)
+
+	get, err := client.GetBucketVersioningV2(ctx, &GetBucketVersioningV2Input{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, enum.VersioningStatusNotSet, get.Status)
+
+	get, err = client.WaitBucketVersioning(ctx, &WaitBucketVersioningInput{Bucket: "bucket", Status: enum.VersioningStatusEnabled, Interval: time.Millisecond})
+	require.Nil(t, err)
+	require.Equal(t, enum.VersioningStatusEnabled, get.Status)
+
+	get, err = client.WaitBucketVersioning(ctx, &WaitBucketVersioningInput{Bucket: "bucket", Status: enum.VersioningStatusSuspended,
+		Timeout: 20 * time.Millisecond, Interval: time.Millisecond})
+	_, ok := err.(*TosClientError)
+	require.True(t, ok)
+	require.Equal(t, enum.VersioningStatusEnabled, get.Status)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	get, err = client.WaitBucketVersioning(canceled, &WaitBucketVersioningInput{Bucket: "bucket", Status: enum.VersioningStatusSuspended})
+	clientErr, ok := err.(*TosClientError)
+	require.True(t, ok)
+	require.Equal(t, context.Canceled, clientErr.Cause)
+	require.Equal(t, enum.VersioningStatusEnabled, get.Status)
+
+	requests := len(transport.recorded())
+	_, err = client.WaitBucketVersioning(ctx, &WaitBucketVersioningInput{Bucket: "b", Status: enum.VersioningStatusEnabled})
+	require.NotNil(t, err)
+	_, err = client.PutBucketVersioning(ctx, &PutBucketVersioningInput{Bucket: "bucket"})
+	require.NotNil(t, err)
+	require.Equal(t, requests, len(transport.recorded()))
+}
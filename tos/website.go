@@ -0,0 +1,116 @@
+package tos
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+func isValidProtocol(protocol enum.ProtocolType) error {
+	if protocol != "" && protocol != enum.ProtocolHttp && protocol != enum.ProtocolHttps {
+		return newTosClientError("tos: invalid protocol, must be http or https", nil)
+	}
+	return nil
+}
+
+// isValidWebsite checks RedirectAllRequestsTo is exclusive with other settings, and IndexDocument is set otherwise
+func isValidWebsite(input *PutBucketWebsiteInput) error {
+	if redirect := input.RedirectAllRequestsTo; redirect != nil {
+		if input.IndexDocument != nil || input.ErrorDocument != nil || len(input.RoutingRules) > 0 {
+			return newTosClientError("tos: RedirectAllRequestsTo can not be set with other website settings", nil)
+		}
+		if redirect.HostName == "" {
+			return newTosClientError("tos: HostName of RedirectAllRequestsTo is required", nil)
+		}
+		return isValidProtocol(redirect.Protocol)
+	}
+	if input.IndexDocument == nil || input.IndexDocument.Suffix == "" {
+		return newTosClientError("tos: Suffix of IndexDocument is required", nil)
+	}
+	if strings.Contains(input.IndexDocument.Suffix, "/") {
+		return newTosClientError("tos: Suffix of IndexDocument can not contain '/'", nil)
+	}
+	for _, rule := range input.RoutingRules {
+		if rule.Condition.KeyPrefixEquals == "" && rule.Condition.HttpErrorCodeReturnedEquals == 0 {
+			return newTosClientError("tos: condition of routing rule is empty", nil)
+		}
+		if rule.Redirect.ReplaceKeyWith != "" && rule.Redirect.ReplaceKeyPrefixWith != "" {
+			return newTosClientError("tos: ReplaceKeyWith and ReplaceKeyPrefixWith of routing rule can not be set together", nil)
+		}
+		if err := isValidProtocol(rule.Redirect.Protocol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PutBucketWebsite set static website configuration of bucket
+func (cli *ClientV2) PutBucketWebsite(ctx context.Context, input *PutBucketWebsiteInput) (*PutBucketWebsiteOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	if err := isValidWebsite(input); err != nil {
+		return nil, err
+	}
+	data, contentMD5, err := marshalInput("PutBucketWebsiteInput", input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("website", "").
+		WithHeader(HeaderContentMD5, contentMD5).
+		Request(ctx, http.MethodPut, bytes.NewReader(data), cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &PutBucketWebsiteOutput{RequestInfo: res.RequestInfo()}, nil
+}
+
+// GetBucketWebsite get static website configuration of bucket,
+// *WebsiteNotConfiguredError is returned if the bucket has no website configuration.
+func (cli *ClientV2) GetBucketWebsite(ctx context.Context, input *GetBucketWebsiteInput) (*GetBucketWebsiteOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("website", "").
+		WithRetry(nil, StatusCodeClassifier{}).
+		Request(ctx, http.MethodGet, nil, cli.roundTripper(http.StatusOK))
+	if err != nil {
+		return nil, newWebsiteNotConfiguredError(err)
+	}
+	defer res.Close()
+	output := GetBucketWebsiteOutput{RequestInfo: res.RequestInfo()}
+	if err = marshalOutput(output.RequestID, res.Body, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// DeleteBucketWebsite delete static website configuration of bucket
+func (cli *ClientV2) DeleteBucketWebsite(ctx context.Context, input *DeleteBucketWebsiteInput) (*DeleteBucketWebsiteOutput, error) {
+	if input == nil {
+		return nil, InputIsNilClientError
+	}
+	if err := IsValidBucketName(input.Bucket); err != nil {
+		return nil, err
+	}
+	res, err := cli.newBuilder(input.Bucket, "").
+		WithQuery("website", "").
+		Request(ctx, http.MethodDelete, nil, cli.roundTripper(http.StatusNoContent))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return &DeleteBucketWebsiteOutput{RequestInfo: res.RequestInfo()}, nil
+}
@@ -0,0 +1,87 @@
+package tos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/codes"
+	"github.com/volcengine/ve-tos-golang-sdk/v2/tos/enum"
+)
+
+// websiteConfiguration is the serialized form of the index, error document and routing rules in TestBucketWebsite
+const websiteConfiguration = `{"IndexDocument":{"Suffix":"index.html","ForbiddenSubDir":true},"ErrorDocument":{"Key":"error.html"},
+	"RoutingRules":[{"Condition":{"KeyPrefixEquals":"docs/","HttpErrorCodeReturnedEquals":404},
+	"Redirect":{"Protocol":"https","HostName":"example.com","ReplaceKeyPrefixWith":"documents/","HttpRedirectCode":301}}]}`
+
+func TestBucketWebsite(t *testing.T) {
+	noSuchWebsite := newMockResponse(http.StatusNotFound, `{"Code":"NoSuchWebsiteConfiguration"}`)
+	transport := &mockTransport{responses: []func() *Response{
+		noSuchWebsite,
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, websiteConfiguration),
+		newMockResponse(http.StatusOK, ""),
+		newMockResponse(http.StatusOK, `{"RedirectAllRequestsTo":{"HostName":"example.com","Protocol":"https"}}`),
+		newMockResponse(http.StatusNoContent, ""),
+		noSuchWebsite,
+	}}
+	client := newMockClient(t, transport)
+	ctx := context.Background()
+
+	_, err := client.GetBucketWebsite(ctx, &GetBucketWebsiteInput{Bucket: "bucket"})
+	notConfigured, ok := err.(*WebsiteNotConfiguredError)
+	require.True(t, ok)
+	require.Equal(t, codes.NoSuchWebsiteConfiguration, notConfigured.Code)
+	require.Equal(t, codes.NoSuchWebsiteConfiguration, Code(err))
+	require.Equal(t, http.StatusNotFound, StatusCode(err))
+	require.Equal(t, "request-id", RequestID(err))
+
+	input := &PutBucketWebsiteInput{
+		Bucket:        "bucket",
+		IndexDocument: &IndexDocument{Suffix: "index.html", ForbiddenSubDir: true},
+		ErrorDocument: &ErrorDocument{Key: "error.html"},
+		RoutingRules: []RoutingRule{{
+			Condition: RoutingRuleCondition{KeyPrefixEquals: "docs/", HttpErrorCodeReturnedEquals: http.StatusNotFound},
+			Redirect: RoutingRuleRedirect{Protocol: enum.ProtocolHttps, HostName: "example.com",
+				ReplaceKeyPrefixWith: "documents/", HttpRedirectCode: http.StatusMovedPermanently},
+		}},
+	}
+	_, err = client.PutBucketWebsite(ctx, input)
+	require.Nil(t, err)
+	require.JSONEq(t, websiteConfiguration, requestBody(t, transport.recorded()[1]))
+
+	get, err := client.GetBucketWebsite(ctx, &GetBucketWebsiteInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, input.IndexDocument, get.IndexDocument)
+	require.Equal(t, input.ErrorDocument, get.ErrorDocument)
+	require.Equal(t, input.RoutingRules, get.RoutingRules)
+	require.Nil(t, get.RedirectAllRequestsTo)
+
+	_, err = client.PutBucketWebsite(ctx, &PutBucketWebsiteInput{Bucket: "bucket",
+		RedirectAllRequestsTo: &RedirectAllRequestsTo{HostName: "example.com", Protocol: enum.ProtocolHttps}})
+	require.Nil(t, err)
+	require.JSONEq(t, `{"RedirectAllRequestsTo":{"HostName":"example.com","Protocol":"https"}}`, requestBody(t, transport.recorded()[3]))
+	get, err = client.GetBucketWebsite(ctx, &GetBucketWebsiteInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	require.Equal(t, "example.com", get.RedirectAllRequestsTo.HostName)
+
+	_, err = client.DeleteBucketWebsite(ctx, &DeleteBucketWebsiteInput{Bucket: "bucket"})
+	require.Nil(t, err)
+	_, err = client.GetBucketWebsite(ctx, &GetBucketWebsiteInput{Bucket: "bucket"})
+	_, ok = err.(*WebsiteNotConfiguredError)
+	require.True(t, ok)
+
+	requests := len(transport.recorded())
+	for _, invalid := range []*PutBucketWebsiteInput{
+		{Bucket: "bucket"},
+		{Bucket: "bucket", IndexDocument: &IndexDocument{Suffix: "dir/index.html"}},
+		{Bucket: "bucket", RedirectAllRequestsTo: &RedirectAllRequestsTo{HostName: "example.com"}, IndexDocument: &IndexDocument{Suffix: "index.html"}},
+		{Bucket: "bucket", RedirectAllRequestsTo: &RedirectAllRequestsTo{HostName: "example.com", Protocol: "ftp"}},
+		{Bucket: "bucket", IndexDocument: &IndexDocument{Suffix: "index.html"}, RoutingRules: []RoutingRule{{}}},
+	} {
+		_, err = client.PutBucketWebsite(ctx, invalid)
+		require.NotNil(t, err)
+	}
+	require.Equal(t, requests, len(transport.recorded()))
+}